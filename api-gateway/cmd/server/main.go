@@ -1,19 +1,62 @@
 package main
 
 import (
-	"auth-service/internal/service"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"auth-service/pkg/authpb"
 
 	"api-gateway/internal/infrastructure"
 )
 
 func main() {
-	// Здесь ты создаёшь сервис авторизации
-	// Это пример, в реальности ты можешь подставить нужную инициализацию
-	authService := &service.AuthServiceImpl{}
+	authAddr := os.Getenv("AUTH_GRPC_ADDR")
+	if authAddr == "" {
+		authAddr = "localhost:9081"
+	}
+
+	interceptors := []grpc.UnaryClientInterceptor{infrastructure.RequestContextInterceptor}
+	if token := os.Getenv("AUTH_GRPC_TOKEN"); token != "" {
+		interceptors = append(interceptors, infrastructure.ServiceTokenInterceptor(token))
+	}
+
+	conn, err := grpc.NewClient(authAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create auth-service client: %v", err)
+	}
+	defer conn.Close()
+
+	authClient := authpb.NewAuthServiceClient(conn)
+
+	userServiceURL, err := url.Parse(os.Getenv("USER_SERVICE_URL"))
+	if err != nil || userServiceURL.Host == "" {
+		userServiceURL = &url.URL{Scheme: "http", Host: "localhost:8082"}
+	}
+
+	authServiceURL, err := url.Parse(os.Getenv("AUTH_SERVICE_URL"))
+	if err != nil || authServiceURL.Host == "" {
+		authServiceURL = &url.URL{Scheme: "http", Host: "localhost:8081"}
+	}
+
+	mapServiceURL, err := url.Parse(os.Getenv("MAP_SERVICE_URL"))
+	if err != nil || mapServiceURL.Host == "" {
+		mapServiceURL = &url.URL{Scheme: "http", Host: "localhost:8083"}
+	}
+
+	gatewayToken := os.Getenv("GATEWAY_TOKEN")
+	if gatewayToken == "" {
+		log.Println("GATEWAY_TOKEN is not set, services cannot verify forwarded user headers")
+	}
 
-	router := infrastructure.SetupRouter(authService)
+	router := infrastructure.SetupRouter(authClient, userServiceURL, authServiceURL, mapServiceURL, gatewayToken)
 
 	log.Println("API Gateway starting on :8080")
 	if err := http.ListenAndServe(":8080", router); err != nil {
@@ -3,6 +3,20 @@ module api-gateway
 go 1.22
 
 require (
+	auth-service v0.0.0
 	github.com/go-chi/chi/v5 v5.0.12
 	github.com/go-chi/cors v1.2.1
+	github.com/google/uuid v1.6.0
+	google.golang.org/grpc v1.64.1
 )
+
+require (
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+replace auth-service => ../auth-service
+replace messaging => ../messaging
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"auth-service/pkg/authpb"
+)
+
+const (
+	requestIDHeader    = "X-Request-ID"
+	tenantHeader       = "X-Tenant"
+	serviceTokenHeader = "X-Service-Token"
+	gatewayTokenHeader = "X-Gateway-Token"
+)
+
+const requestIDContextKey contextKey = "request_id"
+
+func requestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+func userFrom(ctx context.Context) *authpb.User {
+	user, _ := ctx.Value(userContextKey).(*authpb.User)
+	return user
+}
+
+// requestID присваивает запросу идентификатор, который дальше уходит во все сервисы
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDContextKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestContextInterceptor передаёт идентификатор запроса и тенант в метаданных gRPC-вызовов
+func RequestContextInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := requestIDFrom(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, requestIDHeader, id)
+	}
+	if user := userFrom(ctx); user != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, tenantHeader, user.GetTenant())
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// ServiceTokenInterceptor подписывает каждый gRPC-вызов общим токеном, который проверяет auth-service
+func ServiceTokenInterceptor(token string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, serviceTokenHeader, token)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
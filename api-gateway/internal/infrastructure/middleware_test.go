@@ -0,0 +1,50 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"auth-service/pkg/authpb"
+)
+
+// outgoingMetadata прогоняет вызов через цепочку клиентских интерцепторов шлюза и возвращает метаданные,
+// с которыми он ушёл бы в auth-service
+func outgoingMetadata(t *testing.T, ctx context.Context, interceptors ...grpc.UnaryClientInterceptor) metadata.MD {
+	t.Helper()
+	var sent metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	if err := invoker(ctx, "/auth.AuthService/ValidateToken", nil, nil, nil); err != nil {
+		t.Fatalf("invoke: %v", err)
+	}
+	return sent
+}
+
+func TestClientInterceptors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDContextKey, "req-1")
+	ctx = context.WithValue(ctx, userContextKey, &authpb.User{Id: 1, Tenant: "north"})
+
+	md := outgoingMetadata(t, ctx, RequestContextInterceptor, ServiceTokenInterceptor("gateway-token"))
+	for header, want := range map[string]string{requestIDHeader: "req-1", tenantHeader: "north", serviceTokenHeader: "gateway-token"} {
+		if got := md.Get(header); len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %v, want %q", header, got, want)
+		}
+	}
+
+	// Без пользователя и идентификатора запроса уходит только токен сервиса
+	md = outgoingMetadata(t, context.Background(), RequestContextInterceptor, ServiceTokenInterceptor("gateway-token"))
+	if len(md.Get(tenantHeader)) != 0 || len(md.Get(requestIDHeader)) != 0 || len(md.Get(serviceTokenHeader)) != 1 {
+		t.Errorf("metadata = %v, want only the service token", md)
+	}
+}
@@ -0,0 +1,39 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+)
+
+// Заголовки, которые сервисы принимают только от шлюза
+var forwardedUserHeaders = []string{"X-User-ID", "X-User-Email", "X-User-Role", "X-User-Identity-Key", gatewayTokenHeader}
+
+// newServiceProxy проксирует запрос в сервис; gatewayToken подтверждает сервису заголовки пользователя
+func newServiceProxy(target *url.URL, gatewayToken string) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+
+		for _, header := range forwardedUserHeaders {
+			r.Header.Del(header)
+		}
+
+		r.Header.Set(requestIDHeader, requestIDFrom(r.Context()))
+		if gatewayToken != "" {
+			r.Header.Set(gatewayTokenHeader, gatewayToken)
+		}
+		if user := userFrom(r.Context()); user != nil {
+			r.Header.Set(tenantHeader, user.GetTenant())
+			r.Header.Set("X-User-ID", strconv.FormatUint(user.GetId(), 10))
+			r.Header.Set("X-User-Email", user.GetEmail())
+			r.Header.Set("X-User-Role", user.GetRole())
+			if identityKey := user.GetIdentityKey(); identityKey != "" {
+				r.Header.Set("X-User-Identity-Key", identityKey)
+			}
+		}
+	}
+	return proxy
+}
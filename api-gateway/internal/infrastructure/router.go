@@ -4,52 +4,120 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"auth-service/pkg/authpb"
 )
 
+type contextKey string
+
+const userContextKey contextKey = "user"
+
 type AuthHandler struct {
-	authService *usecase.AuthServiceImpl
+	authClient authpb.AuthServiceClient
 }
 
-func NewAuthHandler(authService *usecase.AuthServiceImpl) *AuthHandler {
-	return &AuthHandler{authService: authService}
+type registerRequest struct {
+	Tenant       string `json:"tenant"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	Role         string `json:"role"`
+	ReferralCode string `json:"referral_code"`
+	DeviceID     string `json:"device_id"`
 }
 
-func SetupRouter(authService *usecase.AuthServiceImpl) http.Handler {
+type loginRequest struct {
+	Tenant   string `json:"tenant"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	Token string `json:"token"`
+}
+
+func NewAuthHandler(authClient authpb.AuthServiceClient) *AuthHandler {
+	return &AuthHandler{authClient: authClient}
+}
+
+func SetupRouter(authClient authpb.AuthServiceClient, userServiceURL, authServiceURL, mapServiceURL *url.URL, gatewayToken string) http.Handler {
 	r := chi.NewRouter()
 
+	r.Use(requestID)
+
 	// CORS
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "Last-Event-ID", "X-Tenant", "X-Request-ID"},
+		ExposedHeaders:   []string{"ETag", "X-Request-ID"},
 		AllowCredentials: true,
 	}))
 
 	// Rate Limiting
 	r.Use(rateLimiter)
 
-	authHandler := NewAuthHandler(authService)
+	authHandler := NewAuthHandler(authClient)
+	userProxy := newServiceProxy(userServiceURL, gatewayToken)
+	// Принятие приглашения есть только в HTTP API auth-service, там путь без префикса /auth
+	authProxy := http.StripPrefix("/auth", newServiceProxy(authServiceURL, gatewayToken))
+	// У map-service свои пути без префикса /map
+	mapProxy := http.StripPrefix("/map", newServiceProxy(mapServiceURL, gatewayToken))
 
 	// Public routes
 	r.Route("/auth", func(r chi.Router) {
 		r.Post("/register", authHandler.Register)
 		r.Post("/login", authHandler.Login)
+		r.Post("/refresh", authHandler.Refresh)
+		r.Post("/invitations/accept", authProxy.ServeHTTP)
 	})
 
+	// Миниатюры аватаров публичные: тег img не передаёт токен, а адреса картинок случайные
+	r.Get("/avatars/*", userProxy.ServeHTTP)
+
 	// Protected routes
 	r.Group(func(r chi.Router) {
-		r.Use(jwtAuthentication(authService))
+		r.Use(jwtAuthentication(authClient))
 
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/me", authHandler.Me)
+			r.Handle("/", userProxy)
+			r.Handle("/*", userProxy)
+		})
+
+		r.Route("/households", func(r chi.Router) {
+			r.Handle("/", userProxy)
+			r.Handle("/*", userProxy)
+		})
+
+		r.Route("/collectors", func(r chi.Router) {
+			r.Handle("/", userProxy)
+			r.Handle("/*", userProxy)
+		})
+
+		r.Route("/imports", func(r chi.Router) {
+			r.Handle("/", userProxy)
+			r.Handle("/*", userProxy)
+		})
+
+		r.Route("/referrals", func(r chi.Router) {
+			r.Handle("/*", userProxy)
+		})
+
+		r.Route("/stats", func(r chi.Router) {
+			r.Handle("/*", userProxy)
 		})
 
 		r.Route("/map", func(r chi.Router) {
-			// r.Get("/points", getPointsHandler())
+			// Зоны обслуживания принадлежат сборщикам и выгружаются из user-service
+			r.Get("/zones/export", http.StripPrefix("/map", userProxy).ServeHTTP)
+			r.Handle("/*", mapProxy)
 		})
 	})
 
@@ -57,15 +125,22 @@ func SetupRouter(authService *usecase.AuthServiceImpl) http.Handler {
 }
 
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
-	var req usecase.RegisterRequest
+	var req registerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := h.authService.Register(r.Context(), req)
+	resp, err := h.authClient.Register(r.Context(), &authpb.RegisterRequest{
+		Tenant:       tenantOf(r, req.Tenant),
+		Email:        req.Email,
+		Password:     req.Password,
+		Role:         req.Role,
+		ReferralCode: req.ReferralCode,
+		DeviceId:     req.DeviceID,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeGRPCError(w, err)
 		return
 	}
 
@@ -73,15 +148,35 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	var req usecase.LoginRequest
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.Login(r.Context(), &authpb.LoginRequest{
+		Tenant:   tenantOf(r, req.Tenant),
+		Email:    req.Email,
+		Password: req.Password,
+	})
+	if err != nil {
+		writeGRPCError(w, err)
+		return
+	}
+
+	writeJSON(w, resp, http.StatusOK)
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resp, err := h.authService.Login(r.Context(), req)
+	resp, err := h.authClient.Refresh(r.Context(), &authpb.RefreshRequest{Token: req.Token})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeGRPCError(w, err)
 		return
 	}
 
@@ -89,16 +184,45 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
-	user := r.Context().Value("user")
+	user := r.Context().Value(userContextKey)
 	writeJSON(w, user, http.StatusOK)
 }
 
+func tenantOf(r *http.Request, bodyTenant string) string {
+	if bodyTenant != "" {
+		return bodyTenant
+	}
+	return r.Header.Get("X-Tenant")
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+func writeGRPCError(w http.ResponseWriter, err error) {
+	st, _ := status.FromError(err)
+
+	code := http.StatusBadGateway
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.AlreadyExists:
+		code = http.StatusConflict
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	}
+
+	http.Error(w, st.Message(), code)
+}
+
 func rateLimiter(next http.Handler) http.Handler {
 	// TODO: Реализовать лимит запросов через golang.org/x/time/rate
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -106,7 +230,7 @@ func rateLimiter(next http.Handler) http.Handler {
 	})
 }
 
-func jwtAuthentication(authService *usecase.AuthServiceImpl) func(http.Handler) http.Handler {
+func jwtAuthentication(authClient authpb.AuthServiceClient) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -116,13 +240,20 @@ func jwtAuthentication(authService *usecase.AuthServiceImpl) func(http.Handler)
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			user, err := authService.ValidateToken(tokenString)
+			user, err := authClient.ValidateToken(r.Context(), &authpb.ValidateTokenRequest{
+				Token:  tokenString,
+				Tenant: r.Header.Get("X-Tenant"),
+			})
 			if err != nil {
+				if status.Code(err) == codes.PermissionDenied {
+					http.Error(w, "cross-tenant access denied", http.StatusForbidden)
+					return
+				}
 				http.Error(w, "invalid token", http.StatusUnauthorized)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "user", user)
+			ctx := context.WithValue(r.Context(), userContextKey, user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
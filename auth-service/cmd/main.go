@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
+	"auth-service/internal/events"
+	"auth-service/internal/mail"
 	"auth-service/internal/repo"
 	"auth-service/internal/server"
+	"messaging/outbox"
 )
 
 func main() {
@@ -23,7 +28,14 @@ func main() {
 	}
 	defer logger.Sync()
 
-	db, err := repo.NewPostgresDatabase(logger)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(logger, os.Args[2:]); err != nil {
+			logger.Fatal("Migration command failed", zap.Error(err))
+		}
+		return
+	}
+
+	db, err := repo.NewDatabase(logger)
 	if err != nil {
 		logger.Fatal("Failed to connect to database", zap.Error(err))
 	}
@@ -33,7 +45,55 @@ func main() {
 		logger.Fatal("JWT_SECRET is not set")
 	}
 
-	authServer := server.NewAuthServer(db, jwtSecret, logger)
+	platformAdminKey := os.Getenv("PLATFORM_ADMIN_KEY")
+	if platformAdminKey == "" {
+		logger.Warn("PLATFORM_ADMIN_KEY is not set, tenant management is disabled")
+	}
+
+	// События об учётных записях копятся в outbox, пока транспорт не настроен
+	if publisher := events.NewPublisher(logger); publisher != nil {
+		relay := outbox.NewRelay(db.Outbox(), publisher, outbox.RelayOptions{Logger: zap.NewStdLog(logger)})
+		go relay.Run(context.Background())
+	} else {
+		logger.Warn("Neither REDIS_ADDR nor USER_EVENTS_URL is set, account events stay in the outbox")
+	}
+
+	invitations := server.InvitationConfig{
+		Secret:    os.Getenv("INVITATIONS_SECRET"),
+		AcceptURL: os.Getenv("INVITATION_ACCEPT_URL"),
+		Mailer:    mail.NewMailer(logger),
+	}
+	if invitations.Secret == "" {
+		logger.Warn("INVITATIONS_SECRET is not set, invitations from other services are disabled")
+	}
+	if invitations.AcceptURL == "" {
+		invitations.AcceptURL = "http://localhost:8080/auth/invitations/accept"
+	}
+
+	authServer := server.NewAuthServer(db, jwtSecret, platformAdminKey, invitations, logger)
+
+	grpcPort := os.Getenv("AUTH_SERVICE_GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9081"
+	}
+
+	// Без токена gRPC-порт нельзя открывать наружу: GetUser отдаёт учётные записи без проверки вызывающего
+	grpcToken := os.Getenv("GRPC_SERVICE_TOKEN")
+	if grpcToken == "" {
+		logger.Warn("GRPC_SERVICE_TOKEN is not set, gRPC calls are not authenticated")
+	}
+
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		logger.Fatal("Failed to listen for gRPC", zap.Error(err), zap.String("port", grpcPort))
+	}
+
+	go func() {
+		logger.Info("Starting Authentication gRPC Service on :" + grpcPort)
+		if err := authServer.GRPC(grpcToken).Serve(grpcListener); err != nil {
+			logger.Fatal("gRPC server failed to start", zap.Error(err))
+		}
+	}()
 
 	logger.Info("Starting Authentication Service on :8081")
 	if err := http.ListenAndServe(":8081", authServer.Routes()); err != nil {
@@ -0,0 +1,81 @@
+// Package events выбирает, куда relay отправляет события об учётных записях
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"messaging"
+)
+
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// HTTPPublisher отправляет тело каждого сообщения POST-запросом, подписанным так же,
+// как этого ждёт приём событий в user-service: HMAC-SHA256 от "<timestamp>.<тело>".
+// Нужен там, где нет Redis.
+type HTTPPublisher struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+func NewHTTPPublisher(url, secret string) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, messages ...messaging.Message) error {
+	for _, message := range messages {
+		if err := p.post(ctx, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *HTTPPublisher) post(ctx context.Context, message messaging.Message) error {
+	body := []byte(message.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, p.sign(timestamp, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("event %s delivery failed: %w", message.ID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("event %s rejected with status %d: %s", message.ID, resp.StatusCode, bytes.TrimSpace(reason))
+	}
+	return nil
+}
+
+func (p *HTTPPublisher) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
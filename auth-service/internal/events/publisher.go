@@ -0,0 +1,26 @@
+package events
+
+import (
+	"os"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"messaging"
+	"messaging/redisstream"
+)
+
+// NewPublisher выбирает транспорт по окружению: Redis Streams при REDIS_ADDR,
+// иначе подписанный HTTP на USER_EVENTS_URL. Без обоих возвращает nil, и события копятся в outbox.
+func NewPublisher(logger *zap.Logger) messaging.Publisher {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		client := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("REDIS_PASSWORD")})
+		logger.Info("Publishing account events to Redis Streams", zap.String("addr", addr))
+		return redisstream.New(client, redisstream.Options{Logger: zap.NewStdLog(logger)})
+	}
+	if url := os.Getenv("USER_EVENTS_URL"); url != "" {
+		logger.Info("Publishing account events over HTTP", zap.String("url", url))
+		return NewHTTPPublisher(url, os.Getenv("USER_EVENTS_SECRET"))
+	}
+	return nil
+}
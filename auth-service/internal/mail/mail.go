@@ -0,0 +1,73 @@
+// Package mail отправляет письма пользователям: через SMTP, если он настроен, иначе только в лог
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+// NewMailer берёт настройки из SMTP_ADDR (host:port), SMTP_USER, SMTP_PASSWORD и SMTP_FROM
+func NewMailer(logger *zap.Logger) model.Mailer {
+	addr := os.Getenv("SMTP_ADDR")
+	if addr == "" {
+		logger.Warn("SMTP_ADDR is not set, emails are written to the log only")
+		return &LogMailer{logger: logger}
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	mailer := &SMTPMailer{addr: addr, from: from}
+	if user := os.Getenv("SMTP_USER"); user != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		mailer.auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+	}
+	logger.Info("Sending emails via SMTP", zap.String("addr", addr), zap.String("from", from))
+	return mailer
+}
+
+// LogMailer нужен для разработки: письмо целиком попадает в лог
+type LogMailer struct {
+	logger *zap.Logger
+}
+
+func (m *LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	requestctx.Logger(ctx, m.logger).Info("Email not sent, SMTP is not configured", zap.String("to", to), zap.String("subject", subject), zap.String("body", body))
+	return nil
+}
+
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func (m *SMTPMailer) Send(_ context.Context, to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") || strings.ContainsAny(subject, "\r\n") {
+		return fmt.Errorf("invalid email header")
+	}
+
+	message := strings.Join([]string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("send email to %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"messaging"
+)
+
+// Топик, в который публикуются события об учётных записях
+const TopicAccounts = "accounts"
+
+// События об учётных записях, которые получают другие сервисы
+const (
+	EventUserRegistered   = "user.registered"
+	EventUserEmailChanged = "user.email_changed"
+	EventUserRoleChanged  = "user.role_changed"
+	EventUserDeleted      = "user.deleted"
+)
+
+// ReferralClaim — код пригласившего и устройство, которые житель указал при регистрации
+type ReferralClaim struct {
+	Code     string `json:"code"`
+	DeviceID string `json:"device_id,omitempty"`
+}
+
+// UserEvent — тело событий user.*. В нём всегда полное состояние учётной записи,
+// поэтому получатель может применить любое событие, не зная предыдущих.
+// ID и Type повторяют поля сообщения, чтобы тело можно было отправить и без конверта.
+// Version — версия учётной записи на момент события: событие с меньшей версией устарело.
+// Referral бывает только в user.registered.
+type UserEvent struct {
+	ID          string         `json:"id"`
+	Type        string         `json:"type"`
+	IdentityKey string         `json:"identity_key"`
+	AuthUserID  uint           `json:"auth_user_id"`
+	Tenant      string         `json:"tenant"`
+	Email       string         `json:"email"`
+	Role        UserRole       `json:"role"`
+	Version     int64          `json:"version"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Referral    *ReferralClaim `json:"referral,omitempty"`
+}
+
+// NewUserEvent снимает состояние пользователя в сообщение для outbox; user.Tenant должен быть загружен
+func NewUserEvent(eventType string, user *User) (messaging.Message, error) {
+	message, err := messaging.NewMessage(TopicAccounts, eventType, user.IdentityKey, nil)
+	if err != nil {
+		return messaging.Message{}, err
+	}
+
+	event := UserEvent{
+		ID:          message.ID,
+		Type:        eventType,
+		IdentityKey: user.IdentityKey,
+		AuthUserID:  user.ID,
+		Tenant:      user.Tenant.Slug,
+		Email:       user.Email,
+		Role:        user.Role,
+		Version:     user.AccountVersion,
+		OccurredAt:  message.OccurredAt,
+	}
+	if eventType == EventUserRegistered {
+		event.Referral = user.Referral
+	}
+	message.Payload, err = json.Marshal(event)
+	return message, err
+}
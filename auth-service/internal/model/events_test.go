@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Код пригласившего нужен user-service только при создании профиля, поэтому он есть лишь в user.registered
+func TestNewUserEventReferral(t *testing.T) {
+	user := &User{IdentityKey: "2f1c7a52-6d4e-4b8a-9f3e-1c2d3e4f5a6b", Tenant: Tenant{Slug: "default"}, Email: "resident@example.com", Role: RoleUser,
+		Referral: &ReferralClaim{Code: "ABCD1234", DeviceID: "phone-1"}}
+
+	tests := []struct {
+		eventType    string
+		wantReferral bool
+	}{
+		{EventUserRegistered, true},
+		{EventUserEmailChanged, false},
+		{EventUserRoleChanged, false},
+	}
+	for _, tt := range tests {
+		message, err := NewUserEvent(tt.eventType, user)
+		if err != nil {
+			t.Fatalf("NewUserEvent(%s): %v", tt.eventType, err)
+		}
+		var event UserEvent
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		if got := event.Referral != nil && *event.Referral == *user.Referral; got != tt.wantReferral {
+			t.Fatalf("%s referral = %+v, want present: %v", tt.eventType, event.Referral, tt.wantReferral)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invitation — приглашение завести учётную запись с заранее известным email.
+// Хранится только SHA-256 токена: сам токен есть лишь в письме.
+// IdentityKey получит учётная запись после принятия: по нему user-service узнаёт заранее заведённый профиль.
+type Invitation struct {
+	gorm.Model
+	TenantID    uint     `gorm:"not null;index:idx_invitations_tenant_email"`
+	Tenant      Tenant   `json:"-"`
+	Email       string   `gorm:"not null;index:idx_invitations_tenant_email"`
+	Role        UserRole `gorm:"not null;default:'user'"`
+	TokenHash   string   `json:"-" gorm:"not null;uniqueIndex"`
+	IdentityKey string   `gorm:"type:uuid"`
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time
+}
+
+type InvitationRepository interface {
+	CreateInvitation(ctx context.Context, invitation *Invitation) error
+	// FindPendingInvitation ищет непринятое и неистёкшее приглашение на email
+	FindPendingInvitation(ctx context.Context, tenantID uint, email string, now time.Time) (*Invitation, error)
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	// AcceptInvitation создаёт пользователя и отмечает приглашение принятым в одной транзакции;
+	// событие user.registered пишется так же, как в UserRepository.Create
+	AcceptInvitation(ctx context.Context, invitation *Invitation, user *User) error
+}
+
+type InvitationService interface {
+	// Invite создаёт приглашение и отправляет письмо; если приглашение уже ждёт ответа, повторно не отправляет.
+	// identityKey — ключ будущей учётной записи; пустой означает новый случайный.
+	Invite(ctx context.Context, tenantSlug, email, name, identityKey string) (invitation *Invitation, created bool, err error)
+	AcceptInvitation(ctx context.Context, token, password string) (*User, error)
+}
+
+// Mailer отправляет письма; реализация выбирается в main
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+const DefaultTenantSlug = "default"
+
+type Tenant struct {
+	gorm.Model
+	Slug string `gorm:"uniqueIndex;not null"`
+	Name string `gorm:"not null"`
+}
+
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant *Tenant) error
+	FindTenantBySlug(ctx context.Context, slug string) (*Tenant, error)
+	FindTenantByID(ctx context.Context, tenantID uint) (*Tenant, error)
+}
+
+type TenantService interface {
+	CreateTenant(ctx context.Context, slug, name string) (*Tenant, error)
+	ResolveTenant(ctx context.Context, slug string) (*Tenant, error)
+}
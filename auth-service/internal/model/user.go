@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,23 +15,50 @@ const (
 	RoleCollector UserRole = "collector"
 )
 
+// IdentityKey — постоянный идентификатор учётной записи, по которому её узнают другие сервисы;
+// в отличие от ID он не зависит от последовательности в базе auth-service.
+// PasswordHash не сериализуется: обработчики отдают User в ответах как есть
 type User struct {
 	gorm.Model
-	Email        string   `gorm:"unique;not null"`
-	PasswordHash string   `gorm:"not null"`
+	IdentityKey  string   `gorm:"type:uuid;uniqueIndex"`
+	TenantID     uint     `gorm:"not null;uniqueIndex:idx_users_tenant_email"`
+	Tenant       Tenant   `json:"-"`
+	Email        string   `gorm:"not null;uniqueIndex:idx_users_tenant_email"`
+	PasswordHash string   `json:"-" gorm:"not null"`
 	Role         UserRole `gorm:"not null;default:'user'"`
 	LastLogin    time.Time
 	ProfileImage string
+	// AccountVersion растёт при каждом изменении email или роли и уходит в события user.*,
+	// чтобы получатели могли отбросить устаревшие события
+	AccountVersion int64 `gorm:"not null;default:1"`
+	// Referral не хранится: код пригласившего из регистрации уходит только в событие user.registered
+	Referral *ReferralClaim `json:"-" gorm:"-"`
 }
 
+// Create, UpdateAccount и Delete пишут события user.* в outbox в той же транзакции, что и изменение
 type UserRepository interface {
-	Create(user *User) error
-	FindByEmail(email string) (*User, error)
-	UpdateLastLogin(userID uint) error
+	Create(ctx context.Context, user *User) error
+	FindByEmail(ctx context.Context, tenantID uint, email string) (*User, error)
+	FindByID(ctx context.Context, tenantID, userID uint) (*User, error)
+	UpdateLastLogin(ctx context.Context, tenantID, userID uint) error
+	// UpdateAccount сохраняет email и роль и записывает по событию на каждый тип из eventTypes
+	UpdateAccount(ctx context.Context, user *User, eventTypes ...string) error
+	Delete(ctx context.Context, user *User) error
 }
 
 type AuthService interface {
-	Register(email, password string, role UserRole) (*User, error)
-	Login(email, password string) (string, error)
-	ValidateToken(tokenString string) (*User, error)
+	// Register принимает только роль user; админов заводит CreateAdmin по ключу платформы.
+	// referral может быть nil, если житель пришёл без приглашения
+	Register(ctx context.Context, tenantSlug, email, password string, role UserRole, referral *ReferralClaim) (*User, error)
+	CreateAdmin(ctx context.Context, tenantSlug, email, password string) (*User, error)
+	Login(ctx context.Context, tenantSlug, email, password string) (string, error)
+	ValidateToken(ctx context.Context, tokenString string) (*User, error)
+	Refresh(ctx context.Context, tokenString string) (string, error)
+	GetUser(ctx context.Context, tenantSlug string, userID uint) (*User, error)
+	GetUserByEmail(ctx context.Context, tenantSlug, email string) (*User, error)
+	AuthorizeTenant(ctx context.Context, user *User, tenantSlug string) error
+	// ChangeEmail, ChangeRole и DeleteUser выполняются от имени actor: себя может менять каждый, других — только админ
+	ChangeEmail(ctx context.Context, actor *User, userID uint, email string) (*User, error)
+	ChangeRole(ctx context.Context, actor *User, userID uint, role UserRole) (*User, error)
+	DeleteUser(ctx context.Context, actor *User, userID uint) error
 }
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Обработчики регистрации, смены email и роли и принятия приглашения кодируют User напрямую
+func TestUserJSONOmitsPasswordHash(t *testing.T) {
+	user := User{IdentityKey: "2f1c7a52-6d4e-4b8a-9f3e-1c2d3e4f5a6b", Email: "resident@example.com", PasswordHash: "$2a$10$secret", Role: RoleUser}
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if strings.Contains(string(data), "PasswordHash") || strings.Contains(string(data), "$2a$10$secret") {
+		t.Fatalf("User JSON %s exposes the password hash", data)
+	}
+	if !strings.Contains(string(data), "resident@example.com") {
+		t.Fatalf("User JSON %s, want the email", data)
+	}
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"auth-service/internal/model"
+	"messaging/outbox"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
+type Database interface {
+	model.UserRepository
+	model.TenantRepository
+	model.InvitationRepository
+	// Outbox — события user.*, записанные вместе с изменениями пользователей
+	Outbox() outbox.Store
+}
+
+// NewDatabase выбирает хранилище по DB_DRIVER; SQLite и память нужны для тестов и локальной разработки
+func NewDatabase(logger *zap.Logger) (Database, error) {
+	driver := os.Getenv("DB_DRIVER")
+	switch driver {
+	case "", DriverPostgres:
+		return NewPostgresDatabase(logger)
+	case DriverSQLite:
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "auth-service.db"
+		}
+		return NewSQLiteDatabase(path, logger)
+	case DriverMemory:
+		logger.Warn("Using in-memory database, data will be lost on restart")
+		return NewMemoryDatabase(), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+func (pd *PostgresDatabase) CreateInvitation(ctx context.Context, invitation *model.Invitation) error {
+	if err := pd.DB.WithContext(ctx).Create(invitation).Error; err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to create invitation", zap.Error(err), zap.String("email", invitation.Email))
+		return fmt.Errorf("invitation creation failed: %w", err)
+	}
+	return nil
+}
+
+func (pd *PostgresDatabase) FindPendingInvitation(ctx context.Context, tenantID uint, email string, now time.Time) (*model.Invitation, error) {
+	var invitation model.Invitation
+	result := pd.DB.WithContext(ctx).
+		Where("tenant_id = ? AND email = ? AND accepted_at IS NULL AND expires_at > ?", tenantID, email, now).
+		Order("expires_at DESC").First(&invitation)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, result.Error
+		}
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find pending invitation", zap.Error(result.Error), zap.String("email", email))
+		return nil, fmt.Errorf("invitation lookup failed: %w", result.Error)
+	}
+	return &invitation, nil
+}
+
+func (pd *PostgresDatabase) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	result := pd.DB.WithContext(ctx).Preload("Tenant").Where("token_hash = ?", tokenHash).First(&invitation)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			requestctx.Logger(ctx, pd.logger).Info("Invitation not found")
+			return nil, result.Error
+		}
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find invitation", zap.Error(result.Error))
+		return nil, fmt.Errorf("invitation lookup failed: %w", result.Error)
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation помечает приглашение условием accepted_at IS NULL, так что принять его можно только один раз
+func (pd *PostgresDatabase) AcceptInvitation(ctx context.Context, invitation *model.Invitation, user *model.User) error {
+	err := pd.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&model.Invitation{}).Where("id = ? AND accepted_at IS NULL", invitation.ID).Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		user.AccountVersion = 1
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		invitation.AcceptedAt = &now
+		return writeUserEvents(tx, user, model.EventUserRegistered)
+	})
+	if err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to accept invitation", zap.Error(err), zap.Uint("invitation_id", invitation.ID))
+		return fmt.Errorf("invitation acceptance failed: %w", err)
+	}
+	return nil
+}
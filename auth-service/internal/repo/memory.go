@@ -0,0 +1,294 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"messaging/outbox"
+)
+
+// MemoryDatabase хранит данные в памяти процесса и ведёт себя так же, как PostgresDatabase
+type MemoryDatabase struct {
+	mu           sync.RWMutex
+	users        map[uint]model.User
+	tenants      map[uint]model.Tenant
+	invitations  map[uint]model.Invitation
+	outbox       *outbox.MemoryStore
+	nextUserID   uint
+	nextTenantID uint
+	nextInviteID uint
+}
+
+func NewMemoryDatabase() *MemoryDatabase {
+	md := &MemoryDatabase{
+		users:       map[uint]model.User{},
+		tenants:     map[uint]model.Tenant{},
+		invitations: map[uint]model.Invitation{},
+		outbox:      outbox.NewMemoryStore(),
+	}
+	_ = md.CreateTenant(context.Background(), &model.Tenant{Slug: model.DefaultTenantSlug, Name: "Default"})
+	return md
+}
+
+func (md *MemoryDatabase) Create(_ context.Context, user *model.User) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	return md.create(user)
+}
+
+// create вызывается под блокировкой
+func (md *MemoryDatabase) create(user *model.User) error {
+	if _, ok := md.tenants[user.TenantID]; !ok {
+		return fmt.Errorf("user creation failed: tenant %d does not exist", user.TenantID)
+	}
+	for _, existing := range md.users {
+		if existing.TenantID == user.TenantID && existing.Email == user.Email {
+			return fmt.Errorf("user creation failed: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+
+	md.nextUserID++
+	now := time.Now()
+	user.ID = md.nextUserID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	user.AccountVersion = 1
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+
+	if err := md.appendEvents(user, model.EventUserRegistered); err != nil {
+		return fmt.Errorf("user creation failed: %w", err)
+	}
+	stored := *user
+	stored.Tenant = model.Tenant{}
+	md.users[user.ID] = stored
+	return nil
+}
+
+func (md *MemoryDatabase) FindByEmail(_ context.Context, tenantID uint, email string) (*model.User, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	for _, user := range md.users {
+		if user.TenantID == tenantID && user.Email == email {
+			return md.withTenant(user), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (md *MemoryDatabase) FindByID(_ context.Context, tenantID, userID uint) (*model.User, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	user, ok := md.users[userID]
+	if !ok || user.TenantID != tenantID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return md.withTenant(user), nil
+}
+
+func (md *MemoryDatabase) UpdateLastLogin(_ context.Context, tenantID, userID uint) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	user, ok := md.users[userID]
+	if !ok || user.TenantID != tenantID {
+		return nil
+	}
+	user.LastLogin = time.Now()
+	md.users[userID] = user
+	return nil
+}
+
+func (md *MemoryDatabase) UpdateAccount(_ context.Context, user *model.User, eventTypes ...string) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	stored, ok := md.users[user.ID]
+	if !ok || stored.TenantID != user.TenantID {
+		return fmt.Errorf("account update failed: %w", gorm.ErrRecordNotFound)
+	}
+	for _, existing := range md.users {
+		if existing.ID != user.ID && existing.TenantID == user.TenantID && existing.Email == user.Email {
+			return fmt.Errorf("account update failed: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+
+	user.AccountVersion = stored.AccountVersion + 1
+	if err := md.appendEvents(user, eventTypes...); err != nil {
+		return fmt.Errorf("account update failed: %w", err)
+	}
+	stored.Email = user.Email
+	stored.Role = user.Role
+	stored.AccountVersion = user.AccountVersion
+	stored.UpdatedAt = time.Now()
+	md.users[user.ID] = stored
+	return nil
+}
+
+func (md *MemoryDatabase) Delete(_ context.Context, user *model.User) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	stored, ok := md.users[user.ID]
+	if !ok || stored.TenantID != user.TenantID {
+		return fmt.Errorf("user deletion failed: %w", gorm.ErrRecordNotFound)
+	}
+	user.AccountVersion = stored.AccountVersion + 1
+	if err := md.appendEvents(user, model.EventUserDeleted); err != nil {
+		return fmt.Errorf("user deletion failed: %w", err)
+	}
+	delete(md.users, user.ID)
+	return nil
+}
+
+func (md *MemoryDatabase) Outbox() outbox.Store {
+	return md.outbox
+}
+
+func (md *MemoryDatabase) CreateTenant(_ context.Context, tenant *model.Tenant) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	for _, existing := range md.tenants {
+		if existing.Slug == tenant.Slug {
+			return fmt.Errorf("tenant creation failed: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+
+	md.nextTenantID++
+	now := time.Now()
+	tenant.ID = md.nextTenantID
+	tenant.CreatedAt = now
+	tenant.UpdatedAt = now
+	md.tenants[tenant.ID] = *tenant
+	return nil
+}
+
+func (md *MemoryDatabase) FindTenantBySlug(_ context.Context, slug string) (*model.Tenant, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	for _, tenant := range md.tenants {
+		if tenant.Slug == slug {
+			return &tenant, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (md *MemoryDatabase) FindTenantByID(_ context.Context, tenantID uint) (*model.Tenant, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	tenant, ok := md.tenants[tenantID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &tenant, nil
+}
+
+func (md *MemoryDatabase) CreateInvitation(_ context.Context, invitation *model.Invitation) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if _, ok := md.tenants[invitation.TenantID]; !ok {
+		return fmt.Errorf("invitation creation failed: tenant %d does not exist", invitation.TenantID)
+	}
+	for _, existing := range md.invitations {
+		if existing.TokenHash == invitation.TokenHash {
+			return fmt.Errorf("invitation creation failed: %w", gorm.ErrDuplicatedKey)
+		}
+	}
+
+	md.nextInviteID++
+	now := time.Now()
+	invitation.ID = md.nextInviteID
+	invitation.CreatedAt = now
+	invitation.UpdatedAt = now
+	if invitation.Role == "" {
+		invitation.Role = model.RoleUser
+	}
+	stored := *invitation
+	stored.Tenant = model.Tenant{}
+	md.invitations[invitation.ID] = stored
+	return nil
+}
+
+func (md *MemoryDatabase) FindPendingInvitation(_ context.Context, tenantID uint, email string, now time.Time) (*model.Invitation, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	var found *model.Invitation
+	for _, invitation := range md.invitations {
+		if invitation.TenantID != tenantID || invitation.Email != email || invitation.AcceptedAt != nil || !invitation.ExpiresAt.After(now) {
+			continue
+		}
+		if found == nil || invitation.ExpiresAt.After(found.ExpiresAt) {
+			invitation := invitation
+			found = &invitation
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+func (md *MemoryDatabase) FindInvitationByTokenHash(_ context.Context, tokenHash string) (*model.Invitation, error) {
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	for _, invitation := range md.invitations {
+		if invitation.TokenHash == tokenHash {
+			invitation.Tenant = md.tenants[invitation.TenantID]
+			return &invitation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (md *MemoryDatabase) AcceptInvitation(_ context.Context, invitation *model.Invitation, user *model.User) error {
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	stored, ok := md.invitations[invitation.ID]
+	if !ok || stored.AcceptedAt != nil {
+		return fmt.Errorf("invitation acceptance failed: %w", gorm.ErrRecordNotFound)
+	}
+	if err := md.create(user); err != nil {
+		return fmt.Errorf("invitation acceptance failed: %w", err)
+	}
+	now := time.Now()
+	stored.AcceptedAt = &now
+	md.invitations[invitation.ID] = stored
+	invitation.AcceptedAt = &now
+	return nil
+}
+
+// appendEvents вызывается под блокировкой, поэтому событие попадает в outbox вместе с изменением
+func (md *MemoryDatabase) appendEvents(user *model.User, eventTypes ...string) error {
+	snapshot := md.withTenant(*user)
+	for _, eventType := range eventTypes {
+		message, err := model.NewUserEvent(eventType, snapshot)
+		if err != nil {
+			return err
+		}
+		md.outbox.Add(message)
+	}
+	return nil
+}
+
+// withTenant повторяет Preload("Tenant") из PostgresDatabase
+func (md *MemoryDatabase) withTenant(user model.User) *model.User {
+	user.Tenant = md.tenants[user.TenantID]
+	return &user
+}
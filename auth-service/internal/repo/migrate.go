@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"embed"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"dbmigrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Ключ pg_advisory_xact_lock, чтобы две реплики не мигрировали одновременно
+const migrationLockKey = 817236001
+
+func NewMigrator(db *gorm.DB, logger *zap.Logger) *dbmigrate.Migrator {
+	return dbmigrate.New(db, migrationFiles, "auth-service", migrationLockKey, zap.NewStdLog(logger))
+}
@@ -0,0 +1,43 @@
+-- Базы, созданные до миграций, уже содержат users от AutoMigrate, но без tenant_id и с глобальным
+-- уникальным индексом на email: первая миграция дополняет таблицу до новой схемы
+CREATE TABLE IF NOT EXISTS tenants (
+    id         BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    slug       TEXT NOT NULL,
+    name       TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants (slug);
+CREATE INDEX IF NOT EXISTS idx_tenants_deleted_at ON tenants (deleted_at);
+
+-- Пользователи без явного тенанта попадают в тенант по умолчанию
+INSERT INTO tenants (created_at, updated_at, slug, name)
+SELECT NOW(), NOW(), 'default', 'Default'
+WHERE NOT EXISTS (SELECT 1 FROM tenants WHERE slug = 'default');
+
+CREATE TABLE IF NOT EXISTS users (
+    id            BIGSERIAL PRIMARY KEY,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ,
+    deleted_at    TIMESTAMPTZ,
+    tenant_id     BIGINT NOT NULL REFERENCES tenants (id),
+    email         TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role          TEXT NOT NULL DEFAULT 'user',
+    last_login    TIMESTAMPTZ,
+    profile_image TEXT
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id BIGINT REFERENCES tenants (id);
+UPDATE users SET tenant_id = (SELECT id FROM tenants WHERE slug = 'default') WHERE tenant_id IS NULL;
+ALTER TABLE users ALTER COLUMN tenant_id SET NOT NULL;
+
+-- AutoMigrate создавал уникальность email по всей базе; gorm разных версий называл её по-разному
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users (tenant_id, email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS outbox_events;
+DROP INDEX IF EXISTS idx_users_identity_key;
+ALTER TABLE users DROP COLUMN IF EXISTS identity_key;
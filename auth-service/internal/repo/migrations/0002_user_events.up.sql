@@ -0,0 +1,41 @@
+ALTER TABLE users ADD COLUMN identity_key UUID;
+UPDATE users SET identity_key = gen_random_uuid();
+CREATE UNIQUE INDEX idx_users_identity_key ON users (identity_key);
+
+CREATE TABLE outbox_events (
+    id           BIGSERIAL PRIMARY KEY,
+    event_id     UUID NOT NULL,
+    type         TEXT NOT NULL,
+    payload      TEXT NOT NULL,
+    created_at   TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE UNIQUE INDEX idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX idx_outbox_events_published_at ON outbox_events (published_at);
+
+-- Уже зарегистрированные пользователи отправляются получателям как user.registered
+WITH registered AS (
+    SELECT gen_random_uuid() AS event_id, u.*, t.slug AS tenant
+    FROM users u
+    JOIN tenants t ON t.id = u.tenant_id
+    WHERE u.deleted_at IS NULL
+)
+INSERT INTO outbox_events (event_id, type, payload, created_at)
+SELECT event_id,
+       'user.registered',
+       json_build_object(
+           'id', event_id,
+           'type', 'user.registered',
+           'identity_key', identity_key,
+           'auth_user_id', id,
+           'tenant', tenant,
+           'email', email,
+           'role', role,
+           'occurred_at', NOW()
+       )::TEXT,
+       NOW()
+FROM registered
+ORDER BY id;
@@ -0,0 +1,21 @@
+CREATE TABLE outbox_events (
+    id           BIGSERIAL PRIMARY KEY,
+    event_id     UUID NOT NULL,
+    type         TEXT NOT NULL,
+    payload      TEXT NOT NULL,
+    created_at   TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE UNIQUE INDEX idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX idx_outbox_events_published_at ON outbox_events (published_at);
+
+INSERT INTO outbox_events (event_id, type, payload, created_at, attempts, last_error)
+SELECT message_id, type, payload, created_at, attempts, last_error
+FROM outbox_messages
+WHERE published_at IS NULL AND topic = 'accounts'
+ORDER BY id;
+
+DROP TABLE IF EXISTS outbox_messages;
@@ -0,0 +1,34 @@
+-- Outbox переезжает в общую таблицу модуля messaging
+CREATE TABLE outbox_messages (
+    id           BIGSERIAL PRIMARY KEY,
+    message_id   UUID NOT NULL,
+    topic        TEXT NOT NULL,
+    type         TEXT NOT NULL,
+    key          TEXT,
+    payload      TEXT NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE UNIQUE INDEX idx_outbox_messages_message_id ON outbox_messages (message_id);
+CREATE INDEX idx_outbox_messages_published_at ON outbox_messages (published_at);
+
+-- Неотправленные события переносятся, чтобы relay доставил их уже новым транспортом
+INSERT INTO outbox_messages (message_id, topic, type, key, payload, occurred_at, created_at, attempts, last_error)
+SELECT event_id,
+       'accounts',
+       type,
+       payload::JSON->>'identity_key',
+       payload,
+       COALESCE(created_at, NOW()),
+       created_at,
+       attempts,
+       last_error
+FROM outbox_events
+WHERE published_at IS NULL
+ORDER BY id;
+
+DROP TABLE outbox_events;
@@ -0,0 +1,16 @@
+CREATE TABLE invitations (
+    id          BIGSERIAL PRIMARY KEY,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    tenant_id   BIGINT NOT NULL REFERENCES tenants (id),
+    email       TEXT NOT NULL,
+    role        TEXT NOT NULL DEFAULT 'user',
+    token_hash  TEXT NOT NULL,
+    expires_at  TIMESTAMPTZ,
+    accepted_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_invitations_token_hash ON invitations (token_hash);
+CREATE INDEX idx_invitations_tenant_email ON invitations (tenant_id, email);
+CREATE INDEX idx_invitations_deleted_at ON invitations (deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN account_version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Ключ учётной записи, которую получит приглашённый; user-service заранее привязывает его к профилю
+ALTER TABLE invitations ADD COLUMN identity_key UUID;
@@ -1,6 +1,7 @@
 package repo
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -11,6 +12,9 @@ import (
 	"gorm.io/gorm"
 
 	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+	"messaging"
+	"messaging/outbox"
 )
 
 type PostgresDatabase struct {
@@ -19,6 +23,25 @@ type PostgresDatabase struct {
 }
 
 func NewPostgresDatabase(logger *zap.Logger) (*PostgresDatabase, error) {
+	db, err := OpenPostgres(logger)
+	if err != nil {
+		return nil, err
+	}
+	return NewPostgresDatabaseWithDB(db, logger)
+}
+
+// NewPostgresDatabaseWithDB работает с уже открытым соединением, например с тестовой схемой
+func NewPostgresDatabaseWithDB(db *gorm.DB, logger *zap.Logger) (*PostgresDatabase, error) {
+	// Сервис не стартует на базе, к которой не применены все миграции
+	if err := NewMigrator(db, logger).RequireLatest(context.Background()); err != nil {
+		logger.Error("Database schema is not up to date", zap.Error(err))
+		return nil, err
+	}
+
+	return &PostgresDatabase{DB: db, logger: logger}, nil
+}
+
+func OpenPostgres(logger *zap.Logger) (*gorm.DB, error) {
 	// Загружаем .env файл
 	if err := godotenv.Load(); err != nil {
 		logger.Warn(".env file not found, using environment variables")
@@ -54,42 +77,140 @@ func NewPostgresDatabase(logger *zap.Logger) (*PostgresDatabase, error) {
 		return nil, fmt.Errorf("database connection failed: %w", err)
 	}
 
-	// Авто-миграция моделей
-	if err = db.AutoMigrate(&model.User{}); err != nil {
-		logger.Error("Failed to auto-migrate database", zap.Error(err))
-		return nil, fmt.Errorf("auto-migration failed: %w", err)
-	}
-
 	logger.Info("Successfully connected to PostgreSQL", zap.String("host", host), zap.String("db", dbname))
-	return &PostgresDatabase{DB: db, logger: logger}, nil
+	return db, nil
 }
 
-func (pd *PostgresDatabase) Create(user *model.User) error {
-	if err := pd.DB.Create(user).Error; err != nil {
-		pd.logger.Error("Failed to create user", zap.Error(err), zap.String("email", user.Email))
+func (pd *PostgresDatabase) Create(ctx context.Context, user *model.User) error {
+	err := pd.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user.AccountVersion = 1
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return writeUserEvents(tx, user, model.EventUserRegistered)
+	})
+	if err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to create user", zap.Error(err), zap.String("email", user.Email))
 		return fmt.Errorf("user creation failed: %w", err)
 	}
 	return nil
 }
 
-func (pd *PostgresDatabase) FindByEmail(email string) (*model.User, error) {
+func (pd *PostgresDatabase) FindByEmail(ctx context.Context, tenantID uint, email string) (*model.User, error) {
 	var user model.User
-	result := pd.DB.Where("email = ?", email).First(&user)
+	result := pd.DB.WithContext(ctx).Preload("Tenant").Where("tenant_id = ? AND email = ?", tenantID, email).First(&user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
-			pd.logger.Info("User not found", zap.String("email", email))
+			requestctx.Logger(ctx, pd.logger).Info("User not found", zap.String("email", email), zap.Uint("tenant_id", tenantID))
 			return nil, result.Error
 		}
-		pd.logger.Error("Failed to find user by email", zap.Error(result.Error), zap.String("email", email))
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find user by email", zap.Error(result.Error), zap.String("email", email), zap.Uint("tenant_id", tenantID))
 		return nil, fmt.Errorf("user lookup failed: %w", result.Error)
 	}
 	return &user, nil
 }
 
-func (pd *PostgresDatabase) UpdateLastLogin(userID uint) error {
-	if err := pd.DB.Model(&model.User{}).Where("id = ?", userID).Update("last_login", time.Now()).Error; err != nil {
-		pd.logger.Error("Failed to update last login", zap.Error(err), zap.Uint("user_id", userID))
+func (pd *PostgresDatabase) FindByID(ctx context.Context, tenantID, userID uint) (*model.User, error) {
+	var user model.User
+	result := pd.DB.WithContext(ctx).Preload("Tenant").Where("tenant_id = ? AND id = ?", tenantID, userID).First(&user)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			requestctx.Logger(ctx, pd.logger).Info("User not found", zap.Uint("user_id", userID), zap.Uint("tenant_id", tenantID))
+			return nil, result.Error
+		}
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find user by id", zap.Error(result.Error), zap.Uint("user_id", userID), zap.Uint("tenant_id", tenantID))
+		return nil, fmt.Errorf("user lookup failed: %w", result.Error)
+	}
+	return &user, nil
+}
+
+func (pd *PostgresDatabase) UpdateLastLogin(ctx context.Context, tenantID, userID uint) error {
+	if err := pd.DB.WithContext(ctx).Model(&model.User{}).Where("tenant_id = ? AND id = ?", tenantID, userID).Update("last_login", time.Now()).Error; err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to update last login", zap.Error(err), zap.Uint("user_id", userID))
 		return fmt.Errorf("last login update failed: %w", err)
 	}
 	return nil
 }
+
+func (pd *PostgresDatabase) UpdateAccount(ctx context.Context, user *model.User, eventTypes ...string) error {
+	err := pd.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("tenant_id = ? AND id = ?", user.TenantID, user.ID).
+			Updates(map[string]interface{}{
+				"email":           user.Email,
+				"role":            user.Role,
+				"account_version": gorm.Expr("account_version + 1"),
+				"updated_at":      time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := loadAccountVersion(tx, user); err != nil {
+			return err
+		}
+		return writeUserEvents(tx, user, eventTypes...)
+	})
+	if err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to update account", zap.Error(err), zap.Uint("user_id", user.ID))
+		return fmt.Errorf("account update failed: %w", err)
+	}
+	return nil
+}
+
+// Delete удаляет учётную запись безвозвратно, чтобы email можно было зарегистрировать заново
+func (pd *PostgresDatabase) Delete(ctx context.Context, user *model.User) error {
+	err := pd.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Удаление тоже получает новую версию; UPDATE заодно блокирует строку до конца транзакции
+		result := tx.Model(&model.User{}).Where("tenant_id = ? AND id = ?", user.TenantID, user.ID).
+			Update("account_version", gorm.Expr("account_version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := loadAccountVersion(tx, user); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("tenant_id = ? AND id = ?", user.TenantID, user.ID).Delete(&model.User{}).Error; err != nil {
+			return err
+		}
+		return writeUserEvents(tx, user, model.EventUserDeleted)
+	})
+	if err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to delete user", zap.Error(err), zap.Uint("user_id", user.ID))
+		return fmt.Errorf("user deletion failed: %w", err)
+	}
+	return nil
+}
+
+// Outbox отдаёт записанные события relay-воркеру
+func (pd *PostgresDatabase) Outbox() outbox.Store {
+	return outbox.NewGormStore(pd.DB)
+}
+
+// loadAccountVersion перечитывает версию, записанную в этой транзакции
+func loadAccountVersion(tx *gorm.DB, user *model.User) error {
+	return tx.Model(&model.User{}).Where("tenant_id = ? AND id = ?", user.TenantID, user.ID).
+		Pluck("account_version", &user.AccountVersion).Error
+}
+
+// writeUserEvents добавляет события в outbox внутри транзакции изменения
+func writeUserEvents(tx *gorm.DB, user *model.User, eventTypes ...string) error {
+	if user.Tenant.ID != user.TenantID {
+		if err := tx.First(&user.Tenant, user.TenantID).Error; err != nil {
+			return err
+		}
+	}
+	messages := make([]messaging.Message, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		message, err := model.NewUserEvent(eventType, user)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	return outbox.Write(tx, messages...)
+}
@@ -0,0 +1,46 @@
+package repo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"auth-service/internal/repo"
+	"auth-service/internal/repo/repotest"
+	"dbmigrate/pgtest"
+)
+
+func TestMemoryDatabase(t *testing.T) {
+	repotest.RunDatabaseSuite(t, func(t *testing.T) repo.Database {
+		return repo.NewMemoryDatabase()
+	})
+}
+
+// PostgresDatabase на SQLite: проверяет запросы, не зависящие от диалекта
+func TestSQLiteDatabase(t *testing.T) {
+	repotest.RunDatabaseSuite(t, func(t *testing.T) repo.Database {
+		db, err := repo.NewSQLiteDatabase(filepath.Join(t.TempDir(), "auth-service.db"), zap.NewNop())
+		if err != nil {
+			t.Fatalf("NewSQLiteDatabase: %v", err)
+		}
+		return db
+	})
+}
+
+// PostgresDatabase на настоящем PostgreSQL со схемой из миграций; пропускается без TEST_POSTGRES_DSN
+func TestPostgresDatabase(t *testing.T) {
+	pgtest.DSN(t)
+	repotest.RunDatabaseSuite(t, func(t *testing.T) repo.Database {
+		db := pgtest.Open(t)
+		if err := repo.NewMigrator(db, zap.NewNop()).Up(context.Background()); err != nil {
+			t.Fatalf("migrate up: %v", err)
+		}
+		database, err := repo.NewPostgresDatabaseWithDB(db, zap.NewNop())
+		if err != nil {
+			t.Fatalf("NewPostgresDatabaseWithDB: %v", err)
+		}
+		return database
+	})
+}
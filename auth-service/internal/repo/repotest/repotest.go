@@ -0,0 +1,354 @@
+// Package repotest содержит общий набор проверок, который должна проходить каждая реализация repo.Database:
+//
+//	func TestMemoryDatabase(t *testing.T) {
+//		repotest.RunDatabaseSuite(t, func(t *testing.T) repo.Database {
+//			return repo.NewMemoryDatabase()
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/repo"
+)
+
+// RunDatabaseSuite запускает проверки на свежей базе, которую возвращает newDatabase
+func RunDatabaseSuite(t *testing.T, newDatabase func(t *testing.T) repo.Database) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, db repo.Database)
+	}{
+		{"DefaultTenantExists", testDefaultTenantExists},
+		{"CreateAndFindTenant", testCreateAndFindTenant},
+		{"DuplicateTenantSlug", testDuplicateTenantSlug},
+		{"CreateAndFindUser", testCreateAndFindUser},
+		{"EmailUniquePerTenant", testEmailUniquePerTenant},
+		{"CrossTenantLookup", testCrossTenantLookup},
+		{"UpdateLastLogin", testUpdateLastLogin},
+		{"UpdateAndDeleteAccount", testUpdateAndDeleteAccount},
+		{"OutboxEvents", testOutboxEvents},
+		{"Invitations", testInvitations},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newDatabase(t))
+		})
+	}
+}
+
+func testDefaultTenantExists(t *testing.T, db repo.Database) {
+	tenant, err := db.FindTenantBySlug(context.Background(), model.DefaultTenantSlug)
+	if err != nil {
+		t.Fatalf("FindTenantBySlug(default): %v", err)
+	}
+	if tenant.ID == 0 {
+		t.Fatal("default tenant has no ID")
+	}
+}
+
+func testCreateAndFindTenant(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, db, "almaty")
+
+	bySlug, err := db.FindTenantBySlug(ctx, "almaty")
+	if err != nil {
+		t.Fatalf("FindTenantBySlug: %v", err)
+	}
+	if bySlug.ID != tenant.ID || bySlug.Name != tenant.Name {
+		t.Fatalf("FindTenantBySlug = %+v, want %+v", bySlug, tenant)
+	}
+
+	byID, err := db.FindTenantByID(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("FindTenantByID: %v", err)
+	}
+	if byID.Slug != "almaty" {
+		t.Fatalf("FindTenantByID slug = %q, want almaty", byID.Slug)
+	}
+
+	if _, err := db.FindTenantBySlug(ctx, "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindTenantBySlug(missing) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := db.FindTenantByID(ctx, tenant.ID+1000); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindTenantByID(missing) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func testDuplicateTenantSlug(t *testing.T, db repo.Database) {
+	mustCreateTenant(t, db, "astana")
+	if err := db.CreateTenant(context.Background(), &model.Tenant{Slug: "astana", Name: "Again"}); err == nil {
+		t.Fatal("CreateTenant with duplicate slug succeeded")
+	}
+}
+
+func testCreateAndFindUser(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, db, "shymkent")
+	user := mustCreateUser(t, db, tenant, "resident@example.com")
+
+	byEmail, err := db.FindByEmail(ctx, tenant.ID, "resident@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if byEmail.ID != user.ID || byEmail.Role != model.RoleUser {
+		t.Fatalf("FindByEmail = %+v, want user %d with role user", byEmail, user.ID)
+	}
+	if byEmail.Tenant.Slug != "shymkent" {
+		t.Fatalf("FindByEmail tenant = %q, want shymkent", byEmail.Tenant.Slug)
+	}
+
+	byID, err := db.FindByID(ctx, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if byID.Email != "resident@example.com" || byID.Tenant.Slug != "shymkent" {
+		t.Fatalf("FindByID = %+v", byID)
+	}
+
+	if _, err := db.FindByEmail(ctx, tenant.ID, "nobody@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByEmail(missing) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func testEmailUniquePerTenant(t *testing.T, db repo.Database) {
+	first := mustCreateTenant(t, db, "first")
+	second := mustCreateTenant(t, db, "second")
+
+	mustCreateUser(t, db, first, "shared@example.com")
+	mustCreateUser(t, db, second, "shared@example.com")
+
+	duplicate := &model.User{TenantID: first.ID, Email: "shared@example.com", PasswordHash: "hash", Role: model.RoleUser}
+	if err := db.Create(context.Background(), duplicate); err == nil {
+		t.Fatal("Create with duplicate email in the same tenant succeeded")
+	}
+}
+
+func testCrossTenantLookup(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, db, "own")
+	other := mustCreateTenant(t, db, "other")
+	user := mustCreateUser(t, db, own, "private@example.com")
+
+	if _, err := db.FindByID(ctx, other.ID, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByID from another tenant error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := db.FindByEmail(ctx, other.ID, user.Email); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByEmail from another tenant error = %v, want gorm.ErrRecordNotFound", err)
+	}
+}
+
+func testUpdateLastLogin(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, db, "login")
+	other := mustCreateTenant(t, db, "intruder")
+	user := mustCreateUser(t, db, own, "login@example.com")
+
+	if err := db.UpdateLastLogin(ctx, other.ID, user.ID); err != nil {
+		t.Fatalf("UpdateLastLogin from another tenant: %v", err)
+	}
+	found, err := db.FindByID(ctx, own.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !found.LastLogin.IsZero() {
+		t.Fatal("UpdateLastLogin from another tenant changed the user")
+	}
+
+	if err := db.UpdateLastLogin(ctx, own.ID, user.ID); err != nil {
+		t.Fatalf("UpdateLastLogin: %v", err)
+	}
+	found, err = db.FindByID(ctx, own.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.LastLogin.IsZero() {
+		t.Fatal("UpdateLastLogin did not set LastLogin")
+	}
+}
+
+func testUpdateAndDeleteAccount(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, db, "accounts")
+	user := mustCreateUser(t, db, tenant, "old@example.com")
+	mustCreateUser(t, db, tenant, "taken@example.com")
+
+	user.Email = "taken@example.com"
+	if err := db.UpdateAccount(ctx, user, model.EventUserEmailChanged); err == nil {
+		t.Fatal("UpdateAccount to an email taken in the same tenant succeeded")
+	}
+
+	user.Email = "new@example.com"
+	user.Role = model.RoleCollector
+	if err := db.UpdateAccount(ctx, user, model.EventUserEmailChanged, model.EventUserRoleChanged); err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	found, err := db.FindByID(ctx, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Email != "new@example.com" || found.Role != model.RoleCollector {
+		t.Fatalf("FindByID after UpdateAccount = %+v", found)
+	}
+
+	if err := db.Delete(ctx, user); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := db.FindByID(ctx, tenant.ID, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindByID after Delete error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if err := db.Delete(ctx, user); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("second Delete error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	// После удаления email снова свободен
+	mustCreateUser(t, db, tenant, "new@example.com")
+}
+
+func testOutboxEvents(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, db, "outbox")
+	user := mustCreateUser(t, db, tenant, "events@example.com")
+
+	user.Role = model.RoleAdmin
+	if err := db.UpdateAccount(ctx, user, model.EventUserRoleChanged); err != nil {
+		t.Fatalf("UpdateAccount: %v", err)
+	}
+	if err := db.Delete(ctx, user); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	events, err := db.Outbox().Pending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	want := []string{model.EventUserRegistered, model.EventUserRoleChanged, model.EventUserDeleted}
+	if len(events) != len(want) {
+		t.Fatalf("Pending returned %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		var payload model.UserEvent
+		if err := json.Unmarshal([]byte(event.Payload), &payload); err != nil {
+			t.Fatalf("event %d payload: %v", i, err)
+		}
+		if event.Topic != model.TopicAccounts || event.Key != user.IdentityKey {
+			t.Fatalf("event %d topic/key = %s/%s", i, event.Topic, event.Key)
+		}
+		if event.Type != want[i] || payload.Type != want[i] || payload.ID != event.MessageID {
+			t.Fatalf("event %d = %s (payload %+v), want %s", i, event.Type, payload, want[i])
+		}
+		if payload.Tenant != "outbox" || payload.AuthUserID != user.ID || payload.Email != "events@example.com" {
+			t.Fatalf("event %d payload = %+v", i, payload)
+		}
+		// Каждое изменение и удаление поднимает версию учётной записи
+		if payload.Version != int64(i+1) {
+			t.Fatalf("event %d version = %d, want %d", i, payload.Version, i+1)
+		}
+	}
+
+	if err := db.Outbox().MarkFailed(ctx, events[0].ID, "unavailable"); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	if err := db.Outbox().MarkPublished(ctx, events[1].ID); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	pending, err := db.Outbox().Pending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != events[0].ID || pending[1].ID != events[2].ID {
+		t.Fatalf("Pending after marking = %+v", pending)
+	}
+	if pending[0].Attempts != 1 || pending[0].LastError != "unavailable" {
+		t.Fatalf("failed event = %+v, want one attempt with last error", pending[0])
+	}
+}
+
+func mustCreateTenant(t *testing.T, db repo.Database, slug string) *model.Tenant {
+	t.Helper()
+	tenant := &model.Tenant{Slug: slug, Name: slug}
+	if err := db.CreateTenant(context.Background(), tenant); err != nil {
+		t.Fatalf("CreateTenant(%q): %v", slug, err)
+	}
+	return tenant
+}
+
+func testInvitations(t *testing.T, db repo.Database) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, db, "invites")
+	now := time.Now()
+
+	expired := &model.Invitation{TenantID: tenant.ID, Email: "guest@example.com", TokenHash: "expired", IdentityKey: uuid.NewString(), ExpiresAt: now.Add(-time.Hour)}
+	invitation := &model.Invitation{TenantID: tenant.ID, Email: "guest@example.com", TokenHash: "pending", IdentityKey: uuid.NewString(), ExpiresAt: now.Add(time.Hour)}
+	for _, inv := range []*model.Invitation{expired, invitation} {
+		if err := db.CreateInvitation(ctx, inv); err != nil {
+			t.Fatalf("CreateInvitation(%s): %v", inv.TokenHash, err)
+		}
+	}
+	if err := db.CreateInvitation(ctx, &model.Invitation{TenantID: tenant.ID, Email: "other@example.com", TokenHash: "pending", ExpiresAt: now.Add(time.Hour)}); err == nil {
+		t.Fatal("CreateInvitation with a duplicate token hash succeeded")
+	}
+
+	pending, err := db.FindPendingInvitation(ctx, tenant.ID, "guest@example.com", now)
+	if err != nil {
+		t.Fatalf("FindPendingInvitation: %v", err)
+	}
+	if pending.ID != invitation.ID {
+		t.Fatalf("FindPendingInvitation = %d, want %d", pending.ID, invitation.ID)
+	}
+
+	found, err := db.FindInvitationByTokenHash(ctx, "pending")
+	if err != nil {
+		t.Fatalf("FindInvitationByTokenHash: %v", err)
+	}
+	if found.Tenant.Slug != tenant.Slug || found.Role != model.RoleUser || found.IdentityKey != invitation.IdentityKey {
+		t.Fatalf("FindInvitationByTokenHash = %+v, want tenant %q, role user and the invitation identity key", found, tenant.Slug)
+	}
+
+	user := &model.User{IdentityKey: uuid.NewString(), TenantID: tenant.ID, Tenant: found.Tenant, Email: found.Email, PasswordHash: "hash", Role: found.Role}
+	if err := db.AcceptInvitation(ctx, found, user); err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+	if user.ID == 0 || found.AcceptedAt == nil {
+		t.Fatalf("AcceptInvitation did not create the user or mark the invitation: %+v", found)
+	}
+	again := &model.User{IdentityKey: uuid.NewString(), TenantID: tenant.ID, Email: "again@example.com", PasswordHash: "hash"}
+	if err := db.AcceptInvitation(ctx, found, again); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("AcceptInvitation(twice) error = %v, want gorm.ErrRecordNotFound", err)
+	}
+	if _, err := db.FindByEmail(ctx, tenant.ID, "again@example.com"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("second acceptance created a user: %v", err)
+	}
+	if _, err := db.FindPendingInvitation(ctx, tenant.ID, "guest@example.com", now); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("FindPendingInvitation after acceptance error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	events, err := db.Outbox().Pending(ctx, 10)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != model.EventUserRegistered || events[0].Key != user.IdentityKey {
+		t.Fatalf("Pending = %+v, want one user.registered event", events)
+	}
+}
+
+func mustCreateUser(t *testing.T, db repo.Database, tenant *model.Tenant, email string) *model.User {
+	t.Helper()
+	user := &model.User{IdentityKey: uuid.NewString(), TenantID: tenant.ID, Email: email, PasswordHash: "hash", Role: model.RoleUser}
+	if err := db.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%q): %v", email, err)
+	}
+	if user.ID == 0 {
+		t.Fatalf("Create(%q) did not assign an ID", email)
+	}
+	return user
+}
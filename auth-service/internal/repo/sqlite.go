@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"fmt"
+
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"messaging/outbox"
+)
+
+// NewSQLiteDatabase открывает файловую базу SQLite; запросы PostgresDatabase не зависят от диалекта.
+// Схема создаётся через AutoMigrate, поскольку SQL-миграции написаны для PostgreSQL.
+func NewSQLiteDatabase(path string, logger *zap.Logger) (*PostgresDatabase, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		logger.Error("Failed to open SQLite database", zap.Error(err), zap.String("path", path))
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+
+	if err = db.AutoMigrate(&model.Tenant{}, &model.User{}, &model.Invitation{}, &outbox.Record{}); err != nil {
+		logger.Error("Failed to auto-migrate SQLite database", zap.Error(err))
+		return nil, fmt.Errorf("auto-migration failed: %w", err)
+	}
+
+	tenant := model.Tenant{Slug: model.DefaultTenantSlug, Name: "Default"}
+	if err = db.Where("slug = ?", tenant.Slug).FirstOrCreate(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("default tenant setup failed: %w", err)
+	}
+
+	logger.Info("Successfully opened SQLite database", zap.String("path", path))
+	return &PostgresDatabase{DB: db, logger: logger}, nil
+}
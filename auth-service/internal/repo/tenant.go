@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+func (pd *PostgresDatabase) CreateTenant(ctx context.Context, tenant *model.Tenant) error {
+	if err := pd.DB.WithContext(ctx).Create(tenant).Error; err != nil {
+		requestctx.Logger(ctx, pd.logger).Error("Failed to create tenant", zap.Error(err), zap.String("slug", tenant.Slug))
+		return fmt.Errorf("tenant creation failed: %w", err)
+	}
+	return nil
+}
+
+func (pd *PostgresDatabase) FindTenantBySlug(ctx context.Context, slug string) (*model.Tenant, error) {
+	var tenant model.Tenant
+	result := pd.DB.WithContext(ctx).Where("slug = ?", slug).First(&tenant)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			requestctx.Logger(ctx, pd.logger).Info("Tenant not found", zap.String("slug", slug))
+			return nil, result.Error
+		}
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find tenant by slug", zap.Error(result.Error), zap.String("slug", slug))
+		return nil, fmt.Errorf("tenant lookup failed: %w", result.Error)
+	}
+	return &tenant, nil
+}
+
+func (pd *PostgresDatabase) FindTenantByID(ctx context.Context, tenantID uint) (*model.Tenant, error) {
+	var tenant model.Tenant
+	result := pd.DB.WithContext(ctx).First(&tenant, tenantID)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			requestctx.Logger(ctx, pd.logger).Info("Tenant not found", zap.Uint("tenant_id", tenantID))
+			return nil, result.Error
+		}
+		requestctx.Logger(ctx, pd.logger).Error("Failed to find tenant by id", zap.Error(result.Error), zap.Uint("tenant_id", tenantID))
+		return nil, fmt.Errorf("tenant lookup failed: %w", result.Error)
+	}
+	return &tenant, nil
+}
@@ -0,0 +1,67 @@
+package requestctx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	TenantHeader    = "X-Tenant"
+	// ServiceTokenHeader — метаданные gRPC с общим токеном внутренних сервисов
+	ServiceTokenHeader = "X-Service-Token"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	tenantKey
+	userIDKey
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func WithTenant(ctx context.Context, tenantSlug string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantSlug)
+}
+
+func Tenant(ctx context.Context) string {
+	tenantSlug, _ := ctx.Value(tenantKey).(string)
+	return tenantSlug
+}
+
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+func UserID(ctx context.Context) uint {
+	userID, _ := ctx.Value(userIDKey).(uint)
+	return userID
+}
+
+// Logger дополняет логгер значениями запроса, чтобы логи разных сервисов можно было связать
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	var fields []zap.Field
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String("request_id", requestID))
+	}
+	if tenantSlug := Tenant(ctx); tenantSlug != "" {
+		fields = append(fields, zap.String("tenant", tenantSlug))
+	}
+	if userID := UserID(ctx); userID != 0 {
+		fields = append(fields, zap.Uint("actor_id", userID))
+	}
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+	"auth-service/internal/service"
+)
+
+type changeEmailRequest struct {
+	Email string `json:"email"`
+}
+
+type changeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func (s *AuthServer) handleChangeEmail(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := s.accountRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req changeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.authService.ChangeEmail(r.Context(), actor, userID, req.Email)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Email change failed", zap.Error(err), zap.Uint("user_id", userID))
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode change email response", zap.Error(err))
+	}
+}
+
+func (s *AuthServer) handleChangeRole(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := s.accountRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req changeRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.authService.ChangeRole(r.Context(), actor, userID, model.UserRole(req.Role))
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Role change failed", zap.Error(err), zap.Uint("user_id", userID))
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode change role response", zap.Error(err))
+	}
+}
+
+func (s *AuthServer) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := s.accountRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.authService.DeleteUser(r.Context(), actor, userID); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("User deletion failed", zap.Error(err), zap.Uint("user_id", userID))
+		http.Error(w, err.Error(), accountErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// accountRequest проверяет Bearer-токен и тенант запроса и разбирает ID пользователя из пути
+func (s *AuthServer) accountRequest(w http.ResponseWriter, r *http.Request) (*model.User, uint, bool) {
+	tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if tokenString == "" {
+		http.Error(w, "Missing token", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	actor, err := s.authService.ValidateToken(r.Context(), tokenString)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, 0, false
+	}
+	if err := s.authService.AuthorizeTenant(r.Context(), actor, r.Header.Get(requestctx.TenantHeader)); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, 0, false
+	}
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID == 0 {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+	return actor, uint(userID), true
+}
+
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrUserExists):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidEmail), errors.Is(err, service.ErrInvalidRole):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
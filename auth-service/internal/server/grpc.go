@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+	"auth-service/internal/service"
+	"auth-service/pkg/authpb"
+)
+
+type grpcAuthServer struct {
+	authpb.UnimplementedAuthServiceServer
+	authService model.AuthService
+	logger      *zap.Logger
+}
+
+// GRPC отдаёт gRPC-сервер поверх того же AuthService, что и HTTP-маршруты.
+// С непустым serviceToken принимаются только вызовы, в метаданных которых передан этот токен.
+func (s *AuthServer) GRPC(serviceToken string) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{requestContextInterceptor}
+	if serviceToken != "" {
+		interceptors = append(interceptors, serviceTokenInterceptor(serviceToken, s.logger))
+	}
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	authpb.RegisterAuthServiceServer(srv, &grpcAuthServer{
+		authService: s.authService,
+		logger:      s.logger,
+	})
+	return srv
+}
+
+func (g *grpcAuthServer) Register(ctx context.Context, req *authpb.RegisterRequest) (*authpb.User, error) {
+	user, err := g.authService.Register(ctx, req.GetTenant(), req.GetEmail(), req.GetPassword(), model.UserRole(req.GetRole()),
+		&model.ReferralClaim{Code: req.GetReferralCode(), DeviceID: req.GetDeviceId()})
+	if err != nil {
+		requestctx.Logger(ctx, g.logger).Error("gRPC registration failed", zap.Error(err), zap.String("email", req.GetEmail()))
+		return nil, grpcError(err)
+	}
+	return toProtoUser(user), nil
+}
+
+func (g *grpcAuthServer) Login(ctx context.Context, req *authpb.LoginRequest) (*authpb.TokenResponse, error) {
+	token, err := g.authService.Login(ctx, req.GetTenant(), req.GetEmail(), req.GetPassword())
+	if err != nil {
+		requestctx.Logger(ctx, g.logger).Error("gRPC login failed", zap.Error(err), zap.String("email", req.GetEmail()))
+		return nil, grpcError(err)
+	}
+	return &authpb.TokenResponse{Token: token}, nil
+}
+
+func (g *grpcAuthServer) ValidateToken(ctx context.Context, req *authpb.ValidateTokenRequest) (*authpb.User, error) {
+	user, err := g.authService.ValidateToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if err := g.authService.AuthorizeTenant(ctx, user, req.GetTenant()); err != nil {
+		return nil, grpcError(err)
+	}
+	return toProtoUser(user), nil
+}
+
+func (g *grpcAuthServer) Refresh(ctx context.Context, req *authpb.RefreshRequest) (*authpb.TokenResponse, error) {
+	token, err := g.authService.Refresh(ctx, req.GetToken())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &authpb.TokenResponse{Token: token}, nil
+}
+
+func (g *grpcAuthServer) GetUser(ctx context.Context, req *authpb.GetUserRequest) (*authpb.User, error) {
+	var (
+		user *model.User
+		err  error
+	)
+
+	switch lookup := req.GetLookup().(type) {
+	case *authpb.GetUserRequest_Id:
+		user, err = g.authService.GetUser(ctx, req.GetTenant(), uint(lookup.Id))
+	case *authpb.GetUserRequest_Email:
+		user, err = g.authService.GetUserByEmail(ctx, req.GetTenant(), lookup.Email)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "either id or email is required")
+	}
+
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toProtoUser(user), nil
+}
+
+// Переносит значения запроса из gRPC-метаданных в контекст
+func requestContextInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstMetadataValue(md, requestctx.RequestIDHeader)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	ctx = requestctx.WithRequestID(ctx, requestID)
+
+	if tenantSlug := firstMetadataValue(md, requestctx.TenantHeader); tenantSlug != "" {
+		ctx = requestctx.WithTenant(ctx, tenantSlug)
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestctx.RequestIDHeader, requestID))
+	return handler(ctx, req)
+}
+
+func serviceTokenInterceptor(token string, logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if subtle.ConstantTimeCompare([]byte(firstMetadataValue(md, requestctx.ServiceTokenHeader)), []byte(token)) != 1 {
+			requestctx.Logger(ctx, logger).Info("gRPC call without a valid service token", zap.String("method", info.FullMethod))
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid service token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func toProtoUser(user *model.User) *authpb.User {
+	pbUser := &authpb.User{
+		Id:           uint64(user.ID),
+		Tenant:       user.Tenant.Slug,
+		Email:        user.Email,
+		Role:         string(user.Role),
+		ProfileImage: user.ProfileImage,
+		CreatedAt:    timestamppb.New(user.CreatedAt),
+		IdentityKey:  user.IdentityKey,
+	}
+	if !user.LastLogin.IsZero() {
+		pbUser.LastLogin = timestamppb.New(user.LastLogin)
+	}
+	return pbUser
+}
+
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrInvalidToken):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrUserExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrTenantNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrCrossTenantAccess), errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidRole):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
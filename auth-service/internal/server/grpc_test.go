@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"auth-service/internal/model"
+	"auth-service/internal/repo"
+	"auth-service/internal/requestctx"
+	"auth-service/pkg/authpb"
+)
+
+const testServiceToken = "gateway-token"
+
+// startGRPC поднимает gRPC-сервер на свободном порту и возвращает клиента, который, как шлюз,
+// подписывает вызовы токеном clientToken; пустой clientToken — вызовы без токена
+func startGRPC(t *testing.T, serverToken, clientToken string) authpb.AuthServiceClient {
+	t.Helper()
+	srv := NewAuthServer(repo.NewMemoryDatabase(), "test-secret", "", InvitationConfig{}, zap.NewNop()).GRPC(serverToken)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	options := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if clientToken != "" {
+		options = append(options, grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(metadata.AppendToOutgoingContext(ctx, requestctx.ServiceTokenHeader, clientToken), method, req, reply, cc, opts...)
+		}))
+	}
+	conn, err := grpc.NewClient(listener.Addr().String(), options...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return authpb.NewAuthServiceClient(conn)
+}
+
+func expectCode(t *testing.T, err error, want codes.Code) {
+	t.Helper()
+	if got := status.Code(err); got != want {
+		t.Fatalf("status code = %s (%v), want %s", got, err, want)
+	}
+}
+
+func TestGRPCServiceToken(t *testing.T) {
+	tests := []struct {
+		name                     string
+		serverToken, clientToken string
+		want                     codes.Code
+	}{
+		{name: "no token", serverToken: testServiceToken, want: codes.Unauthenticated},
+		{name: "wrong token", serverToken: testServiceToken, clientToken: "guess", want: codes.Unauthenticated},
+		// Пропущенный интерцептором вызов доходит до обработчика, который отклоняет пустой запрос
+		{name: "valid token", serverToken: testServiceToken, clientToken: testServiceToken, want: codes.InvalidArgument},
+		{name: "token check disabled", want: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startGRPC(t, tt.serverToken, tt.clientToken)
+			_, err := client.GetUser(context.Background(), &authpb.GetUserRequest{Tenant: model.DefaultTenantSlug})
+			expectCode(t, err, tt.want)
+		})
+	}
+}
+
+func TestGRPCAuthFlow(t *testing.T) {
+	ctx := context.Background()
+	client := startGRPC(t, testServiceToken, testServiceToken)
+
+	user, err := client.Register(ctx, &authpb.RegisterRequest{Tenant: model.DefaultTenantSlug, Email: "resident@example.com", Password: "password"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if user.GetTenant() != model.DefaultTenantSlug || user.GetRole() != string(model.RoleUser) || user.GetIdentityKey() == "" {
+		t.Fatalf("Register = %v, want a plain user of the default tenant with an identity key", user)
+	}
+	_, err = client.Register(ctx, &authpb.RegisterRequest{Tenant: model.DefaultTenantSlug, Email: "resident@example.com", Password: "password"})
+	expectCode(t, err, codes.AlreadyExists)
+	_, err = client.Register(ctx, &authpb.RegisterRequest{Tenant: model.DefaultTenantSlug, Email: "admin@example.com", Password: "password", Role: string(model.RoleAdmin)})
+	expectCode(t, err, codes.PermissionDenied)
+
+	// X-Request-ID из метаданных возвращается в заголовке ответа
+	var header metadata.MD
+	token, err := client.Login(metadata.AppendToOutgoingContext(ctx, requestctx.RequestIDHeader, "req-1"),
+		&authpb.LoginRequest{Tenant: model.DefaultTenantSlug, Email: "resident@example.com", Password: "password"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if got := header.Get(requestctx.RequestIDHeader); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("request id header = %v, want req-1", got)
+	}
+
+	validated, err := client.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: token.GetToken(), Tenant: model.DefaultTenantSlug})
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if validated.GetId() != user.GetId() {
+		t.Fatalf("ValidateToken = user %d, want %d", validated.GetId(), user.GetId())
+	}
+	_, err = client.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: token.GetToken(), Tenant: "north"})
+	expectCode(t, err, codes.PermissionDenied)
+	_, err = client.ValidateToken(ctx, &authpb.ValidateTokenRequest{Token: "not-a-token"})
+	expectCode(t, err, codes.Unauthenticated)
+
+	found, err := client.GetUser(ctx, &authpb.GetUserRequest{Tenant: model.DefaultTenantSlug, Lookup: &authpb.GetUserRequest_Email{Email: "resident@example.com"}})
+	if err != nil {
+		t.Fatalf("GetUser(email): %v", err)
+	}
+	if found.GetId() != user.GetId() {
+		t.Fatalf("GetUser(email) = user %d, want %d", found.GetId(), user.GetId())
+	}
+	_, err = client.GetUser(ctx, &authpb.GetUserRequest{Tenant: model.DefaultTenantSlug, Lookup: &authpb.GetUserRequest_Id{Id: user.GetId() + 100}})
+	expectCode(t, err, codes.NotFound)
+	_, err = client.GetUser(ctx, &authpb.GetUserRequest{Tenant: model.DefaultTenantSlug})
+	expectCode(t, err, codes.InvalidArgument)
+
+	refreshed, err := client.Refresh(ctx, &authpb.RefreshRequest{Token: token.GetToken()})
+	if err != nil || refreshed.GetToken() == "" {
+		t.Fatalf("Refresh = %v, %v; want a new token", refreshed, err)
+	}
+}
@@ -2,83 +2,219 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 
 	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+	"auth-service/internal/service"
 )
 
 type registerRequest struct {
+	Tenant   string `json:"tenant"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+	// Код пригласившего и устройство передаются в user-service вместе с событием регистрации
+	ReferralCode string `json:"referral_code"`
+	DeviceID     string `json:"device_id"`
 }
 
 type loginRequest struct {
+	Tenant   string `json:"tenant"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
+type refreshRequest struct {
+	Token string `json:"token"`
+}
+
+type createTenantRequest struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type createAdminRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Тенант из тела запроса имеет приоритет над заголовком
+func requestTenant(r *http.Request, bodyTenant string) string {
+	if bodyTenant != "" {
+		return bodyTenant
+	}
+	return r.Header.Get(requestctx.TenantHeader)
+}
+
 func (s *AuthServer) handleRegister(w http.ResponseWriter, r *http.Request) {
 	var req registerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.logger.Error("Invalid register request body", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Invalid register request body", zap.Error(err))
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	user, err := s.authService.Register(req.Email, req.Password, model.UserRole(req.Role))
+	user, err := s.authService.Register(r.Context(), requestTenant(r, req.Tenant), req.Email, req.Password, model.UserRole(req.Role),
+		&model.ReferralClaim{Code: req.ReferralCode, DeviceID: req.DeviceID})
 	if err != nil {
-		s.logger.Error("Registration failed", zap.Error(err), zap.String("email", req.Email))
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		requestctx.Logger(r.Context(), s.logger).Error("Registration failed", zap.Error(err), zap.String("email", req.Email))
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(user); err != nil {
-		s.logger.Error("Failed to encode register response", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode register response", zap.Error(err))
 	}
 }
 
 func (s *AuthServer) handleLogin(w http.ResponseWriter, r *http.Request) {
 	var req loginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.logger.Error("Invalid login request body", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Invalid login request body", zap.Error(err))
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	token, err := s.authService.Login(req.Email, req.Password)
+	token, err := s.authService.Login(r.Context(), requestTenant(r, req.Tenant), req.Email, req.Password)
 	if err != nil {
-		s.logger.Error("Login failed", zap.Error(err), zap.String("email", req.Email))
+		requestctx.Logger(r.Context(), s.logger).Error("Login failed", zap.Error(err), zap.String("email", req.Email))
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]string{"token": token}); err != nil {
-		s.logger.Error("Failed to encode login response", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode login response", zap.Error(err))
 	}
 }
 
 func (s *AuthServer) handleValidateToken(w http.ResponseWriter, r *http.Request) {
 	tokenString := r.Header.Get("Authorization")
 	if tokenString == "" {
-		s.logger.Info("Missing token in validate request")
+		requestctx.Logger(r.Context(), s.logger).Info("Missing token in validate request")
 		http.Error(w, "Missing token", http.StatusBadRequest)
 		return
 	}
 
-	user, err := s.authService.ValidateToken(tokenString)
+	user, err := s.authService.ValidateToken(r.Context(), tokenString)
 	if err != nil {
-		s.logger.Error("Token validation failed", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Token validation failed", zap.Error(err))
 		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
+	if err := s.authService.AuthorizeTenant(r.Context(), user, r.Header.Get(requestctx.TenantHeader)); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode validate response", zap.Error(err))
+	}
+}
+
+func (s *AuthServer) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Invalid refresh request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	token, err := s.authService.Refresh(r.Context(), req.Token)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Token refresh failed", zap.Error(err))
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]string{"token": token}); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode refresh response", zap.Error(err))
+	}
+}
+
+// platformRequest пропускает только запросы с ключом администратора платформы
+func (s *AuthServer) platformRequest(w http.ResponseWriter, r *http.Request) bool {
+	if s.platformAdminKey == "" || r.Header.Get("X-Platform-Admin-Key") != s.platformAdminKey {
+		requestctx.Logger(r.Context(), s.logger).Info("Unauthorized platform request", zap.String("path", r.URL.Path))
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (s *AuthServer) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
+	if !s.platformRequest(w, r) {
+		return
+	}
+
+	var req createTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Invalid create tenant request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := s.tenantService.CreateTenant(r.Context(), req.Slug, req.Name)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Tenant creation failed", zap.Error(err), zap.String("slug", req.Slug))
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrTenantExists) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(tenant); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode create tenant response", zap.Error(err))
+	}
+}
+
+func (s *AuthServer) handleCreateAdmin(w http.ResponseWriter, r *http.Request) {
+	if !s.platformRequest(w, r) {
+		return
+	}
+
+	var req createAdminRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Invalid create admin request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	slug := chi.URLParam(r, "slug")
+	user, err := s.authService.CreateAdmin(r.Context(), slug, req.Email, req.Password)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Admin creation failed", zap.Error(err), zap.String("tenant", slug))
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrTenantNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrUserExists):
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
 	if err := json.NewEncoder(w).Encode(user); err != nil {
-		s.logger.Error("Failed to encode validate response", zap.Error(err))
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode create admin response", zap.Error(err))
 	}
 }
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"auth-service/internal/requestctx"
+	"auth-service/internal/service"
+)
+
+type inviteRequest struct {
+	Tenant      string `json:"tenant"`
+	Email       string `json:"email"`
+	Name        string `json:"name"`
+	IdentityKey string `json:"identity_key"`
+}
+
+type inviteResponse struct {
+	ID          uint      `json:"id"`
+	Email       string    `json:"email"`
+	IdentityKey string    `json:"identity_key"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	// Created = false — приглашение уже было отправлено раньше и ещё действует
+	Created bool `json:"created"`
+}
+
+type acceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// handleInvite вызывается другими сервисами (импорт жителей в user-service), а не пользователями,
+// поэтому запрос подписывается общим секретом INVITATIONS_SECRET
+func (s *AuthServer) handleInvite(w http.ResponseWriter, r *http.Request) {
+	if s.invitationsSecret == "" {
+		http.Error(w, "Invitations are disabled", http.StatusNotFound)
+		return
+	}
+
+	body, err := verifySignedBody(r, []byte(s.invitationsSecret), time.Now())
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errInvalidSignature) {
+			status = http.StatusUnauthorized
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var req inviteRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invitation, created, err := s.invitationService.Invite(r.Context(), requestTenant(r, req.Tenant), req.Email, req.Name, req.IdentityKey)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Info("Invitation failed", zap.Error(err), zap.String("email", req.Email))
+		http.Error(w, err.Error(), invitationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	resp := inviteResponse{ID: invitation.ID, Email: invitation.Email, IdentityKey: invitation.IdentityKey, ExpiresAt: invitation.ExpiresAt, Created: created}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode invite response", zap.Error(err))
+	}
+}
+
+func (s *AuthServer) handleAcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req acceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.invitationService.AcceptInvitation(r.Context(), req.Token, req.Password)
+	if err != nil {
+		requestctx.Logger(r.Context(), s.logger).Info("Invitation acceptance failed", zap.Error(err))
+		http.Error(w, err.Error(), invitationErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(user); err != nil {
+		requestctx.Logger(r.Context(), s.logger).Error("Failed to encode accept invitation response", zap.Error(err))
+	}
+}
+
+func invitationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvitationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvitationExpired):
+		return http.StatusGone
+	case errors.Is(err, service.ErrUserExists):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidEmail), errors.Is(err, service.ErrPasswordTooShort), errors.Is(err, service.ErrInvalidIdentityKey):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrTenantNotFound):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"auth-service/internal/requestctx"
+)
+
+// requestContext кладёт идентификатор запроса и тенант в контекст до вызова обработчиков
+func requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestctx.RequestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestctx.RequestIDHeader, requestID)
+
+		ctx := requestctx.WithRequestID(r.Context(), requestID)
+		if tenantSlug := r.Header.Get(requestctx.TenantHeader); tenantSlug != "" {
+			ctx = requestctx.WithTenant(ctx, tenantSlug)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -11,18 +11,35 @@ import (
 )
 
 type AuthServer struct {
-	router      *chi.Mux
-	authService model.AuthService
-	logger      *zap.Logger
+	router            *chi.Mux
+	authService       model.AuthService
+	tenantService     model.TenantService
+	invitationService model.InvitationService
+	platformAdminKey  string
+	invitationsSecret string
+	logger            *zap.Logger
 }
 
-func NewAuthServer(db *repo.PostgresDatabase, jwtSecret string, logger *zap.Logger) *AuthServer {
-	authService := service.NewAuthService(db, jwtSecret, logger)
+// InvitationConfig — настройки приглашений; без Secret приём POST /invitations отключён
+type InvitationConfig struct {
+	Secret string
+	// Страница приёма приглашения, в письме к ней добавляется параметр token
+	AcceptURL string
+	Mailer    model.Mailer
+}
+
+func NewAuthServer(db repo.Database, jwtSecret, platformAdminKey string, invitations InvitationConfig, logger *zap.Logger) *AuthServer {
+	authService := service.NewAuthService(db, db, jwtSecret, logger)
+	tenantService := service.NewTenantService(db, logger)
 
 	server := &AuthServer{
-		router:      chi.NewRouter(),
-		authService: authService,
-		logger:      logger,
+		router:            chi.NewRouter(),
+		authService:       authService,
+		tenantService:     tenantService,
+		invitationService: service.NewInvitationService(db, invitations.Mailer, invitations.AcceptURL, logger),
+		platformAdminKey:  platformAdminKey,
+		invitationsSecret: invitations.Secret,
+		logger:            logger,
 	}
 
 	server.setupRoutes()
@@ -30,11 +47,12 @@ func NewAuthServer(db *repo.PostgresDatabase, jwtSecret string, logger *zap.Logg
 }
 
 func (s *AuthServer) setupRoutes() {
+	s.router.Use(requestContext)
 	s.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Tenant", "X-Platform-Admin-Key", "X-Request-ID", "X-Signature", "X-Signature-Timestamp"},
+		ExposedHeaders:   []string{"Link", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -42,6 +60,14 @@ func (s *AuthServer) setupRoutes() {
 	s.router.Post("/register", s.handleRegister)
 	s.router.Post("/login", s.handleLogin)
 	s.router.Post("/validate", s.handleValidateToken)
+	s.router.Post("/refresh", s.handleRefresh)
+	s.router.Post("/tenants", s.handleCreateTenant)
+	s.router.Post("/tenants/{slug}/admins", s.handleCreateAdmin)
+	s.router.Put("/users/{id}/email", s.handleChangeEmail)
+	s.router.Put("/users/{id}/role", s.handleChangeRole)
+	s.router.Delete("/users/{id}", s.handleDeleteUser)
+	s.router.Post("/invitations", s.handleInvite)
+	s.router.Post("/invitations/accept", s.handleAcceptInvitation)
 }
 
 func (s *AuthServer) Routes() *chi.Mux {
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signatureHeader          = "X-Signature"
+	signatureTimestampHeader = "X-Signature-Timestamp"
+
+	signatureMaxAge = 5 * time.Minute
+	maxSignedBody   = 64 << 10
+)
+
+var errInvalidSignature = errors.New("invalid request signature")
+
+// verifySignedBody проверяет подпись запроса от другого сервиса: HMAC-SHA256 от "<timestamp>.<тело>"
+// общим секретом, как у событий, которые auth-service сам отправляет в user-service
+func verifySignedBody(r *http.Request, secret []byte, now time.Time) ([]byte, error) {
+	timestamp := r.Header.Get(signatureTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return nil, errInvalidSignature
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxSignedBody {
+		return nil, errors.New("request body too large")
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.TrimSpace(r.Header.Get(signatureHeader))), []byte(expected)) {
+		return nil, errInvalidSignature
+	}
+	return body, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+var (
+	ErrForbidden    = errors.New("operation not permitted")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrInvalidEmail = errors.New("invalid email")
+)
+
+func (s *AuthServiceImpl) ChangeEmail(ctx context.Context, actor *model.User, userID uint, email string) (*model.User, error) {
+	user, err := s.managedUser(ctx, actor, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	if email == user.Email {
+		return user, nil
+	}
+
+	existing, err := s.userRepo.FindByEmail(ctx, user.TenantID, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("user check failed: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	previous := user.Email
+	user.Email = email
+	if err := s.userRepo.UpdateAccount(ctx, user, model.EventUserEmailChanged); err != nil {
+		return nil, err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("User email changed", zap.Uint("user_id", user.ID), zap.String("previous", previous), zap.String("email", email))
+	return user, nil
+}
+
+func (s *AuthServiceImpl) ChangeRole(ctx context.Context, actor *model.User, userID uint, role model.UserRole) (*model.User, error) {
+	user, err := s.managedUser(ctx, actor, userID, true)
+	if err != nil {
+		return nil, err
+	}
+	if !validRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if role == user.Role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := s.userRepo.UpdateAccount(ctx, user, model.EventUserRoleChanged); err != nil {
+		return nil, err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("User role changed", zap.Uint("user_id", user.ID), zap.String("role", string(role)), zap.Uint("actor_id", actor.ID))
+	return user, nil
+}
+
+func (s *AuthServiceImpl) DeleteUser(ctx context.Context, actor *model.User, userID uint) error {
+	user, err := s.managedUser(ctx, actor, userID, false)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(ctx, user); err != nil {
+		return err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("User deleted", zap.Uint("user_id", user.ID), zap.Uint("actor_id", actor.ID))
+	return nil
+}
+
+// managedUser загружает пользователя из тенанта actor и проверяет, что actor вправе его менять
+func (s *AuthServiceImpl) managedUser(ctx context.Context, actor *model.User, userID uint, adminOnly bool) (*model.User, error) {
+	if actor.Role != model.RoleAdmin && (adminOnly || actor.ID != userID) {
+		requestctx.Logger(ctx, s.logger).Info("Account change denied", zap.Uint("actor_id", actor.ID), zap.Uint("user_id", userID))
+		return nil, ErrForbidden
+	}
+
+	user, err := s.userRepo.FindByID(ctx, actor.TenantID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	return user, nil
+}
+
+func validRole(role model.UserRole) bool {
+	return role == model.RoleUser || role == model.RoleAdmin || role == model.RoleCollector
+}
@@ -1,16 +1,20 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
 )
 
 var (
@@ -18,26 +22,61 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInvalidToken       = errors.New("invalid token")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrCrossTenantAccess  = errors.New("cross-tenant access denied")
+)
+
+const (
+	tokenTTL      = 24 * time.Hour
+	refreshWindow = 7 * 24 * time.Hour
 )
 
 type AuthServiceImpl struct {
-	userRepo  model.UserRepository
-	jwtSecret []byte
-	logger    *zap.Logger
+	userRepo   model.UserRepository
+	tenantRepo model.TenantRepository
+	jwtSecret  []byte
+	logger     *zap.Logger
 }
 
-func NewAuthService(repo model.UserRepository, jwtSecret string, logger *zap.Logger) *AuthServiceImpl {
+func NewAuthService(repo model.UserRepository, tenantRepo model.TenantRepository, jwtSecret string, logger *zap.Logger) *AuthServiceImpl {
 	return &AuthServiceImpl{
-		userRepo:  repo,
-		jwtSecret: []byte(jwtSecret),
-		logger:    logger,
+		userRepo:   repo,
+		tenantRepo: tenantRepo,
+		jwtSecret:  []byte(jwtSecret),
+		logger:     logger,
+	}
+}
+
+// Register заводит обычного пользователя; другие роли выдаёт админ через ChangeRole
+func (s *AuthServiceImpl) Register(ctx context.Context, tenantSlug, email, password string, role model.UserRole, referral *model.ReferralClaim) (*model.User, error) {
+	if role != "" && role != model.RoleUser {
+		requestctx.Logger(ctx, s.logger).Info("Registration with a privileged role denied", zap.String("email", email), zap.String("role", string(role)))
+		return nil, ErrForbidden
 	}
+	// Код проверяет user-service, когда создаёт профиль; здесь он только передаётся дальше
+	if referral != nil {
+		referral.Code = strings.TrimSpace(referral.Code)
+		referral.DeviceID = strings.TrimSpace(referral.DeviceID)
+		if referral.Code == "" {
+			referral = nil
+		}
+	}
+	return s.createAccount(ctx, tenantSlug, email, password, model.RoleUser, referral)
 }
 
-func (s *AuthServiceImpl) Register(email, password string, role model.UserRole) (*model.User, error) {
-	existingUser, err := s.userRepo.FindByEmail(email)
+// CreateAdmin заводит администратора тенанта по ключу платформы — так у тенанта появляется первый админ
+func (s *AuthServiceImpl) CreateAdmin(ctx context.Context, tenantSlug, email, password string) (*model.User, error) {
+	return s.createAccount(ctx, tenantSlug, email, password, model.RoleAdmin, nil)
+}
+
+func (s *AuthServiceImpl) createAccount(ctx context.Context, tenantSlug, email, password string, role model.UserRole, referral *model.ReferralClaim) (*model.User, error) {
+	tenant, err := resolveTenant(ctx, s.tenantRepo, tenantSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	existingUser, err := s.userRepo.FindByEmail(ctx, tenant.ID, email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		s.logger.Error("Failed to check existing user", zap.Error(err), zap.String("email", email))
+		requestctx.Logger(ctx, s.logger).Error("Failed to check existing user", zap.Error(err), zap.String("email", email))
 		return nil, fmt.Errorf("user check failed: %w", err)
 	}
 	if existingUser != nil {
@@ -46,96 +85,217 @@ func (s *AuthServiceImpl) Register(email, password string, role model.UserRole)
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		s.logger.Error("Failed to hash password", zap.Error(err))
+		requestctx.Logger(ctx, s.logger).Error("Failed to hash password", zap.Error(err))
 		return nil, fmt.Errorf("password hashing failed: %w", err)
 	}
 
 	user := &model.User{
+		IdentityKey:  uuid.NewString(),
+		TenantID:     tenant.ID,
+		Tenant:       *tenant,
 		Email:        email,
 		PasswordHash: string(hashedPassword),
 		Role:         role,
+		Referral:     referral,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
-		s.logger.Error("Failed to create user", zap.Error(err), zap.String("email", email))
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		requestctx.Logger(ctx, s.logger).Error("Failed to create user", zap.Error(err), zap.String("email", email))
 		return nil, fmt.Errorf("user creation failed: %w", err)
 	}
 
-	s.logger.Info("User registered successfully", zap.String("email", email), zap.String("role", string(role)))
+	requestctx.Logger(ctx, s.logger).Info("User registered successfully", zap.String("email", email), zap.String("role", string(role)), zap.String("tenant", tenant.Slug))
 	return user, nil
 }
 
-func (s *AuthServiceImpl) Login(email, password string) (string, error) {
-	user, err := s.userRepo.FindByEmail(email)
+func (s *AuthServiceImpl) Login(ctx context.Context, tenantSlug, email, password string) (string, error) {
+	tenant, err := resolveTenant(ctx, s.tenantRepo, tenantSlug)
 	if err != nil {
-		s.logger.Info("Login attempt with non-existent user", zap.String("email", email))
+		if errors.Is(err, ErrTenantNotFound) {
+			return "", ErrInvalidCredentials
+		}
+		return "", err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, tenant.ID, email)
+	if err != nil {
+		requestctx.Logger(ctx, s.logger).Info("Login attempt with non-existent user", zap.String("email", email))
 		return "", ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		s.logger.Info("Invalid password attempt", zap.String("email", email))
+		requestctx.Logger(ctx, s.logger).Info("Invalid password attempt", zap.String("email", email))
 		return "", ErrInvalidCredentials
 	}
 
-	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
-		s.logger.Error("Failed to update last login during login", zap.Error(err), zap.Uint("user_id", user.ID))
+	if err := s.userRepo.UpdateLastLogin(ctx, tenant.ID, user.ID); err != nil {
+		requestctx.Logger(ctx, s.logger).Error("Failed to update last login during login", zap.Error(err), zap.Uint("user_id", user.ID))
 		return "", fmt.Errorf("last login update failed: %w", err)
 	}
 
+	tokenString, err := s.issueToken(ctx, user, tenant)
+	if err != nil {
+		return "", err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("User logged in successfully", zap.String("email", email))
+	return tokenString, nil
+}
+
+func (s *AuthServiceImpl) ValidateToken(ctx context.Context, tokenString string) (*model.User, error) {
+	claims, err := s.parseToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userFromClaims(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Token validated successfully", zap.String("email", user.Email), zap.String("tenant", user.Tenant.Slug))
+	return user, nil
+}
+
+// Обновить можно и истёкший токен, если с момента истечения прошло не больше refreshWindow
+func (s *AuthServiceImpl) Refresh(ctx context.Context, tokenString string) (string, error) {
+	claims, err := s.parseToken(ctx, tokenString, jwt.WithoutClaimsValidation())
+	if err != nil {
+		return "", err
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		requestctx.Logger(ctx, s.logger).Info("Refresh attempt with token without expiry")
+		return "", ErrInvalidToken
+	}
+	if time.Since(exp.Time) > refreshWindow {
+		requestctx.Logger(ctx, s.logger).Info("Refresh attempt with stale token", zap.Time("expired_at", exp.Time))
+		return "", ErrInvalidToken
+	}
+
+	user, err := s.userFromClaims(ctx, claims)
+	if err != nil {
+		return "", err
+	}
+
+	tokenString, err = s.issueToken(ctx, user, &user.Tenant)
+	if err != nil {
+		return "", err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Token refreshed successfully", zap.String("email", user.Email))
+	return tokenString, nil
+}
+
+func (s *AuthServiceImpl) GetUser(ctx context.Context, tenantSlug string, userID uint) (*model.User, error) {
+	tenant, err := resolveTenant(ctx, s.tenantRepo, tenantSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, tenant.ID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	return user, nil
+}
+
+func (s *AuthServiceImpl) GetUserByEmail(ctx context.Context, tenantSlug, email string) (*model.User, error) {
+	tenant, err := resolveTenant(ctx, s.tenantRepo, tenantSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, tenant.ID, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("user lookup failed: %w", err)
+	}
+	return user, nil
+}
+
+func (s *AuthServiceImpl) AuthorizeTenant(ctx context.Context, user *model.User, tenantSlug string) error {
+	if tenantSlug == "" || user.Tenant.Slug == tenantSlug {
+		return nil
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Cross-tenant access attempt", zap.Uint("user_id", user.ID), zap.String("user_tenant", user.Tenant.Slug), zap.String("requested_tenant", tenantSlug))
+	return ErrCrossTenantAccess
+}
+
+func (s *AuthServiceImpl) issueToken(ctx context.Context, user *model.User, tenant *model.Tenant) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    user.Role,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"role":      user.Role,
+		"tenant_id": tenant.ID,
+		"tenant":    tenant.Slug,
+		"exp":       time.Now().Add(tokenTTL).Unix(),
+		"iat":       time.Now().Unix(),
 	})
 
 	tokenString, err := token.SignedString(s.jwtSecret)
 	if err != nil {
-		s.logger.Error("Failed to sign JWT token", zap.Error(err), zap.String("email", email))
+		requestctx.Logger(ctx, s.logger).Error("Failed to sign JWT token", zap.Error(err), zap.String("email", user.Email))
 		return "", fmt.Errorf("token signing failed: %w", err)
 	}
-
-	s.logger.Info("User logged in successfully", zap.String("email", email))
 	return tokenString, nil
 }
 
-func (s *AuthServiceImpl) ValidateToken(tokenString string) (*model.User, error) {
+func (s *AuthServiceImpl) parseToken(ctx context.Context, tokenString string, opts ...jwt.ParserOption) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return s.jwtSecret, nil
-	})
+	}, opts...)
 
 	if err != nil {
-		s.logger.Error("JWT parsing failed", zap.Error(err))
+		requestctx.Logger(ctx, s.logger).Error("JWT parsing failed", zap.Error(err))
 		return nil, ErrInvalidToken
 	}
 
 	if !token.Valid {
-		s.logger.Info("Invalid JWT token provided")
+		requestctx.Logger(ctx, s.logger).Info("Invalid JWT token provided")
 		return nil, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		s.logger.Error("Invalid JWT claims")
+		requestctx.Logger(ctx, s.logger).Error("Invalid JWT claims")
 		return nil, ErrInvalidToken
 	}
+	return claims, nil
+}
 
+func (s *AuthServiceImpl) userFromClaims(ctx context.Context, claims jwt.MapClaims) (*model.User, error) {
 	email, ok := claims["email"].(string)
 	if !ok || email == "" {
-		s.logger.Error("Invalid token payload: missing email")
+		requestctx.Logger(ctx, s.logger).Error("Invalid token payload: missing email")
 		return nil, ErrInvalidToken
 	}
 
-	user, err := s.userRepo.FindByEmail(email)
+	tenantID, ok := claims["tenant_id"].(float64)
+	if !ok || tenantID <= 0 {
+		requestctx.Logger(ctx, s.logger).Error("Invalid token payload: missing tenant")
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, uint(tenantID), email)
 	if err != nil {
-		s.logger.Error("User not found during token validation", zap.Error(err), zap.String("email", email))
+		requestctx.Logger(ctx, s.logger).Error("User not found during token validation", zap.Error(err), zap.String("email", email))
 		return nil, ErrUserNotFound
 	}
 
-	s.logger.Info("Token validated successfully", zap.String("email", email))
+	if tenantSlug, _ := claims["tenant"].(string); tenantSlug != user.Tenant.Slug {
+		requestctx.Logger(ctx, s.logger).Error("Token tenant does not match user tenant", zap.String("email", email), zap.String("tenant", tenantSlug))
+		return nil, ErrInvalidToken
+	}
 	return user, nil
 }
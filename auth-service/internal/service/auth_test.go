@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"auth-service/internal/model"
+	"auth-service/internal/repo"
+)
+
+const testJWTSecret = "test-secret"
+
+// newTestAuthService — сервис на базе в памяти с тенантом по умолчанию и тенантом north
+func newTestAuthService(t *testing.T) (*AuthServiceImpl, *model.Tenant) {
+	t.Helper()
+	db := repo.NewMemoryDatabase()
+	north, err := NewTenantService(db, zap.NewNop()).CreateTenant(context.Background(), "north", "North")
+	if err != nil {
+		t.Fatalf("CreateTenant: %v", err)
+	}
+	return NewAuthService(db, db, testJWTSecret, zap.NewNop()), north
+}
+
+func mustRegister(t *testing.T, auth *AuthServiceImpl, tenant, email, password string) *model.User {
+	t.Helper()
+	user, err := auth.Register(context.Background(), tenant, email, password, "", nil)
+	if err != nil {
+		t.Fatalf("Register(%s, %s): %v", tenant, email, err)
+	}
+	return user
+}
+
+func mustLogin(t *testing.T, auth *AuthServiceImpl, tenant, email, password string) string {
+	t.Helper()
+	token, err := auth.Login(context.Background(), tenant, email, password)
+	if err != nil {
+		t.Fatalf("Login(%s, %s): %v", tenant, email, err)
+	}
+	return token
+}
+
+// Один email в разных тенантах — разные учётные записи с разными паролями
+func TestTenantScopedAccounts(t *testing.T) {
+	ctx := context.Background()
+	auth, _ := newTestAuthService(t)
+	home := mustRegister(t, auth, model.DefaultTenantSlug, "resident@example.com", "home-password")
+	north := mustRegister(t, auth, "north", "resident@example.com", "north-password")
+	if home.ID == north.ID || home.IdentityKey == north.IdentityKey {
+		t.Fatalf("accounts in two tenants share id %d or identity key %s", home.ID, home.IdentityKey)
+	}
+
+	if _, err := auth.Register(ctx, "north", "resident@example.com", "again", "", nil); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("Register(duplicate in tenant) error = %v, want ErrUserExists", err)
+	}
+	if _, err := auth.Login(ctx, "north", "resident@example.com", "home-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login(north with the default tenant password) error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := auth.Login(ctx, "south", "resident@example.com", "north-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login(unknown tenant) error = %v, want ErrInvalidCredentials", err)
+	}
+	if _, err := auth.GetUser(ctx, model.DefaultTenantSlug, north.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("GetUser(north user through default tenant) error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestTokenClaims(t *testing.T) {
+	ctx := context.Background()
+	auth, north := newTestAuthService(t)
+	user := mustRegister(t, auth, "north", "resident@example.com", "password")
+	token := mustLogin(t, auth, "north", "resident@example.com", "password")
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return []byte(testJWTSecret), nil }); err != nil {
+		t.Fatalf("parse token: %v", err)
+	}
+	if claims["tenant"] != "north" || claims["tenant_id"] != float64(north.ID) || claims["user_id"] != float64(user.ID) ||
+		claims["email"] != user.Email || claims["role"] != string(model.RoleUser) {
+		t.Fatalf("claims = %v, want the north tenant, the user and the user role", claims)
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil || time.Until(exp.Time) > tokenTTL || time.Until(exp.Time) < tokenTTL-time.Minute {
+		t.Fatalf("exp = %v, %v; want about %s from now", exp, err, tokenTTL)
+	}
+
+	validated, err := auth.ValidateToken(ctx, token)
+	if err != nil {
+		t.Fatalf("ValidateToken: %v", err)
+	}
+	if validated.ID != user.ID || validated.Tenant.Slug != "north" {
+		t.Fatalf("ValidateToken = user %d in %q, want user %d in north", validated.ID, validated.Tenant.Slug, user.ID)
+	}
+	for _, tt := range []struct {
+		tenant  string
+		wantErr error
+	}{
+		{tenant: ""},
+		{tenant: "north"},
+		{tenant: model.DefaultTenantSlug, wantErr: ErrCrossTenantAccess},
+	} {
+		if err := auth.AuthorizeTenant(ctx, validated, tt.tenant); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("AuthorizeTenant(%q) error = %v, want %v", tt.tenant, err, tt.wantErr)
+		}
+	}
+}
+
+// Токен с подменённым тенантом или чужой подписью не принимается
+func TestValidateTokenRejectsForgedClaims(t *testing.T) {
+	ctx := context.Background()
+	auth, north := newTestAuthService(t)
+	user := mustRegister(t, auth, "north", "resident@example.com", "password")
+
+	sign := func(secret string, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("sign token: %v", err)
+		}
+		return token
+	}
+	claims := func(tenant string) jwt.MapClaims {
+		return jwt.MapClaims{"user_id": user.ID, "email": user.Email, "role": user.Role, "tenant_id": north.ID, "tenant": tenant,
+			"exp": time.Now().Add(time.Hour).Unix()}
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "tenant slug of another tenant", token: sign(testJWTSecret, claims(model.DefaultTenantSlug))},
+		{name: "another secret", token: sign("other-secret", claims("north"))},
+		{name: "expired", token: sign(testJWTSecret, jwt.MapClaims{"email": user.Email, "tenant_id": north.ID, "tenant": "north", "exp": time.Now().Add(-time.Minute).Unix()})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.ValidateToken(ctx, tt.token); !errors.Is(err, ErrInvalidToken) {
+				t.Fatalf("ValidateToken error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+var (
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation has expired")
+	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
+	ErrInvalidIdentityKey = errors.New("identity key must be a UUID")
+)
+
+const (
+	invitationTTL     = 7 * 24 * time.Hour
+	minPasswordLength = 8
+)
+
+type InvitationDatabase interface {
+	model.UserRepository
+	model.TenantRepository
+	model.InvitationRepository
+}
+
+type InvitationServiceImpl struct {
+	db        InvitationDatabase
+	mailer    model.Mailer
+	acceptURL string
+	logger    *zap.Logger
+}
+
+// NewInvitationService: acceptURL — страница, которой в параметре token передаётся токен из письма
+func NewInvitationService(db InvitationDatabase, mailer model.Mailer, acceptURL string, logger *zap.Logger) *InvitationServiceImpl {
+	return &InvitationServiceImpl{db: db, mailer: mailer, acceptURL: acceptURL, logger: logger}
+}
+
+// Invite не создаёт второе приглашение, пока первое ждёт ответа: повторный импорт одного
+// и того же списка не рассылает письма заново. Ключ ждущего приглашения возвращается как есть.
+func (s *InvitationServiceImpl) Invite(ctx context.Context, tenantSlug, email, name, identityKey string) (*model.Invitation, bool, error) {
+	tenant, err := resolveTenant(ctx, s.db, tenantSlug)
+	if err != nil {
+		return nil, false, err
+	}
+
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, false, ErrInvalidEmail
+	}
+	if identityKey == "" {
+		identityKey = uuid.NewString()
+	} else if _, err := uuid.Parse(identityKey); err != nil {
+		return nil, false, ErrInvalidIdentityKey
+	}
+
+	existing, err := s.db.FindByEmail(ctx, tenant.ID, email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("user check failed: %w", err)
+	}
+	if existing != nil {
+		return nil, false, ErrUserExists
+	}
+
+	now := time.Now()
+	pending, err := s.db.FindPendingInvitation(ctx, tenant.ID, email, now)
+	if err == nil {
+		return pending, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, false, err
+	}
+	invitation := &model.Invitation{
+		TenantID:    tenant.ID,
+		Email:       email,
+		Role:        model.RoleUser,
+		TokenHash:   hashInvitationToken(token),
+		IdentityKey: identityKey,
+		ExpiresAt:   now.Add(invitationTTL),
+	}
+	if err := s.db.CreateInvitation(ctx, invitation); err != nil {
+		return nil, false, err
+	}
+
+	subject, body := s.invitationEmail(tenant, name, token, invitation.ExpiresAt)
+	if err := s.mailer.Send(ctx, email, subject, body); err != nil {
+		requestctx.Logger(ctx, s.logger).Error("Failed to send invitation", zap.Error(err), zap.String("email", email))
+		return nil, false, fmt.Errorf("invitation email failed: %w", err)
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Invitation sent", zap.String("email", email), zap.String("tenant", tenant.Slug))
+	return invitation, true, nil
+}
+
+// AcceptInvitation заводит учётную запись на email из приглашения с ключом из приглашения;
+// профиль в user-service найдёт её по этому ключу через событие user.registered
+func (s *InvitationServiceImpl) AcceptInvitation(ctx context.Context, token, password string) (*model.User, error) {
+	invitation, err := s.db.FindInvitationByTokenHash(ctx, hashInvitationToken(token))
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && invitation.AcceptedAt != nil {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	if len(password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("password hashing failed: %w", err)
+	}
+	// У приглашений, созданных до появления ключа, его нет
+	identityKey := invitation.IdentityKey
+	if identityKey == "" {
+		identityKey = uuid.NewString()
+	}
+	user := &model.User{
+		IdentityKey:  identityKey,
+		TenantID:     invitation.TenantID,
+		Tenant:       invitation.Tenant,
+		Email:        invitation.Email,
+		PasswordHash: string(hashedPassword),
+		Role:         invitation.Role,
+	}
+
+	if err := s.db.AcceptInvitation(ctx, invitation, user); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrInvitationNotFound
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Invitation accepted", zap.String("email", user.Email), zap.Uint("user_id", user.ID))
+	return user, nil
+}
+
+func (s *InvitationServiceImpl) invitationEmail(tenant *model.Tenant, name, token string, expiresAt time.Time) (string, string) {
+	greeting := "Hello,"
+	if name = strings.TrimSpace(name); name != "" {
+		greeting = fmt.Sprintf("Hello, %s,", name)
+	}
+
+	link := s.acceptURL
+	if strings.Contains(link, "?") {
+		link += "&token=" + url.QueryEscape(token)
+	} else {
+		link += "?token=" + url.QueryEscape(token)
+	}
+
+	body := fmt.Sprintf("%s\n\n%s has registered you for the waste collection service.\n"+
+		"Choose a password to activate your account:\n\n%s\n\nThe link is valid until %s.\n",
+		greeting, tenant.Name, link, expiresAt.UTC().Format("2 January 2006 15:04 MST"))
+	return "Your waste collection account", body
+}
+
+func newInvitationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("invitation token generation failed: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
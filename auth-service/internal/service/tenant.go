@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"auth-service/internal/model"
+	"auth-service/internal/requestctx"
+)
+
+var (
+	ErrTenantExists      = errors.New("tenant already exists")
+	ErrTenantNotFound    = errors.New("tenant not found")
+	ErrInvalidTenantSlug = errors.New("invalid tenant slug")
+)
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+type TenantServiceImpl struct {
+	tenantRepo model.TenantRepository
+	logger     *zap.Logger
+}
+
+func NewTenantService(repo model.TenantRepository, logger *zap.Logger) *TenantServiceImpl {
+	return &TenantServiceImpl{
+		tenantRepo: repo,
+		logger:     logger,
+	}
+}
+
+func (s *TenantServiceImpl) CreateTenant(ctx context.Context, slug, name string) (*model.Tenant, error) {
+	if !tenantSlugPattern.MatchString(slug) {
+		return nil, ErrInvalidTenantSlug
+	}
+	if name == "" {
+		name = slug
+	}
+
+	existing, err := s.tenantRepo.FindTenantBySlug(ctx, slug)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("tenant check failed: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrTenantExists
+	}
+
+	tenant := &model.Tenant{Slug: slug, Name: name}
+	if err := s.tenantRepo.CreateTenant(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("tenant creation failed: %w", err)
+	}
+
+	requestctx.Logger(ctx, s.logger).Info("Tenant created successfully", zap.String("slug", slug))
+	return tenant, nil
+}
+
+func (s *TenantServiceImpl) ResolveTenant(ctx context.Context, slug string) (*model.Tenant, error) {
+	return resolveTenant(ctx, s.tenantRepo, slug)
+}
+
+func resolveTenant(ctx context.Context, repo model.TenantRepository, slug string) (*model.Tenant, error) {
+	if slug == "" {
+		slug = model.DefaultTenantSlug
+	}
+
+	tenant, err := repo.FindTenantBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("tenant lookup failed: %w", err)
+	}
+	return tenant, nil
+}
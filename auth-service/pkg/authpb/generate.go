@@ -0,0 +1,4 @@
+// Package authpb содержит сгенерированные gRPC-клиент и сервер auth-service.
+package authpb
+
+//go:generate protoc -I ../../api/proto --go_out=../.. --go_opt=module=auth-service --go-grpc_out=../.. --go-grpc_opt=module=auth-service auth/v1/auth.proto
@@ -0,0 +1,307 @@
+// Package dbmigrate применяет SQL-миграции сервисов. Каждый сервис встраивает свой каталог migrations
+// и передаёт собственные имя и ключ блокировки: версии хранятся в schema_migrations отдельно по сервисам,
+// а миграции разных сервисов в одной базе не ждут друг друга.
+package dbmigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type schemaMigration struct {
+	Service   string `gorm:"primaryKey"`
+	Version   int64  `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Logger получает сообщения о применённых и откаченных миграциях; подходит *log.Logger
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Ключ блокировки для настройки schema_migrations, общей для всех сервисов базы
+const versionTableLockKey = 817236000
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	service    string
+	// Ключ pg_advisory_xact_lock, чтобы две реплики не мигрировали одновременно
+	lockKey int64
+	logger  Logger
+}
+
+// New читает миграции из каталога migrations в files. Файлы встроены в бинарник сервиса,
+// поэтому ошибка здесь — ошибка сборки, и New паникует.
+func New(db *gorm.DB, files fs.FS, service string, lockKey int64, logger Logger) *Migrator {
+	migrations, err := loadMigrations(files)
+	if err != nil {
+		panic(err)
+	}
+	return &Migrator{db: db, migrations: migrations, service: service, lockKey: lockKey, logger: logger}
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionPart, label, _ := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q: %w", name, err)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %q: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d must have both up and down files", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func (m *Migrator) ensureVersionTable(ctx context.Context) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", versionTableLockKey).Error; err != nil {
+			return err
+		}
+
+		var legacy bool
+		err := tx.Raw(`SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = 'schema_migrations'
+		) AND NOT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'schema_migrations' AND column_name = 'service'
+		)`).Scan(&legacy).Error
+		if err != nil {
+			return err
+		}
+		if legacy {
+			// Таблица без колонки service вела версии одного сервиса: её записи достаются
+			// первому сервису, который запустит мигратор на этой базе
+			if err := tx.Exec("ALTER TABLE schema_migrations ADD COLUMN service TEXT").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE schema_migrations SET service = ?", m.service).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(`ALTER TABLE schema_migrations
+				ALTER COLUMN service SET NOT NULL,
+				DROP CONSTRAINT schema_migrations_pkey,
+				ADD PRIMARY KEY (service, version)`).Error; err != nil {
+				return err
+			}
+			m.logger.Printf("Schema version table upgraded, existing versions assigned to %s", m.service)
+		}
+
+		return tx.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			service    TEXT NOT NULL,
+			version    BIGINT NOT NULL,
+			name       TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (service, version)
+		)`).Error
+	})
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]schemaMigration, error) {
+	if err := m.ensureVersionTable(ctx); err != nil {
+		return nil, fmt.Errorf("schema version table setup failed: %w", err)
+	}
+
+	var rows []schemaMigration
+	if err := m.db.WithContext(ctx).Where("service = ?", m.service).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("schema version lookup failed: %w", err)
+	}
+
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+func (m *Migrator) Up(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		ran := false
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", m.lockKey).Error; err != nil {
+				return err
+			}
+			// Другая реплика могла применить миграцию, пока мы ждали блокировку
+			done, err := m.isApplied(tx, migration.Version)
+			if err != nil || done {
+				return err
+			}
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&schemaMigration{Service: m.service, Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			m.logger.Printf("Migration applied: %d_%s", migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		ran := false
+		err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", m.lockKey).Error; err != nil {
+				return err
+			}
+			// Другая реплика могла откатить миграцию, пока мы ждали блокировку
+			done, err := m.isApplied(tx, migration.Version)
+			if err != nil || !done {
+				return err
+			}
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			ran = true
+			return tx.Where("service = ? AND version = ?", m.service, migration.Version).Delete(&schemaMigration{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if ran {
+			m.logger.Printf("Migration rolled back: %d_%s", migration.Version, migration.Name)
+		}
+		steps--
+	}
+	return nil
+}
+
+// isApplied перечитывает версию внутри транзакции, уже взявшей блокировку
+func (m *Migrator) isApplied(tx *gorm.DB, version int64) (bool, error) {
+	var count int64
+	err := tx.Model(&schemaMigration{}).Where("service = ? AND version = ?", m.service, version).Count(&count).Error
+	return count > 0, err
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// RequireLatest проверяет, что все встроенные миграции применены
+func (m *Migrator) RequireLatest(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s, run `migrate up`", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package dbmigrate_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/gorm"
+
+	"dbmigrate"
+	"dbmigrate/pgtest"
+)
+
+const testLockKey = 817236999
+
+var discard = log.New(io.Discard, "", 0)
+
+// migrations строит каталог, в котором вторая миграция не переживёт повторного применения
+func migrations(table string) fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0001_create.up.sql":   {Data: []byte("CREATE TABLE " + table + " (id INT PRIMARY KEY)")},
+		"migrations/0001_create.down.sql": {Data: []byte("DROP TABLE " + table)},
+		"migrations/0002_seed.up.sql":     {Data: []byte("INSERT INTO " + table + " (id) VALUES (1)")},
+		"migrations/0002_seed.down.sql":   {Data: []byte("DELETE FROM " + table)},
+	}
+}
+
+func appliedVersions(t *testing.T, db *gorm.DB, service string) []int64 {
+	t.Helper()
+	var versions []int64
+	if err := db.Raw("SELECT version FROM schema_migrations WHERE service = ? ORDER BY version", service).Scan(&versions).Error; err != nil {
+		t.Fatalf("read schema_migrations: %v", err)
+	}
+	return versions
+}
+
+// Реплики, стартующие одновременно, применяют каждую миграцию ровно один раз
+func TestPostgresConcurrentUp(t *testing.T) {
+	db := pgtest.Open(t)
+	files := migrations("widgets")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- dbmigrate.New(db, files, "widgets", testLockKey, discard).Up(context.Background())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+	}
+
+	if got := appliedVersions(t, db, "widgets"); len(got) != 2 {
+		t.Fatalf("applied versions = %v, want [1 2]", got)
+	}
+	if err := dbmigrate.New(db, files, "widgets", testLockKey, discard).RequireLatest(context.Background()); err != nil {
+		t.Fatalf("RequireLatest: %v", err)
+	}
+}
+
+// Сервисы с одинаковыми номерами версий не мешают друг другу в общей базе
+func TestPostgresServicesKeepSeparateVersions(t *testing.T) {
+	db := pgtest.Open(t)
+	ctx := context.Background()
+	first := dbmigrate.New(db, migrations("first_items"), "first", testLockKey, discard)
+	second := dbmigrate.New(db, migrations("second_items"), "second", testLockKey+1, discard)
+
+	for _, m := range []*dbmigrate.Migrator{first, second} {
+		if err := m.Up(ctx); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+	}
+	if err := first.Down(ctx, 2); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+
+	if got := appliedVersions(t, db, "first"); len(got) != 0 {
+		t.Fatalf("first service versions = %v, want none", got)
+	}
+	if got := appliedVersions(t, db, "second"); len(got) != 2 {
+		t.Fatalf("second service versions = %v, want [1 2]", got)
+	}
+	if err := second.RequireLatest(ctx); err != nil {
+		t.Fatalf("RequireLatest(second): %v", err)
+	}
+}
+
+// Таблица версий без колонки service достаётся первому запущенному сервису, уже применённое не повторяется
+func TestPostgresLegacyVersionTable(t *testing.T) {
+	db := pgtest.Open(t)
+	ctx := context.Background()
+	err := db.Exec(`CREATE TABLE schema_migrations (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`).Error
+	if err == nil {
+		err = db.Exec("CREATE TABLE legacy_items (id INT PRIMARY KEY)").Error
+	}
+	if err == nil {
+		err = db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (1, 'create', now())").Error
+	}
+	if err != nil {
+		t.Fatalf("prepare legacy schema: %v", err)
+	}
+
+	if err := dbmigrate.New(db, migrations("legacy_items"), "legacy", testLockKey, discard).Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := appliedVersions(t, db, "legacy"); len(got) != 2 {
+		t.Fatalf("legacy service versions = %v, want [1 2]", got)
+	}
+
+	// Второй сервис начинает с чистого листа
+	if err := dbmigrate.New(db, migrations("other_items"), "other", testLockKey+1, discard).Up(ctx); err != nil {
+		t.Fatalf("Up(other): %v", err)
+	}
+	if got := appliedVersions(t, db, "other"); len(got) != 2 {
+		t.Fatalf("other service versions = %v, want [1 2]", got)
+	}
+}
@@ -0,0 +1,4 @@
+-- Выполняется образом postgres при первом запуске: у user-service и map-service свои базы,
+-- auth-service работает в базе из POSTGRES_DB
+CREATE DATABASE user_service_db;
+CREATE DATABASE map_service_db;
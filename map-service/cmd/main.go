@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"map-service/internal/infrastructure/database"
+	"map-service/internal/infrastructure/repository"
+	"map-service/internal/infrastructure/server"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration command failed: %v", err)
+		}
+		return
+	}
+
+	repos, err := openRepositories()
+	if err != nil {
+		log.Fatalf("Failed to connect to repo: %v", err)
+	}
+
+	location := time.UTC
+	if tz := os.Getenv("DEFAULT_TIMEZONE"); tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			log.Fatalf("Invalid DEFAULT_TIMEZONE: %v", err)
+		}
+	}
+
+	gatewayToken := os.Getenv("GATEWAY_TOKEN")
+	if gatewayToken == "" {
+		log.Println("GATEWAY_TOKEN is not set, user headers are trusted without verification")
+	}
+
+	srv := server.NewMapServer(repos, server.Config{DefaultLocation: location, GatewayToken: gatewayToken})
+
+	log.Println("Starting Map Service on :8083")
+	if err := http.ListenAndServe(":8083", srv.Routes()); err != nil {
+		log.Fatalf("Server failed to start: %v", err)
+	}
+}
+
+// openRepositories выбирает хранилище по DB_DRIVER: postgres (по умолчанию), sqlite или memory
+func openRepositories() (repository.Repositories, error) {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "postgres":
+		db, err := database.NewPostgresDatabase()
+		if err != nil {
+			return repository.Repositories{}, err
+		}
+		return repository.NewPostgresRepositories(db), nil
+	case "sqlite":
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "map-service.db"
+		}
+		db, err := database.NewSQLiteDatabase(path)
+		if err != nil {
+			return repository.Repositories{}, err
+		}
+		return repository.NewPostgresRepositories(db), nil
+	case "memory":
+		log.Println("Using in-memory repositories, data will be lost on restart")
+		return repository.NewMemoryRepositories(), nil
+	default:
+		return repository.Repositories{}, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
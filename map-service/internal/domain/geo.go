@@ -0,0 +1,37 @@
+package domain
+
+import "math"
+
+// Средний радиус Земли; PostGIS считает расстояния на сфере с тем же радиусом
+const earthRadiusMeters = 6371008.8
+
+type GeoPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+func (p GeoPoint) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// DistanceTo — расстояние по дуге большого круга в метрах
+func (p GeoPoint) DistanceTo(other GeoPoint) float64 {
+	lat1, lat2 := p.Latitude*math.Pi/180, other.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - p.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// GeoBounds — прямоугольник в градусах, стороны в порядке bbox из GeoJSON: запад, юг, восток, север
+type GeoBounds struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+func (b GeoBounds) Contains(p GeoPoint) bool {
+	return p.Longitude >= b.West && p.Longitude <= b.East && p.Latitude >= b.South && p.Latitude <= b.North
+}
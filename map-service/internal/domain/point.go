@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPointNotFound = errors.New("collection point not found")
+	ErrInvalidInput  = errors.New("invalid input")
+)
+
+// Тенант, если шлюз не передал заголовок X-Tenant
+const DefaultTenant = "default"
+
+type WasteCategory string
+
+const (
+	CategoryPlastic     WasteCategory = "plastic"
+	CategoryPaper       WasteCategory = "paper"
+	CategoryGlass       WasteCategory = "glass"
+	CategoryMetal       WasteCategory = "metal"
+	CategoryOrganic     WasteCategory = "organic"
+	CategoryTextile     WasteCategory = "textile"
+	CategoryElectronics WasteCategory = "electronics"
+	CategoryBatteries   WasteCategory = "batteries"
+	CategoryHazardous   WasteCategory = "hazardous"
+	CategoryBulky       WasteCategory = "bulky"
+)
+
+var WasteCategories = []WasteCategory{
+	CategoryPlastic, CategoryPaper, CategoryGlass, CategoryMetal, CategoryOrganic,
+	CategoryTextile, CategoryElectronics, CategoryBatteries, CategoryHazardous, CategoryBulky,
+}
+
+func (c WasteCategory) Valid() bool {
+	for _, known := range WasteCategories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// OpeningInterval — часы работы в один из дней недели; время HH:MM в часовом поясе сервиса
+type OpeningInterval struct {
+	Weekday time.Weekday `json:"weekday"`
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+// Operator — организация, которая обслуживает пункт
+type Operator struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone,omitempty"`
+	Website string `json:"website,omitempty"`
+}
+
+// CollectionPoint — пункт приёма отходов. Пустой OpeningHours означает, что пункт доступен круглосуточно.
+// В PostgreSQL по Latitude/Longitude строится вычисляемая колонка location типа geography(Point).
+type CollectionPoint struct {
+	ID           uuid.UUID         `json:"id" gorm:"type:uuid;primary_key"`
+	Tenant       string            `json:"-" gorm:"not null;index"`
+	Name         string            `json:"name" gorm:"not null"`
+	Description  string            `json:"description,omitempty"`
+	Address      string            `json:"address,omitempty"`
+	Latitude     float64           `json:"lat" gorm:"not null"`
+	Longitude    float64           `json:"lon" gorm:"not null"`
+	Categories   []WasteCategory   `json:"categories" gorm:"serializer:json;not null"`
+	OpeningHours []OpeningInterval `json:"opening_hours" gorm:"serializer:json"`
+	Operator     Operator          `json:"operator" gorm:"embedded;embeddedPrefix:operator_"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+func (p CollectionPoint) Location() GeoPoint {
+	return GeoPoint{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+func (p CollectionPoint) Accepts(category WasteCategory) bool {
+	for _, accepted := range p.Categories {
+		if accepted == category {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenAt сообщает, работает ли пункт в момент t; t уже переведено в часовой пояс сервиса
+func (p CollectionPoint) OpenAt(t time.Time) bool {
+	if len(p.OpeningHours) == 0 {
+		return true
+	}
+	clock := t.Format("15:04")
+	for _, interval := range p.OpeningHours {
+		if t.Weekday() == interval.Weekday && interval.Opens <= clock && clock < interval.Closes {
+			return true
+		}
+	}
+	return false
+}
+
+// PointFilter — условия выборки пунктов; пустые поля не ограничивают выборку
+type PointFilter struct {
+	Category WasteCategory
+	// Bounds оставляет пункты внутри прямоугольника
+	Bounds *GeoBounds
+	Limit  int
+	Offset int
+}
+
+// NearbyQuery — поиск пунктов в радиусе RadiusMeters от Center
+type NearbyQuery struct {
+	Center       GeoPoint
+	RadiusMeters float64
+	Category     WasteCategory
+	Limit        int
+}
+
+// NearbyPoint — найденный пункт и расстояние до него по сфере
+type NearbyPoint struct {
+	CollectionPoint
+	DistanceMeters float64 `json:"distance_m"`
+	OpenNow        bool    `json:"open_now"`
+}
+
+// Ограничения на загрузку пунктов из GeoJSON
+const (
+	MaxImportBytes    = 10 << 20
+	MaxImportFeatures = 5000
+)
+
+// PointImportItem — объект из загруженного файла: пункт или причина, по которой его не удалось разобрать
+type PointImportItem struct {
+	// Feature — номер объекта в массиве features, с нуля
+	Feature   int
+	FeatureID string
+	Point     *CollectionPoint
+	Err       error
+}
+
+type PointImportError struct {
+	Feature int    `json:"feature"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// PointImportReport — итог загрузки. Файл с ошибками не создаёт ни одного пункта,
+// а при dry_run пункты не создаются вовсе.
+type PointImportReport struct {
+	DryRun   bool               `json:"dry_run"`
+	Total    int                `json:"total"`
+	Valid    int                `json:"valid"`
+	Created  int                `json:"created"`
+	Errors   []PointImportError `json:"errors"`
+	PointIDs []uuid.UUID        `json:"point_ids,omitempty"`
+}
+
+type PointRepository interface {
+	Create(ctx context.Context, point *CollectionPoint) error
+	// CreateBatch сохраняет все пункты или ни одного
+	CreateBatch(ctx context.Context, points []CollectionPoint) error
+	Update(ctx context.Context, point *CollectionPoint) error
+	Delete(ctx context.Context, tenant string, id uuid.UUID) error
+	FindByID(ctx context.Context, tenant string, id uuid.UUID) (*CollectionPoint, error)
+	List(ctx context.Context, tenant string, filter PointFilter) ([]CollectionPoint, error)
+	// Nearby возвращает пункты в радиусе от ближайшего к дальнему; OpenNow заполняет сервис
+	Nearby(ctx context.Context, tenant string, query NearbyQuery) ([]NearbyPoint, error)
+}
+
+type PointService interface {
+	CreatePoint(ctx context.Context, tenant string, point *CollectionPoint) error
+	UpdatePoint(ctx context.Context, tenant string, point *CollectionPoint) error
+	DeletePoint(ctx context.Context, tenant string, id uuid.UUID) error
+	GetPoint(ctx context.Context, tenant string, id uuid.UUID) (*CollectionPoint, error)
+	ListPoints(ctx context.Context, tenant string, filter PointFilter) ([]CollectionPoint, error)
+	FindNearby(ctx context.Context, tenant string, query NearbyQuery) ([]NearbyPoint, error)
+	// ExportPoints отдаёт все подходящие пункты без ограничения на размер страницы
+	ExportPoints(ctx context.Context, tenant string, filter PointFilter) ([]CollectionPoint, error)
+	ImportPoints(ctx context.Context, tenant string, items []PointImportItem, dryRun bool) (*PointImportReport, error)
+}
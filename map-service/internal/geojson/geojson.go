@@ -0,0 +1,231 @@
+// Package geojson переводит пункты приёма в GeoJSON (RFC 7946) и обратно.
+// Свойства объекта совпадают с полями пункта в API, так что выгруженный файл можно загрузить снова.
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"map-service/internal/domain"
+)
+
+// ContentType — тип содержимого GeoJSON из RFC 7946
+const ContentType = "application/geo+json"
+
+var ErrInvalidDocument = errors.New("invalid GeoJSON document")
+
+type FeatureCollection struct {
+	Type     string          `json:"type"`
+	CRS      json.RawMessage `json:"crs,omitempty"`
+	Features []Feature       `json:"features"`
+}
+
+type Feature struct {
+	Type       string          `json:"type"`
+	ID         json.RawMessage `json:"id,omitempty"`
+	Geometry   *Geometry       `json:"geometry"`
+	Properties json.RawMessage `json:"properties"`
+}
+
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type pointProperties struct {
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description,omitempty"`
+	Address      string                   `json:"address,omitempty"`
+	Categories   categoryList             `json:"categories"`
+	OpeningHours []domain.OpeningInterval `json:"opening_hours"`
+	Operator     domain.Operator          `json:"operator"`
+	CreatedAt    *time.Time               `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time               `json:"updated_at,omitempty"`
+
+	// Плоские поля оператора: многие ГИС не умеют вложенные объекты в атрибутах
+	OperatorName    string `json:"operator_name,omitempty"`
+	OperatorPhone   string `json:"operator_phone,omitempty"`
+	OperatorWebsite string `json:"operator_website,omitempty"`
+}
+
+// categoryList принимает и массив, и строку через запятую — так категории обычно приходят из таблиц атрибутов
+type categoryList []domain.WasteCategory
+
+func (c *categoryList) UnmarshalJSON(data []byte) error {
+	var joined string
+	if err := json.Unmarshal(data, &joined); err == nil {
+		*c = nil
+		for _, part := range strings.Split(joined, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				*c = append(*c, domain.WasteCategory(part))
+			}
+		}
+		return nil
+	}
+	var list []domain.WasteCategory
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("categories must be an array or a comma-separated string")
+	}
+	*c = list
+	return nil
+}
+
+// EncodePoints собирает FeatureCollection, в которой каждый пункт — объект Point с координатами [lon, lat]
+func EncodePoints(points []domain.CollectionPoint) (*FeatureCollection, error) {
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(points))}
+	for _, point := range points {
+		id, err := json.Marshal(point.ID)
+		if err != nil {
+			return nil, err
+		}
+		coordinates, err := json.Marshal([2]float64{point.Longitude, point.Latitude})
+		if err != nil {
+			return nil, err
+		}
+		createdAt, updatedAt := point.CreatedAt, point.UpdatedAt
+		properties, err := json.Marshal(pointProperties{
+			Name:         point.Name,
+			Description:  point.Description,
+			Address:      point.Address,
+			Categories:   point.Categories,
+			OpeningHours: point.OpeningHours,
+			Operator:     point.Operator,
+			CreatedAt:    &createdAt,
+			UpdatedAt:    &updatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		collection.Features = append(collection.Features, Feature{
+			Type:       "Feature",
+			ID:         id,
+			Geometry:   &Geometry{Type: "Point", Coordinates: coordinates},
+			Properties: properties,
+		})
+	}
+	return collection, nil
+}
+
+// DecodePoints разбирает FeatureCollection. Ошибка возвращается, только если не читается сам документ;
+// проблемы отдельных объектов попадают в Err соответствующего элемента.
+func DecodePoints(data []byte) ([]domain.PointImportItem, error) {
+	var collection FeatureCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("%w: expected a FeatureCollection, got %q", ErrInvalidDocument, collection.Type)
+	}
+	if err := checkCRS(collection.CRS); err != nil {
+		return nil, err
+	}
+	if len(collection.Features) > domain.MaxImportFeatures {
+		return nil, fmt.Errorf("%w: at most %d features can be imported at once", ErrInvalidDocument, domain.MaxImportFeatures)
+	}
+
+	items := make([]domain.PointImportItem, 0, len(collection.Features))
+	for i, feature := range collection.Features {
+		item := domain.PointImportItem{Feature: i, FeatureID: featureID(feature.ID)}
+		item.Point, item.Err = decodePoint(feature)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodePoint(feature Feature) (*domain.CollectionPoint, error) {
+	if feature.Type != "Feature" {
+		return nil, fmt.Errorf("type must be Feature, got %q", feature.Type)
+	}
+	location, err := decodeGeometry(feature.Geometry)
+	if err != nil {
+		return nil, err
+	}
+
+	var properties pointProperties
+	if len(feature.Properties) > 0 && !bytes.Equal(feature.Properties, []byte("null")) {
+		if err := json.Unmarshal(feature.Properties, &properties); err != nil {
+			return nil, fmt.Errorf("invalid properties: %v", err)
+		}
+	}
+	operator := properties.Operator
+	if operator.Name == "" {
+		operator.Name = properties.OperatorName
+	}
+	if operator.Phone == "" {
+		operator.Phone = properties.OperatorPhone
+	}
+	if operator.Website == "" {
+		operator.Website = properties.OperatorWebsite
+	}
+
+	return &domain.CollectionPoint{
+		Name:         properties.Name,
+		Description:  properties.Description,
+		Address:      properties.Address,
+		Latitude:     location.Latitude,
+		Longitude:    location.Longitude,
+		Categories:   properties.Categories,
+		OpeningHours: properties.OpeningHours,
+		Operator:     operator,
+	}, nil
+}
+
+// decodeGeometry принимает только Point с позицией [lon, lat] или [lon, lat, высота]
+func decodeGeometry(geometry *Geometry) (domain.GeoPoint, error) {
+	if geometry == nil {
+		return domain.GeoPoint{}, errors.New("geometry is missing")
+	}
+	if geometry.Type != "Point" {
+		return domain.GeoPoint{}, fmt.Errorf("geometry must be a Point, got %q", geometry.Type)
+	}
+
+	var position []float64
+	if err := json.Unmarshal(geometry.Coordinates, &position); err != nil {
+		return domain.GeoPoint{}, errors.New("point coordinates must be an array of numbers")
+	}
+	if len(position) != 2 && len(position) != 3 {
+		return domain.GeoPoint{}, fmt.Errorf("point must have 2 or 3 coordinates, got %d", len(position))
+	}
+	location := domain.GeoPoint{Longitude: position[0], Latitude: position[1]}
+	if !location.Valid() {
+		return domain.GeoPoint{}, fmt.Errorf("position [%g, %g] is out of range, expected [lon, lat]", position[0], position[1])
+	}
+	return location, nil
+}
+
+// checkCRS пропускает документы без crs и с WGS 84: RFC 7946 разрешает только его,
+// а старые выгрузки иногда всё ещё указывают систему координат явно
+func checkCRS(raw json.RawMessage) error {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	var crs struct {
+		Properties struct {
+			Name string `json:"name"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(raw, &crs); err != nil {
+		return fmt.Errorf("%w: unreadable crs", ErrInvalidDocument)
+	}
+	name := strings.ToUpper(crs.Properties.Name)
+	if strings.HasSuffix(name, "CRS84") || strings.HasSuffix(name, "EPSG::4326") || name == "EPSG:4326" {
+		return nil
+	}
+	return fmt.Errorf("%w: coordinates must be WGS 84 longitude/latitude, got crs %q", ErrInvalidDocument, crs.Properties.Name)
+}
+
+// featureID возвращает id объекта для отчёта: строку как есть, число — его записью
+func featureID(raw json.RawMessage) string {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	return string(raw)
+}
@@ -0,0 +1,182 @@
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+)
+
+func TestDecodePointsDocument(t *testing.T) {
+	feature := `{"type":"Feature","geometry":{"type":"Point","coordinates":[76.9,43.2]},"properties":{"name":"Depot"}}`
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "not JSON", data: `type=FeatureCollection`, wantErr: true},
+		{name: "single feature", data: feature, wantErr: true},
+		{name: "geometry collection", data: `{"type":"GeometryCollection","geometries":[]}`, wantErr: true},
+		{name: "no crs", data: `{"type":"FeatureCollection","features":[` + feature + `]}`},
+		{name: "null crs", data: `{"type":"FeatureCollection","crs":null,"features":[` + feature + `]}`},
+		{name: "CRS84", data: `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"urn:ogc:def:crs:OGC:1.3:CRS84"}},"features":[` + feature + `]}`},
+		{name: "EPSG:4326", data: `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:4326"}},"features":[` + feature + `]}`},
+		{name: "projected crs", data: `{"type":"FeatureCollection","crs":{"type":"name","properties":{"name":"EPSG:3857"}},"features":[` + feature + `]}`, wantErr: true},
+		{name: "unreadable crs", data: `{"type":"FeatureCollection","crs":"EPSG:4326","features":[` + feature + `]}`, wantErr: true},
+		{
+			name:    "too many features",
+			data:    `{"type":"FeatureCollection","features":[` + strings.Repeat(feature+",", domain.MaxImportFeatures) + feature + `]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := DecodePoints([]byte(tt.data))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDocument) {
+					t.Fatalf("DecodePoints error = %v, want ErrInvalidDocument", err)
+				}
+				return
+			}
+			if err != nil || len(items) != 1 || items[0].Err != nil {
+				t.Fatalf("DecodePoints = %+v, %v; want one valid item", items, err)
+			}
+		})
+	}
+}
+
+func TestDecodePointsFeature(t *testing.T) {
+	tests := []struct {
+		name        string
+		feature     string
+		wantID      string
+		wantErr     string
+		wantPoint   domain.CollectionPoint
+		checkFields bool
+	}{
+		{
+			name:        "nested operator and category array",
+			feature:     `{"type":"Feature","id":"depot-1","geometry":{"type":"Point","coordinates":[76.9,43.2]},"properties":{"name":"Depot","address":"Abay 10","categories":["plastic","glass"],"opening_hours":[{"weekday":1,"opens":"09:00","closes":"18:00"}],"operator":{"name":"City","phone":"+7 700"}}}`,
+			wantID:      "depot-1",
+			checkFields: true,
+			wantPoint: domain.CollectionPoint{
+				Name: "Depot", Address: "Abay 10", Latitude: 43.2, Longitude: 76.9,
+				Categories:   []domain.WasteCategory{"plastic", "glass"},
+				OpeningHours: []domain.OpeningInterval{{Weekday: time.Monday, Opens: "09:00", Closes: "18:00"}},
+				Operator:     domain.Operator{Name: "City", Phone: "+7 700"},
+			},
+		},
+		{
+			name:        "flat operator and comma-separated categories",
+			feature:     `{"type":"Feature","id":7,"geometry":{"type":"Point","coordinates":[76.9,43.2,850]},"properties":{"name":"Depot","categories":"plastic, glass,","operator_name":"City","operator_website":"https://example.com"}}`,
+			wantID:      "7",
+			checkFields: true,
+			wantPoint: domain.CollectionPoint{
+				Name: "Depot", Latitude: 43.2, Longitude: 76.9,
+				Categories: []domain.WasteCategory{"plastic", "glass"},
+				Operator:   domain.Operator{Name: "City", Website: "https://example.com"},
+			},
+		},
+		{
+			name:        "nested operator wins over flat fields",
+			feature:     `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{"operator":{"name":"Nested"},"operator_name":"Flat","operator_phone":"112"}}`,
+			checkFields: true,
+			wantPoint:   domain.CollectionPoint{Operator: domain.Operator{Name: "Nested", Phone: "112"}},
+		},
+		{name: "null properties", feature: `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":null}`},
+		{name: "not a feature", feature: `{"type":"Point","coordinates":[0,0]}`, wantErr: "type must be Feature"},
+		{name: "missing geometry", feature: `{"type":"Feature","geometry":null,"properties":{}}`, wantErr: "geometry is missing"},
+		{name: "line", feature: `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{}}`, wantErr: "must be a Point"},
+		{name: "one coordinate", feature: `{"type":"Feature","geometry":{"type":"Point","coordinates":[0]},"properties":{}}`, wantErr: "2 or 3 coordinates"},
+		{name: "text coordinates", feature: `{"type":"Feature","geometry":{"type":"Point","coordinates":["76.9","43.2"]},"properties":{}}`, wantErr: "array of numbers"},
+		{name: "latitude first", feature: `{"type":"Feature","geometry":{"type":"Point","coordinates":[43.2,176.9]},"properties":{}}`, wantErr: "out of range"},
+		{name: "bad categories", feature: `{"type":"Feature","geometry":{"type":"Point","coordinates":[0,0]},"properties":{"categories":{"plastic":true}}}`, wantErr: "categories must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := DecodePoints([]byte(`{"type":"FeatureCollection","features":[` + tt.feature + `]}`))
+			if err != nil {
+				t.Fatalf("DecodePoints: %v", err)
+			}
+			item := items[0]
+			if item.Feature != 0 || item.FeatureID != tt.wantID {
+				t.Errorf("item position/id = %d/%q, want 0/%q", item.Feature, item.FeatureID, tt.wantID)
+			}
+			if tt.wantErr != "" {
+				if item.Err == nil || !strings.Contains(item.Err.Error(), tt.wantErr) {
+					t.Fatalf("item error = %v, want one mentioning %q", item.Err, tt.wantErr)
+				}
+				return
+			}
+			if item.Err != nil {
+				t.Fatalf("item error = %v", item.Err)
+			}
+			if tt.checkFields && fmt.Sprintf("%+v", *item.Point) != fmt.Sprintf("%+v", tt.wantPoint) {
+				t.Fatalf("point = %+v\nwant %+v", *item.Point, tt.wantPoint)
+			}
+		})
+	}
+}
+
+// Выгруженный файл загружается обратно без потерь
+func TestEncodePointsRoundTrip(t *testing.T) {
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	point := domain.CollectionPoint{
+		ID:           uuid.New(),
+		Name:         "Depot",
+		Description:  "Behind the market",
+		Address:      "Abay 10",
+		Latitude:     43.238949,
+		Longitude:    76.889709,
+		Categories:   []domain.WasteCategory{domain.CategoryPaper, domain.CategoryBatteries},
+		OpeningHours: []domain.OpeningInterval{{Weekday: time.Saturday, Opens: "10:00", Closes: "14:00"}},
+		Operator:     domain.Operator{Name: "City", Website: "https://example.com"},
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+
+	collection, err := EncodePoints([]domain.CollectionPoint{point})
+	if err != nil {
+		t.Fatalf("EncodePoints: %v", err)
+	}
+	data, err := json.Marshal(collection)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"coordinates":[76.889709,43.238949]`) {
+		t.Errorf("document %s, want coordinates in [lon, lat] order", data)
+	}
+
+	items, err := DecodePoints(data)
+	if err != nil || len(items) != 1 || items[0].Err != nil {
+		t.Fatalf("DecodePoints = %+v, %v", items, err)
+	}
+	if items[0].FeatureID != point.ID.String() {
+		t.Errorf("feature id = %q, want the point id", items[0].FeatureID)
+	}
+	// Идентификатор и даты назначает сервис при загрузке
+	want := point
+	want.ID, want.CreatedAt, want.UpdatedAt = uuid.Nil, time.Time{}, time.Time{}
+	if got := *items[0].Point; fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", want) {
+		t.Fatalf("round trip = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEncodePointsEmpty(t *testing.T) {
+	collection, err := EncodePoints(nil)
+	if err != nil {
+		t.Fatalf("EncodePoints: %v", err)
+	}
+	data, _ := json.Marshal(collection)
+	if string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Fatalf("empty collection = %s", data)
+	}
+}
@@ -0,0 +1,20 @@
+package database
+
+import (
+	"embed"
+	"log"
+
+	"gorm.io/gorm"
+
+	"dbmigrate"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Ключ pg_advisory_xact_lock, чтобы две реплики не мигрировали одновременно
+const migrationLockKey = 817236003
+
+func NewMigrator(db *gorm.DB) *dbmigrate.Migrator {
+	return dbmigrate.New(db, migrationFiles, "map-service", migrationLockKey, log.Default())
+}
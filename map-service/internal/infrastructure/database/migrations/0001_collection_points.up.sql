@@ -0,0 +1,26 @@
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+CREATE TABLE collection_points (
+    id               UUID PRIMARY KEY,
+    tenant           TEXT NOT NULL,
+    name             TEXT NOT NULL,
+    description      TEXT,
+    address          TEXT,
+    latitude         DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude        DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    categories       JSONB NOT NULL,
+    opening_hours    JSONB,
+    operator_name    TEXT NOT NULL,
+    operator_phone   TEXT,
+    operator_website TEXT,
+    created_at       TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ,
+    -- Приложение пишет только lat/lon, точка для ST_DWithin вычисляется из них
+    location         GEOGRAPHY(Point, 4326) GENERATED ALWAYS AS (
+        ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography
+    ) STORED
+);
+
+CREATE INDEX idx_collection_points_tenant ON collection_points (tenant);
+CREATE INDEX idx_collection_points_location ON collection_points USING GIST (location);
+CREATE INDEX idx_collection_points_categories ON collection_points USING GIN (categories jsonb_path_ops);
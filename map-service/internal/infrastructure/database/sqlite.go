@@ -0,0 +1,22 @@
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"map-service/internal/domain"
+)
+
+// NewSQLiteDatabase нужен для тестов и локальной разработки без PostgreSQL и PostGIS.
+// SQL-миграции написаны под PostgreSQL, поэтому схема создаётся через AutoMigrate.
+func NewSQLiteDatabase(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = db.AutoMigrate(&domain.CollectionPoint{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
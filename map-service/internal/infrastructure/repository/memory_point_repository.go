@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+)
+
+type MemoryPointRepository struct {
+	mu     sync.RWMutex
+	points map[uuid.UUID]domain.CollectionPoint
+}
+
+func NewMemoryPointRepository() domain.PointRepository {
+	return &MemoryPointRepository{points: map[uuid.UUID]domain.CollectionPoint{}}
+}
+
+func (r *MemoryPointRepository) Create(_ context.Context, point *domain.CollectionPoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.points[point.ID] = *point
+	return nil
+}
+
+func (r *MemoryPointRepository) CreateBatch(_ context.Context, points []domain.CollectionPoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, point := range points {
+		r.points[point.ID] = point
+	}
+	return nil
+}
+
+func (r *MemoryPointRepository) Update(_ context.Context, point *domain.CollectionPoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.points[point.ID]
+	if !ok || existing.Tenant != point.Tenant {
+		return domain.ErrPointNotFound
+	}
+	point.CreatedAt = existing.CreatedAt
+	r.points[point.ID] = *point
+	return nil
+}
+
+func (r *MemoryPointRepository) Delete(_ context.Context, tenant string, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	point, ok := r.points[id]
+	if !ok || point.Tenant != tenant {
+		return domain.ErrPointNotFound
+	}
+	delete(r.points, id)
+	return nil
+}
+
+func (r *MemoryPointRepository) FindByID(_ context.Context, tenant string, id uuid.UUID) (*domain.CollectionPoint, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	point, ok := r.points[id]
+	if !ok || point.Tenant != tenant {
+		return nil, domain.ErrPointNotFound
+	}
+	return &point, nil
+}
+
+func (r *MemoryPointRepository) List(_ context.Context, tenant string, filter domain.PointFilter) ([]domain.CollectionPoint, error) {
+	points := r.tenantPoints(tenant, filter.Category)
+	if filter.Bounds != nil {
+		inside := points[:0]
+		for _, point := range points {
+			if filter.Bounds.Contains(point.Location()) {
+				inside = append(inside, point)
+			}
+		}
+		points = inside
+	}
+	return paginate(points, filter.Limit, filter.Offset), nil
+}
+
+func (r *MemoryPointRepository) Nearby(_ context.Context, tenant string, query domain.NearbyQuery) ([]domain.NearbyPoint, error) {
+	return pointsWithin(r.tenantPoints(tenant, query.Category), query), nil
+}
+
+// tenantPoints возвращает пункты тенанта в порядке выдачи списка: по названию, затем по ID
+func (r *MemoryPointRepository) tenantPoints(tenant string, category domain.WasteCategory) []domain.CollectionPoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var points []domain.CollectionPoint
+	for _, point := range r.points {
+		if point.Tenant == tenant && (category == "" || point.Accepts(category)) {
+			points = append(points, point)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Name != points[j].Name {
+			return points[i].Name < points[j].Name
+		}
+		return points[i].ID.String() < points[j].ID.String()
+	})
+	return points
+}
+
+func paginate(points []domain.CollectionPoint, limit, offset int) []domain.CollectionPoint {
+	if offset >= len(points) {
+		return nil
+	}
+	points = points[offset:]
+	if limit > 0 && limit < len(points) {
+		points = points[:limit]
+	}
+	return points
+}
+
+// pointsWithin считает расстояния в Go; так ищут пункты память и базы без PostGIS
+func pointsWithin(points []domain.CollectionPoint, query domain.NearbyQuery) []domain.NearbyPoint {
+	var nearby []domain.NearbyPoint
+	for _, point := range points {
+		if query.Category != "" && !point.Accepts(query.Category) {
+			continue
+		}
+		distance := query.Center.DistanceTo(point.Location())
+		if distance <= query.RadiusMeters {
+			nearby = append(nearby, domain.NearbyPoint{CollectionPoint: point, DistanceMeters: distance})
+		}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].DistanceMeters != nearby[j].DistanceMeters {
+			return nearby[i].DistanceMeters < nearby[j].DistanceMeters
+		}
+		return nearby[i].ID.String() < nearby[j].ID.String()
+	})
+	if query.Limit > 0 && query.Limit < len(nearby) {
+		nearby = nearby[:query.Limit]
+	}
+	return nearby
+}
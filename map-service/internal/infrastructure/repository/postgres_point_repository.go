@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"map-service/internal/domain"
+)
+
+// Точка запроса в виде geography; параметры — долгота и широта
+const queryPoint = "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+
+type PostgresPointRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPointRepository(db *gorm.DB) domain.PointRepository {
+	return &PostgresPointRepository{db: db}
+}
+
+func (r *PostgresPointRepository) Create(ctx context.Context, point *domain.CollectionPoint) error {
+	return r.db.WithContext(ctx).Create(point).Error
+}
+
+// CreateBatch пишет пункты пачками в одной транзакции
+func (r *PostgresPointRepository) CreateBatch(ctx context.Context, points []domain.CollectionPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(&points, 500).Error
+	})
+}
+
+func (r *PostgresPointRepository) Update(ctx context.Context, point *domain.CollectionPoint) error {
+	result := r.db.WithContext(ctx).Model(&domain.CollectionPoint{}).
+		Where("tenant = ? AND id = ?", point.Tenant, point.ID).
+		Select("name", "description", "address", "latitude", "longitude", "categories", "opening_hours",
+			"operator_name", "operator_phone", "operator_website", "updated_at").
+		Updates(point)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPointNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPointRepository) Delete(ctx context.Context, tenant string, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("tenant = ? AND id = ?", tenant, id).Delete(&domain.CollectionPoint{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrPointNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPointRepository) FindByID(ctx context.Context, tenant string, id uuid.UUID) (*domain.CollectionPoint, error) {
+	var point domain.CollectionPoint
+	err := r.db.WithContext(ctx).Where("tenant = ? AND id = ?", tenant, id).First(&point).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrPointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &point, nil
+}
+
+func (r *PostgresPointRepository) List(ctx context.Context, tenant string, filter domain.PointFilter) ([]domain.CollectionPoint, error) {
+	query := r.withCategory(r.db.WithContext(ctx).Where("tenant = ?", tenant), filter.Category).Order("name, id")
+	if bounds := filter.Bounds; bounds != nil {
+		query = query.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", bounds.South, bounds.North, bounds.West, bounds.East)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var points []domain.CollectionPoint
+	err := query.Find(&points).Error
+	return points, err
+}
+
+// Nearby в PostgreSQL отбирает и сортирует пункты по GiST-индексу location, в остальных базах считает расстояния в Go.
+// Расстояние в ответе считается в Go на той же сфере, что и ST_Distance с use_spheroid = false.
+func (r *PostgresPointRepository) Nearby(ctx context.Context, tenant string, query domain.NearbyQuery) ([]domain.NearbyPoint, error) {
+	if r.db.Dialector.Name() != "postgres" {
+		var points []domain.CollectionPoint
+		if err := r.withCategory(r.db.WithContext(ctx).Where("tenant = ?", tenant), query.Category).Find(&points).Error; err != nil {
+			return nil, err
+		}
+		return pointsWithin(points, query), nil
+	}
+
+	center := []any{query.Center.Longitude, query.Center.Latitude}
+	db := r.db.WithContext(ctx).
+		Where("tenant = ? AND ST_DWithin(location, "+queryPoint+", ?, false)", tenant, center[0], center[1], query.RadiusMeters).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ST_Distance(location, " + queryPoint + ", false), id",
+			Vars:               center,
+			WithoutParentheses: true,
+		}})
+	db = r.withCategory(db, query.Category)
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var points []domain.CollectionPoint
+	if err := db.Find(&points).Error; err != nil {
+		return nil, err
+	}
+	nearby := make([]domain.NearbyPoint, 0, len(points))
+	for _, point := range points {
+		nearby = append(nearby, domain.NearbyPoint{CollectionPoint: point, DistanceMeters: query.Center.DistanceTo(point.Location())})
+	}
+	return nearby, nil
+}
+
+// withCategory оставляет пункты, которые принимают category. В PostgreSQL условие идёт через GIN-индекс,
+// в SQLite массив лежит строкой JSON; значения категорий проверены сервисом, поэтому LIKE безопасен.
+func (r *PostgresPointRepository) withCategory(db *gorm.DB, category domain.WasteCategory) *gorm.DB {
+	if category == "" {
+		return db
+	}
+	if r.db.Dialector.Name() == "postgres" {
+		return db.Where("categories @> ?::jsonb", fmt.Sprintf("[%q]", category))
+	}
+	return db.Where("categories LIKE ?", fmt.Sprintf("%%%q%%", category))
+}
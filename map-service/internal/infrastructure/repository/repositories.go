@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"map-service/internal/domain"
+)
+
+type Repositories struct {
+	Points domain.PointRepository
+}
+
+func NewPostgresRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Points: NewPostgresPointRepository(db),
+	}
+}
+
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		Points: NewMemoryPointRepository(),
+	}
+}
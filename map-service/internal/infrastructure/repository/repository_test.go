@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"dbmigrate/pgtest"
+
+	"map-service/internal/infrastructure/database"
+	"map-service/internal/infrastructure/repository"
+	"map-service/internal/infrastructure/repository/repotest"
+)
+
+func TestMemoryRepositories(t *testing.T) {
+	repotest.RunSuite(t, func(t *testing.T) repository.Repositories {
+		return repository.NewMemoryRepositories()
+	})
+}
+
+// Postgres-реализация на SQLite: проверяет запросы, не зависящие от диалекта
+func TestSQLiteRepositories(t *testing.T) {
+	repotest.RunSuite(t, func(t *testing.T) repository.Repositories {
+		db, err := database.NewSQLiteDatabase(filepath.Join(t.TempDir(), "map-service.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteDatabase: %v", err)
+		}
+		return repository.NewPostgresRepositories(db)
+	})
+}
+
+// Postgres-реализация на настоящем PostgreSQL с PostGIS и схемой из миграций; пропускается без TEST_POSTGRES_DSN
+func TestPostgresRepositories(t *testing.T) {
+	pgtest.DSN(t)
+	repotest.RunSuite(t, func(t *testing.T) repository.Repositories {
+		db := pgtest.Open(t)
+		if err := database.NewMigrator(db).Up(context.Background()); err != nil {
+			t.Fatalf("migrate up: %v", err)
+		}
+		return repository.NewPostgresRepositories(db)
+	})
+}
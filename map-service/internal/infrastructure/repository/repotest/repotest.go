@@ -0,0 +1,306 @@
+// Package repotest содержит общий набор проверок, который должна проходить каждая реализация репозиториев:
+//
+//	func TestMemoryRepositories(t *testing.T) {
+//		repotest.RunSuite(t, func(t *testing.T) repository.Repositories {
+//			return repository.NewMemoryRepositories()
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+	"map-service/internal/infrastructure/repository"
+)
+
+// RunSuite запускает проверки на свежем наборе репозиториев, который возвращает newRepos
+func RunSuite(t *testing.T, newRepos func(t *testing.T) repository.Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repos repository.Repositories)
+	}{
+		{"CreateAndFindPoint", testCreateAndFindPoint},
+		{"UpdatePoint", testUpdatePoint},
+		{"DeletePoint", testDeletePoint},
+		{"TenantIsolation", testTenantIsolation},
+		{"ListPoints", testListPoints},
+		{"CreateBatch", testCreateBatch},
+		{"NearbyPoints", testNearbyPoints},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepos(t))
+		})
+	}
+}
+
+func newPoint(tenant, name string, lat, lon float64, categories ...domain.WasteCategory) *domain.CollectionPoint {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return &domain.CollectionPoint{
+		ID:         uuid.New(),
+		Tenant:     tenant,
+		Name:       name,
+		Latitude:   lat,
+		Longitude:  lon,
+		Categories: categories,
+		Operator:   domain.Operator{Name: "City Recycling"},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func createPoints(t *testing.T, repos repository.Repositories, points ...*domain.CollectionPoint) {
+	t.Helper()
+	for _, point := range points {
+		if err := repos.Points.Create(context.Background(), point); err != nil {
+			t.Fatalf("Create(%s): %v", point.Name, err)
+		}
+	}
+}
+
+func testCreateAndFindPoint(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	point := newPoint("default", "Depot", 55.75, 37.61, domain.CategoryGlass, domain.CategoryPaper)
+	point.Address = "Tverskaya 1"
+	point.OpeningHours = []domain.OpeningInterval{{Weekday: time.Monday, Opens: "09:00", Closes: "18:00"}}
+	point.Operator.Phone = "+7 495 000-00-00"
+	createPoints(t, repos, point)
+
+	found, err := repos.Points.FindByID(ctx, "default", point.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "Depot" || found.Address != "Tverskaya 1" || found.Latitude != 55.75 || found.Longitude != 37.61 {
+		t.Errorf("FindByID returned %+v", found)
+	}
+	if len(found.Categories) != 2 || !found.Accepts(domain.CategoryGlass) || !found.Accepts(domain.CategoryPaper) {
+		t.Errorf("categories = %v, want glass and paper", found.Categories)
+	}
+	if len(found.OpeningHours) != 1 || found.OpeningHours[0].Opens != "09:00" {
+		t.Errorf("opening hours = %+v", found.OpeningHours)
+	}
+	if found.Operator.Name != "City Recycling" || found.Operator.Phone != "+7 495 000-00-00" {
+		t.Errorf("operator = %+v", found.Operator)
+	}
+
+	if _, err := repos.Points.FindByID(ctx, "default", uuid.New()); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("FindByID(unknown) error = %v, want ErrPointNotFound", err)
+	}
+}
+
+func testUpdatePoint(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	point := newPoint("default", "Depot", 55.75, 37.61, domain.CategoryGlass)
+	createPoints(t, repos, point)
+
+	updated := *point
+	updated.Name = "Central depot"
+	updated.Latitude = 55.76
+	updated.Categories = []domain.WasteCategory{domain.CategoryMetal}
+	updated.OpeningHours = nil
+	updated.Operator = domain.Operator{Name: "EcoLine", Website: "https://ecoline.example"}
+	updated.UpdatedAt = point.UpdatedAt.Add(time.Hour)
+	if err := repos.Points.Update(ctx, &updated); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	found, err := repos.Points.FindByID(ctx, "default", point.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "Central depot" || found.Latitude != 55.76 || !found.Accepts(domain.CategoryMetal) || found.Accepts(domain.CategoryGlass) {
+		t.Errorf("point after update = %+v", found)
+	}
+	if found.Operator.Name != "EcoLine" || found.Operator.Website != "https://ecoline.example" {
+		t.Errorf("operator after update = %+v", found.Operator)
+	}
+	if !found.CreatedAt.Equal(point.CreatedAt) {
+		t.Errorf("created_at changed from %v to %v", point.CreatedAt, found.CreatedAt)
+	}
+
+	missing := newPoint("default", "Missing", 0, 0, domain.CategoryGlass)
+	if err := repos.Points.Update(ctx, missing); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("Update(unknown) error = %v, want ErrPointNotFound", err)
+	}
+}
+
+func testDeletePoint(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	point := newPoint("default", "Depot", 55.75, 37.61, domain.CategoryGlass)
+	createPoints(t, repos, point)
+
+	if err := repos.Points.Delete(ctx, "default", point.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repos.Points.FindByID(ctx, "default", point.ID); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("FindByID after delete error = %v, want ErrPointNotFound", err)
+	}
+	if err := repos.Points.Delete(ctx, "default", point.ID); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("second Delete error = %v, want ErrPointNotFound", err)
+	}
+}
+
+func testTenantIsolation(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	point := newPoint("north", "Depot", 55.75, 37.61, domain.CategoryGlass)
+	createPoints(t, repos, point)
+
+	if _, err := repos.Points.FindByID(ctx, "south", point.ID); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("FindByID from another tenant error = %v, want ErrPointNotFound", err)
+	}
+	if err := repos.Points.Delete(ctx, "south", point.ID); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("Delete from another tenant error = %v, want ErrPointNotFound", err)
+	}
+	foreign := *point
+	foreign.Tenant = "south"
+	foreign.Name = "Hijacked"
+	if err := repos.Points.Update(ctx, &foreign); !errors.Is(err, domain.ErrPointNotFound) {
+		t.Errorf("Update from another tenant error = %v, want ErrPointNotFound", err)
+	}
+
+	points, err := repos.Points.List(ctx, "south", domain.PointFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(points) != 0 {
+		t.Errorf("List for another tenant returned %d points", len(points))
+	}
+	nearby, err := repos.Points.Nearby(ctx, "south", domain.NearbyQuery{Center: point.Location(), RadiusMeters: 1000})
+	if err != nil {
+		t.Fatalf("Nearby: %v", err)
+	}
+	if len(nearby) != 0 {
+		t.Errorf("Nearby for another tenant returned %d points", len(nearby))
+	}
+}
+
+func testListPoints(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	createPoints(t, repos,
+		newPoint("default", "Charlie", 55.70, 37.60, domain.CategoryGlass, domain.CategoryPlastic),
+		newPoint("default", "Alpha", 55.71, 37.61, domain.CategoryPlastic),
+		newPoint("default", "Bravo", 55.72, 37.62, domain.CategoryBatteries),
+	)
+
+	points, err := repos.Points.List(ctx, "default", domain.PointFilter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if names := pointNames(points); len(names) != 3 || names[0] != "Alpha" || names[1] != "Bravo" || names[2] != "Charlie" {
+		t.Errorf("List order = %v, want Alpha, Bravo, Charlie", names)
+	}
+
+	points, err = repos.Points.List(ctx, "default", domain.PointFilter{Category: domain.CategoryPlastic})
+	if err != nil {
+		t.Fatalf("List(plastic): %v", err)
+	}
+	if names := pointNames(points); len(names) != 2 || names[0] != "Alpha" || names[1] != "Charlie" {
+		t.Errorf("List(plastic) = %v, want Alpha, Charlie", names)
+	}
+
+	points, err = repos.Points.List(ctx, "default", domain.PointFilter{Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("List(page): %v", err)
+	}
+	if names := pointNames(points); len(names) != 1 || names[0] != "Bravo" {
+		t.Errorf("List(limit 1, offset 1) = %v, want Bravo", names)
+	}
+
+	bounds := &domain.GeoBounds{West: 37.605, South: 55.705, East: 37.7, North: 55.8}
+	points, err = repos.Points.List(ctx, "default", domain.PointFilter{Bounds: bounds})
+	if err != nil {
+		t.Fatalf("List(bbox): %v", err)
+	}
+	if names := pointNames(points); len(names) != 2 || names[0] != "Alpha" || names[1] != "Bravo" {
+		t.Errorf("List(bbox) = %v, want Alpha, Bravo", names)
+	}
+	points, err = repos.Points.List(ctx, "default", domain.PointFilter{Bounds: bounds, Category: domain.CategoryPlastic})
+	if err != nil {
+		t.Fatalf("List(bbox, plastic): %v", err)
+	}
+	if names := pointNames(points); len(names) != 1 || names[0] != "Alpha" {
+		t.Errorf("List(bbox, plastic) = %v, want Alpha", names)
+	}
+}
+
+func testCreateBatch(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	points := []domain.CollectionPoint{
+		*newPoint("default", "First", 55.70, 37.60, domain.CategoryGlass),
+		*newPoint("default", "Second", 55.71, 37.61, domain.CategoryPaper),
+	}
+	if err := repos.Points.CreateBatch(ctx, points); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if err := repos.Points.CreateBatch(ctx, nil); err != nil {
+		t.Fatalf("CreateBatch(empty): %v", err)
+	}
+
+	for _, point := range points {
+		found, err := repos.Points.FindByID(ctx, "default", point.ID)
+		if err != nil {
+			t.Fatalf("FindByID(%s): %v", point.Name, err)
+		}
+		if found.Name != point.Name || !found.Accepts(point.Categories[0]) {
+			t.Errorf("FindByID(%s) = %+v", point.Name, found)
+		}
+	}
+}
+
+func testNearbyPoints(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	center := domain.GeoPoint{Latitude: 55.7500, Longitude: 37.6200}
+	// Около 111 м на тысячную градуса широты
+	createPoints(t, repos,
+		newPoint("default", "Far", 55.7540, 37.6200, domain.CategoryGlass),
+		newPoint("default", "Near", 55.7510, 37.6200, domain.CategoryGlass, domain.CategoryPaper),
+		newPoint("default", "Middle", 55.7475, 37.6200, domain.CategoryPaper),
+		newPoint("default", "Outside", 55.7700, 37.6200, domain.CategoryGlass),
+	)
+
+	nearby, err := repos.Points.Nearby(ctx, "default", domain.NearbyQuery{Center: center, RadiusMeters: 1000})
+	if err != nil {
+		t.Fatalf("Nearby: %v", err)
+	}
+	var names []string
+	for _, point := range nearby {
+		names = append(names, point.Name)
+	}
+	if len(names) != 3 || names[0] != "Near" || names[1] != "Middle" || names[2] != "Far" {
+		t.Fatalf("Nearby = %v, want Near, Middle, Far", names)
+	}
+	if distance := nearby[0].DistanceMeters; distance < 105 || distance > 117 {
+		t.Errorf("distance to Near = %.1f m, want about 111 m", distance)
+	}
+
+	nearby, err = repos.Points.Nearby(ctx, "default", domain.NearbyQuery{Center: center, RadiusMeters: 1000, Category: domain.CategoryGlass, Limit: 1})
+	if err != nil {
+		t.Fatalf("Nearby(glass, limit 1): %v", err)
+	}
+	if len(nearby) != 1 || nearby[0].Name != "Near" {
+		t.Errorf("Nearby(glass, limit 1) = %+v, want only Near", nearby)
+	}
+
+	nearby, err = repos.Points.Nearby(ctx, "default", domain.NearbyQuery{Center: center, RadiusMeters: 3000, Category: domain.CategoryGlass})
+	if err != nil {
+		t.Fatalf("Nearby(glass, 3 km): %v", err)
+	}
+	if len(nearby) != 3 || nearby[2].Name != "Outside" {
+		t.Errorf("Nearby(glass, 3 km) returned %d points, want Near, Far and Outside", len(nearby))
+	}
+}
+
+func pointNames(points []domain.CollectionPoint) []string {
+	names := make([]string, 0, len(points))
+	for _, point := range points {
+		names = append(names, point.Name)
+	}
+	return names
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"map-service/internal/domain"
+	"map-service/internal/geojson"
+	"map-service/internal/requestctx"
+)
+
+// exportPoints отдаёт пункты как GeoJSON FeatureCollection; фильтры bbox и category те же, что у списка
+func (s *MapServer) exportPoints(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.PointFilter{Category: domain.WasteCategory(query.Get("category"))}
+
+	var err error
+	if filter.Bounds, err = bboxParam(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	points, err := s.PointService.ExportPoints(r.Context(), requestctx.Tenant(r.Context()), filter)
+	if err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+	collection, err := geojson.EncodePoints(points)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", geojson.ContentType)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// importPoints создаёт пункты из GeoJSON FeatureCollection. Файл с ошибками отклоняется целиком
+// с отчётом по каждому объекту; dry_run=true только проверяет файл.
+func (s *MapServer) importPoints(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, domain.MaxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "file is larger than 10 MB", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := geojson.DecodePoints(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	report, err := s.PointService.ImportPoints(r.Context(), requestctx.Tenant(r.Context()), items, dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+
+	switch {
+	case dryRun:
+		w.WriteHeader(http.StatusOK)
+	case len(report.Errors) > 0:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	default:
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"map-service/internal/domain"
+	"map-service/internal/geojson"
+	"map-service/internal/infrastructure/repository"
+	"map-service/internal/requestctx"
+)
+
+const (
+	almatyDepot = `{"type":"Feature","id":"almaty","geometry":{"type":"Point","coordinates":[76.9,43.2]},"properties":{"name":"Almaty depot","categories":"plastic,glass","operator_name":"City"}}`
+	astanaDepot = `{"type":"Feature","id":"astana","geometry":{"type":"Point","coordinates":[71.4,51.1]},"properties":{"name":"Astana depot","categories":["paper"],"operator":{"name":"City"}}}`
+	// Без оператора: разбирается, но не проходит проверку сервиса
+	noOperatorDepot = `{"type":"Feature","id":"no-operator","geometry":{"type":"Point","coordinates":[69.6,42.3]},"properties":{"name":"Shymkent depot","categories":["metal"]}}`
+)
+
+func TestImportPoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		role        string
+		wantStatus  int
+		wantCreated int
+		wantErrors  []string
+	}{
+		{name: "valid file", body: collection(almatyDepot, astanaDepot), wantStatus: http.StatusCreated, wantCreated: 2},
+		{name: "dry run", query: "?dry_run=true", body: collection(almatyDepot, astanaDepot), wantStatus: http.StatusOK},
+		{name: "one bad feature rejects the file", body: collection(almatyDepot, noOperatorDepot), wantStatus: http.StatusUnprocessableEntity, wantErrors: []string{"no-operator"}},
+		{name: "dry run reports errors", query: "?dry_run=true", body: collection(noOperatorDepot, `{"type":"Feature","geometry":null}`), wantStatus: http.StatusOK, wantErrors: []string{"no-operator", ""}},
+		{name: "no features", body: collection(), wantStatus: http.StatusBadRequest},
+		{name: "not GeoJSON", body: `{"points":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "resident", body: collection(almatyDepot), role: "user", wantStatus: http.StatusForbidden},
+		{name: "anonymous", body: collection(almatyDepot), role: "-", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, repos := newTestServer(t)
+
+			rec := srv.do(t, http.MethodPost, "/points/import"+tt.query, tt.role, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, strings.TrimSpace(rec.Body.String()), tt.wantStatus)
+			}
+
+			stored, err := repos.Points.List(context.Background(), domain.DefaultTenant, domain.PointFilter{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(stored) != tt.wantCreated {
+				t.Fatalf("stored %d points, want %d", len(stored), tt.wantCreated)
+			}
+			if rec.Code >= http.StatusBadRequest && rec.Code != http.StatusUnprocessableEntity {
+				return
+			}
+
+			var report domain.PointImportReport
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("decode report: %v", err)
+			}
+			if report.Created != tt.wantCreated || len(report.PointIDs) != tt.wantCreated {
+				t.Errorf("report created %d (%d ids), want %d", report.Created, len(report.PointIDs), tt.wantCreated)
+			}
+			if report.DryRun != (tt.query != "") || report.Valid != report.Total-len(tt.wantErrors) {
+				t.Errorf("report = %+v, want %d invalid features", report, len(tt.wantErrors))
+			}
+			if len(report.Errors) != len(tt.wantErrors) {
+				t.Fatalf("report errors = %+v, want %v", report.Errors, tt.wantErrors)
+			}
+			for i, rowErr := range report.Errors {
+				if rowErr.ID != tt.wantErrors[i] || rowErr.Message == "" {
+					t.Errorf("error %d = %+v, want feature %q with a message", i, rowErr, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExportPoints(t *testing.T) {
+	srv, _ := newTestServer(t)
+	rec := srv.do(t, http.MethodPost, "/points/import", "", collection(almatyDepot, astanaDepot))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import status = %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantNames  []string
+	}{
+		{name: "everything", wantStatus: http.StatusOK, wantNames: []string{"Almaty depot", "Astana depot"}},
+		{name: "by category", query: "?category=paper", wantStatus: http.StatusOK, wantNames: []string{"Astana depot"}},
+		{name: "by bbox", query: "?bbox=75,42,78,44", wantStatus: http.StatusOK, wantNames: []string{"Almaty depot"}},
+		{name: "nothing matches", query: "?category=bulky", wantStatus: http.StatusOK},
+		{name: "unknown category", query: "?category=plutonium", wantStatus: http.StatusBadRequest},
+		{name: "swapped bbox", query: "?bbox=78,44,75,42", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := srv.do(t, http.MethodGet, "/points/export"+tt.query, "user", "")
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, strings.TrimSpace(rec.Body.String()), tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if contentType := rec.Header().Get("Content-Type"); contentType != geojson.ContentType {
+				t.Errorf("Content-Type = %q, want %q", contentType, geojson.ContentType)
+			}
+
+			// Выгрузка читается тем же разбором, что и загрузка
+			items, err := geojson.DecodePoints(rec.Body.Bytes())
+			if err != nil {
+				t.Fatalf("DecodePoints: %v", err)
+			}
+			var names []string
+			for _, item := range items {
+				if item.Err != nil {
+					t.Fatalf("exported feature %d: %v", item.Feature, item.Err)
+				}
+				names = append(names, item.Point.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Fatalf("exported %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+type testServer struct {
+	*MapServer
+}
+
+func newTestServer(t *testing.T) (*testServer, repository.Repositories) {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	return &testServer{NewMapServer(repos, Config{DefaultLocation: time.UTC})}, repos
+}
+
+// do выполняет запрос с заголовками шлюза; пустая роль — администратор, "-" — анонимный запрос
+func (s *testServer) do(t *testing.T, method, path, role, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	switch role {
+	case "-":
+	case "":
+		role = requestctx.RoleAdmin
+		fallthrough
+	default:
+		req.Header.Set(requestctx.UserIDHeader, "1")
+		req.Header.Set(requestctx.UserEmailHeader, role+"@example.com")
+		req.Header.Set(requestctx.UserRoleHeader, role)
+	}
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func collection(features ...string) string {
+	return `{"type":"FeatureCollection","features":[` + strings.Join(features, ",") + `]}`
+}
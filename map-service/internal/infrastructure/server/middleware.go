@@ -0,0 +1,71 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+	"map-service/internal/requestctx"
+)
+
+// requestContext переносит идентификатор запроса, тенанта и пользователя из заголовков шлюза в контекст.
+// Заголовки пользователя без токена шлюза отклоняются.
+func (s *MapServer) requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestctx.RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestctx.RequestIDHeader, requestID)
+
+		tenant := r.Header.Get(requestctx.TenantHeader)
+		if tenant == "" {
+			tenant = domain.DefaultTenant
+		}
+
+		ctx := requestctx.WithTenant(requestctx.WithRequestID(r.Context(), requestID), tenant)
+		if authUserID := r.Header.Get(requestctx.UserIDHeader); authUserID != "" {
+			if !s.fromGateway(r) {
+				http.Error(w, "Invalid gateway token", http.StatusUnauthorized)
+				return
+			}
+			ctx = requestctx.WithActor(ctx, &requestctx.Actor{
+				AuthUserID: authUserID,
+				Email:      r.Header.Get(requestctx.UserEmailHeader),
+				Role:       r.Header.Get(requestctx.UserRoleHeader),
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *MapServer) fromGateway(r *http.Request) bool {
+	if s.GatewayToken == "" {
+		return true
+	}
+	token := r.Header.Get(requestctx.GatewayTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.GatewayToken)) == 1
+}
+
+// requireRole пропускает только запросы от пользователей с одной из указанных ролей
+func requireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor := requestctx.ActorFrom(r.Context())
+			if actor == nil {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if actor.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
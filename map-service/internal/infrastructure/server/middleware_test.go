@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"map-service/internal/requestctx"
+)
+
+func TestRequestContextGatewayToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		sent       string
+		withUser   bool
+		wantStatus int
+		wantActor  bool
+	}{
+		{name: "valid token", configured: "secret", sent: "secret", withUser: true, wantStatus: http.StatusOK, wantActor: true},
+		{name: "missing token", configured: "secret", withUser: true, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", configured: "secret", sent: "guess", withUser: true, wantStatus: http.StatusUnauthorized},
+		{name: "anonymous without token", configured: "secret", wantStatus: http.StatusOK},
+		{name: "verification disabled", withUser: true, wantStatus: http.StatusOK, wantActor: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &MapServer{GatewayToken: tt.configured}
+			var actor *requestctx.Actor
+			handler := srv.requestContext(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = requestctx.ActorFrom(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/points", nil)
+			if tt.withUser {
+				req.Header.Set(requestctx.UserIDHeader, "1")
+				req.Header.Set(requestctx.UserEmailHeader, "resident@example.com")
+				req.Header.Set(requestctx.UserRoleHeader, "admin")
+			}
+			if tt.sent != "" {
+				req.Header.Set(requestctx.GatewayTokenHeader, tt.sent)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d (%s), want %d", rec.Code, rec.Body.String(), tt.wantStatus)
+			}
+			if (actor != nil) != tt.wantActor {
+				t.Fatalf("actor = %+v, want present: %v", actor, tt.wantActor)
+			}
+		})
+	}
+}
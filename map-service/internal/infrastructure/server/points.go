@@ -0,0 +1,247 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+	"map-service/internal/requestctx"
+)
+
+// pointRequest — тело POST и PUT; id, тенант и даты задаёт сервис
+type pointRequest struct {
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	Address      string                   `json:"address"`
+	Latitude     *float64                 `json:"lat"`
+	Longitude    *float64                 `json:"lon"`
+	Categories   []domain.WasteCategory   `json:"categories"`
+	OpeningHours []domain.OpeningInterval `json:"opening_hours"`
+	Operator     domain.Operator          `json:"operator"`
+}
+
+func (req pointRequest) point() (*domain.CollectionPoint, error) {
+	if req.Latitude == nil || req.Longitude == nil {
+		return nil, fmt.Errorf("%w: lat and lon are required", domain.ErrInvalidInput)
+	}
+	return &domain.CollectionPoint{
+		Name:         req.Name,
+		Description:  req.Description,
+		Address:      req.Address,
+		Latitude:     *req.Latitude,
+		Longitude:    *req.Longitude,
+		Categories:   req.Categories,
+		OpeningHours: req.OpeningHours,
+		Operator:     req.Operator,
+	}, nil
+}
+
+func decodePoint(r *http.Request) (*domain.CollectionPoint, error) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	var req pointRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	return req.point()
+}
+
+func (s *MapServer) createPoint(w http.ResponseWriter, r *http.Request) {
+	point, err := decodePoint(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.PointService.CreatePoint(r.Context(), requestctx.Tenant(r.Context()), point); err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(point)
+}
+
+func (s *MapServer) getPoint(w http.ResponseWriter, r *http.Request) {
+	pointID, err := uuid.Parse(chi.URLParam(r, "pointID"))
+	if err != nil {
+		http.Error(w, "Invalid point ID", http.StatusBadRequest)
+		return
+	}
+
+	point, err := s.PointService.GetPoint(r.Context(), requestctx.Tenant(r.Context()), pointID)
+	if err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(point)
+}
+
+func (s *MapServer) updatePoint(w http.ResponseWriter, r *http.Request) {
+	pointID, err := uuid.Parse(chi.URLParam(r, "pointID"))
+	if err != nil {
+		http.Error(w, "Invalid point ID", http.StatusBadRequest)
+		return
+	}
+
+	point, err := decodePoint(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	point.ID = pointID
+
+	if err := s.PointService.UpdatePoint(r.Context(), requestctx.Tenant(r.Context()), point); err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(point)
+}
+
+func (s *MapServer) deletePoint(w http.ResponseWriter, r *http.Request) {
+	pointID, err := uuid.Parse(chi.URLParam(r, "pointID"))
+	if err != nil {
+		http.Error(w, "Invalid point ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.PointService.DeletePoint(r.Context(), requestctx.Tenant(r.Context()), pointID); err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *MapServer) listPoints(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.PointFilter{Category: domain.WasteCategory(query.Get("category"))}
+
+	var err error
+	if filter.Bounds, err = bboxParam(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, err = intParam(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Offset, err = intParam(query, "offset"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	points, err := s.PointService.ListPoints(r.Context(), requestctx.Tenant(r.Context()), filter)
+	if err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+	if points == nil {
+		points = []domain.CollectionPoint{}
+	}
+
+	json.NewEncoder(w).Encode(points)
+}
+
+// findNearbyPoints: lat и lon обязательны, radius в метрах
+func (s *MapServer) findNearbyPoints(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	nearby := domain.NearbyQuery{Category: domain.WasteCategory(query.Get("category"))}
+
+	var err error
+	if query.Get("lat") == "" || query.Get("lon") == "" {
+		http.Error(w, "lat and lon are required", http.StatusBadRequest)
+		return
+	}
+	if nearby.Center.Latitude, err = floatParam(query, "lat"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if nearby.Center.Longitude, err = floatParam(query, "lon"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if nearby.RadiusMeters, err = floatParam(query, "radius"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if nearby.Limit, err = intParam(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	points, err := s.PointService.FindNearby(r.Context(), requestctx.Tenant(r.Context()), nearby)
+	if err != nil {
+		http.Error(w, err.Error(), mapErrorStatus(err))
+		return
+	}
+	if points == nil {
+		points = []domain.NearbyPoint{}
+	}
+
+	json.NewEncoder(w).Encode(points)
+}
+
+// bboxParam читает bbox=запад,юг,восток,север в градусах; прямоугольник через антимеридиан не поддерживается
+func bboxParam(query url.Values) (*domain.GeoBounds, error) {
+	value := query.Get("bbox")
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bbox %q: expected west,south,east,north", value)
+	}
+	var sides [4]float64
+	for i, part := range parts {
+		side, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox %q: expected west,south,east,north", value)
+		}
+		sides[i] = side
+	}
+
+	bounds := &domain.GeoBounds{West: sides[0], South: sides[1], East: sides[2], North: sides[3]}
+	if !(domain.GeoPoint{Latitude: bounds.South, Longitude: bounds.West}).Valid() ||
+		!(domain.GeoPoint{Latitude: bounds.North, Longitude: bounds.East}).Valid() ||
+		bounds.West > bounds.East || bounds.South > bounds.North {
+		return nil, fmt.Errorf("invalid bbox %q: sides are out of range or in the wrong order", value)
+	}
+	return bounds, nil
+}
+
+func floatParam(query url.Values, name string) (float64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.New("invalid " + name)
+	}
+	return number, nil
+}
+
+func intParam(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 {
+		return 0, errors.New("invalid " + name)
+	}
+	return number, nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"map-service/internal/domain"
+	"map-service/internal/infrastructure/repository"
+	"map-service/internal/requestctx"
+	"map-service/internal/usecase"
+)
+
+type MapServer struct {
+	Router       *chi.Mux
+	PointService domain.PointService
+	GatewayToken string
+}
+
+// Config — настройки сервера из окружения
+type Config struct {
+	// Часовой пояс, в котором заданы часы работы пунктов
+	DefaultLocation *time.Location
+	// Общий со шлюзом токен: заголовкам X-User-* верим только вместе с ним; пустой отключает проверку
+	GatewayToken string
+}
+
+func NewMapServer(repos repository.Repositories, cfg Config) *MapServer {
+	srv := &MapServer{
+		Router:       chi.NewRouter(),
+		PointService: usecase.NewPointService(repos.Points, cfg.DefaultLocation),
+		GatewayToken: cfg.GatewayToken,
+	}
+
+	srv.setupRoutes()
+	return srv
+}
+
+// setupRoutes: шлюз отдаёт эти пути под префиксом /map и пускает только запросы с токеном
+func (s *MapServer) setupRoutes() {
+	s.Router.Use(s.requestContext)
+
+	s.Router.Get("/points", s.listPoints)
+	s.Router.Get("/points/nearby", s.findNearbyPoints)
+	s.Router.Get("/points/export", s.exportPoints)
+	s.Router.Get("/points/{pointID}", s.getPoint)
+
+	s.Router.Group(func(r chi.Router) {
+		r.Use(requireRole(requestctx.RoleAdmin))
+
+		r.Post("/points", s.createPoint)
+		r.Post("/points/import", s.importPoints)
+		r.Put("/points/{pointID}", s.updatePoint)
+		r.Delete("/points/{pointID}", s.deletePoint)
+	})
+}
+
+func (s *MapServer) Routes() http.Handler {
+	return s.Router
+}
+
+func mapErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPointNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
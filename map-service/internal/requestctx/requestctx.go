@@ -0,0 +1,59 @@
+package requestctx
+
+import "context"
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	TenantHeader    = "X-Tenant"
+	UserIDHeader    = "X-User-ID"
+	UserEmailHeader = "X-User-Email"
+	UserRoleHeader  = "X-User-Role"
+	// Токен, которым шлюз подтверждает заголовки X-User-*
+	GatewayTokenHeader = "X-Gateway-Token"
+)
+
+// Роль, которой разрешено менять пункты приёма
+const RoleAdmin = "admin"
+
+// Actor — аутентифицированный пользователь, от имени которого шлюз выполняет запрос
+type Actor struct {
+	AuthUserID string
+	Email      string
+	Role       string
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	tenantKey
+	actorKey
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithTenant запоминает slug тенанта: своего списка тенантов у map-service нет, ему достаточно значения от шлюза
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey).(string)
+	return tenant
+}
+
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFrom(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(actorKey).(*Actor)
+	return actor
+}
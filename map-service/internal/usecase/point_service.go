@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"map-service/internal/domain"
+)
+
+const (
+	DefaultNearbyRadius = 1000.0
+	MaxNearbyRadius     = 50000.0
+	DefaultNearbyLimit  = 20
+	MaxNearbyLimit      = 100
+	maxListLimit        = 500
+	maxPointText        = 200
+	maxPointDescription = 2000
+)
+
+var clockPattern = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+type PointServiceImpl struct {
+	repo     domain.PointRepository
+	location *time.Location
+	now      func() time.Time
+}
+
+// NewPointService: location — часовой пояс, в котором заданы часы работы пунктов
+func NewPointService(repo domain.PointRepository, location *time.Location) domain.PointService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &PointServiceImpl{repo: repo, location: location, now: time.Now}
+}
+
+func (s *PointServiceImpl) CreatePoint(ctx context.Context, tenant string, point *domain.CollectionPoint) error {
+	if err := normalizePoint(point); err != nil {
+		return err
+	}
+	point.ID = uuid.New()
+	point.Tenant = tenant
+	point.CreatedAt = s.now()
+	point.UpdatedAt = point.CreatedAt
+	return s.repo.Create(ctx, point)
+}
+
+// UpdatePoint заменяет описание пункта целиком, ID берётся из пути запроса
+func (s *PointServiceImpl) UpdatePoint(ctx context.Context, tenant string, point *domain.CollectionPoint) error {
+	if err := normalizePoint(point); err != nil {
+		return err
+	}
+	point.Tenant = tenant
+	point.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, point); err != nil {
+		return err
+	}
+
+	stored, err := s.repo.FindByID(ctx, tenant, point.ID)
+	if err != nil {
+		return err
+	}
+	*point = *stored
+	return nil
+}
+
+func (s *PointServiceImpl) DeletePoint(ctx context.Context, tenant string, id uuid.UUID) error {
+	return s.repo.Delete(ctx, tenant, id)
+}
+
+func (s *PointServiceImpl) GetPoint(ctx context.Context, tenant string, id uuid.UUID) (*domain.CollectionPoint, error) {
+	return s.repo.FindByID(ctx, tenant, id)
+}
+
+func (s *PointServiceImpl) ListPoints(ctx context.Context, tenant string, filter domain.PointFilter) ([]domain.CollectionPoint, error) {
+	if filter.Category != "" && !filter.Category.Valid() {
+		return nil, fmt.Errorf("%w: unknown category %q", domain.ErrInvalidInput, filter.Category)
+	}
+	if filter.Limit == 0 || filter.Limit > maxListLimit {
+		filter.Limit = maxListLimit
+	}
+	return s.repo.List(ctx, tenant, filter)
+}
+
+// FindNearby ищет пункты в радиусе от точки и отмечает, какие из них открыты прямо сейчас
+func (s *PointServiceImpl) FindNearby(ctx context.Context, tenant string, query domain.NearbyQuery) ([]domain.NearbyPoint, error) {
+	if !query.Center.Valid() {
+		return nil, fmt.Errorf("%w: lat must be within [-90, 90] and lon within [-180, 180]", domain.ErrInvalidInput)
+	}
+	if query.Category != "" && !query.Category.Valid() {
+		return nil, fmt.Errorf("%w: unknown category %q", domain.ErrInvalidInput, query.Category)
+	}
+	switch {
+	case query.RadiusMeters == 0:
+		query.RadiusMeters = DefaultNearbyRadius
+	case query.RadiusMeters < 0 || query.RadiusMeters > MaxNearbyRadius || math.IsNaN(query.RadiusMeters):
+		return nil, fmt.Errorf("%w: radius must be between 0 and %.0f meters", domain.ErrInvalidInput, MaxNearbyRadius)
+	}
+	if query.Limit == 0 {
+		query.Limit = DefaultNearbyLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxNearbyLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidInput, MaxNearbyLimit)
+	}
+
+	points, err := s.repo.Nearby(ctx, tenant, query)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().In(s.location)
+	for i := range points {
+		points[i].OpenNow = points[i].OpenAt(now)
+	}
+	return points, nil
+}
+
+func (s *PointServiceImpl) ExportPoints(ctx context.Context, tenant string, filter domain.PointFilter) ([]domain.CollectionPoint, error) {
+	if filter.Category != "" && !filter.Category.Valid() {
+		return nil, fmt.Errorf("%w: unknown category %q", domain.ErrInvalidInput, filter.Category)
+	}
+	filter.Limit, filter.Offset = 0, 0
+	return s.repo.List(ctx, tenant, filter)
+}
+
+// ImportPoints проверяет каждый объект файла и создаёт пункты, только если ошибок нет ни в одном
+func (s *PointServiceImpl) ImportPoints(ctx context.Context, tenant string, items []domain.PointImportItem, dryRun bool) (*domain.PointImportReport, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: the collection has no features", domain.ErrInvalidInput)
+	}
+	if len(items) > domain.MaxImportFeatures {
+		return nil, fmt.Errorf("%w: at most %d features can be imported at once", domain.ErrInvalidInput, domain.MaxImportFeatures)
+	}
+
+	report := &domain.PointImportReport{DryRun: dryRun, Total: len(items), Errors: []domain.PointImportError{}}
+	now := s.now()
+	points := make([]domain.CollectionPoint, 0, len(items))
+	for _, item := range items {
+		err := item.Err
+		if err == nil {
+			err = normalizePoint(item.Point)
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, domain.PointImportError{
+				Feature: item.Feature,
+				ID:      item.FeatureID,
+				Message: strings.TrimPrefix(err.Error(), domain.ErrInvalidInput.Error()+": "),
+			})
+			continue
+		}
+
+		point := *item.Point
+		point.ID = uuid.New()
+		point.Tenant = tenant
+		point.CreatedAt = now
+		point.UpdatedAt = now
+		points = append(points, point)
+	}
+	report.Valid = len(points)
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+	if err := s.repo.CreateBatch(ctx, points); err != nil {
+		return nil, err
+	}
+	report.Created = len(points)
+	for _, point := range points {
+		report.PointIDs = append(report.PointIDs, point.ID)
+	}
+	return report, nil
+}
+
+// normalizePoint обрезает пробелы, убирает повторы категорий и проверяет поля пункта
+func normalizePoint(point *domain.CollectionPoint) error {
+	point.Name = strings.TrimSpace(point.Name)
+	point.Description = strings.TrimSpace(point.Description)
+	point.Address = strings.TrimSpace(point.Address)
+	point.Operator.Name = strings.TrimSpace(point.Operator.Name)
+	point.Operator.Phone = strings.TrimSpace(point.Operator.Phone)
+	point.Operator.Website = strings.TrimSpace(point.Operator.Website)
+
+	switch {
+	case point.Name == "":
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	case len(point.Name) > maxPointText, len(point.Address) > maxPointText, len(point.Operator.Name) > maxPointText,
+		len(point.Operator.Phone) > maxPointText, len(point.Operator.Website) > maxPointText:
+		return fmt.Errorf("%w: name, address and operator fields must be at most %d characters", domain.ErrInvalidInput, maxPointText)
+	case len(point.Description) > maxPointDescription:
+		return fmt.Errorf("%w: description must be at most %d characters", domain.ErrInvalidInput, maxPointDescription)
+	case !point.Location().Valid():
+		return fmt.Errorf("%w: lat must be within [-90, 90] and lon within [-180, 180]", domain.ErrInvalidInput)
+	case point.Operator.Name == "":
+		return fmt.Errorf("%w: operator.name is required", domain.ErrInvalidInput)
+	}
+	if point.Operator.Website != "" {
+		if website, err := url.Parse(point.Operator.Website); err != nil || (website.Scheme != "http" && website.Scheme != "https") || website.Host == "" {
+			return fmt.Errorf("%w: operator.website must be an http(s) URL", domain.ErrInvalidInput)
+		}
+	}
+
+	if len(point.Categories) == 0 {
+		return fmt.Errorf("%w: at least one category is required", domain.ErrInvalidInput)
+	}
+	seen := map[domain.WasteCategory]bool{}
+	categories := point.Categories[:0]
+	for _, category := range point.Categories {
+		if !category.Valid() {
+			return fmt.Errorf("%w: unknown category %q", domain.ErrInvalidInput, category)
+		}
+		if !seen[category] {
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	point.Categories = categories
+
+	for _, interval := range point.OpeningHours {
+		if interval.Weekday < time.Sunday || interval.Weekday > time.Saturday {
+			return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", domain.ErrInvalidInput)
+		}
+		if !clockPattern.MatchString(interval.Opens) || !clockPattern.MatchString(interval.Closes) {
+			return fmt.Errorf("%w: opening hours must be in HH:MM format", domain.ErrInvalidInput)
+		}
+		if interval.Opens >= interval.Closes {
+			return fmt.Errorf("%w: opening hours must close after they open", domain.ErrInvalidInput)
+		}
+	}
+	if point.OpeningHours == nil {
+		point.OpeningHours = []domain.OpeningInterval{}
+	}
+	sort.Slice(point.OpeningHours, func(i, j int) bool {
+		if point.OpeningHours[i].Weekday != point.OpeningHours[j].Weekday {
+			return point.OpeningHours[i].Weekday < point.OpeningHours[j].Weekday
+		}
+		return point.OpeningHours[i].Opens < point.OpeningHours[j].Opens
+	})
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package dedup отсекает повторную доставку сообщений на стороне получателя
+package dedup
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"messaging"
+)
+
+// Store помнит, какие сообщения получатель уже обработал
+type Store interface {
+	Seen(ctx context.Context, consumer, messageID string) (bool, error)
+	Mark(ctx context.Context, consumer, messageID string) error
+	// Purge удаляет отметки старше before и возвращает их число
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Handler пропускает сообщения, которые consumer уже обработал, и запоминает успешно обработанные.
+// Отметка ставится после обработчика: если процесс упадёт между ними, сообщение обработается ещё раз,
+// поэтому обработчик всё равно должен быть идемпотентным. Одновременные повторы внутри процесса
+// ждут друг друга, чтобы не выполняться параллельно.
+func Handler(store Store, consumer string, next messaging.Handler) messaging.Handler {
+	var locks keyedMutex
+	return func(ctx context.Context, message messaging.Message) error {
+		unlock := locks.lock(message.ID)
+		defer unlock()
+
+		seen, err := store.Seen(ctx, consumer, message.ID)
+		if err != nil {
+			return err
+		}
+		if seen {
+			return nil
+		}
+		if err := next(ctx, message); err != nil {
+			return err
+		}
+		return store.Mark(ctx, consumer, message.ID)
+	}
+}
+
+// Processed — строка processed_messages
+type Processed struct {
+	Consumer    string    `gorm:"primaryKey"`
+	MessageID   string    `gorm:"primaryKey"`
+	ProcessedAt time.Time `gorm:"index"`
+}
+
+func (Processed) TableName() string {
+	return "processed_messages"
+}
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Seen(ctx context.Context, consumer, messageID string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&Processed{}).Where("consumer = ? AND message_id = ?", consumer, messageID).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *GormStore) Mark(ctx context.Context, consumer, messageID string) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Processed{Consumer: consumer, MessageID: messageID, ProcessedAt: time.Now()}).Error
+}
+
+// Purge удаляет отметки старше before; сообщения старше этого срока брокер уже не должен повторять
+func (s *GormStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("processed_at < ?", before).Delete(&Processed{})
+	return result.RowsAffected, result.Error
+}
+
+type MemoryStore struct {
+	mu   sync.RWMutex
+	seen map[[2]string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{seen: map[[2]string]time.Time{}}
+}
+
+func (s *MemoryStore) Seen(_ context.Context, consumer, messageID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.seen[[2]string{consumer, messageID}]
+	return ok, nil
+}
+
+func (s *MemoryStore) Mark(_ context.Context, consumer, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := [2]string{consumer, messageID}
+	if _, ok := s.seen[key]; !ok {
+		s.seen[key] = time.Now()
+	}
+	return nil
+}
+
+func (s *MemoryStore) Purge(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int64
+	for key, processedAt := range s.seen {
+		if processedAt.Before(before) {
+			delete(s.seen, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+type PurgeOptions struct {
+	// Сколько хранить отметки; срок должен быть больше, чем брокер может повторять сообщение. По умолчанию 7 дней
+	Retention time.Duration
+	// Как часто чистить; по умолчанию раз в час
+	Interval time.Duration
+	// По умолчанию log.Default()
+	Logger *log.Logger
+}
+
+// RunPurge удаляет отметки старше Retention сразу и затем каждые Interval, пока не отменят ctx.
+// Одновременная чистка с нескольких экземпляров безопасна: каждый удаляет те же старые строки.
+func RunPurge(ctx context.Context, store Store, opts PurgeOptions) {
+	if opts.Retention <= 0 {
+		opts.Retention = 7 * 24 * time.Hour
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Hour
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := store.Purge(ctx, time.Now().Add(-opts.Retention)); err != nil && ctx.Err() == nil {
+			opts.Logger.Printf("Failed to purge processed messages: %v", err)
+		} else if purged > 0 {
+			opts.Logger.Printf("Purged %d processed message marks", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// keyedMutex сериализует обработку одного и того же сообщения
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*keyedLock{}
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
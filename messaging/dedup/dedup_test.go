@@ -0,0 +1,58 @@
+package dedup_test
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"messaging/dedup"
+)
+
+// Старые отметки удаляются первым же проходом, свежие остаются до конца срока
+func TestRunPurge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := dedup.NewMemoryStore()
+	retention := 200 * time.Millisecond
+
+	if err := store.Mark(ctx, "consumer", "old"); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+	time.Sleep(retention + 50*time.Millisecond)
+	if err := store.Mark(ctx, "consumer", "fresh"); err != nil {
+		t.Fatalf("Mark: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dedup.RunPurge(ctx, store, dedup.PurgeOptions{Retention: retention, Interval: 10 * time.Millisecond, Logger: log.New(io.Discard, "", 0)})
+	}()
+
+	deadline := time.Now().Add(retention / 2)
+	for {
+		seen, err := store.Seen(ctx, "consumer", "old")
+		if err != nil {
+			t.Fatalf("Seen: %v", err)
+		}
+		if !seen {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("old mark was not purged")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if seen, err := store.Seen(ctx, "consumer", "fresh"); err != nil || !seen {
+		t.Fatalf("Seen(fresh) = %v, %v; want the fresh mark kept", seen, err)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunPurge did not stop after cancel")
+	}
+}
@@ -0,0 +1,120 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// InProcess доставляет сообщения подписчикам того же процесса синхронно, внутри Publish.
+// Каждая группа получает сообщение один раз. Ошибки и паники обработчиков возвращаются издателю,
+// чтобы relay outbox повторил отправку; группы, уже обработавшие сообщение, увидят его снова.
+// Ошибки слушателей издателю не возвращаются.
+type InProcess struct {
+	mu        sync.RWMutex
+	groups    map[string]map[string]Handler
+	listeners map[string]map[int]Handler
+	nextID    int
+	closed    bool
+}
+
+func NewInProcess() *InProcess {
+	return &InProcess{groups: map[string]map[string]Handler{}, listeners: map[string]map[int]Handler{}}
+}
+
+func (b *InProcess) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if b.groups[topic] == nil {
+		b.groups[topic] = map[string]Handler{}
+	}
+	if _, ok := b.groups[topic][group]; ok {
+		return fmt.Errorf("group %q already subscribed to %q", group, topic)
+	}
+	b.groups[topic][group] = handler
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.groups[topic], group)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+func (b *InProcess) Listen(ctx context.Context, topic string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	if b.listeners[topic] == nil {
+		b.listeners[topic] = map[int]Handler{}
+	}
+	b.nextID++
+	id := b.nextID
+	b.listeners[topic][id] = handler
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.listeners[topic], id)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+func (b *InProcess) Publish(ctx context.Context, messages ...Message) error {
+	var errs []error
+	for _, message := range messages {
+		b.mu.RLock()
+		if b.closed {
+			b.mu.RUnlock()
+			return ErrClosed
+		}
+		handlers := make(map[string]Handler, len(b.groups[message.Topic]))
+		for group, handler := range b.groups[message.Topic] {
+			handlers[group] = handler
+		}
+		listeners := make([]Handler, 0, len(b.listeners[message.Topic]))
+		for _, handler := range b.listeners[message.Topic] {
+			listeners = append(listeners, handler)
+		}
+		b.mu.RUnlock()
+
+		for group, handler := range handlers {
+			if err := deliver(ctx, handler, message); err != nil {
+				errs = append(errs, fmt.Errorf("group %s, message %s: %w", group, message.ID, err))
+			}
+		}
+		for _, handler := range listeners {
+			_ = deliver(ctx, handler, message)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *InProcess) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.groups = map[string]map[string]Handler{}
+	b.listeners = map[string]map[int]Handler{}
+	return nil
+}
+
+func deliver(ctx context.Context, handler Handler, message Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, message)
+}
@@ -0,0 +1,14 @@
+package messaging_test
+
+import (
+	"testing"
+
+	"messaging"
+	"messaging/messagingtest"
+)
+
+func TestInProcess(t *testing.T) {
+	messagingtest.RunBrokerSuite(t, func(t *testing.T) messaging.Broker {
+		return messaging.NewInProcess()
+	})
+}
@@ -0,0 +1,68 @@
+// Package messaging — общий для сервисов слой событий: сообщение, издатель и подписчик.
+// Доставка везде не меньше одного раза, поэтому обработчики должны переносить повторы
+// (см. пакет dedup).
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrClosed = errors.New("broker is closed")
+
+// Message — событие в транспорте. ID уникален и сохраняется при повторной доставке.
+// Topic задаёт поток: порядок сообщений гарантируется только внутри одного топика.
+type Message struct {
+	ID         string          `json:"id"`
+	Topic      string          `json:"topic"`
+	Type       string          `json:"type"`
+	Key        string          `json:"key,omitempty"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+func NewMessage(topic, messageType, key string, payload any) (Message, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		ID:         uuid.NewString(),
+		Topic:      topic,
+		Type:       messageType,
+		Key:        key,
+		Payload:    data,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
+
+// Handler обрабатывает сообщение; ошибка означает, что сообщение нужно доставить ещё раз
+type Handler func(ctx context.Context, message Message) error
+
+type Publisher interface {
+	Publish(ctx context.Context, messages ...Message) error
+}
+
+type Subscriber interface {
+	// Subscribe начинает доставку сообщений топика в фоне и работает до отмены ctx или Close.
+	// Подписчики с одной group делят сообщения между собой, разные группы получают каждое сообщение.
+	Subscribe(ctx context.Context, topic, group string, handler Handler) error
+}
+
+// Listener доставляет все новые сообщения топика каждому слушателю, без групп и подтверждений.
+// Подходит для уведомлений в реальном времени: сообщение, опубликованное до Listen
+// или не обработанное из-за ошибки, слушателю повторно не доставляется.
+type Listener interface {
+	Listen(ctx context.Context, topic string, handler Handler) error
+}
+
+type Broker interface {
+	Publisher
+	Subscriber
+	Listener
+	Close() error
+}
@@ -0,0 +1,237 @@
+// Package messagingtest содержит общий набор проверок для реализаций messaging.Broker:
+//
+//	func TestInProcess(t *testing.T) {
+//		messagingtest.RunBrokerSuite(t, func(t *testing.T) messaging.Broker {
+//			return messaging.NewInProcess()
+//		})
+//	}
+package messagingtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"messaging"
+	"messaging/dedup"
+)
+
+// Сколько ждать доставки; брокер должен повторять сообщения с ошибкой быстрее
+const deliveryTimeout = 10 * time.Second
+
+// RunBrokerSuite запускает проверки на свежем брокере, который возвращает newBroker
+func RunBrokerSuite(t *testing.T, newBroker func(t *testing.T) messaging.Broker) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, broker messaging.Broker)
+	}{
+		{"DeliversInOrder", testDeliversInOrder},
+		{"EachGroupGetsMessage", testEachGroupGetsMessage},
+		{"RedeliversAfterError", testRedeliversAfterError},
+		{"DedupSkipsRepeats", testDedupSkipsRepeats},
+		{"EachListenerGetsNewMessages", testEachListenerGetsNewMessages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := newBroker(t)
+			defer broker.Close()
+			tt.run(t, broker)
+		})
+	}
+}
+
+func testDeliversInOrder(t *testing.T, broker messaging.Broker) {
+	topic := uniqueTopic(t)
+	received := newCollector()
+	mustSubscribe(t, broker, topic, "orders", received.handle)
+
+	var want []string
+	for i := 0; i < 5; i++ {
+		message := mustMessage(t, topic, i)
+		want = append(want, message.ID)
+		mustPublish(t, broker, message)
+	}
+
+	got := received.wait(t, len(want))
+	for i := range want {
+		if got[i].ID != want[i] {
+			t.Fatalf("message %d = %s, want %s", i, got[i].ID, want[i])
+		}
+	}
+	if got[0].Topic != topic || got[0].Type != "test.created" || string(got[0].Payload) != "0" {
+		t.Fatalf("message fields = %+v", got[0])
+	}
+}
+
+func testEachGroupGetsMessage(t *testing.T, broker messaging.Broker) {
+	topic := uniqueTopic(t)
+	first, second := newCollector(), newCollector()
+	mustSubscribe(t, broker, topic, "first", first.handle)
+	mustSubscribe(t, broker, topic, "second", second.handle)
+
+	message := mustMessage(t, topic, 1)
+	mustPublish(t, broker, message)
+
+	if got := first.wait(t, 1); got[0].ID != message.ID {
+		t.Fatalf("first group got %s, want %s", got[0].ID, message.ID)
+	}
+	if got := second.wait(t, 1); got[0].ID != message.ID {
+		t.Fatalf("second group got %s, want %s", got[0].ID, message.ID)
+	}
+}
+
+// testRedeliversAfterError принимает оба способа повторить сообщение: брокер доставляет его сам
+// или возвращает ошибку из Publish, и тогда издатель (relay outbox) отправляет его снова
+func testRedeliversAfterError(t *testing.T, broker messaging.Broker) {
+	topic := uniqueTopic(t)
+	received := newCollector()
+	var mu sync.Mutex
+	failed := false
+	mustSubscribe(t, broker, topic, "flaky", func(ctx context.Context, message messaging.Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed {
+			failed = true
+			return errors.New("temporary failure")
+		}
+		return received.handle(ctx, message)
+	})
+
+	message := mustMessage(t, topic, 1)
+	if err := broker.Publish(context.Background(), message); err != nil {
+		mustPublish(t, broker, message)
+	}
+
+	if got := received.wait(t, 1); got[0].ID != message.ID {
+		t.Fatalf("redelivered %s, want %s", got[0].ID, message.ID)
+	}
+}
+
+func testDedupSkipsRepeats(t *testing.T, broker messaging.Broker) {
+	topic := uniqueTopic(t)
+	received := newCollector()
+	mustSubscribe(t, broker, topic, "dedup", dedup.Handler(dedup.NewMemoryStore(), "dedup", received.handle))
+
+	message := mustMessage(t, topic, 1)
+	mustPublish(t, broker, message)
+	mustPublish(t, broker, message)
+	marker := mustMessage(t, topic, 2)
+	mustPublish(t, broker, marker)
+
+	got := received.wait(t, 2)
+	if got[0].ID != message.ID || got[1].ID != marker.ID {
+		t.Fatalf("received %s, %s; want %s once, then %s", got[0].ID, got[1].ID, message.ID, marker.ID)
+	}
+}
+
+func testEachListenerGetsNewMessages(t *testing.T, broker messaging.Broker) {
+	topic := uniqueTopic(t)
+	mustPublish(t, broker, mustMessage(t, topic, 0))
+
+	first, second := newCollector(), newCollector()
+	mustListen(t, broker, topic, first.handle)
+	mustListen(t, broker, topic, func(ctx context.Context, message messaging.Message) error {
+		_ = second.handle(ctx, message)
+		// Ошибка слушателя не приводит к повторной доставке
+		return errors.New("ignored")
+	})
+
+	message := mustMessage(t, topic, 1)
+	mustPublish(t, broker, message)
+	marker := mustMessage(t, topic, 2)
+	mustPublish(t, broker, marker)
+
+	for name, c := range map[string]*collector{"first": first, "second": second} {
+		got := c.wait(t, 2)
+		if got[0].ID != message.ID || got[1].ID != marker.ID {
+			t.Fatalf("%s listener received %s, %s; want %s, %s", name, got[0].ID, got[1].ID, message.ID, marker.ID)
+		}
+	}
+}
+
+type collector struct {
+	mu       sync.Mutex
+	messages []messaging.Message
+	notify   chan struct{}
+}
+
+func newCollector() *collector {
+	return &collector{notify: make(chan struct{}, 1)}
+}
+
+func (c *collector) handle(_ context.Context, message messaging.Message) error {
+	c.mu.Lock()
+	c.messages = append(c.messages, message)
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// wait ждёт n сообщений и проверяет, что лишних не пришло
+func (c *collector) wait(t *testing.T, n int) []messaging.Message {
+	t.Helper()
+	deadline := time.After(deliveryTimeout)
+	for {
+		c.mu.Lock()
+		got := append([]messaging.Message(nil), c.messages...)
+		c.mu.Unlock()
+		if len(got) > n {
+			t.Fatalf("received %d messages, want %d", len(got), n)
+		}
+		if len(got) == n {
+			return got
+		}
+
+		select {
+		case <-c.notify:
+		case <-deadline:
+			t.Fatalf("received %d messages in %s, want %d", len(got), deliveryTimeout, n)
+		}
+	}
+}
+
+func uniqueTopic(t *testing.T) string {
+	return fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+}
+
+func mustMessage(t *testing.T, topic string, payload int) messaging.Message {
+	t.Helper()
+	message, err := messaging.NewMessage(topic, "test.created", "", payload)
+	if err != nil {
+		t.Fatalf("NewMessage: %v", err)
+	}
+	return message
+}
+
+func mustSubscribe(t *testing.T, broker messaging.Broker, topic, group string, handler messaging.Handler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := broker.Subscribe(ctx, topic, group, handler); err != nil {
+		t.Fatalf("Subscribe(%s, %s): %v", topic, group, err)
+	}
+}
+
+func mustListen(t *testing.T, broker messaging.Broker, topic string, handler messaging.Handler) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := broker.Listen(ctx, topic, handler); err != nil {
+		t.Fatalf("Listen(%s): %v", topic, err)
+	}
+}
+
+func mustPublish(t *testing.T, broker messaging.Broker, message messaging.Message) {
+	t.Helper()
+	if err := broker.Publish(context.Background(), message); err != nil {
+		t.Fatalf("Publish(%s): %v", message.ID, err)
+	}
+}
@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"messaging"
+)
+
+// MemoryStore — outbox для хранилищ в памяти. Атомарность с изменением обеспечивает вызывающий:
+// Add нужно вызывать под той же блокировкой, под которой меняются данные.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Add(messages ...messaging.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, message := range messages {
+		record := NewRecord(message)
+		record.ID = uint64(len(s.records) + 1)
+		record.CreatedAt = time.Now()
+		s.records = append(s.records, record)
+	}
+}
+
+func (s *MemoryStore) Pending(_ context.Context, limit int) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []Record
+	for _, record := range s.records {
+		if record.PublishedAt == nil && len(records) < limit {
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+func (s *MemoryStore) MarkPublished(_ context.Context, id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.record(id); record != nil {
+		now := time.Now()
+		record.PublishedAt = &now
+		record.Attempts++
+		record.LastError = ""
+	}
+	return nil
+}
+
+func (s *MemoryStore) MarkFailed(_ context.Context, id uint64, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record := s.record(id); record != nil {
+		record.Attempts++
+		record.LastError = reason
+	}
+	return nil
+}
+
+func (s *MemoryStore) record(id uint64) *Record {
+	if id == 0 || id > uint64(len(s.records)) {
+		return nil
+	}
+	return &s.records[id-1]
+}
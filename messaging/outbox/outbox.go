@@ -0,0 +1,101 @@
+// Package outbox сохраняет сообщения в таблицу outbox_messages в той же транзакции,
+// что и бизнес-изменение, а Relay потом отправляет их издателю.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"messaging"
+)
+
+// Record — строка outbox_messages. ID задаёт порядок отправки.
+type Record struct {
+	ID          uint64 `gorm:"primaryKey"`
+	MessageID   string `gorm:"type:uuid;uniqueIndex;not null"`
+	Topic       string `gorm:"not null"`
+	Type        string `gorm:"not null"`
+	Key         string
+	Payload     string    `gorm:"not null"`
+	OccurredAt  time.Time `gorm:"not null"`
+	CreatedAt   time.Time
+	PublishedAt *time.Time `gorm:"index"`
+	Attempts    int        `gorm:"not null;default:0"`
+	LastError   string
+}
+
+func (Record) TableName() string {
+	return "outbox_messages"
+}
+
+func NewRecord(message messaging.Message) Record {
+	return Record{
+		MessageID:  message.ID,
+		Topic:      message.Topic,
+		Type:       message.Type,
+		Key:        message.Key,
+		Payload:    string(message.Payload),
+		OccurredAt: message.OccurredAt,
+	}
+}
+
+func (r Record) Message() messaging.Message {
+	return messaging.Message{
+		ID:         r.MessageID,
+		Topic:      r.Topic,
+		Type:       r.Type,
+		Key:        r.Key,
+		Payload:    []byte(r.Payload),
+		OccurredAt: r.OccurredAt,
+	}
+}
+
+// Write добавляет сообщения в outbox; tx — транзакция, в которой сохраняется само изменение
+func Write(tx *gorm.DB, messages ...messaging.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	records := make([]Record, len(messages))
+	for i, message := range messages {
+		records[i] = NewRecord(message)
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return fmt.Errorf("outbox write failed: %w", err)
+	}
+	return nil
+}
+
+// Store — то, что нужно Relay от хранилища outbox
+type Store interface {
+	// Pending возвращает неотправленные записи в порядке ID
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	MarkFailed(ctx context.Context, id uint64, reason string) error
+}
+
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	var records []Record
+	err := s.db.WithContext(ctx).Where("published_at IS NULL").Order("id").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+func (s *GormStore) MarkPublished(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Model(&Record{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"published_at": time.Now(), "attempts": gorm.Expr("attempts + 1"), "last_error": ""}).Error
+}
+
+func (s *GormStore) MarkFailed(ctx context.Context, id uint64, reason string) error {
+	return s.db.WithContext(ctx).Model(&Record{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"attempts": gorm.Expr("attempts + 1"), "last_error": reason}).Error
+}
@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"messaging"
+)
+
+type RelayOptions struct {
+	// Как часто проверять outbox; по умолчанию раз в секунду
+	Interval time.Duration
+	// Сколько записей читать за раз; по умолчанию 100
+	BatchSize int
+	// По умолчанию log.Default()
+	Logger *log.Logger
+}
+
+// Relay отправляет записи outbox издателю по одной, в порядке ID, и отмечает отправленные.
+// Запись, отправленная, но не отмеченная из-за сбоя, уйдёт повторно: доставка не меньше одного раза.
+// Несколько relay над одной таблицей тоже дают только повторы, поэтому отдельная блокировка не нужна.
+type Relay struct {
+	store     Store
+	publisher messaging.Publisher
+	interval  time.Duration
+	batchSize int
+	logger    *log.Logger
+}
+
+func NewRelay(store Store, publisher messaging.Publisher, opts RelayOptions) *Relay {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	return &Relay{store: store, publisher: publisher, interval: opts.Interval, batchSize: opts.BatchSize, logger: opts.Logger}
+}
+
+// Run работает до отмены ctx
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Printf("Outbox delivery stopped, will retry: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush отправляет накопившиеся записи и возвращает число отправленных.
+// На первой ошибке останавливается, чтобы получатели не увидели сообщения не по порядку.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		records, err := r.store.Pending(ctx, r.batchSize)
+		if err != nil {
+			return published, err
+		}
+
+		for _, record := range records {
+			if err := r.publisher.Publish(ctx, record.Message()); err != nil {
+				if markErr := r.store.MarkFailed(ctx, record.ID, err.Error()); markErr != nil {
+					r.logger.Printf("Failed to record outbox error for message %s: %v", record.MessageID, markErr)
+				}
+				return published, err
+			}
+			if err := r.store.MarkPublished(ctx, record.ID); err != nil {
+				return published, err
+			}
+			published++
+		}
+
+		if len(records) < r.batchSize {
+			return published, nil
+		}
+	}
+}
@@ -0,0 +1,310 @@
+// Package redisstream реализует messaging.Broker на Redis Streams: топик — это стрим,
+// группа подписчиков — consumer group. Необработанные сообщения остаются в pending-списке
+// группы и через ClaimMinIdle забираются заново, так что доставка не меньше одного раза.
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"messaging"
+)
+
+type Options struct {
+	// Префикс ключей стримов; по умолчанию "events:"
+	StreamPrefix string
+	// Имя этого экземпляра внутри группы; по умолчанию имя хоста и PID
+	Consumer string
+	// Приблизительная длина стрима, старые сообщения отбрасываются; по умолчанию 100000
+	MaxLen int64
+	// Сколько ждать новых сообщений за один запрос; по умолчанию 5 секунд
+	Block time.Duration
+	// Через сколько неподтверждённое сообщение забирается повторно; по умолчанию минута
+	ClaimMinIdle time.Duration
+	// Сколько сообщений читать за раз; по умолчанию 16
+	BatchSize int64
+	// По умолчанию log.Default()
+	Logger *log.Logger
+}
+
+type Broker struct {
+	client redis.UniversalClient
+	opts   Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(client redis.UniversalClient, opts Options) *Broker {
+	if opts.StreamPrefix == "" {
+		opts.StreamPrefix = "events:"
+	}
+	if opts.Consumer == "" {
+		host, _ := os.Hostname()
+		opts.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if opts.MaxLen <= 0 {
+		opts.MaxLen = 100000
+	}
+	if opts.Block <= 0 {
+		opts.Block = 5 * time.Second
+	}
+	if opts.ClaimMinIdle <= 0 {
+		opts.ClaimMinIdle = time.Minute
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 16
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Broker{client: client, opts: opts, ctx: ctx, cancel: cancel}
+}
+
+func (b *Broker) Publish(ctx context.Context, messages ...messaging.Message) error {
+	if b.ctx.Err() != nil {
+		return messaging.ErrClosed
+	}
+	for _, message := range messages {
+		err := b.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: b.stream(message.Topic),
+			MaxLen: b.opts.MaxLen,
+			Approx: true,
+			Values: encode(message),
+		}).Err()
+		if err != nil {
+			return fmt.Errorf("publish %s to %s: %w", message.ID, message.Topic, err)
+		}
+	}
+	return nil
+}
+
+// Subscribe создаёт группу, если её нет. Новая группа читает стрим с начала,
+// чтобы не потерять сообщения, опубликованные до первого запуска получателя.
+func (b *Broker) Subscribe(ctx context.Context, topic, group string, handler messaging.Handler) error {
+	if b.ctx.Err() != nil {
+		return messaging.ErrClosed
+	}
+	stream := b.stream(topic)
+	err := b.client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("create group %s on %s: %w", group, stream, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.ctx.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.consume(ctx, stream, group, handler)
+	}()
+	return nil
+}
+
+// Listen читает стрим без consumer group, начиная с последней записи на момент вызова,
+// поэтому каждый экземпляр получает все новые сообщения. Ошибки обработчика только логируются.
+func (b *Broker) Listen(ctx context.Context, topic string, handler messaging.Handler) error {
+	if b.ctx.Err() != nil {
+		return messaging.ErrClosed
+	}
+	stream := b.stream(topic)
+	last, err := b.client.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+	if err != nil {
+		return fmt.Errorf("read last entry of %s: %w", stream, err)
+	}
+	from := "0-0"
+	if len(last) > 0 {
+		from = last[0].ID
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.ctx.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.listen(ctx, stream, from, handler)
+	}()
+	return nil
+}
+
+// Close останавливает чтение и ждёт завершения начатых обработчиков; клиент Redis закрывает владелец
+func (b *Broker) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return nil
+}
+
+func (b *Broker) consume(ctx context.Context, stream, group string, handler messaging.Handler) {
+	for ctx.Err() == nil {
+		// Сначала забираем зависшие сообщения: их владелец упал или обработчик вернул ошибку
+		claimed, _, err := b.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: b.opts.Consumer,
+			MinIdle:  b.opts.ClaimMinIdle,
+			Start:    "0-0",
+			Count:    b.opts.BatchSize,
+		}).Result()
+		if err != nil && !b.pause(ctx, stream, err) {
+			return
+		}
+		b.handle(ctx, stream, group, claimed, handler)
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: b.opts.Consumer,
+			Streams:  []string{stream, ">"},
+			Count:    b.opts.BatchSize,
+			Block:    b.opts.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if !b.pause(ctx, stream, err) {
+				return
+			}
+			continue
+		}
+		for _, s := range streams {
+			b.handle(ctx, stream, group, s.Messages, handler)
+		}
+	}
+}
+
+func (b *Broker) listen(ctx context.Context, stream, from string, handler messaging.Handler) {
+	for ctx.Err() == nil {
+		streams, err := b.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{stream, from},
+			Count:   b.opts.BatchSize,
+			Block:   b.opts.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if !b.pause(ctx, stream, err) {
+				return
+			}
+			continue
+		}
+		for _, s := range streams {
+			for _, entry := range s.Messages {
+				from = entry.ID
+				message, err := decode(entry)
+				if err == nil {
+					err = deliver(ctx, handler, message)
+				}
+				if err != nil {
+					b.opts.Logger.Printf("Listener for %s skipped entry %s: %v", stream, entry.ID, err)
+				}
+			}
+		}
+	}
+}
+
+func (b *Broker) handle(ctx context.Context, stream, group string, entries []redis.XMessage, handler messaging.Handler) {
+	for _, entry := range entries {
+		message, err := decode(entry)
+		if err != nil {
+			// Повтор не исправит битую запись, поэтому она подтверждается и пропускается
+			b.opts.Logger.Printf("Skipping malformed %s entry: %v", stream, err)
+		} else if err := deliver(ctx, handler, message); err != nil {
+			// Без XACK сообщение останется в pending и будет забрано повторно через ClaimMinIdle
+			b.opts.Logger.Printf("Handler for %s entry %s failed: %v", stream, entry.ID, err)
+			continue
+		}
+		if err := b.client.XAck(ctx, stream, group, entry.ID).Err(); err != nil {
+			b.opts.Logger.Printf("Failed to ack %s entry %s: %v", stream, entry.ID, err)
+		}
+	}
+}
+
+// pause ждёт перед повтором после ошибки Redis; false означает, что подписка остановлена
+func (b *Broker) pause(ctx context.Context, stream string, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	b.opts.Logger.Printf("Reading %s failed, retrying: %v", stream, err)
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(time.Second):
+		return true
+	}
+}
+
+func (b *Broker) stream(topic string) string {
+	return b.opts.StreamPrefix + topic
+}
+
+func encode(message messaging.Message) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          message.ID,
+		"topic":       message.Topic,
+		"type":        message.Type,
+		"key":         message.Key,
+		"payload":     string(message.Payload),
+		"occurred_at": message.OccurredAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func decode(entry redis.XMessage) (messaging.Message, error) {
+	field := func(name string) string {
+		value, _ := entry.Values[name].(string)
+		return value
+	}
+
+	message := messaging.Message{
+		ID:      field("id"),
+		Topic:   field("topic"),
+		Type:    field("type"),
+		Key:     field("key"),
+		Payload: []byte(field("payload")),
+	}
+	if message.ID == "" {
+		return message, fmt.Errorf("entry %s has no message id", entry.ID)
+	}
+	if occurredAt := field("occurred_at"); occurredAt != "" {
+		t, err := time.Parse(time.RFC3339Nano, occurredAt)
+		if err != nil {
+			return message, fmt.Errorf("entry %s: %w", entry.ID, err)
+		}
+		message.OccurredAt = t
+	}
+	return message, nil
+}
+
+func deliver(ctx context.Context, handler messaging.Handler, message messaging.Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, message)
+}
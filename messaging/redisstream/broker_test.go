@@ -0,0 +1,29 @@
+package redisstream_test
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"messaging"
+	"messaging/messagingtest"
+	"messaging/redisstream"
+)
+
+func TestRedisStreams(t *testing.T) {
+	messagingtest.RunBrokerSuite(t, func(t *testing.T) messaging.Broker {
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		// Короткие интервалы, чтобы повторная доставка укладывалась в таймаут набора проверок
+		return redisstream.New(client, redisstream.Options{
+			Block:        100 * time.Millisecond,
+			ClaimMinIdle: 200 * time.Millisecond,
+			Logger:       log.New(io.Discard, "", 0),
+		})
+	})
+}
@@ -1,25 +1,211 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/redis/go-redis/v9"
+
+	"messaging"
+	"messaging/dedup"
+	"messaging/outbox"
+	"messaging/redisstream"
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/authclient"
+	"user-service/internal/infrastructure/blob"
 	"user-service/internal/infrastructure/database"
+	"user-service/internal/infrastructure/events"
+	"user-service/internal/infrastructure/geocoding"
+	"user-service/internal/infrastructure/repository"
 	"user-service/internal/infrastructure/server"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration command failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize repo
-	db, err := database.NewPostgresDatabase()
+	repos, err := openRepositories()
 	if err != nil {
 		log.Fatalf("Failed to connect to repo: %v", err)
 	}
 
+	location := time.UTC
+	if tz := os.Getenv("DEFAULT_TIMEZONE"); tz != "" {
+		if location, err = time.LoadLocation(tz); err != nil {
+			log.Fatalf("Invalid DEFAULT_TIMEZONE: %v", err)
+		}
+		if tz == "Local" {
+			log.Fatalf("Invalid DEFAULT_TIMEZONE: set an IANA zone name instead of Local")
+		}
+	}
+
+	cfg := server.Config{
+		PlatformAdminKey:    os.Getenv("PLATFORM_ADMIN_KEY"),
+		ActionIngestSecret:  os.Getenv("ACTION_INGEST_SECRET"),
+		AccountEventsSecret: os.Getenv("ACCOUNT_EVENTS_SECRET"),
+		GatewayToken:        os.Getenv("GATEWAY_TOKEN"),
+		DefaultLocation:     location,
+	}
+	if cfg.GatewayToken == "" {
+		log.Println("GATEWAY_TOKEN is not set, user headers are trusted without verification")
+	}
+	if url := os.Getenv("AUTH_SERVICE_URL"); url != "" && os.Getenv("INVITATIONS_SECRET") != "" {
+		cfg.Inviter = authclient.NewInviter(url, os.Getenv("INVITATIONS_SECRET"))
+	}
+	blobDir := os.Getenv("BLOB_DIR")
+	if blobDir == "" {
+		blobDir = "data/blobs"
+	}
+	if cfg.Blobs, err = blob.NewLocalStore(blobDir); err != nil {
+		log.Fatalf("Failed to open blob storage: %v", err)
+	}
+	if path := os.Getenv("GAZETTEER_PATH"); path != "" {
+		if cfg.Geocoder, err = geocoding.LoadGazetteer(path); err != nil {
+			log.Fatalf("Failed to load gazetteer: %v", err)
+		}
+	}
+
+	broker := openBroker()
+	defer broker.Close()
+	cfg.Events = broker
+
 	// Initialize server
-	srv := server.NewUserServer(db)
+	srv := server.NewUserServer(repos, cfg)
+
+	if err := subscribe(broker, repos, srv); err != nil {
+		log.Fatalf("Failed to subscribe to events: %v", err)
+	}
+	relay := outbox.NewRelay(repos.Outbox, broker, outbox.RelayOptions{})
+	go relay.Run(context.Background())
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go failStaleImports(ctx, srv.ImportService)
+	go dedup.RunPurge(ctx, repos.Processed, dedup.PurgeOptions{Retention: processedRetention()})
+
+	httpServer := &http.Server{Addr: ":8082", Handler: srv.Routes()}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		// Сначала загрузки: они останавливаются между строками и записывают итог со статусом failed.
+		// Потоки SSE сами не закрываются, их обрывает срок shutdownCtx, а клиенты переподключаются с Last-Event-ID.
+		if err := srv.ImportService.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Imports did not stop in time: %v", err)
+		}
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("HTTP server shutdown: %v", err)
+		}
+	}()
 
 	log.Println("Starting User Service on :8082")
-	if err := http.ListenAndServe(":8082", srv.Routes()); err != nil {
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatalf("Server failed to start: %v", err)
 	}
+	<-stopped
+}
+
+// shutdownTimeout укладывается в стандартные 30 секунд, которые Docker и Kubernetes дают до SIGKILL
+const shutdownTimeout = 20 * time.Second
+
+// failStaleImports при старте и затем раз в минуту закрывает загрузки, брошенные упавшими экземплярами
+func failStaleImports(ctx context.Context, imports domain.ImportService) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if failed, err := imports.FailStaleImports(ctx); err != nil {
+			log.Printf("Failed to close stale imports: %v", err)
+		} else if failed > 0 {
+			log.Printf("Marked %d stale imports as failed", failed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processedRetention — сколько помнить обработанные события из DEDUP_RETENTION; по умолчанию 7 дней.
+// Повтор события старше этого срока снова попадёт в обработчик, поэтому срок должен покрывать повторы брокера.
+func processedRetention() time.Duration {
+	value := os.Getenv("DEDUP_RETENTION")
+	if value == "" {
+		return 0
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		log.Fatalf("Invalid DEDUP_RETENTION %q: use a positive duration such as 168h", value)
+	}
+	return retention
+}
+
+// openBroker подключается к Redis Streams при REDIS_ADDR; без него события доставляются
+// только внутри процесса, а события auth-service приходят по HTTP
+func openBroker() messaging.Broker {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		log.Println("REDIS_ADDR is not set, events are delivered in process only")
+		return messaging.NewInProcess()
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, Password: os.Getenv("REDIS_PASSWORD")})
+	log.Printf("Using Redis Streams at %s for events", addr)
+	return redisstream.New(client, redisstream.Options{})
+}
+
+// subscribe регистрирует подписчиков. Группа называется по сервису, так что экземпляры
+// user-service делят сообщения между собой; ленту действий и кэш статистики каждый экземпляр ведёт сам.
+func subscribe(broker messaging.Broker, repos repository.Repositories, srv *server.UserServer) error {
+	ctx := context.Background()
+	if err := broker.Listen(ctx, domain.TopicActions, srv.ActionFeed.Handle); err != nil {
+		return err
+	}
+	if err := broker.Listen(ctx, domain.TopicActions, events.ActionHandler(srv.UserStatsService)); err != nil {
+		return err
+	}
+	accounts := dedup.Handler(repos.Processed, "user-service.accounts", events.AccountHandler(srv.AccountService))
+	return broker.Subscribe(ctx, domain.TopicAccounts, "user-service", accounts)
+}
+
+// openRepositories выбирает хранилище по DB_DRIVER: postgres (по умолчанию), sqlite или memory
+func openRepositories() (repository.Repositories, error) {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", "postgres":
+		db, err := database.NewPostgresDatabase()
+		if err != nil {
+			return repository.Repositories{}, err
+		}
+		return repository.NewPostgresRepositories(db), nil
+	case "sqlite":
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = "user-service.db"
+		}
+		db, err := database.NewSQLiteDatabase(path)
+		if err != nil {
+			return repository.Repositories{}, err
+		}
+		return repository.NewPostgresRepositories(db), nil
+	case "memory":
+		log.Println("Using in-memory repositories, data will be lost on restart")
+		return repository.NewMemoryRepositories(), nil
+	default:
+		return repository.Repositories{}, fmt.Errorf("unknown database driver %q", driver)
+	}
 }
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// События об учётных записях, которые публикует auth-service
+const (
+	EventAccountRegistered   = "user.registered"
+	EventAccountEmailChanged = "user.email_changed"
+	EventAccountRoleChanged  = "user.role_changed"
+	EventAccountDeleted      = "user.deleted"
+)
+
+// ErrProfileEmailTaken — email учётной записи занят профилем, который не приглашали для неё
+var ErrProfileEmailTaken = errors.New("email belongs to a profile that was not invited for this account")
+
+// AccountEvent несёт полное состояние учётной записи, поэтому события можно применять независимо друг от друга.
+// AuthUserID — числовой ID в auth-service, его же шлюз передаёт в X-User-ID.
+// Version растёт с каждым изменением учётной записи; 0 приходит от старых версий auth-service.
+// Referral есть только в user.registered, если при регистрации указали код пригласившего.
+type AccountEvent struct {
+	ID          uuid.UUID      `json:"id"`
+	Type        string         `json:"type"`
+	IdentityKey uuid.UUID      `json:"identity_key"`
+	AuthUserID  uint64         `json:"auth_user_id"`
+	Tenant      string         `json:"tenant"`
+	Email       string         `json:"email"`
+	Role        UserRole       `json:"role"`
+	Version     int64          `json:"version"`
+	OccurredAt  time.Time      `json:"occurred_at"`
+	Referral    *ReferralClaim `json:"referral,omitempty"`
+}
+
+type AccountService interface {
+	// ApplyAccountEvent создаёт, обновляет или удаляет профиль; для user.deleted возвращает nil профиль
+	ApplyAccountEvent(ctx context.Context, event AccountEvent) (*User, error)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrAchievementAwarded = errors.New("achievement already awarded")
+
+type AchievementKind string
+
+const (
+	// AchievementCount засчитывается после Target действий за всё время
+	AchievementCount AchievementKind = "count"
+	// AchievementStreak засчитывается после Target периодов подряд, в каждом из которых было хотя бы MinPerPeriod действий
+	AchievementStreak AchievementKind = "streak"
+)
+
+// AchievementDefinition описывает достижение декларативно; пустой Category означает любую категорию отходов
+type AchievementDefinition struct {
+	Code         string            `json:"code"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	Badge        string            `json:"badge"`
+	Kind         AchievementKind   `json:"kind"`
+	Action       ActionType        `json:"action"`
+	Category     string            `json:"category,omitempty"`
+	Period       AggregationPeriod `json:"period,omitempty"`
+	MinPerPeriod int64             `json:"min_per_period,omitempty"`
+	Target       int64             `json:"target"`
+}
+
+var DefaultAchievements = []AchievementDefinition{
+	{Code: "first_sort", Title: "First sort", Description: "Sort waste for the first time", Badge: "seedling", Kind: AchievementCount, Action: ActionWasteSorted, Target: 1},
+	{Code: "sorter_100", Title: "Sorting pro", Description: "Sort waste 100 times", Badge: "recycling-gold", Kind: AchievementCount, Action: ActionWasteSorted, Target: 100},
+	{Code: "plastic_10_weeks", Title: "Plastic patrol", Description: "Sort plastic 10 weeks in a row", Badge: "bottle", Kind: AchievementStreak, Action: ActionWasteSorted, Category: "plastic", Period: PeriodWeek, MinPerPeriod: 1, Target: 10},
+	{Code: "daily_sorter_7", Title: "Daily habit", Description: "Sort waste 7 days in a row", Badge: "calendar", Kind: AchievementStreak, Action: ActionWasteSorted, Period: PeriodDay, MinPerPeriod: 1, Target: 7},
+	{Code: "explorer_10", Title: "Explorer", Description: "Visit collection points 10 times", Badge: "compass", Kind: AchievementCount, Action: ActionPointVisited, Target: 10},
+	{Code: "reporter_5", Title: "Watchful neighbour", Description: "File 5 reports", Badge: "megaphone", Kind: AchievementCount, Action: ActionReportFiled, Target: 5},
+}
+
+// UserAchievement — выданный пользователю значок; выдаётся один раз
+type UserAchievement struct {
+	TenantID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	UserID   uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	Code     string    `json:"code" gorm:"primaryKey"`
+	EarnedAt time.Time `json:"earned_at" gorm:"not null"`
+}
+
+type AchievementProgress struct {
+	AchievementDefinition
+	Progress      int64      `json:"progress"`
+	CurrentStreak int64      `json:"current_streak,omitempty"`
+	LongestStreak int64      `json:"longest_streak,omitempty"`
+	EarnedAt      *time.Time `json:"earned_at,omitempty"`
+}
+
+type AchievementOverview struct {
+	Timezone   string                `json:"timezone"`
+	Earned     []AchievementProgress `json:"earned"`
+	InProgress []AchievementProgress `json:"in_progress"`
+}
+
+type AchievementRepository interface {
+	AwardAchievement(ctx context.Context, achievement *UserAchievement) error
+	ListUserAchievements(ctx context.Context, tenantID, userID uuid.UUID) ([]UserAchievement, error)
+}
+
+type AchievementService interface {
+	Definitions() []AchievementDefinition
+	Evaluate(ctx context.Context, tenantID, userID uuid.UUID, loc *time.Location) ([]UserAchievement, error)
+	Overview(ctx context.Context, tenantID, userID uuid.UUID, loc *time.Location) (*AchievementOverview, error)
+}
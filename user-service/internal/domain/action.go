@@ -0,0 +1,206 @@
+package domain
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrActionExists = errors.New("action already recorded")
+
+type ActionType string
+
+const (
+	ActionProfileUpdated  ActionType = "profile_updated"
+	ActionWasteSorted     ActionType = "waste_sorted"
+	ActionPickupRequested ActionType = "pickup_requested"
+	ActionPointVisited    ActionType = "point_visited"
+	ActionReportFiled     ActionType = "report_filed"
+	// Награда пригласившему за нового жителя, записывается сервисом приглашений
+	ActionReferralRewarded ActionType = "referral_rewarded"
+)
+
+// ActionTypes — каталог действий, которые принимает сервис
+var ActionTypes = []ActionType{
+	ActionProfileUpdated,
+	ActionWasteSorted,
+	ActionPickupRequested,
+	ActionPointVisited,
+	ActionReportFiled,
+	ActionReferralRewarded,
+}
+
+func (t ActionType) Valid() bool {
+	for _, known := range ActionTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Источник действий, записанных самим user-service
+const ActionSourceUserService = "user-service"
+
+type UserAction struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID  `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Action    ActionType `json:"action"`
+	Source    string     `json:"source" gorm:"not null;default:'user-service'"`
+	Category  string     `json:"category,omitempty"`
+	Details   string     `json:"details"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ActionEvent — действие, присланное другим сервисом; ID задаёт отправитель, повторная доставка не создаёт дубликат
+type ActionEvent struct {
+	ID         uuid.UUID  `json:"id"`
+	Tenant     string     `json:"tenant"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Action     ActionType `json:"action"`
+	Source     string     `json:"source"`
+	Category   string     `json:"category"`
+	Details    string     `json:"details"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc"
+	SortAsc  SortOrder = "asc"
+)
+
+// ActionCursor — позиция последней выданной записи; следующая страница начинается строго после неё
+type ActionCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ActionFilter — условия выборки истории действий; пустые поля не ограничивают выборку
+type ActionFilter struct {
+	Types    []ActionType
+	Category string
+	From     *time.Time
+	To       *time.Time
+	Order    SortOrder
+	After    *ActionCursor
+	Limit    int
+}
+
+func (c ActionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func ParseActionCursor(value string) (*ActionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	createdPart, idPart, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdPart)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	id, err := uuid.Parse(idPart)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidInput)
+	}
+	return &ActionCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+type ActionPage struct {
+	Actions    []UserAction `json:"actions"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type AggregationPeriod string
+
+const (
+	PeriodDay   AggregationPeriod = "day"
+	PeriodWeek  AggregationPeriod = "week"
+	PeriodMonth AggregationPeriod = "month"
+)
+
+func (p AggregationPeriod) Valid() bool {
+	return p == PeriodDay || p == PeriodWeek || p == PeriodMonth
+}
+
+// Start возвращает начало дня, недели (с понедельника) или месяца, которому принадлежит t в часовом поясе loc
+func (p AggregationPeriod) Start(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch p {
+	case PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	case PeriodWeek:
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// Next возвращает начало следующего периода; календарная арифметика корректна и при переходе на летнее время
+func (p AggregationPeriod) Next(start time.Time) time.Time {
+	switch p {
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// ActionSummaryQuery — параметры агрегации; границы периодов считаются в Location, неделя начинается с понедельника
+type ActionSummaryQuery struct {
+	Types    []ActionType
+	Category string
+	From     *time.Time
+	To       *time.Time
+	Period   AggregationPeriod
+	Location *time.Location
+}
+
+// ActionCount — число действий одного типа за период, начинающийся в PeriodStart
+type ActionCount struct {
+	PeriodStart time.Time
+	Action      ActionType
+	Count       int64
+}
+
+type ActionBucket struct {
+	Start  time.Time            `json:"start"`
+	Counts map[ActionType]int64 `json:"counts"`
+	Total  int64                `json:"total"`
+}
+
+type ActionSummary struct {
+	Period   AggregationPeriod    `json:"period"`
+	Timezone string               `json:"timezone"`
+	Buckets  []ActionBucket       `json:"buckets"`
+	Totals   map[ActionType]int64 `json:"totals"`
+}
+
+// ActionObserver получает каждое сохранённое действие; ошибки наблюдатель обрабатывает сам
+type ActionObserver interface {
+	ActionRecorded(ctx context.Context, action UserAction)
+}
+
+type ActionService interface {
+	RecordAction(ctx context.Context, tenantID, userID uuid.UUID, action ActionType, details string) error
+	IngestAction(ctx context.Context, event ActionEvent) (*UserAction, error)
+	ListActions(ctx context.Context, tenantID, userID uuid.UUID, filter ActionFilter) (*ActionPage, error)
+	SummarizeActions(ctx context.Context, tenantID, userID uuid.UUID, query ActionSummaryQuery) (*ActionSummary, error)
+}
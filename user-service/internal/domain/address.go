@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAddressNotFound  = errors.New("address not found")
+	ErrLocationNotFound = errors.New("location not found")
+)
+
+// Точность геокодирования: до дома, до улицы или только город
+const (
+	GeoPrecisionHouse  = "house"
+	GeoPrecisionStreet = "street"
+	GeoPrecisionCity   = "city"
+	GeoPrecisionManual = "manual"
+)
+
+// Address — один из адресов пользователя; координаты пустые, если геокодер адрес не нашёл.
+// В PostgreSQL по Latitude/Longitude строится вычисляемая колонка location типа geography(Point).
+type Address struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID     uuid.UUID `json:"-" gorm:"type:uuid;not null;index:idx_user_addresses_tenant_user"`
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index:idx_user_addresses_tenant_user"`
+	Label        string    `json:"label,omitempty"`
+	Street       string    `json:"street" gorm:"not null"`
+	House        string    `json:"house"`
+	Apartment    string    `json:"apartment,omitempty"`
+	City         string    `json:"city" gorm:"not null"`
+	PostalCode   string    `json:"postal_code,omitempty"`
+	Latitude     *float64  `json:"lat,omitempty"`
+	Longitude    *float64  `json:"lon,omitempty"`
+	GeoPrecision string    `json:"geo_precision,omitempty"`
+	IsPrimary    bool      `json:"is_primary" gorm:"not null;default:false"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Address) TableName() string {
+	return "user_addresses"
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+// GeocodeResult — найденная точка и то, насколько точно она соответствует адресу
+type GeocodeResult struct {
+	Point      GeoPoint
+	Precision  string
+	PostalCode string
+}
+
+// Geocoder переводит адрес в координаты; ErrLocationNotFound означает, что адрес неизвестен
+type Geocoder interface {
+	Geocode(ctx context.Context, address Address) (*GeocodeResult, error)
+}
+
+type AddressService interface {
+	ListAddresses(ctx context.Context, tenantID, userID uuid.UUID) ([]Address, error)
+	AddAddress(ctx context.Context, tenantID, userID uuid.UUID, address *Address) error
+	UpdateAddress(ctx context.Context, tenantID, userID uuid.UUID, address *Address) error
+	DeleteAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) error
+	SetPrimaryAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) (*Address, error)
+}
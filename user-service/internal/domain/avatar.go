@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrAvatarNotFound = errors.New("avatar not found")
+	ErrBlobNotFound   = errors.New("blob not found")
+	// ErrUnsupportedImage — файл не JPEG, PNG, GIF или WebP либо его размеры вне допустимых
+	ErrUnsupportedImage = errors.New("unsupported image")
+)
+
+const (
+	MaxAvatarBytes = 5 << 20
+	// Ограничение на стороны исходника защищает от картинок, которые занимают гигабайты после распаковки
+	MaxAvatarSide = 6000
+	MinAvatarSide = 16
+)
+
+// AvatarSizes — стороны квадратных миниатюр по возрастанию; avatar_url профиля ведёт на самую большую
+var AvatarSizes = []int{64, 128, 256, 512}
+
+// AvatarImages — миниатюры в JPEG без метаданных по стороне в пикселях
+type AvatarImages map[int][]byte
+
+// AvatarPath — публичный путь миниатюры. Путь меняется вместе с картинкой, поэтому
+// по одному адресу всегда отдаются одни и те же байты и их можно кэшировать навсегда.
+func AvatarPath(avatarID uuid.UUID, size int) string {
+	return fmt.Sprintf("/avatars/%s/%d.jpg", avatarID, size)
+}
+
+func AvatarBlobKey(avatarID uuid.UUID, size int) string {
+	return fmt.Sprintf("avatars/%s/%d.jpg", avatarID, size)
+}
+
+type Blob struct {
+	ContentType string
+	Data        []byte
+}
+
+// BlobStore хранит файлы по ключу вида "каталог/имя"; реализация выбирается в main
+type BlobStore interface {
+	Put(ctx context.Context, key string, blob Blob) error
+	// Get возвращает ErrBlobNotFound, если ключа нет
+	Get(ctx context.Context, key string) (*Blob, error)
+	// Delete не считает ошибкой отсутствие ключа
+	Delete(ctx context.Context, key string) error
+}
+
+type AvatarService interface {
+	// SetAvatar сохраняет миниатюры новой картинки и удаляет прежние
+	SetAvatar(ctx context.Context, tenantID, userID uuid.UUID, images AvatarImages) (*User, error)
+	DeleteAvatar(ctx context.Context, tenantID, userID uuid.UUID) (*User, error)
+	// GetAvatar отдаёт миниатюру без проверки тенанта: идентификатор картинки случайный и виден только в профиле
+	GetAvatar(ctx context.Context, avatarID uuid.UUID, size int) (*Blob, error)
+}
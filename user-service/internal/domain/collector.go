@@ -0,0 +1,236 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNotCollector             = errors.New("user is not a collector")
+	ErrCollectorProfileNotFound = errors.New("collector profile not found")
+	ErrZoneNotFound             = errors.New("service zone not found")
+	ErrCertificationNotFound    = errors.New("certification not found")
+)
+
+type VehicleType string
+
+const (
+	VehicleCompactorTruck VehicleType = "compactor_truck"
+	VehicleFlatbedTruck   VehicleType = "flatbed_truck"
+	VehicleVan            VehicleType = "van"
+	VehicleCargoBike      VehicleType = "cargo_bike"
+)
+
+var VehicleTypes = []VehicleType{VehicleCompactorTruck, VehicleFlatbedTruck, VehicleVan, VehicleCargoBike}
+
+func (t VehicleType) Valid() bool {
+	for _, known := range VehicleTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type CollectorAvailability string
+
+const (
+	CollectorAvailable   CollectorAvailability = "available"
+	CollectorUnavailable CollectorAvailability = "unavailable"
+	CollectorOnLeave     CollectorAvailability = "on_leave"
+)
+
+func (a CollectorAvailability) Valid() bool {
+	return a == CollectorAvailable || a == CollectorUnavailable || a == CollectorOnLeave
+}
+
+// Классы опасных отходов, для вывоза которых нужен допуск
+const (
+	HazardBatteries   = "batteries"
+	HazardElectronics = "electronics"
+	HazardChemicals   = "chemicals"
+	HazardMedical     = "medical"
+	HazardMercury     = "mercury"
+)
+
+var HazardClasses = []string{HazardBatteries, HazardElectronics, HazardChemicals, HazardMedical, HazardMercury}
+
+// Vehicle — закреплённая за сборщиком машина; пустой Type означает, что машины нет
+type Vehicle struct {
+	Type       VehicleType `json:"type,omitempty" gorm:"column:vehicle_type"`
+	Plate      string      `json:"plate,omitempty" gorm:"column:vehicle_plate"`
+	CapacityKg int         `json:"capacity_kg,omitempty" gorm:"column:capacity_kg"`
+	VolumeM3   float64     `json:"volume_m3,omitempty" gorm:"column:volume_m3"`
+}
+
+// CollectorProfile дополняет обычный профиль пользователя с ролью collector
+type CollectorProfile struct {
+	UserID           uuid.UUID             `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TenantID         uuid.UUID             `json:"-" gorm:"type:uuid;not null;index"`
+	Vehicle          Vehicle               `json:"vehicle" gorm:"embedded"`
+	Availability     CollectorAvailability `json:"availability" gorm:"not null"`
+	AvailabilityNote string                `json:"availability_note,omitempty"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+}
+
+// CollectorShift — еженедельная смена; время в формате HH:MM в часовом поясе сервиса
+type CollectorShift struct {
+	ID       uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID uuid.UUID    `json:"-" gorm:"type:uuid;not null;index:idx_collector_shifts_tenant_user"`
+	UserID   uuid.UUID    `json:"-" gorm:"type:uuid;not null;index:idx_collector_shifts_tenant_user"`
+	Weekday  time.Weekday `json:"weekday"`
+	Starts   string       `json:"starts" gorm:"not null"`
+	Ends     string       `json:"ends" gorm:"not null"`
+}
+
+// Covers сообщает, идёт ли смена в момент t; t уже переведено в часовой пояс смены
+func (s CollectorShift) Covers(t time.Time) bool {
+	clock := t.Format("15:04")
+	return t.Weekday() == s.Weekday && s.Starts <= clock && clock < s.Ends
+}
+
+type CollectorCertification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_collector_certifications_tenant_user"`
+	UserID    uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_collector_certifications_tenant_user"`
+	Hazard    string     `json:"hazard" gorm:"not null"`
+	Number    string     `json:"number" gorm:"not null"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (c CollectorCertification) ValidAt(t time.Time) bool {
+	return !t.Before(c.IssuedAt) && (c.ExpiresAt == nil || t.Before(*c.ExpiresAt))
+}
+
+// GeoPolygon — многоугольник в формате GeoJSON: первое кольцо внешнее, остальные — вырезы,
+// координаты [lon, lat], каждое кольцо замкнуто
+type GeoPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// Contains проверяет попадание точки лучом; точка на границе может попасть в любую сторону
+func (p GeoPolygon) Contains(point GeoPoint) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], point) {
+		return false
+	}
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, point) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds — наименьший прямоугольник, в котором лежит внешнее кольцо
+func (p GeoPolygon) Bounds() GeoBounds {
+	if len(p.Coordinates) == 0 || len(p.Coordinates[0]) == 0 {
+		return GeoBounds{}
+	}
+	first := p.Coordinates[0][0]
+	bounds := GeoBounds{West: first[0], South: first[1], East: first[0], North: first[1]}
+	for _, position := range p.Coordinates[0][1:] {
+		bounds.West = min(bounds.West, position[0])
+		bounds.East = max(bounds.East, position[0])
+		bounds.South = min(bounds.South, position[1])
+		bounds.North = max(bounds.North, position[1])
+	}
+	return bounds
+}
+
+func ringContains(ring [][2]float64, point GeoPoint) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > point.Latitude) != (yj > point.Latitude) &&
+			point.Longitude < (xj-xi)*(point.Latitude-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// GeoBounds — прямоугольник в градусах, стороны в порядке bbox из GeoJSON: запад, юг, восток, север
+type GeoBounds struct {
+	West  float64
+	South float64
+	East  float64
+	North float64
+}
+
+func (b GeoBounds) Overlaps(other GeoBounds) bool {
+	return b.West <= other.East && other.West <= b.East && b.South <= other.North && other.South <= b.North
+}
+
+// ServiceZone — территория, которую обслуживает сборщик.
+// В PostgreSQL по Boundary строится вычисляемая колонка area типа geography(Polygon).
+type ServiceZone struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID  `json:"-" gorm:"type:uuid;not null;index:idx_collector_zones_tenant_user"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index:idx_collector_zones_tenant_user"`
+	Name      string     `json:"name" gorm:"not null"`
+	Boundary  GeoPolygon `json:"boundary" gorm:"serializer:json;not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (ServiceZone) TableName() string {
+	return "collector_zones"
+}
+
+type CollectorDetails struct {
+	User           *User                    `json:"user"`
+	Profile        CollectorProfile         `json:"profile"`
+	OnShift        bool                     `json:"on_shift"`
+	Shifts         []CollectorShift         `json:"shifts"`
+	Certifications []CollectorCertification `json:"certifications"`
+	Zones          []ServiceZone            `json:"zones"`
+}
+
+// CollectorFilter — условия выборки сборщиков; пустые поля не ограничивают выборку
+type CollectorFilter struct {
+	Availability CollectorAvailability
+	VehicleType  VehicleType
+	// Hazard оставляет сборщиков с действующим допуском к этому классу отходов
+	Hazard string
+	// Point оставляет сборщиков, в зону которых попадает точка
+	Point *GeoPoint
+	// OnShift оставляет тех, у кого смена идёт прямо сейчас
+	OnShift bool
+}
+
+type CollectorRepository interface {
+	FindProfile(ctx context.Context, tenantID, userID uuid.UUID) (*CollectorProfile, error)
+	SaveProfile(ctx context.Context, profile *CollectorProfile) error
+	ListShifts(ctx context.Context, tenantID, userID uuid.UUID) ([]CollectorShift, error)
+	ReplaceShifts(ctx context.Context, tenantID, userID uuid.UUID, shifts []CollectorShift) error
+	ListCertifications(ctx context.Context, tenantID, userID uuid.UUID) ([]CollectorCertification, error)
+	SaveCertification(ctx context.Context, certification *CollectorCertification) error
+	DeleteCertification(ctx context.Context, tenantID, userID, certificationID uuid.UUID) error
+	ListZones(ctx context.Context, tenantID, userID uuid.UUID) ([]ServiceZone, error)
+	SaveZone(ctx context.Context, zone *ServiceZone) error
+	DeleteZone(ctx context.Context, tenantID, userID, zoneID uuid.UUID) error
+	// ZonesCovering возвращает зоны тенанта, которые содержат точку
+	ZonesCovering(ctx context.Context, tenantID uuid.UUID, point GeoPoint) ([]ServiceZone, error)
+	// ListTenantZones возвращает зоны всех сборщиков тенанта
+	ListTenantZones(ctx context.Context, tenantID uuid.UUID) ([]ServiceZone, error)
+}
+
+type CollectorService interface {
+	ListCollectors(ctx context.Context, tenantID uuid.UUID, filter CollectorFilter) ([]CollectorDetails, error)
+	GetCollector(ctx context.Context, tenantID, userID uuid.UUID) (*CollectorDetails, error)
+	UpdateProfile(ctx context.Context, tenantID, userID uuid.UUID, profile *CollectorProfile) (*CollectorDetails, error)
+	ReplaceShifts(ctx context.Context, tenantID, userID uuid.UUID, shifts []CollectorShift) ([]CollectorShift, error)
+	AddCertification(ctx context.Context, tenantID, userID uuid.UUID, certification *CollectorCertification) error
+	DeleteCertification(ctx context.Context, tenantID, userID, certificationID uuid.UUID) error
+	AddZone(ctx context.Context, tenantID, userID uuid.UUID, zone *ServiceZone) error
+	UpdateZone(ctx context.Context, tenantID, userID uuid.UUID, zone *ServiceZone) error
+	DeleteZone(ctx context.Context, tenantID, userID, zoneID uuid.UUID) error
+	// ListTenantZones отдаёт зоны всех сборщиков; с bounds — только те, чей прямоугольник пересекается с ним
+	ListTenantZones(ctx context.Context, tenantID uuid.UUID, bounds *GeoBounds) ([]ServiceZone, error)
+}
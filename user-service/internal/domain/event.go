@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Топики брокера: в users и user-actions пишет этот сервис, в accounts — auth-service.
+// user-actions — лента для живых подписок, без outbox: пропущенное читается из истории.
+const (
+	TopicUsers    = "users"
+	TopicAccounts = "accounts"
+	TopicActions  = "user-actions"
+)
+
+// Типы событий, которые публикует сервис
+const (
+	EventPreferencesChanged = "user.preferences_changed"
+	EventActionRecorded     = "user.action_recorded"
+)
+
+// Event — сообщение для подписчиков; Payload сериализован заранее, чтобы транспорт не зависел от типов
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	TenantID   uuid.UUID       `json:"tenant_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+func NewEvent(eventType string, tenantID uuid.UUID, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{ID: uuid.New(), Type: eventType, TenantID: tenantID, OccurredAt: time.Now().UTC(), Payload: data}, nil
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrHouseholdNotFound  = errors.New("household not found")
+	ErrAlreadyInHousehold = errors.New("user already belongs to a household")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationInvalid  = errors.New("invitation is expired, used or addressed to another email")
+	ErrNotHouseholdOwner  = errors.New("only the household owner can do this")
+	ErrNotHouseholdMember = errors.New("user is not a member of this household")
+	ErrOwnerMustTransfer  = errors.New("owner must transfer ownership before leaving a household with members")
+)
+
+type HouseholdRole string
+
+const (
+	HouseholdOwner  HouseholdRole = "owner"
+	HouseholdMember HouseholdRole = "member"
+)
+
+// HouseholdSettings — общие для всех жильцов настройки вывоза
+type HouseholdSettings struct {
+	PickupDay   string   `json:"pickup_day,omitempty"`
+	Bins        []string `json:"bins,omitempty"`
+	PickupNotes string   `json:"pickup_notes,omitempty"`
+}
+
+// Household объединяет жильцов одного адреса; AddressID указывает на адрес владельца
+type Household struct {
+	ID        uuid.UUID         `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID         `json:"-" gorm:"type:uuid;not null;index"`
+	Name      string            `json:"name" gorm:"not null"`
+	AddressID *uuid.UUID        `json:"address_id,omitempty" gorm:"type:uuid"`
+	Settings  HouseholdSettings `json:"settings" gorm:"serializer:json"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// HouseholdMembership — пользователь состоит не более чем в одном домохозяйстве
+type HouseholdMembership struct {
+	HouseholdID uuid.UUID     `json:"household_id" gorm:"type:uuid;primaryKey"`
+	UserID      uuid.UUID     `json:"user_id" gorm:"type:uuid;primaryKey;uniqueIndex:idx_household_members_tenant_user"`
+	TenantID    uuid.UUID     `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_household_members_tenant_user"`
+	Role        HouseholdRole `json:"role" gorm:"not null"`
+	JoinedAt    time.Time     `json:"joined_at"`
+}
+
+func (HouseholdMembership) TableName() string {
+	return "household_members"
+}
+
+// HouseholdInvitation принимается по коду; если указан Email, принять его может только владелец этого email
+type HouseholdInvitation struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID    uuid.UUID  `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_household_invitations_tenant_code"`
+	HouseholdID uuid.UUID  `json:"household_id" gorm:"type:uuid;not null;index"`
+	Code        string     `json:"code" gorm:"not null;uniqueIndex:idx_household_invitations_tenant_code"`
+	Email       string     `json:"email,omitempty"`
+	InvitedBy   uuid.UUID  `json:"invited_by" gorm:"type:uuid;not null"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	AcceptedBy  *uuid.UUID `json:"accepted_by,omitempty" gorm:"type:uuid"`
+	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type HouseholdMemberView struct {
+	HouseholdMembership
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type HouseholdDetails struct {
+	Household
+	Address *Address              `json:"address,omitempty"`
+	Members []HouseholdMemberView `json:"members"`
+}
+
+// HouseholdSummary — действия и баллы всех жильцов вместе
+type HouseholdSummary struct {
+	HouseholdID uuid.UUID       `json:"household_id"`
+	Actions     *ActionSummary  `json:"actions"`
+	Points      int64           `json:"points"`
+	Members     []PointsBalance `json:"members"`
+}
+
+// HouseholdActor — пользователь шлюза; профиль тенанта находится по ключу учётной записи, администратору профиль не обязателен
+type HouseholdActor struct {
+	IdentityKey uuid.UUID
+	Admin       bool
+}
+
+type HouseholdRepository interface {
+	CreateHousehold(ctx context.Context, household *Household, owner *HouseholdMembership) error
+	FindHousehold(ctx context.Context, tenantID, householdID uuid.UUID) (*Household, error)
+	UpdateHousehold(ctx context.Context, household *Household) error
+	DeleteHousehold(ctx context.Context, tenantID, householdID uuid.UUID) error
+	FindMembership(ctx context.Context, tenantID, userID uuid.UUID) (*HouseholdMembership, error)
+	ListMembers(ctx context.Context, tenantID, householdID uuid.UUID) ([]HouseholdMembership, error)
+	TransferOwnership(ctx context.Context, tenantID, householdID, from, to uuid.UUID) error
+	RemoveMember(ctx context.Context, tenantID, householdID, userID uuid.UUID) error
+	CreateInvitation(ctx context.Context, invitation *HouseholdInvitation) error
+	FindInvitationByCode(ctx context.Context, tenantID uuid.UUID, code string) (*HouseholdInvitation, error)
+	AcceptInvitation(ctx context.Context, invitation *HouseholdInvitation, member *HouseholdMembership) error
+}
+
+type HouseholdService interface {
+	CreateHousehold(ctx context.Context, tenantID uuid.UUID, actor HouseholdActor, household *Household) (*HouseholdDetails, error)
+	GetHousehold(ctx context.Context, tenantID, householdID uuid.UUID, actor HouseholdActor) (*HouseholdDetails, error)
+	GetUserHousehold(ctx context.Context, tenantID, userID uuid.UUID, actor HouseholdActor) (*HouseholdDetails, error)
+	UpdateHousehold(ctx context.Context, tenantID uuid.UUID, actor HouseholdActor, household *Household) (*HouseholdDetails, error)
+	DeleteHousehold(ctx context.Context, tenantID, householdID uuid.UUID, actor HouseholdActor) error
+	Invite(ctx context.Context, tenantID, householdID uuid.UUID, actor HouseholdActor, email string) (*HouseholdInvitation, error)
+	Join(ctx context.Context, tenantID uuid.UUID, actor HouseholdActor, code string) (*HouseholdDetails, error)
+	RemoveMember(ctx context.Context, tenantID, householdID, userID uuid.UUID, actor HouseholdActor) error
+	TransferOwnership(ctx context.Context, tenantID, householdID, userID uuid.UUID, actor HouseholdActor) (*HouseholdDetails, error)
+	Summary(ctx context.Context, tenantID, householdID uuid.UUID, actor HouseholdActor, query ActionSummaryQuery) (*HouseholdSummary, error)
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrImportNotFound = errors.New("import not found")
+	// ErrAlreadyRegistered — у жителя уже есть учётная запись, приглашать некого
+	ErrAlreadyRegistered = errors.New("account already exists")
+	ErrImportsStopped    = errors.New("imports are not accepted while the service is shutting down")
+)
+
+type ImportStatus string
+
+const (
+	ImportPending   ImportStatus = "pending"
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+const (
+	MaxImportRows = 50000
+	// Отчёт хранит не больше этого числа ошибок, остальные только считаются в Failed
+	MaxImportErrors = 1000
+	// Незавершённая загрузка, которая дольше этого не сохраняла прогресс, брошена упавшим экземпляром
+	ImportHeartbeatTimeout = 5 * time.Minute
+)
+
+// ImportColumns — распознаваемые заголовки файла; обязателен только email, порядок любой
+var ImportColumns = []string{"email", "name", "city", "street", "house", "apartment", "postal_code"}
+
+// ImportRow — строка файла; Row — её номер в файле с учётом заголовка, как его видит человек в таблице
+type ImportRow struct {
+	Row        int
+	Email      string
+	Name       string
+	City       string
+	Street     string
+	House      string
+	Apartment  string
+	PostalCode string
+}
+
+// ImportRowError — причина, по которой строка не загружена или загружена не полностью
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Email   string `json:"email,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJob — загрузка одного файла. При DryRun строки только проверяются, а счётчики
+// показывают, что произошло бы при настоящей загрузке.
+type ImportJob struct {
+	ID        uuid.UUID        `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID        `json:"-" gorm:"type:uuid;not null;index:idx_user_imports_tenant_created"`
+	CreatedBy string           `json:"created_by,omitempty"`
+	FileName  string           `json:"file_name"`
+	DryRun    bool             `json:"dry_run" gorm:"not null;default:false"`
+	Invite    bool             `json:"invite" gorm:"not null;default:false"`
+	Status    ImportStatus     `json:"status" gorm:"not null"`
+	TotalRows int              `json:"total_rows" gorm:"not null;default:0"`
+	Processed int              `json:"processed_rows" gorm:"not null;default:0"`
+	Created   int              `json:"created" gorm:"not null;default:0"`
+	Updated   int              `json:"updated" gorm:"not null;default:0"`
+	Unchanged int              `json:"unchanged" gorm:"not null;default:0"`
+	Failed    int              `json:"failed" gorm:"not null;default:0"`
+	Invited   int              `json:"invited" gorm:"not null;default:0"`
+	Errors    []ImportRowError `json:"errors,omitempty" gorm:"serializer:json"`
+	// Error — причина, по которой загрузка остановилась целиком
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index:idx_user_imports_tenant_created"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// HeartbeatAt обновляется при каждом сохранении прогресса
+	HeartbeatAt *time.Time `json:"-"`
+}
+
+func (ImportJob) TableName() string {
+	return "user_imports"
+}
+
+// ImportRequest — разобранный файл: Records[0] — заголовок
+type ImportRequest struct {
+	FileName string
+	Records  [][]string
+	DryRun   bool
+	Invite   bool
+}
+
+type ImportRepository interface {
+	CreateImport(ctx context.Context, job *ImportJob) error
+	// SaveImport сохраняет прогресс и итог загрузки
+	SaveImport(ctx context.Context, job *ImportJob) error
+	FindImport(ctx context.Context, tenantID, importID uuid.UUID) (*ImportJob, error)
+	ListImports(ctx context.Context, tenantID uuid.UUID, limit int) ([]ImportJob, error)
+	// FailStaleImports переводит в failed загрузки всех тенантов, которые не завершились
+	// и не сохраняли прогресс с heartbeatBefore; возвращает их число
+	FailStaleImports(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error)
+}
+
+// Inviter просит auth-service пригласить жителя; ErrAlreadyRegistered — учётная запись уже есть
+type Inviter interface {
+	// Invite возвращает ключ, который получит учётная запись приглашённого: переданный
+	// или ключ приглашения, которое уже ждёт ответа
+	Invite(ctx context.Context, tenantSlug, email, name string, identityKey uuid.UUID) (uuid.UUID, error)
+}
+
+type ImportService interface {
+	// StartImport проверяет заголовок и запускает загрузку в фоне; ход загрузки виден через GetImport
+	StartImport(ctx context.Context, tenant *Tenant, request ImportRequest) (*ImportJob, error)
+	GetImport(ctx context.Context, tenantID, importID uuid.UUID) (*ImportJob, error)
+	ListImports(ctx context.Context, tenantID uuid.UUID, limit int) ([]ImportJob, error)
+	// FailStaleImports закрывает загрузки, брошенные упавшими или перезапущенными экземплярами
+	FailStaleImports(ctx context.Context) (int64, error)
+	// Shutdown останавливает идущие загрузки между строками и ждёт, пока они сохранят итог
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPointsAlreadyCredited = errors.New("points for this action are already credited")
+	ErrUnbalancedTransaction = errors.New("points transaction entries do not balance")
+	ErrInsufficientPoints    = errors.New("insufficient points balance")
+)
+
+// Системные счета, с которых начисляются баллы; сумма всех проводок по тенанту всегда равна нулю
+const (
+	AccountRewards     = "system:rewards"
+	AccountAdjustments = "system:adjustments"
+)
+
+func UserAccount(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// IsUserAccount: баланс счёта пользователя не может уйти в минус, системные счета ограничений не имеют
+func IsUserAccount(account string) bool {
+	return strings.HasPrefix(account, "user:")
+}
+
+type PointsTransactionKind string
+
+const (
+	PointsEarned   PointsTransactionKind = "earn"
+	PointsAdjusted PointsTransactionKind = "adjust"
+)
+
+// PointsTransaction объединяет проводки одной операции; ActionID уникален в тенанте и не даёт начислить баллы дважды
+type PointsTransaction struct {
+	ID        uuid.UUID             `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID  uuid.UUID             `json:"tenant_id" gorm:"type:uuid;not null;uniqueIndex:idx_points_transactions_tenant_action"`
+	Kind      PointsTransactionKind `json:"kind" gorm:"not null"`
+	ActionID  *uuid.UUID            `json:"action_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_points_transactions_tenant_action"`
+	Reason    string                `json:"reason,omitempty"`
+	CreatedBy string                `json:"created_by,omitempty"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+type PointsEntry struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID      uuid.UUID `json:"-" gorm:"type:uuid;not null;index:idx_points_entries_tenant_account"`
+	TransactionID uuid.UUID `json:"transaction_id" gorm:"type:uuid;not null;index"`
+	Account       string    `json:"account" gorm:"not null;index:idx_points_entries_tenant_account"`
+	Amount        int64     `json:"amount" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PointsHistoryItem — проводка по счёту пользователя вместе с описанием операции
+type PointsHistoryItem struct {
+	PointsEntry
+	Kind     PointsTransactionKind `json:"kind"`
+	ActionID *uuid.UUID            `json:"action_id,omitempty"`
+	Reason   string                `json:"reason,omitempty"`
+}
+
+type PointsBalance struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Balance int64     `json:"balance"`
+}
+
+type PointsHistoryPage struct {
+	Items  []PointsHistoryItem `json:"items"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// EarningRule — сколько баллов тенант начисляет за действие данного типа
+type EarningRule struct {
+	TenantID  uuid.UUID  `json:"-" gorm:"type:uuid;primaryKey"`
+	Action    ActionType `json:"action" gorm:"primaryKey"`
+	Points    int64      `json:"points" gorm:"not null"`
+	IsDefault bool       `json:"default" gorm:"-"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func (EarningRule) TableName() string {
+	return "points_rules"
+}
+
+// DefaultEarningRules действуют, пока тенант не задал собственное правило
+var DefaultEarningRules = map[ActionType]int64{
+	ActionProfileUpdated:   0,
+	ActionWasteSorted:      10,
+	ActionPickupRequested:  5,
+	ActionPointVisited:     15,
+	ActionReportFiled:      20,
+	ActionReferralRewarded: 50,
+}
+
+type PointsRepository interface {
+	// CreateTransaction возвращает ErrInsufficientPoints, если проводки увели бы счёт пользователя в минус;
+	// проверка баланса и запись идут атомарно, так что параллельные списания не дают перерасхода
+	CreateTransaction(ctx context.Context, transaction *PointsTransaction, entries []PointsEntry) error
+	Balance(ctx context.Context, tenantID uuid.UUID, account string) (int64, error)
+	History(ctx context.Context, tenantID uuid.UUID, account string, limit, offset int) ([]PointsHistoryItem, int64, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]EarningRule, error)
+	SaveRule(ctx context.Context, rule *EarningRule) error
+}
+
+type PointsService interface {
+	CreditAction(ctx context.Context, action UserAction) (*PointsTransaction, error)
+	Balance(ctx context.Context, tenantID, userID uuid.UUID) (*PointsBalance, error)
+	History(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) (*PointsHistoryPage, error)
+	Adjust(ctx context.Context, tenantID, userID uuid.UUID, amount int64, reason string) (*PointsTransaction, error)
+	ListRules(ctx context.Context, tenantID uuid.UUID) ([]EarningRule, error)
+	SetRule(ctx context.Context, tenantID uuid.UUID, action ActionType, points int64) (*EarningRule, error)
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrPreferencesNotFound = errors.New("preferences not found")
+
+type Language string
+
+const (
+	LanguageRussian Language = "ru"
+	LanguageKazakh  Language = "kk"
+	LanguageEnglish Language = "en"
+)
+
+var Languages = []Language{LanguageRussian, LanguageKazakh, LanguageEnglish}
+
+type Units string
+
+const (
+	UnitsMetric   Units = "metric"
+	UnitsImperial Units = "imperial"
+)
+
+type ContactChannel string
+
+const (
+	ChannelPush     ContactChannel = "push"
+	ChannelEmail    ContactChannel = "email"
+	ChannelSMS      ContactChannel = "sms"
+	ChannelTelegram ContactChannel = "telegram"
+)
+
+var ContactChannels = []ContactChannel{ChannelPush, ChannelEmail, ChannelSMS, ChannelTelegram}
+
+// QuietHours — время HH:MM, когда напоминания не отправляются; End раньше Start означает интервал через полночь
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Covers сообщает, попадает ли время суток clock (HH:MM) в тихие часы
+func (q QuietHours) Covers(clock string) bool {
+	if q.Start <= q.End {
+		return q.Start <= clock && clock < q.End
+	}
+	return clock >= q.Start || clock < q.End
+}
+
+const MaxReminderLeadMinutes = 7 * 24 * 60
+
+// Preferences — типизированный документ настроек; QuietHours nil отключает тихие часы
+type Preferences struct {
+	Language            Language         `json:"language"`
+	Units               Units            `json:"units"`
+	Channels            []ContactChannel `json:"channels"`
+	QuietHours          *QuietHours      `json:"quiet_hours"`
+	ReminderLeadMinutes int              `json:"reminder_lead_minutes"`
+}
+
+// DefaultPreferences — настройки пользователя, который их ещё не менял
+func DefaultPreferences() Preferences {
+	return Preferences{
+		Language:            LanguageRussian,
+		Units:               UnitsMetric,
+		Channels:            []ContactChannel{ChannelPush},
+		QuietHours:          &QuietHours{Start: "22:00", End: "08:00"},
+		ReminderLeadMinutes: 60,
+	}
+}
+
+type UserPreferences struct {
+	UserID      uuid.UUID   `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TenantID    uuid.UUID   `json:"-" gorm:"type:uuid;not null;index"`
+	Preferences Preferences `json:"preferences" gorm:"column:document;serializer:json;not null"`
+	// IsDefault — сохранённых настроек нет, показаны значения по умолчанию
+	IsDefault bool      `json:"is_default" gorm:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PreferencesChanged — полезная нагрузка события EventPreferencesChanged
+type PreferencesChanged struct {
+	UserID   uuid.UUID   `json:"user_id"`
+	Changed  []string    `json:"changed"`
+	Previous Preferences `json:"previous"`
+	Current  Preferences `json:"current"`
+}
+
+type PreferencesRepository interface {
+	FindPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*UserPreferences, error)
+	// SavePreferences сохраняет настройки и события об их изменении одной транзакцией
+	SavePreferences(ctx context.Context, preferences *UserPreferences, events ...Event) error
+}
+
+type PreferencesService interface {
+	GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*UserPreferences, error)
+	UpdatePreferences(ctx context.Context, tenantID, userID uuid.UUID, preferences Preferences) (*UserPreferences, error)
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReferralCodeNotFound = errors.New("referral code not found")
+	ErrReferralNotFound     = errors.New("referral not found")
+	ErrReferralCodeTaken    = errors.New("referral code is already taken")
+	ErrAlreadyReferred      = errors.New("user is already attributed to a referrer")
+	ErrNotReferralOwner     = errors.New("only the user or an admin can manage referrals")
+)
+
+// Сколько приглашений в месяц приносят пригласившему баллы; остальные засчитываются без награды
+const MaxReferralRewardsPerMonth = 20
+
+type ReferralStatus string
+
+const (
+	// ReferralPending — житель привязан при регистрации, награда ждёт его первого адреса и действия
+	ReferralPending  ReferralStatus = "pending"
+	ReferralRewarded ReferralStatus = "rewarded"
+	// ReferralBlocked — приглашение засчитано, но награда не выдана из-за ограничений
+	ReferralBlocked ReferralStatus = "blocked"
+)
+
+// Причины, по которым приглашение не награждается
+const (
+	ReferralBlockSameDevice   = "same_device"
+	ReferralBlockSameAddress  = "same_address"
+	ReferralBlockMonthlyLimit = "monthly_limit"
+	// Пригласивший удалил профиль до того, как приглашённый проявил активность
+	ReferralBlockReferrerDeleted = "referrer_deleted"
+)
+
+// ReferralCode — постоянный код пользователя; выпускается при первом запросе
+type ReferralCode struct {
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	TenantID  uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_referral_codes_tenant_code"`
+	Code      string    `json:"code" gorm:"not null;uniqueIndex:idx_referral_codes_tenant_code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Referral связывает нового жителя с пригласившим; у жителя может быть только один пригласивший.
+// District — почтовый индекс или город основного адреса на момент решения о награде.
+// Идентификатор устройства хранится только в виде SHA-256. ResolvedAt — когда награда выдана или заблокирована.
+type Referral struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID    uuid.UUID      `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_referrals_tenant_referred;index:idx_referrals_tenant_referrer;index:idx_referrals_tenant_device"`
+	ReferrerID  uuid.UUID      `json:"referrer_id" gorm:"type:uuid;not null;index:idx_referrals_tenant_referrer"`
+	ReferredID  uuid.UUID      `json:"referred_id" gorm:"type:uuid;not null;uniqueIndex:idx_referrals_tenant_referred"`
+	Code        string         `json:"code" gorm:"not null"`
+	DeviceHash  string         `json:"-" gorm:"index:idx_referrals_tenant_device"`
+	District    string         `json:"district,omitempty"`
+	Status      ReferralStatus `json:"status" gorm:"not null"`
+	BlockReason string         `json:"block_reason,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	ResolvedAt  *time.Time     `json:"resolved_at,omitempty"`
+}
+
+// ReferralClaim — код пригласившего и устройство из запроса на регистрацию; приходит в событии user.registered.
+// Устройство сообщает клиент, поэтому оно лишь одна из проверок перед наградой.
+type ReferralClaim struct {
+	Code     string `json:"code"`
+	DeviceID string `json:"device_id,omitempty"`
+}
+
+// ReferralFilter отбирает приглашения для проверки ограничений; пустые поля не учитываются
+type ReferralFilter struct {
+	ReferrerID    *uuid.UUID
+	DeviceHash    string
+	Status        ReferralStatus
+	ResolvedSince *time.Time
+}
+
+type ReferralCounts struct {
+	Referred int64 `json:"referred"`
+	Pending  int64 `json:"pending"`
+	Rewarded int64 `json:"rewarded"`
+	Blocked  int64 `json:"blocked"`
+}
+
+// ReferralSummary — код пользователя, его приглашения и то, кто пригласил его самого
+type ReferralSummary struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Code       string     `json:"code"`
+	ReferredBy *uuid.UUID `json:"referred_by,omitempty"`
+	ReferralCounts
+}
+
+type ReferrerStats struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	ReferralCounts
+}
+
+type DistrictReferralStats struct {
+	District string `json:"district"`
+	ReferralCounts
+}
+
+type ReferralStats struct {
+	Referrers []ReferrerStats         `json:"referrers"`
+	Districts []DistrictReferralStats `json:"districts"`
+}
+
+type ReferralRepository interface {
+	// CreateReferralCode возвращает ErrReferralCodeTaken, если код или пользователь уже заняты
+	CreateReferralCode(ctx context.Context, code *ReferralCode) error
+	FindReferralCode(ctx context.Context, tenantID, userID uuid.UUID) (*ReferralCode, error)
+	FindReferralCodeByCode(ctx context.Context, tenantID uuid.UUID, code string) (*ReferralCode, error)
+	// CreateReferral возвращает ErrAlreadyReferred, если у жителя уже есть пригласивший
+	CreateReferral(ctx context.Context, referral *Referral) error
+	// ResolveReferral сохраняет статус, причину, район и ResolvedAt ожидающего приглашения;
+	// если приглашение уже не ожидает, возвращает ErrReferralNotFound
+	ResolveReferral(ctx context.Context, referral *Referral) error
+	FindReferralByReferred(ctx context.Context, tenantID, referredID uuid.UUID) (*Referral, error)
+	CountReferrals(ctx context.Context, tenantID uuid.UUID, filter ReferralFilter) (int64, error)
+	CountByReferrer(ctx context.Context, tenantID, referrerID uuid.UUID) (ReferralCounts, error)
+	// TopReferrers сортирует пригласивших по числу приглашений
+	TopReferrers(ctx context.Context, tenantID uuid.UUID, limit int) ([]ReferrerStats, error)
+	CountByDistrict(ctx context.Context, tenantID uuid.UUID) ([]DistrictReferralStats, error)
+}
+
+// ReferralService наблюдает за действиями: первое действие жителя с адресом выдаёт отложенную награду
+type ReferralService interface {
+	ActionObserver
+	// GetReferralSummary выпускает код при первом обращении
+	GetReferralSummary(ctx context.Context, tenantID, userID uuid.UUID) (*ReferralSummary, error)
+	// AttributeReferral привязывает только что созданный профиль к пригласившему; награда ждёт активности жителя
+	AttributeReferral(ctx context.Context, tenantID, userID uuid.UUID, claim ReferralClaim) (*Referral, error)
+	// ReleaseReward решает судьбу ожидающей награды, когда у жителя есть и адрес, и действие;
+	// ограничения проверяются заново на этот момент. Без ожидающего приглашения ничего не делает.
+	ReleaseReward(ctx context.Context, tenantID, userID uuid.UUID) error
+	ReferralStats(ctx context.Context, tenantID uuid.UUID, limit int) (*ReferralStats, error)
+}
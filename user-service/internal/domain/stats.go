@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserActionCount — число действий одного типа, совершённых пользователем за период
+type UserActionCount struct {
+	UserID uuid.UUID
+	ActionCount
+}
+
+// UserStats — сводка для личного кабинета; дни считаются в часовом поясе сервиса
+type UserStats struct {
+	UserID       uuid.UUID            `json:"user_id"`
+	Totals       map[ActionType]int64 `json:"totals"`
+	Total        int64                `json:"total"`
+	ActiveDays   int                  `json:"active_days"`
+	ActiveDays30 int                  `json:"active_days_last_30"`
+	FirstActive  *time.Time           `json:"first_active_day,omitempty"`
+	LastActive   *time.Time           `json:"last_active_day,omitempty"`
+	District     string               `json:"district,omitempty"`
+	ComputedAt   time.Time            `json:"computed_at"`
+}
+
+// TenantStatsQuery — окно для активных пользователей и число месячных когорт удержания
+type TenantStatsQuery struct {
+	Period       AggregationPeriod
+	From         *time.Time
+	To           *time.Time
+	Cohorts      int
+	TopDistricts int
+}
+
+type ActiveUsersBucket struct {
+	Start time.Time `json:"start"`
+	Users int       `json:"users"`
+}
+
+// RetentionCohort — жители, впервые проявившие активность в месяце Month.
+// Active[i] — сколько из них были активны через i месяцев; Active[0] равен Size.
+type RetentionCohort struct {
+	Month  time.Time `json:"month"`
+	Size   int       `json:"size"`
+	Active []int     `json:"active"`
+}
+
+type DistrictActivity struct {
+	District string `json:"district"`
+	Users    int    `json:"users"`
+	Actions  int64  `json:"actions"`
+}
+
+type TenantStats struct {
+	Totals      map[ActionType]int64 `json:"totals"`
+	Total       int64                `json:"total"`
+	Users       int                  `json:"active_users_total"`
+	Period      AggregationPeriod    `json:"period"`
+	Timezone    string               `json:"timezone"`
+	ActiveUsers []ActiveUsersBucket  `json:"active_users"`
+	Cohorts     []RetentionCohort    `json:"retention_cohorts"`
+	Districts   []DistrictActivity   `json:"top_districts"`
+	ComputedAt  time.Time            `json:"computed_at"`
+}
+
+// UserStatsService считает сводки по кэшу, который строится при первом запросе тенанта
+// и дополняется каждым новым действием через ActionRecorded
+type UserStatsService interface {
+	ActionObserver
+	GetUserStats(ctx context.Context, tenantID, userID uuid.UUID) (*UserStats, error)
+	GetTenantStats(ctx context.Context, tenantID uuid.UUID, query TenantStatsQuery) (*TenantStats, error)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const DefaultTenantSlug = "default"
+
+var (
+	ErrTenantNotFound    = errors.New("tenant not found")
+	ErrCrossTenantAccess = errors.New("cross-tenant access denied")
+)
+
+type Tenant struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Slug      string    `json:"slug" gorm:"uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant *Tenant) error
+	FindTenantBySlug(ctx context.Context, slug string) (*Tenant, error)
+}
+
+type TenantService interface {
+	CreateTenant(ctx context.Context, tenant *Tenant) error
+	ResolveTenant(ctx context.Context, slug string) (*Tenant, error)
+}
@@ -1,8 +1,19 @@
 package domain
 
 import (
+  "context"
+  "encoding/json"
+  "errors"
   "time"
   "github.com/google/uuid"
+  "gorm.io/gorm"
+)
+
+var (
+  ErrUserNotFound = errors.New("user not found")
+  ErrInvalidInput = errors.New("invalid input")
+  ErrVersionConflict = errors.New("user was modified concurrently")
+  ErrNotProfileOwner = errors.New("only the user or an admin can change the profile")
 )
 
 type UserRole string
@@ -13,36 +24,109 @@ const (
   RoleCollector UserRole = "collector"
 )
 
+// IdentityKey связывает профиль с учётной записью auth-service; у профилей без учётной записи он пуст
 type User struct {
   ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-  Email     string    `json:"email" gorm:"unique;not null"`
+  IdentityKey *uuid.UUID `json:"identity_key,omitempty" gorm:"type:uuid;uniqueIndex"`
+  TenantID  uuid.UUID `json:"tenant_id" gorm:"type:uuid;not null;uniqueIndex:idx_users_tenant_email"`
+  Email     string    `json:"email" gorm:"not null;uniqueIndex:idx_users_tenant_email"`
   Name      string    `json:"name"`
-  Address   string    `json:"address"`
   Role      UserRole  `json:"role"`
+  // AvatarID меняется с каждой загрузкой картинки; ссылки на миниатюры добавляет MarshalJSON
+  AvatarID  *uuid.UUID `json:"-" gorm:"type:uuid"`
   CreatedAt time.Time `json:"created_at"`
   UpdatedAt time.Time `json:"updated_at"`
+  DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+  Version   int64     `json:"version" gorm:"not null;default:1"`
+  // AccountVersion — версия учётной записи из последнего применённого события auth-service
+  AccountVersion int64 `json:"-" gorm:"not null;default:0"`
+  // InvitedAt задан, пока житель из импорта не принял приглашение; IdentityKey в это время
+  // зарезервирован за учётной записью, которую создаст приглашение
+  InvitedAt *time.Time `json:"-"`
 }
 
-type UserAction struct {
-  ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-  UserID    uuid.UUID `json:"user_id"`
-  Action    string    `json:"action"`
-  Details   string    `json:"details"`
-  CreatedAt time.Time `json:"created_at"`
+// MarshalJSON добавляет к профилю ссылки на аватар: avatar_url на самую крупную миниатюру
+// и avatar_thumbnails на все размеры
+func (u User) MarshalJSON() ([]byte, error) {
+  type plain User
+  out := struct {
+    plain
+    AvatarURL        string         `json:"avatar_url,omitempty"`
+    AvatarThumbnails map[int]string `json:"avatar_thumbnails,omitempty"`
+  }{plain: plain(u)}
+
+  if u.AvatarID != nil {
+    out.AvatarThumbnails = map[int]string{}
+    for _, size := range AvatarSizes {
+      out.AvatarThumbnails[size] = AvatarPath(*u.AvatarID, size)
+    }
+    out.AvatarURL = AvatarPath(*u.AvatarID, AvatarSizes[len(AvatarSizes)-1])
+  }
+  return json.Marshal(out)
+}
+
+// Поля профиля, которые пользователь может менять сам; email, роль и даты принадлежат сервису
+var EditableUserFields = []string{"name"}
+
+// UserPatch — изменения профиля в семантике JSON Merge Patch: отсутствующее поле не меняется, nil очищает его
+type UserPatch map[string]*string
+
+const (
+  DefaultPageLimit = 20
+  MaxPageLimit     = 100
+)
+
+// UserFilter — условия выборки пользователей; пустые поля не ограничивают выборку
+type UserFilter struct {
+  Role        UserRole
+  Name        string
+  // Address ищет подстроку в улице, городе или индексе любого адреса пользователя
+  Address     string
+  CreatedFrom *time.Time
+  CreatedTo   *time.Time
+  Limit       int
+  Offset      int
+}
+
+type UserPage struct {
+  Users  []User `json:"users"`
+  Total  int64  `json:"total"`
+  Limit  int    `json:"limit"`
+  Offset int    `json:"offset"`
 }
 
 type UserRepository interface {
-  Create(user *User) error
-  FindByEmail(email string) (*User, error)
-  Update(user *User) error
-  GetUserActions(userID uuid.UUID) ([]UserAction, error)
-  RecordUserAction(action *UserAction) error
+  Create(ctx context.Context, user *User) error
+  FindByEmail(ctx context.Context, tenantID uuid.UUID, email string) (*User, error)
+  FindByID(ctx context.Context, tenantID, userID uuid.UUID) (*User, error)
+  FindByIdentityKey(ctx context.Context, tenantID, identityKey uuid.UUID) (*User, error)
+  // AccountVersion возвращает последнюю применённую версию учётной записи, учитывая удалённые профили; 0 — событий не было
+  AccountVersion(ctx context.Context, tenantID, identityKey uuid.UUID) (int64, error)
+  List(ctx context.Context, tenantID uuid.UUID, filter UserFilter) ([]User, int64, error)
+  Search(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) ([]User, int64, error)
+  Update(ctx context.Context, user *User) error
+  Delete(ctx context.Context, tenantID, userID uuid.UUID) error
+  GetUserActions(ctx context.Context, tenantID, userID uuid.UUID, filter ActionFilter) ([]UserAction, error)
+  CountUserActions(ctx context.Context, tenantID, userID uuid.UUID, query ActionSummaryQuery) ([]ActionCount, error)
+  // CountTenantActions группирует действия тенанта, созданные до before, по пользователю, периоду в loc и типу
+  CountTenantActions(ctx context.Context, tenantID uuid.UUID, period AggregationPeriod, loc *time.Location, before time.Time) ([]UserActionCount, error)
+  RecordUserAction(ctx context.Context, action *UserAction) error
+  ListAddresses(ctx context.Context, tenantID, userID uuid.UUID) ([]Address, error)
+  FindAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) (*Address, error)
+  // ListPrimaryAddresses возвращает основные адреса всех пользователей тенанта
+  ListPrimaryAddresses(ctx context.Context, tenantID uuid.UUID) ([]Address, error)
+  SaveAddress(ctx context.Context, address *Address) error
+  DeleteAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) error
 }
 
 type UserService interface {
-  CreateUser(user *User) error
-  GetUserProfile(userID uuid.UUID) (*User, error)
-  UpdateUserProfile(user *User) error
-  GetUserActionHistory(userID uuid.UUID) ([]UserAction, error)
+  CreateUser(ctx context.Context, tenantID uuid.UUID, user *User) error
+  GetUserProfile(ctx context.Context, tenantID, userID uuid.UUID) (*User, error)
+  // GetOwnProfile — как GetUserProfile, но только для самого пользователя или админа
+  GetOwnProfile(ctx context.Context, tenantID, userID uuid.UUID) (*User, error)
+  UpdateUserProfile(ctx context.Context, tenantID, userID uuid.UUID, patch UserPatch, expectedVersion int64) (*User, error)
+  ListUsers(ctx context.Context, tenantID uuid.UUID, filter UserFilter) (*UserPage, error)
+  SearchUsers(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) (*UserPage, error)
+  DeleteUser(ctx context.Context, tenantID, userID uuid.UUID) error
 }
   
\ No newline at end of file
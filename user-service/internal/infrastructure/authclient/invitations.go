@@ -0,0 +1,97 @@
+// Package authclient — обращения user-service к HTTP API auth-service
+package authclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// Inviter просит auth-service разослать приглашения. Запрос подписывается общим секретом
+// INVITATIONS_SECRET так же, как подписываются входящие запросы к user-service.
+type Inviter struct {
+	baseURL string
+	secret  []byte
+	client  *http.Client
+}
+
+func NewInviter(baseURL, secret string) *Inviter {
+	return &Inviter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type inviteRequest struct {
+	Tenant      string    `json:"tenant"`
+	Email       string    `json:"email"`
+	Name        string    `json:"name,omitempty"`
+	IdentityKey uuid.UUID `json:"identity_key"`
+}
+
+type inviteResponse struct {
+	IdentityKey uuid.UUID `json:"identity_key"`
+}
+
+func (i *Inviter) Invite(ctx context.Context, tenantSlug, email, name string, identityKey uuid.UUID) (uuid.UUID, error) {
+	body, err := json.Marshal(inviteRequest{Tenant: tenantSlug, Email: email, Name: name, IdentityKey: identityKey})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.baseURL+"/invitations", bytes.NewReader(body))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestctx.RequestIDHeader, requestctx.RequestID(ctx))
+	req.Header.Set("X-Signature-Timestamp", timestamp)
+	req.Header.Set("X-Signature", i.sign(timestamp, body))
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("auth-service is unavailable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return uuid.Nil, domain.ErrAlreadyRegistered
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return uuid.Nil, fmt.Errorf("auth-service rejected the invitation with status %d: %s", resp.StatusCode, bytes.TrimSpace(reason))
+	}
+
+	var invitation inviteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&invitation); err != nil {
+		return uuid.Nil, fmt.Errorf("invalid invitation response: %w", err)
+	}
+	if invitation.IdentityKey == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("auth-service returned an invitation without identity key")
+	}
+	return invitation.IdentityKey, nil
+}
+
+func (i *Inviter) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+-- Базы, созданные до миграций, уже содержат users и user_actions от AutoMigrate, но без tenant_id
+-- и с глобальным уникальным индексом на email: первая миграция дополняет их до новой схемы
+CREATE TABLE IF NOT EXISTS tenants (
+    id         UUID PRIMARY KEY,
+    slug       TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenants_slug ON tenants (slug);
+
+-- Пользователи без явного тенанта попадают в тенант по умолчанию
+INSERT INTO tenants (id, slug, name, created_at)
+SELECT gen_random_uuid(), 'default', 'Default', NOW()
+WHERE NOT EXISTS (SELECT 1 FROM tenants WHERE slug = 'default');
+
+CREATE TABLE IF NOT EXISTS users (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    email      TEXT NOT NULL,
+    name       TEXT,
+    address    TEXT,
+    role       TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants (id);
+UPDATE users SET tenant_id = (SELECT id FROM tenants WHERE slug = 'default') WHERE tenant_id IS NULL;
+ALTER TABLE users ALTER COLUMN tenant_id SET NOT NULL;
+
+-- AutoMigrate создавал уникальность email по всей базе; gorm разных версий называл её по-разному
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users (tenant_id, email);
+
+CREATE TABLE IF NOT EXISTS user_actions (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    user_id    UUID,
+    action     TEXT,
+    details    TEXT,
+    created_at TIMESTAMPTZ
+);
+
+ALTER TABLE user_actions ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants (id);
+UPDATE user_actions SET tenant_id = (SELECT id FROM tenants WHERE slug = 'default') WHERE tenant_id IS NULL;
+ALTER TABLE user_actions ALTER COLUMN tenant_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_user_actions_tenant_id ON user_actions (tenant_id);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_users_search;
+DROP INDEX IF EXISTS idx_users_tenant_created_at;
+
+DROP INDEX IF EXISTS idx_users_tenant_email;
+CREATE UNIQUE INDEX idx_users_tenant_email ON users (tenant_id, email);
+
+DROP INDEX IF EXISTS idx_users_deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
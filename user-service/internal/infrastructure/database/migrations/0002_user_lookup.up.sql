@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+-- После мягкого удаления адрес можно зарегистрировать заново
+DROP INDEX idx_users_tenant_email;
+CREATE UNIQUE INDEX idx_users_tenant_email ON users (tenant_id, email) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_users_tenant_created_at ON users (tenant_id, created_at DESC);
+
+CREATE INDEX idx_users_search ON users
+    USING GIN (to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(email, '')));
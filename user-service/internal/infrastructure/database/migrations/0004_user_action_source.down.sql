@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_actions_tenant_user_created_at;
+
+ALTER TABLE user_actions DROP COLUMN source;
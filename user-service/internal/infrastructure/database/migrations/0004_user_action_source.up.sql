@@ -0,0 +1,3 @@
+ALTER TABLE user_actions ADD COLUMN source TEXT NOT NULL DEFAULT 'user-service';
+
+CREATE INDEX idx_user_actions_tenant_user_created_at ON user_actions (tenant_id, user_id, created_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS points_rules;
+DROP TABLE IF EXISTS points_entries;
+DROP TABLE IF EXISTS points_transactions;
@@ -0,0 +1,32 @@
+CREATE TABLE points_transactions (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    kind       TEXT NOT NULL,
+    action_id  UUID,
+    reason     TEXT,
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+-- Одно действие приносит баллы один раз; у ручных корректировок action_id пустой
+CREATE UNIQUE INDEX idx_points_transactions_tenant_action ON points_transactions (tenant_id, action_id);
+
+CREATE TABLE points_entries (
+    id             UUID PRIMARY KEY,
+    tenant_id      UUID NOT NULL REFERENCES tenants (id),
+    transaction_id UUID NOT NULL REFERENCES points_transactions (id),
+    account        TEXT NOT NULL,
+    amount         BIGINT NOT NULL CHECK (amount <> 0),
+    created_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_points_entries_tenant_account ON points_entries (tenant_id, account, created_at);
+CREATE INDEX idx_points_entries_transaction_id ON points_entries (transaction_id);
+
+CREATE TABLE points_rules (
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    action     TEXT NOT NULL,
+    points     BIGINT NOT NULL CHECK (points >= 0),
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (tenant_id, action)
+);
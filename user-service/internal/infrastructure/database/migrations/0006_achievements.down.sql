@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS user_achievements;
+
+DROP INDEX IF EXISTS idx_user_actions_tenant_user_action_category;
+
+ALTER TABLE user_actions DROP COLUMN category;
@@ -0,0 +1,11 @@
+ALTER TABLE user_actions ADD COLUMN category TEXT;
+
+CREATE INDEX idx_user_actions_tenant_user_action_category ON user_actions (tenant_id, user_id, action, category);
+
+CREATE TABLE user_achievements (
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id   UUID NOT NULL,
+    code      TEXT NOT NULL,
+    earned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (tenant_id, user_id, code)
+);
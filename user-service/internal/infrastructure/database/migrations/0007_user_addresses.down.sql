@@ -0,0 +1,8 @@
+ALTER TABLE users ADD COLUMN address TEXT;
+
+UPDATE users u
+SET address = TRIM(CONCAT_WS(', ', NULLIF(a.city, ''), TRIM(CONCAT_WS(' ', a.street, NULLIF(a.house, ''))), NULLIF(a.apartment, '')))
+FROM user_addresses a
+WHERE a.user_id = u.id AND a.is_primary;
+
+DROP TABLE IF EXISTS user_addresses;
@@ -0,0 +1,37 @@
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+CREATE TABLE user_addresses (
+    id            UUID PRIMARY KEY,
+    tenant_id     UUID NOT NULL REFERENCES tenants (id),
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    label         TEXT,
+    street        TEXT NOT NULL,
+    house         TEXT,
+    apartment     TEXT,
+    city          TEXT NOT NULL,
+    postal_code   TEXT,
+    latitude      DOUBLE PRECISION CHECK (latitude BETWEEN -90 AND 90),
+    longitude     DOUBLE PRECISION CHECK (longitude BETWEEN -180 AND 180),
+    geo_precision TEXT,
+    is_primary    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ,
+    updated_at    TIMESTAMPTZ,
+    -- Точка для пространственных запросов (зоны вывоза, ближайшие пункты); приложение пишет только lat/lon
+    location      GEOGRAPHY(Point, 4326) GENERATED ALWAYS AS (
+        CASE WHEN latitude IS NOT NULL AND longitude IS NOT NULL
+            THEN ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography
+        END
+    ) STORED
+);
+
+CREATE INDEX idx_user_addresses_tenant_user ON user_addresses (tenant_id, user_id);
+CREATE UNIQUE INDEX idx_user_addresses_primary ON user_addresses (user_id) WHERE is_primary;
+CREATE INDEX idx_user_addresses_location ON user_addresses USING GIST (location);
+
+-- Прежний адрес одной строкой переносится в улицу основного адреса без геокодирования
+INSERT INTO user_addresses (id, tenant_id, user_id, street, city, is_primary, created_at, updated_at)
+SELECT gen_random_uuid(), tenant_id, id, address, '', TRUE, NOW(), NOW()
+FROM users
+WHERE COALESCE(TRIM(address), '') <> '';
+
+ALTER TABLE users DROP COLUMN address;
@@ -0,0 +1,40 @@
+CREATE TABLE households (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    name       TEXT NOT NULL,
+    -- Общий адрес берётся из адресов владельца
+    address_id UUID REFERENCES user_addresses (id) ON DELETE SET NULL,
+    settings   JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_households_tenant_id ON households (tenant_id);
+
+CREATE TABLE household_members (
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    user_id      UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    role         TEXT NOT NULL CHECK (role IN ('owner', 'member')),
+    joined_at    TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE UNIQUE INDEX idx_household_members_tenant_user ON household_members (tenant_id, user_id);
+CREATE UNIQUE INDEX idx_household_members_owner ON household_members (household_id) WHERE role = 'owner';
+
+CREATE TABLE household_invitations (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    household_id UUID NOT NULL REFERENCES households (id) ON DELETE CASCADE,
+    code         TEXT NOT NULL,
+    email        TEXT,
+    invited_by   UUID NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    accepted_by  UUID,
+    accepted_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_household_invitations_tenant_code ON household_invitations (tenant_id, code);
+CREATE INDEX idx_household_invitations_household_id ON household_invitations (household_id);
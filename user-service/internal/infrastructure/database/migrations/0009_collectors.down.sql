@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS collector_zones;
+DROP TABLE IF EXISTS collector_certifications;
+DROP TABLE IF EXISTS collector_shifts;
+DROP TABLE IF EXISTS collector_profiles;
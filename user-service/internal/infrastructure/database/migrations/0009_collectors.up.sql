@@ -0,0 +1,54 @@
+CREATE TABLE collector_profiles (
+    user_id           UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id         UUID NOT NULL REFERENCES tenants (id),
+    vehicle_type      TEXT,
+    vehicle_plate     TEXT,
+    capacity_kg       INTEGER CHECK (capacity_kg >= 0),
+    volume_m3         DOUBLE PRECISION CHECK (volume_m3 >= 0),
+    availability      TEXT NOT NULL CHECK (availability IN ('available', 'unavailable', 'on_leave')),
+    availability_note TEXT,
+    updated_at        TIMESTAMPTZ
+);
+
+CREATE INDEX idx_collector_profiles_tenant_id ON collector_profiles (tenant_id);
+
+CREATE TABLE collector_shifts (
+    id        UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    user_id   UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    weekday   SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    starts    TEXT NOT NULL,
+    ends      TEXT NOT NULL,
+    CHECK (starts < ends)
+);
+
+CREATE INDEX idx_collector_shifts_tenant_user ON collector_shifts (tenant_id, user_id);
+
+CREATE TABLE collector_certifications (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    hazard     TEXT NOT NULL,
+    number     TEXT NOT NULL,
+    issued_at  TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_collector_certifications_tenant_user ON collector_certifications (tenant_id, user_id);
+
+CREATE TABLE collector_zones (
+    id         UUID PRIMARY KEY,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    boundary   JSONB NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    -- Приложение пишет GeoJSON в boundary, область для пространственных запросов вычисляется из него
+    area       GEOGRAPHY(Polygon, 4326) GENERATED ALWAYS AS (
+        ST_SetSRID(ST_GeomFromGeoJSON(boundary::text), 4326)::geography
+    ) STORED
+);
+
+CREATE INDEX idx_collector_zones_tenant_user ON collector_zones (tenant_id, user_id);
+CREATE INDEX idx_collector_zones_area ON collector_zones USING GIST (area);
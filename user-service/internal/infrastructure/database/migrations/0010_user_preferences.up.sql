@@ -0,0 +1,9 @@
+CREATE TABLE user_preferences (
+    user_id    UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    -- Документ проверяется сервисом; отсутствующая строка означает настройки по умолчанию
+    document   JSONB NOT NULL,
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_preferences_tenant_id ON user_preferences (tenant_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_users_identity_key;
+ALTER TABLE users DROP COLUMN IF EXISTS identity_key;
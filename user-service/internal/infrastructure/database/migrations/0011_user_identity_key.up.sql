@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN identity_key UUID;
+CREATE UNIQUE INDEX idx_users_identity_key ON users (identity_key);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS processed_messages;
+DROP TABLE IF EXISTS outbox_messages;
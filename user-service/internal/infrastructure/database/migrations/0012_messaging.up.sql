@@ -0,0 +1,24 @@
+-- Таблицы модуля messaging: исходящие события и отметки об обработанных входящих
+CREATE TABLE outbox_messages (
+    id           BIGSERIAL PRIMARY KEY,
+    message_id   UUID NOT NULL,
+    topic        TEXT NOT NULL,
+    type         TEXT NOT NULL,
+    key          TEXT,
+    payload      TEXT NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    created_at   TIMESTAMPTZ,
+    published_at TIMESTAMPTZ,
+    attempts     INTEGER NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+CREATE UNIQUE INDEX idx_outbox_messages_message_id ON outbox_messages (message_id);
+CREATE INDEX idx_outbox_messages_published_at ON outbox_messages (published_at);
+
+CREATE TABLE processed_messages (
+    consumer     TEXT NOT NULL,
+    message_id   TEXT NOT NULL,
+    processed_at TIMESTAMPTZ,
+    PRIMARY KEY (consumer, message_id)
+);
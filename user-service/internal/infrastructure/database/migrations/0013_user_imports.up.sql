@@ -0,0 +1,24 @@
+CREATE TABLE user_imports (
+    id             UUID PRIMARY KEY,
+    tenant_id      UUID NOT NULL REFERENCES tenants (id),
+    created_by     TEXT,
+    file_name      TEXT,
+    dry_run        BOOLEAN NOT NULL DEFAULT FALSE,
+    invite         BOOLEAN NOT NULL DEFAULT FALSE,
+    status         TEXT NOT NULL,
+    total_rows     INTEGER NOT NULL DEFAULT 0,
+    processed      INTEGER NOT NULL DEFAULT 0,
+    created        INTEGER NOT NULL DEFAULT 0,
+    updated        INTEGER NOT NULL DEFAULT 0,
+    unchanged      INTEGER NOT NULL DEFAULT 0,
+    failed         INTEGER NOT NULL DEFAULT 0,
+    invited        INTEGER NOT NULL DEFAULT 0,
+    -- Отчёт по строкам, не больше domain.MaxImportErrors записей
+    errors         JSONB,
+    error          TEXT,
+    created_at     TIMESTAMPTZ,
+    started_at     TIMESTAMPTZ,
+    finished_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_user_imports_tenant_created ON user_imports (tenant_id, created_at);
@@ -0,0 +1,26 @@
+CREATE TABLE referral_codes (
+    user_id    UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    tenant_id  UUID NOT NULL REFERENCES tenants (id),
+    code       TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_referral_codes_tenant_code ON referral_codes (tenant_id, code);
+
+-- Приглашения остаются в статистике и после удаления профилей, поэтому без внешних ключей на users
+CREATE TABLE referrals (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants (id),
+    referrer_id  UUID NOT NULL,
+    referred_id  UUID NOT NULL,
+    code         TEXT NOT NULL,
+    device_hash  TEXT,
+    district     TEXT,
+    status       TEXT NOT NULL CHECK (status IN ('rewarded', 'blocked')),
+    block_reason TEXT,
+    created_at   TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_referrals_tenant_referred ON referrals (tenant_id, referred_id);
+CREATE INDEX idx_referrals_tenant_referrer ON referrals (tenant_id, referrer_id);
+CREATE INDEX idx_referrals_tenant_device ON referrals (tenant_id, device_hash);
@@ -0,0 +1,2 @@
+-- Идентификатор текущей картинки профиля; сами миниатюры лежат в хранилище файлов
+ALTER TABLE users ADD COLUMN avatar_id UUID;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_imports_unfinished;
+ALTER TABLE user_imports DROP COLUMN IF EXISTS heartbeat_at;
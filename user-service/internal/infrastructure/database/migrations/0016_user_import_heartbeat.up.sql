@@ -0,0 +1,4 @@
+-- Время последнего сохранения прогресса: по нему находят загрузки, брошенные упавшим экземпляром
+ALTER TABLE user_imports ADD COLUMN heartbeat_at TIMESTAMPTZ;
+
+CREATE INDEX idx_user_imports_unfinished ON user_imports (heartbeat_at) WHERE status IN ('pending', 'running');
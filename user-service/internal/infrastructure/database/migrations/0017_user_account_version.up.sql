@@ -0,0 +1,2 @@
+-- Версия учётной записи из последнего применённого события: опоздавшие события с меньшей версией отбрасываются
+ALTER TABLE users ADD COLUMN account_version BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+-- Время приглашения жителя из импорта; пока оно задано, identity_key зарезервирован за будущей учётной записью
+ALTER TABLE users ADD COLUMN invited_at TIMESTAMPTZ;
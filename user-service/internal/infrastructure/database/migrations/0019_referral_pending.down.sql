@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_referrals_tenant_referrer_resolved;
+ALTER TABLE referrals DROP COLUMN IF EXISTS resolved_at;
+
+-- Ожидающие приглашения прежняя схема не различает: они считаются засчитанными без награды
+UPDATE referrals SET status = 'blocked', block_reason = 'pending' WHERE status = 'pending';
+ALTER TABLE referrals DROP CONSTRAINT referrals_status_check;
+ALTER TABLE referrals ADD CONSTRAINT referrals_status_check CHECK (status IN ('rewarded', 'blocked'));
@@ -0,0 +1,9 @@
+-- Приглашение записывается при регистрации и ждёт активности жителя, награда решается позже
+ALTER TABLE referrals DROP CONSTRAINT referrals_status_check;
+ALTER TABLE referrals ADD CONSTRAINT referrals_status_check CHECK (status IN ('pending', 'rewarded', 'blocked'));
+
+-- Месячный лимит считается по времени решения о награде
+ALTER TABLE referrals ADD COLUMN resolved_at TIMESTAMPTZ;
+UPDATE referrals SET resolved_at = created_at;
+
+CREATE INDEX idx_referrals_tenant_referrer_resolved ON referrals (tenant_id, referrer_id, resolved_at);
@@ -0,0 +1,2 @@
+-- Старые отметки об обработке удаляются по расписанию, выборке по processed_at нужен индекс
+CREATE INDEX idx_processed_messages_processed_at ON processed_messages (processed_at);
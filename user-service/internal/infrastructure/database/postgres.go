@@ -1,34 +1,36 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-
-	"user-service/internal/domain"
 )
 
 func NewPostgresDatabase() (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		"user_service_db",
-		os.Getenv("DB_PORT"),
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := OpenPostgres()
 	if err != nil {
 		return nil, err
 	}
 
-	// Auto migrate model models
-	err = db.AutoMigrate(&domain.User{}, &domain.UserAction{})
-	if err != nil {
+	// Сервис не стартует на базе, к которой не применены все миграции
+	if err = NewMigrator(db).RequireLatest(context.Background()); err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
+
+func OpenPostgres() (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		"user_service_db",
+		os.Getenv("DB_PORT"),
+	)
+
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
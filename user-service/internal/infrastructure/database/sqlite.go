@@ -0,0 +1,47 @@
+package database
+
+import (
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"messaging/dedup"
+	"messaging/outbox"
+	"user-service/internal/domain"
+)
+
+// NewSQLiteDatabase нужен для тестов и локальной разработки без PostgreSQL.
+// SQL-миграции написаны под PostgreSQL, поэтому схема создаётся через AutoMigrate.
+func NewSQLiteDatabase(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.AutoMigrate(
+		&domain.Tenant{}, &domain.User{}, &domain.UserAction{},
+		&domain.PointsTransaction{}, &domain.PointsEntry{}, &domain.EarningRule{},
+		&domain.UserAchievement{}, &domain.Address{},
+		&domain.Household{}, &domain.HouseholdMembership{}, &domain.HouseholdInvitation{},
+		&domain.CollectorProfile{}, &domain.CollectorShift{}, &domain.CollectorCertification{}, &domain.ServiceZone{},
+		&domain.UserPreferences{}, &domain.ImportJob{},
+		&domain.ReferralCode{}, &domain.Referral{},
+		&outbox.Record{}, &dedup.Processed{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// ID и дата только для новой записи: иначе FirstOrCreate ищет по ним и не находит тенант в уже созданной базе
+	var tenant domain.Tenant
+	err = db.Where(domain.Tenant{Slug: domain.DefaultTenantSlug}).
+		Attrs(domain.Tenant{ID: uuid.New(), Name: "Default", CreatedAt: time.Now()}).
+		FirstOrCreate(&tenant).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,123 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"messaging"
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// Сколько действий может ждать медленного подписчика; переполнение отключает его
+const feedBuffer = 64
+
+type feedKey struct {
+	tenantID uuid.UUID
+	userID   uuid.UUID
+}
+
+// ActionFeed раздаёт новые действия подписчикам ленты пользователя в этом экземпляре.
+// Действия приходят из брокера через Handle, поэтому подписчик видит действия,
+// записанные любым экземпляром сервиса.
+type ActionFeed struct {
+	mu          sync.Mutex
+	subscribers map[feedKey]map[chan domain.UserAction]struct{}
+}
+
+func NewActionFeed() *ActionFeed {
+	return &ActionFeed{subscribers: map[feedKey]map[chan domain.UserAction]struct{}{}}
+}
+
+// Subscribe возвращает канал новых действий пользователя и функцию отписки.
+// Канал закрывается, если подписчик не успевает читать: клиенту нужно переподключиться
+// и дочитать пропущенное из истории.
+func (f *ActionFeed) Subscribe(tenantID, userID uuid.UUID) (<-chan domain.UserAction, func()) {
+	key := feedKey{tenantID: tenantID, userID: userID}
+	ch := make(chan domain.UserAction, feedBuffer)
+
+	f.mu.Lock()
+	if f.subscribers[key] == nil {
+		f.subscribers[key] = map[chan domain.UserAction]struct{}{}
+	}
+	f.subscribers[key][ch] = struct{}{}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.remove(key, ch)
+	}
+}
+
+// Handle — обработчик топика user-actions для messaging.Listener
+func (f *ActionFeed) Handle(ctx context.Context, message messaging.Message) error {
+	return ActionHandler(f)(ctx, message)
+}
+
+// ActionRecorded раздаёт действие напрямую, когда брокера нет и экземпляр один
+func (f *ActionFeed) ActionRecorded(_ context.Context, action domain.UserAction) {
+	f.broadcast(action)
+}
+
+func (f *ActionFeed) broadcast(action domain.UserAction) {
+	key := feedKey{tenantID: action.TenantID, userID: action.UserID}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers[key] {
+		select {
+		case ch <- action:
+		default:
+			f.remove(key, ch)
+		}
+	}
+}
+
+// remove вызывается под f.mu; повторный вызов для уже удалённого канала ничего не делает
+func (f *ActionFeed) remove(key feedKey, ch chan domain.UserAction) {
+	if _, ok := f.subscribers[key][ch]; !ok {
+		return
+	}
+	delete(f.subscribers[key], ch)
+	if len(f.subscribers[key]) == 0 {
+		delete(f.subscribers, key)
+	}
+	close(ch)
+}
+
+// ActionHandler передаёт наблюдателю действия из топика user-actions, записанные любым экземпляром сервиса
+func ActionHandler(observer domain.ActionObserver) messaging.Handler {
+	return func(ctx context.Context, message messaging.Message) error {
+		var action domain.UserAction
+		if err := json.Unmarshal(message.Payload, &action); err != nil {
+			log.Printf("Skipping malformed action message %s: %v", message.ID, err)
+			return nil
+		}
+		observer.ActionRecorded(ctx, action)
+		return nil
+	}
+}
+
+// ActionPublisher — наблюдатель ActionService, который публикует каждое действие в топик user-actions
+func ActionPublisher(publisher messaging.Publisher) domain.ActionObserver {
+	return actionPublisher{publisher: publisher}
+}
+
+type actionPublisher struct {
+	publisher messaging.Publisher
+}
+
+func (p actionPublisher) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	message, err := messaging.NewMessage(domain.TopicActions, domain.EventActionRecorded, action.UserID.String(), action)
+	if err == nil {
+		err = p.publisher.Publish(ctx, message)
+	}
+	if err != nil {
+		log.Printf("Failed to publish action %s to the feed (request %s): %v", action.ID, requestctx.RequestID(ctx), err)
+	}
+}
@@ -0,0 +1,32 @@
+// Package events связывает брокер сообщений с сервисами: разбирает входящие сообщения
+// и передаёт их в usecase
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"messaging"
+	"user-service/internal/domain"
+)
+
+// AccountHandler применяет события учётных записей из auth-service к профилям.
+// Битые и неизвестные события пропускаются: повторная доставка их не исправит.
+func AccountHandler(accounts domain.AccountService) messaging.Handler {
+	return func(ctx context.Context, message messaging.Message) error {
+		var event domain.AccountEvent
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			log.Printf("Skipping malformed account event %s: %v", message.ID, err)
+			return nil
+		}
+
+		_, err := accounts.ApplyAccountEvent(ctx, event)
+		if errors.Is(err, domain.ErrInvalidInput) || errors.Is(err, domain.ErrTenantNotFound) || errors.Is(err, domain.ErrProfileEmailTaken) {
+			log.Printf("Skipping account event %s %s: %v", event.Type, event.ID, err)
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,187 @@
+package geocoding
+
+import (
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"user-service/internal/domain"
+)
+
+//go:embed gazetteer.csv
+var defaultGazetteer string
+
+// Служебные слова, которые жители пишут по-разному и которые не влияют на поиск
+var addressNoise = map[string]bool{
+	"ул": true, "улица": true, "пр": true, "проспект": true, "мкр": true, "микрорайон": true,
+	"д": true, "дом": true, "г": true, "город": true, "street": true, "st": true, "avenue": true,
+	"ave": true, "av": true, "city": true, "str": true,
+}
+
+type place struct {
+	point      domain.GeoPoint
+	postalCode string
+}
+
+type street struct {
+	place
+	hasCentroid bool
+	houses      map[string]place
+}
+
+type city struct {
+	place
+	streets map[string]*street
+}
+
+// Gazetteer — офлайн-геокодер по справочнику адресов. Если дом не найден, возвращается середина
+// улицы, если не найдена улица — центр города.
+type Gazetteer struct {
+	cities map[string]*city
+}
+
+func DefaultGazetteer() *Gazetteer {
+	gazetteer, err := NewGazetteer(strings.NewReader(defaultGazetteer))
+	if err != nil {
+		// Справочник встроен в бинарник, ошибка здесь — ошибка сборки
+		panic(err)
+	}
+	return gazetteer
+}
+
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return NewGazetteer(file)
+}
+
+// NewGazetteer читает CSV со столбцами city,street,house,postal_code,lat,lon;
+// альтернативные названия города и улицы перечисляются через "|".
+func NewGazetteer(r io.Reader) (*Gazetteer, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 6
+
+	g := &Gazetteer{cities: map[string]*city{}}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gazetteer: %w", err)
+		}
+
+		lat, latErr := strconv.ParseFloat(record[4], 64)
+		lon, lonErr := strconv.ParseFloat(record[5], 64)
+		if latErr != nil || lonErr != nil || lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+			return nil, fmt.Errorf("gazetteer: record %d: invalid coordinates", line)
+		}
+		entry := place{point: domain.GeoPoint{Latitude: lat, Longitude: lon}, postalCode: record[3]}
+
+		c := g.city(record[0])
+		if c == nil {
+			return nil, fmt.Errorf("gazetteer: record %d: city is required", line)
+		}
+		switch {
+		case record[1] == "":
+			c.place = entry
+		case record[2] == "":
+			s := c.street(record[1])
+			s.place, s.hasCentroid = entry, true
+		default:
+			c.street(record[1]).houses[normalizeHouse(record[2])] = entry
+		}
+	}
+	return g, nil
+}
+
+func (g *Gazetteer) Geocode(_ context.Context, address domain.Address) (*domain.GeocodeResult, error) {
+	c := g.cities[normalize(address.City)]
+	if c == nil {
+		return nil, domain.ErrLocationNotFound
+	}
+
+	s := c.streets[normalize(address.Street)]
+	if s == nil {
+		return &domain.GeocodeResult{Point: c.point, Precision: domain.GeoPrecisionCity, PostalCode: c.postalCode}, nil
+	}
+	if house, ok := s.houses[normalizeHouse(address.House)]; ok {
+		return &domain.GeocodeResult{Point: house.point, Precision: domain.GeoPrecisionHouse, PostalCode: house.postalCode}, nil
+	}
+	if s.hasCentroid {
+		return &domain.GeocodeResult{Point: s.point, Precision: domain.GeoPrecisionStreet, PostalCode: s.postalCode}, nil
+	}
+	return &domain.GeocodeResult{Point: c.point, Precision: domain.GeoPrecisionCity, PostalCode: c.postalCode}, nil
+}
+
+// city находит или создаёт город, регистрируя все его названия
+func (g *Gazetteer) city(names string) *city {
+	keys := aliases(names)
+	if len(keys) == 0 {
+		return nil
+	}
+	for _, key := range keys {
+		if existing, ok := g.cities[key]; ok {
+			return existing
+		}
+	}
+	c := &city{streets: map[string]*street{}}
+	for _, key := range keys {
+		g.cities[key] = c
+	}
+	return c
+}
+
+func (c *city) street(names string) *street {
+	keys := aliases(names)
+	for _, key := range keys {
+		if existing, ok := c.streets[key]; ok {
+			return existing
+		}
+	}
+	s := &street{houses: map[string]place{}}
+	for _, key := range keys {
+		c.streets[key] = s
+	}
+	return s
+}
+
+func aliases(names string) []string {
+	var keys []string
+	for _, name := range strings.Split(names, "|") {
+		if key := normalize(name); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// normalize приводит название к виду "abay", "tole bi": нижний регистр, без пунктуации и служебных слов
+func normalize(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	kept := words[:0]
+	for _, word := range words {
+		if !addressNoise[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// normalizeHouse оставляет номер с литерой: "д. 10А" -> "10а"
+func normalizeHouse(house string) string {
+	return strings.ReplaceAll(normalize(house), " ", "")
+}
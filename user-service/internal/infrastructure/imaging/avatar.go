@@ -0,0 +1,131 @@
+// Package imaging готовит загруженные картинки профиля: проверяет формат по содержимому,
+// режет квадратные миниатюры и перекодирует их в JPEG, так что EXIF и прочие метаданные
+// исходника в миниатюры не попадают.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net/http"
+
+	// Декодеры регистрируются в image.Decode
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"user-service/internal/domain"
+)
+
+const jpegQuality = 85
+
+// Форматы, которые принимаются по результату http.DetectContentType
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Avatar проверяет картинку и возвращает миниатюры всех размеров sizes. Из центра
+// вырезается квадрат, который сначала уменьшается до наибольшего размера, а остальные
+// размеры получаются из него. Поворот из EXIF применяется к готовым миниатюрам.
+func Avatar(data []byte, sizes []int) (domain.AvatarImages, error) {
+	contentType := http.DetectContentType(data)
+	if !supportedTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s, expected JPEG, PNG, GIF or WebP", domain.ErrUnsupportedImage, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+	switch {
+	case config.Width > domain.MaxAvatarSide || config.Height > domain.MaxAvatarSide:
+		return nil, fmt.Errorf("%w: image is %dx%d, sides must be at most %d pixels",
+			domain.ErrUnsupportedImage, config.Width, config.Height, domain.MaxAvatarSide)
+	case config.Width < domain.MinAvatarSide || config.Height < domain.MinAvatarSide:
+		return nil, fmt.Errorf("%w: image is %dx%d, sides must be at least %d pixels",
+			domain.ErrUnsupportedImage, config.Width, config.Height, domain.MinAvatarSide)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnsupportedImage, err)
+	}
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	largest := 0
+	for _, size := range sizes {
+		largest = max(largest, size)
+	}
+	base := scale(src, centerSquare(src.Bounds()), largest)
+
+	images := domain.AvatarImages{}
+	for _, size := range sizes {
+		thumbnail := base
+		if size != largest {
+			thumbnail = scale(base, base.Bounds(), size)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(thumbnail, orientation), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		images[size] = buf.Bytes()
+	}
+	return images, nil
+}
+
+func centerSquare(bounds image.Rectangle) image.Rectangle {
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// scale рисует часть src на белом фоне: у JPEG нет прозрачности
+func scale(src image.Image, part image.Rectangle, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, part, draw.Over, nil)
+	return dst
+}
+
+// orient поворачивает и отражает квадратную картинку по значению тега EXIF Orientation (1–8)
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	n := src.Bounds().Dx()
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = n-1-x, y
+			case 3:
+				dx, dy = n-1-x, n-1-y
+			case 4:
+				dx, dy = x, n-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = n-1-y, x
+			case 7:
+				dx, dy = n-1-y, n-1-x
+			case 8:
+				dx, dy = y, n-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
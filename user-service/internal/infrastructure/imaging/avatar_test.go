@@ -0,0 +1,114 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"user-service/internal/domain"
+)
+
+func TestAvatar(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		wantErr bool
+	}{
+		{name: "wide PNG", data: func(t *testing.T) []byte { return encodePNG(t, halves(300, 200)) }},
+		{name: "tall JPEG", data: func(t *testing.T) []byte { return encodeJPEG(t, halves(120, 400)) }},
+		{name: "GIF", data: func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			if err := gif.Encode(&buf, halves(64, 64), nil); err != nil {
+				t.Fatalf("gif.Encode: %v", err)
+			}
+			return buf.Bytes()
+		}},
+		{name: "text", data: func(*testing.T) []byte { return []byte("definitely not an image") }, wantErr: true},
+		{name: "SVG", data: func(*testing.T) []byte { return []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`) }, wantErr: true},
+		{name: "truncated PNG", data: func(t *testing.T) []byte { return encodePNG(t, halves(64, 64))[:40] }, wantErr: true},
+		{name: "too small", data: func(t *testing.T) []byte { return encodePNG(t, halves(domain.MinAvatarSide-1, 64)) }, wantErr: true},
+		{name: "too large", data: func(t *testing.T) []byte {
+			return encodePNG(t, image.NewGray(image.Rect(0, 0, domain.MaxAvatarSide+1, 16)))
+		}, wantErr: true},
+	}
+
+	sizes := []int{32, 128, 64}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images, err := Avatar(tt.data(t), sizes)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrUnsupportedImage) {
+					t.Fatalf("Avatar error = %v, want ErrUnsupportedImage", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Avatar: %v", err)
+			}
+			if len(images) != len(sizes) {
+				t.Fatalf("Avatar returned %d sizes, want %d", len(images), len(sizes))
+			}
+			for _, size := range sizes {
+				config, format, err := image.DecodeConfig(bytes.NewReader(images[size]))
+				if err != nil {
+					t.Fatalf("size %d: %v", size, err)
+				}
+				if format != "jpeg" || config.Width != size || config.Height != size {
+					t.Errorf("size %d: got %s %dx%d, want a square JPEG", size, format, config.Width, config.Height)
+				}
+			}
+		})
+	}
+}
+
+// Поворот из EXIF применяется к миниатюре, а сами метаданные в неё не попадают
+func TestAvatarAppliesOrientation(t *testing.T) {
+	// Верх красный, низ синий; после поворота на 90° по часовой красный оказывается справа
+	data := withSegments(encodeJPEG(t, halves(64, 64)), exifSegment(binary.BigEndian, 6))
+
+	images, err := Avatar(data, []int{64})
+	if err != nil {
+		t.Fatalf("Avatar: %v", err)
+	}
+	if bytes.Contains(images[64], []byte("Exif")) {
+		t.Error("thumbnail keeps the EXIF block")
+	}
+	img, err := jpeg.Decode(bytes.NewReader(images[64]))
+	if err != nil {
+		t.Fatalf("jpeg.Decode: %v", err)
+	}
+	if left, right := redness(img.At(8, 32)), redness(img.At(56, 32)); right <= left {
+		t.Fatalf("left/right redness = %d/%d, want the red half on the right", left, right)
+	}
+}
+
+// halves — картинка с красной верхней и синей нижней половиной
+func halves(width, height int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}})
+	for y := height / 2; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetColorIndex(x, y, 1)
+		}
+	}
+	return img
+}
+
+func redness(c color.Color) int {
+	r, _, b, _ := c.RGBA()
+	return int(r>>8) - int(b>>8)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,112 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := encodeJPEG(t, image.NewGray(image.Rect(0, 0, 16, 16)))
+	app0 := []byte{0xFF, 0xE0, 0x00, 0x07, 'J', 'F', 'I', 'F', 0x00}
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no EXIF", data: plain, want: 1},
+		{name: "little endian", data: withSegments(plain, exifSegment(binary.LittleEndian, 6)), want: 6},
+		{name: "big endian", data: withSegments(plain, exifSegment(binary.BigEndian, 8)), want: 8},
+		{name: "after another segment", data: withSegments(plain, app0, exifSegment(binary.LittleEndian, 3)), want: 3},
+		{name: "value out of range", data: withSegments(plain, exifSegment(binary.LittleEndian, 9)), want: 1},
+		{name: "segment longer than file", data: withSegments(plain, exifSegment(binary.LittleEndian, 6))[:20], want: 1},
+		{name: "not a JPEG", data: []byte("GIF89a"), want: 1},
+		{name: "empty", want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Fatalf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	a := [4]uint8{255, 0, 0, 255}
+	b := [4]uint8{0, 0, 255, 255}
+	// Куда попадают левый верхний (a) и правый верхний (b) пиксели квадрата 2×2
+	tests := []struct {
+		orientation int
+		wantA       image.Point
+		wantB       image.Point
+	}{
+		{orientation: 1, wantA: image.Pt(0, 0), wantB: image.Pt(1, 0)},
+		{orientation: 2, wantA: image.Pt(1, 0), wantB: image.Pt(0, 0)},
+		{orientation: 3, wantA: image.Pt(1, 1), wantB: image.Pt(0, 1)},
+		{orientation: 4, wantA: image.Pt(0, 1), wantB: image.Pt(1, 1)},
+		{orientation: 5, wantA: image.Pt(0, 0), wantB: image.Pt(0, 1)},
+		{orientation: 6, wantA: image.Pt(1, 0), wantB: image.Pt(1, 1)},
+		{orientation: 7, wantA: image.Pt(1, 1), wantB: image.Pt(1, 0)},
+		{orientation: 8, wantA: image.Pt(0, 1), wantB: image.Pt(0, 0)},
+		{orientation: 0, wantA: image.Pt(0, 0), wantB: image.Pt(1, 0)},
+	}
+
+	for _, tt := range tests {
+		src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		copy(src.Pix[src.PixOffset(0, 0):], a[:])
+		copy(src.Pix[src.PixOffset(1, 0):], b[:])
+
+		dst := orient(src, tt.orientation)
+		if got := dst.RGBAAt(tt.wantA.X, tt.wantA.Y); got.R != 255 {
+			t.Errorf("orientation %d: pixel at %v = %v, want the top-left pixel", tt.orientation, tt.wantA, got)
+		}
+		if got := dst.RGBAAt(tt.wantB.X, tt.wantB.Y); got.B != 255 {
+			t.Errorf("orientation %d: pixel at %v = %v, want the top-right pixel", tt.orientation, tt.wantB, got)
+		}
+	}
+}
+
+// exifSegment — блок APP1 с одним тегом Orientation в IFD0
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withSegments вставляет блоки сразу после маркера начала JPEG
+func withSegments(jpegData []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, jpegData[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, jpegData[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
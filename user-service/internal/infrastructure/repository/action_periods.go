@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+// countByPeriod группирует действия так же, как date_trunc в PostgresUserRepository.CountUserActions
+func countByPeriod(actions []domain.UserAction, period domain.AggregationPeriod, loc *time.Location) []domain.ActionCount {
+	type key struct {
+		start  int64
+		action domain.ActionType
+	}
+
+	counts := map[key]*domain.ActionCount{}
+	for _, action := range actions {
+		start := period.Start(action.CreatedAt, loc)
+		k := key{start: start.Unix(), action: action.Action}
+		if counts[k] == nil {
+			counts[k] = &domain.ActionCount{PeriodStart: start, Action: action.Action}
+		}
+		counts[k].Count++
+	}
+
+	result := make([]domain.ActionCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, *count)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].PeriodStart.Equal(result[j].PeriodStart) {
+			return result[i].PeriodStart.Before(result[j].PeriodStart)
+		}
+		return result[i].Action < result[j].Action
+	})
+	return result
+}
+
+// countUsersByPeriod — то же, что countByPeriod, но отдельно для каждого пользователя
+func countUsersByPeriod(actions []domain.UserAction, period domain.AggregationPeriod, loc *time.Location) []domain.UserActionCount {
+	byUser := map[uuid.UUID][]domain.UserAction{}
+	for _, action := range actions {
+		byUser[action.UserID] = append(byUser[action.UserID], action)
+	}
+
+	var result []domain.UserActionCount
+	for userID, userActions := range byUser {
+		for _, count := range countByPeriod(userActions, period, loc) {
+			result = append(result, domain.UserActionCount{UserID: userID, ActionCount: count})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].UserID.String() < result[j].UserID.String()
+	})
+	return result
+}
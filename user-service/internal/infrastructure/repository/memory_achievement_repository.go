@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryAchievementRepository struct {
+	mu           sync.RWMutex
+	achievements []domain.UserAchievement
+}
+
+func NewMemoryAchievementRepository() domain.AchievementRepository {
+	return &MemoryAchievementRepository{}
+}
+
+func (r *MemoryAchievementRepository) AwardAchievement(_ context.Context, achievement *domain.UserAchievement) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.achievements {
+		if existing.TenantID == achievement.TenantID && existing.UserID == achievement.UserID && existing.Code == achievement.Code {
+			return domain.ErrAchievementAwarded
+		}
+	}
+	r.achievements = append(r.achievements, *achievement)
+	return nil
+}
+
+func (r *MemoryAchievementRepository) ListUserAchievements(_ context.Context, tenantID, userID uuid.UUID) ([]domain.UserAchievement, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var achievements []domain.UserAchievement
+	for _, achievement := range r.achievements {
+		if achievement.TenantID == tenantID && achievement.UserID == userID {
+			achievements = append(achievements, achievement)
+		}
+	}
+	sort.Slice(achievements, func(i, j int) bool {
+		if !achievements[i].EarnedAt.Equal(achievements[j].EarnedAt) {
+			return achievements[i].EarnedAt.Before(achievements[j].EarnedAt)
+		}
+		return achievements[i].Code < achievements[j].Code
+	})
+	return achievements, nil
+}
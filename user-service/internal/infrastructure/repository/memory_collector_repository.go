@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryCollectorRepository struct {
+	mu             sync.RWMutex
+	profiles       map[uuid.UUID]domain.CollectorProfile
+	shifts         []domain.CollectorShift
+	certifications map[uuid.UUID]domain.CollectorCertification
+	zones          map[uuid.UUID]domain.ServiceZone
+}
+
+func NewMemoryCollectorRepository() domain.CollectorRepository {
+	return &MemoryCollectorRepository{
+		profiles:       map[uuid.UUID]domain.CollectorProfile{},
+		certifications: map[uuid.UUID]domain.CollectorCertification{},
+		zones:          map[uuid.UUID]domain.ServiceZone{},
+	}
+}
+
+func (r *MemoryCollectorRepository) FindProfile(_ context.Context, tenantID, userID uuid.UUID) (*domain.CollectorProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[userID]
+	if !ok || profile.TenantID != tenantID {
+		return nil, domain.ErrCollectorProfileNotFound
+	}
+	return &profile, nil
+}
+
+func (r *MemoryCollectorRepository) SaveProfile(_ context.Context, profile *domain.CollectorProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.profiles[profile.UserID] = *profile
+	return nil
+}
+
+func (r *MemoryCollectorRepository) ListShifts(_ context.Context, tenantID, userID uuid.UUID) ([]domain.CollectorShift, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var shifts []domain.CollectorShift
+	for _, shift := range r.shifts {
+		if shift.TenantID == tenantID && shift.UserID == userID {
+			shifts = append(shifts, shift)
+		}
+	}
+	sort.Slice(shifts, func(i, j int) bool {
+		if shifts[i].Weekday != shifts[j].Weekday {
+			return shifts[i].Weekday < shifts[j].Weekday
+		}
+		return shifts[i].Starts < shifts[j].Starts
+	})
+	return shifts, nil
+}
+
+func (r *MemoryCollectorRepository) ReplaceShifts(_ context.Context, tenantID, userID uuid.UUID, shifts []domain.CollectorShift) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.shifts[:0]
+	for _, shift := range r.shifts {
+		if shift.TenantID != tenantID || shift.UserID != userID {
+			kept = append(kept, shift)
+		}
+	}
+	r.shifts = append(kept, shifts...)
+	return nil
+}
+
+func (r *MemoryCollectorRepository) ListCertifications(_ context.Context, tenantID, userID uuid.UUID) ([]domain.CollectorCertification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var certifications []domain.CollectorCertification
+	for _, certification := range r.certifications {
+		if certification.TenantID == tenantID && certification.UserID == userID {
+			certifications = append(certifications, certification)
+		}
+	}
+	sort.Slice(certifications, func(i, j int) bool {
+		a, b := certifications[i], certifications[j]
+		if a.Hazard != b.Hazard {
+			return a.Hazard < b.Hazard
+		}
+		if !a.IssuedAt.Equal(b.IssuedAt) {
+			return a.IssuedAt.Before(b.IssuedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return certifications, nil
+}
+
+func (r *MemoryCollectorRepository) SaveCertification(_ context.Context, certification *domain.CollectorCertification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.certifications[certification.ID] = *certification
+	return nil
+}
+
+func (r *MemoryCollectorRepository) DeleteCertification(_ context.Context, tenantID, userID, certificationID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certification, ok := r.certifications[certificationID]
+	if !ok || certification.TenantID != tenantID || certification.UserID != userID {
+		return domain.ErrCertificationNotFound
+	}
+	delete(r.certifications, certificationID)
+	return nil
+}
+
+func (r *MemoryCollectorRepository) ListZones(_ context.Context, tenantID, userID uuid.UUID) ([]domain.ServiceZone, error) {
+	return r.matchZones(func(zone domain.ServiceZone) bool {
+		return zone.TenantID == tenantID && zone.UserID == userID
+	}), nil
+}
+
+func (r *MemoryCollectorRepository) SaveZone(_ context.Context, zone *domain.ServiceZone) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.zones[zone.ID]; ok {
+		if existing.TenantID != zone.TenantID || existing.UserID != zone.UserID {
+			return domain.ErrZoneNotFound
+		}
+		zone.CreatedAt = existing.CreatedAt
+	}
+	r.zones[zone.ID] = *zone
+	return nil
+}
+
+func (r *MemoryCollectorRepository) DeleteZone(_ context.Context, tenantID, userID, zoneID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	zone, ok := r.zones[zoneID]
+	if !ok || zone.TenantID != tenantID || zone.UserID != userID {
+		return domain.ErrZoneNotFound
+	}
+	delete(r.zones, zoneID)
+	return nil
+}
+
+func (r *MemoryCollectorRepository) ZonesCovering(_ context.Context, tenantID uuid.UUID, point domain.GeoPoint) ([]domain.ServiceZone, error) {
+	zones := r.matchZones(func(zone domain.ServiceZone) bool { return zone.TenantID == tenantID })
+	return zonesContaining(zones, point), nil
+}
+
+func (r *MemoryCollectorRepository) ListTenantZones(_ context.Context, tenantID uuid.UUID) ([]domain.ServiceZone, error) {
+	return r.matchZones(func(zone domain.ServiceZone) bool { return zone.TenantID == tenantID }), nil
+}
+
+func (r *MemoryCollectorRepository) matchZones(match func(domain.ServiceZone) bool) []domain.ServiceZone {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var zones []domain.ServiceZone
+	for _, zone := range r.zones {
+		if match(zone) {
+			zones = append(zones, zone)
+		}
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if !zones[i].CreatedAt.Equal(zones[j].CreatedAt) {
+			return zones[i].CreatedAt.Before(zones[j].CreatedAt)
+		}
+		return zones[i].ID.String() < zones[j].ID.String()
+	})
+	return zones
+}
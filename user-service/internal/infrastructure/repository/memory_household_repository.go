@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryHouseholdRepository struct {
+	mu          sync.RWMutex
+	households  map[uuid.UUID]domain.Household
+	members     map[uuid.UUID]domain.HouseholdMembership
+	invitations map[uuid.UUID]domain.HouseholdInvitation
+}
+
+func NewMemoryHouseholdRepository() domain.HouseholdRepository {
+	return &MemoryHouseholdRepository{
+		households:  map[uuid.UUID]domain.Household{},
+		members:     map[uuid.UUID]domain.HouseholdMembership{},
+		invitations: map[uuid.UUID]domain.HouseholdInvitation{},
+	}
+}
+
+func (r *MemoryHouseholdRepository) CreateHousehold(_ context.Context, household *domain.Household, owner *domain.HouseholdMembership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.members[owner.UserID]; ok {
+		return domain.ErrAlreadyInHousehold
+	}
+	r.households[household.ID] = *household
+	r.members[owner.UserID] = *owner
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) FindHousehold(_ context.Context, tenantID, householdID uuid.UUID) (*domain.Household, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	household, ok := r.households[householdID]
+	if !ok || household.TenantID != tenantID {
+		return nil, domain.ErrHouseholdNotFound
+	}
+	return &household, nil
+}
+
+func (r *MemoryHouseholdRepository) UpdateHousehold(_ context.Context, household *domain.Household) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.households[household.ID]
+	if !ok || existing.TenantID != household.TenantID {
+		return domain.ErrHouseholdNotFound
+	}
+	household.CreatedAt = existing.CreatedAt
+	r.households[household.ID] = *household
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) DeleteHousehold(_ context.Context, tenantID, householdID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	household, ok := r.households[householdID]
+	if !ok || household.TenantID != tenantID {
+		return domain.ErrHouseholdNotFound
+	}
+	for userID, member := range r.members {
+		if member.HouseholdID == householdID {
+			delete(r.members, userID)
+		}
+	}
+	for id, invitation := range r.invitations {
+		if invitation.HouseholdID == householdID {
+			delete(r.invitations, id)
+		}
+	}
+	delete(r.households, householdID)
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) FindMembership(_ context.Context, tenantID, userID uuid.UUID) (*domain.HouseholdMembership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[userID]
+	if !ok || member.TenantID != tenantID {
+		return nil, domain.ErrNotHouseholdMember
+	}
+	return &member, nil
+}
+
+func (r *MemoryHouseholdRepository) ListMembers(_ context.Context, tenantID, householdID uuid.UUID) ([]domain.HouseholdMembership, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var members []domain.HouseholdMembership
+	for _, member := range r.members {
+		if member.TenantID == tenantID && member.HouseholdID == householdID {
+			members = append(members, member)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].JoinedAt.Equal(members[j].JoinedAt) {
+			return members[i].JoinedAt.Before(members[j].JoinedAt)
+		}
+		return members[i].UserID.String() < members[j].UserID.String()
+	})
+	return members, nil
+}
+
+func (r *MemoryHouseholdRepository) TransferOwnership(_ context.Context, tenantID, householdID, from, to uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	owner, ok := r.members[from]
+	if !ok || owner.TenantID != tenantID || owner.HouseholdID != householdID {
+		return domain.ErrNotHouseholdMember
+	}
+	member, ok := r.members[to]
+	if !ok || member.TenantID != tenantID || member.HouseholdID != householdID {
+		return domain.ErrNotHouseholdMember
+	}
+	owner.Role, member.Role = domain.HouseholdMember, domain.HouseholdOwner
+	r.members[from], r.members[to] = owner, member
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) RemoveMember(_ context.Context, tenantID, householdID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	member, ok := r.members[userID]
+	if !ok || member.TenantID != tenantID || member.HouseholdID != householdID {
+		return domain.ErrNotHouseholdMember
+	}
+	delete(r.members, userID)
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) CreateInvitation(_ context.Context, invitation *domain.HouseholdInvitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+func (r *MemoryHouseholdRepository) FindInvitationByCode(_ context.Context, tenantID uuid.UUID, code string) (*domain.HouseholdInvitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, invitation := range r.invitations {
+		if invitation.TenantID == tenantID && invitation.Code == code {
+			return &invitation, nil
+		}
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+func (r *MemoryHouseholdRepository) AcceptInvitation(_ context.Context, invitation *domain.HouseholdInvitation, member *domain.HouseholdMembership) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.invitations[invitation.ID]
+	if !ok || existing.TenantID != invitation.TenantID || existing.AcceptedAt != nil {
+		return domain.ErrInvitationInvalid
+	}
+	if _, ok := r.members[member.UserID]; ok {
+		return domain.ErrAlreadyInHousehold
+	}
+	existing.AcceptedBy = invitation.AcceptedBy
+	existing.AcceptedAt = invitation.AcceptedAt
+	r.invitations[invitation.ID] = existing
+	r.members[member.UserID] = *member
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryImportRepository struct {
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]domain.ImportJob
+}
+
+func NewMemoryImportRepository() domain.ImportRepository {
+	return &MemoryImportRepository{jobs: map[uuid.UUID]domain.ImportJob{}}
+}
+
+func (r *MemoryImportRepository) CreateImport(_ context.Context, job *domain.ImportJob) error {
+	return r.SaveImport(context.Background(), job)
+}
+
+func (r *MemoryImportRepository) SaveImport(_ context.Context, job *domain.ImportJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *job
+	saved.Errors = append([]domain.ImportRowError(nil), job.Errors...)
+	r.jobs[job.ID] = saved
+	return nil
+}
+
+func (r *MemoryImportRepository) FindImport(_ context.Context, tenantID, importID uuid.UUID) (*domain.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[importID]
+	if !ok || job.TenantID != tenantID {
+		return nil, domain.ErrImportNotFound
+	}
+	job.Errors = append([]domain.ImportRowError(nil), job.Errors...)
+	return &job, nil
+}
+
+func (r *MemoryImportRepository) ListImports(_ context.Context, tenantID uuid.UUID, limit int) ([]domain.ImportJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var jobs []domain.ImportJob
+	for _, job := range r.jobs {
+		if job.TenantID == tenantID {
+			job.Errors = nil
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (r *MemoryImportRepository) FailStaleImports(_ context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var failed int64
+	for id, job := range r.jobs {
+		if job.Status != domain.ImportPending && job.Status != domain.ImportRunning {
+			continue
+		}
+		heartbeat := job.CreatedAt
+		if job.HeartbeatAt != nil {
+			heartbeat = *job.HeartbeatAt
+		}
+		if !heartbeat.Before(heartbeatBefore) {
+			continue
+		}
+		finished := time.Now().UTC()
+		job.Status, job.Error, job.FinishedAt = domain.ImportFailed, reason, &finished
+		r.jobs[id] = job
+		failed++
+	}
+	return failed, nil
+}
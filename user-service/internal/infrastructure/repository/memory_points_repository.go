@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryPointsRepository struct {
+	mu           sync.RWMutex
+	transactions map[uuid.UUID]domain.PointsTransaction
+	entries      []domain.PointsEntry
+	rules        map[uuid.UUID]map[domain.ActionType]domain.EarningRule
+}
+
+func NewMemoryPointsRepository() domain.PointsRepository {
+	return &MemoryPointsRepository{
+		transactions: map[uuid.UUID]domain.PointsTransaction{},
+		rules:        map[uuid.UUID]map[domain.ActionType]domain.EarningRule{},
+	}
+}
+
+func (r *MemoryPointsRepository) CreateTransaction(_ context.Context, transaction *domain.PointsTransaction, entries []domain.PointsEntry) error {
+	if !entriesBalance(entries) {
+		return domain.ErrUnbalancedTransaction
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if transaction.ActionID != nil {
+		for _, existing := range r.transactions {
+			if existing.TenantID == transaction.TenantID && existing.ActionID != nil && *existing.ActionID == *transaction.ActionID {
+				return domain.ErrPointsAlreadyCredited
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Amount < 0 && domain.IsUserAccount(entry.Account) && r.balance(entry.TenantID, entry.Account)+entry.Amount < 0 {
+			return domain.ErrInsufficientPoints
+		}
+	}
+
+	r.transactions[transaction.ID] = *transaction
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func (r *MemoryPointsRepository) Balance(_ context.Context, tenantID uuid.UUID, account string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.balance(tenantID, account), nil
+}
+
+func (r *MemoryPointsRepository) balance(tenantID uuid.UUID, account string) int64 {
+	var balance int64
+	for _, entry := range r.entries {
+		if entry.TenantID == tenantID && entry.Account == account {
+			balance += entry.Amount
+		}
+	}
+	return balance
+}
+
+func (r *MemoryPointsRepository) History(_ context.Context, tenantID uuid.UUID, account string, limit, offset int) ([]domain.PointsHistoryItem, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var items []domain.PointsHistoryItem
+	for _, entry := range r.entries {
+		if entry.TenantID != tenantID || entry.Account != account {
+			continue
+		}
+		transaction := r.transactions[entry.TransactionID]
+		items = append(items, domain.PointsHistoryItem{
+			PointsEntry: entry,
+			Kind:        transaction.Kind,
+			ActionID:    transaction.ActionID,
+			Reason:      transaction.Reason,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.After(items[j].CreatedAt)
+		}
+		return items[i].ID.String() < items[j].ID.String()
+	})
+
+	total := int64(len(items))
+	if offset >= len(items) {
+		return nil, total, nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
+
+func (r *MemoryPointsRepository) ListRules(_ context.Context, tenantID uuid.UUID) ([]domain.EarningRule, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var rules []domain.EarningRule
+	for _, rule := range r.rules[tenantID] {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Action < rules[j].Action })
+	return rules, nil
+}
+
+func (r *MemoryPointsRepository) SaveRule(_ context.Context, rule *domain.EarningRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rules[rule.TenantID] == nil {
+		r.rules[rule.TenantID] = map[domain.ActionType]domain.EarningRule{}
+	}
+	r.rules[rule.TenantID][rule.Action] = *rule
+	return nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"messaging/outbox"
+	"user-service/internal/domain"
+)
+
+type MemoryPreferencesRepository struct {
+	mu          sync.RWMutex
+	preferences map[uuid.UUID]domain.UserPreferences
+	outbox      *outbox.MemoryStore
+}
+
+func NewMemoryPreferencesRepository(outbox *outbox.MemoryStore) domain.PreferencesRepository {
+	return &MemoryPreferencesRepository{preferences: map[uuid.UUID]domain.UserPreferences{}, outbox: outbox}
+}
+
+func (r *MemoryPreferencesRepository) FindPreferences(_ context.Context, tenantID, userID uuid.UUID) (*domain.UserPreferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	preferences, ok := r.preferences[userID]
+	if !ok || preferences.TenantID != tenantID {
+		return nil, domain.ErrPreferencesNotFound
+	}
+	preferences.Preferences.Channels = append([]domain.ContactChannel(nil), preferences.Preferences.Channels...)
+	return &preferences, nil
+}
+
+func (r *MemoryPreferencesRepository) SavePreferences(_ context.Context, preferences *domain.UserPreferences, events ...domain.Event) error {
+	messages, err := eventMessages(events)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *preferences
+	saved.Preferences.Channels = append([]domain.ContactChannel(nil), preferences.Preferences.Channels...)
+	r.preferences[preferences.UserID] = saved
+	r.outbox.Add(messages...)
+	return nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryReferralRepository struct {
+	mu        sync.RWMutex
+	codes     map[uuid.UUID]domain.ReferralCode
+	referrals map[uuid.UUID]domain.Referral
+}
+
+func NewMemoryReferralRepository() domain.ReferralRepository {
+	return &MemoryReferralRepository{
+		codes:     map[uuid.UUID]domain.ReferralCode{},
+		referrals: map[uuid.UUID]domain.Referral{},
+	}
+}
+
+func (r *MemoryReferralRepository) CreateReferralCode(_ context.Context, code *domain.ReferralCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.codes[code.UserID]; ok {
+		return domain.ErrReferralCodeTaken
+	}
+	for _, existing := range r.codes {
+		if existing.TenantID == code.TenantID && existing.Code == code.Code {
+			return domain.ErrReferralCodeTaken
+		}
+	}
+	r.codes[code.UserID] = *code
+	return nil
+}
+
+func (r *MemoryReferralRepository) FindReferralCode(_ context.Context, tenantID, userID uuid.UUID) (*domain.ReferralCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	code, ok := r.codes[userID]
+	if !ok || code.TenantID != tenantID {
+		return nil, domain.ErrReferralCodeNotFound
+	}
+	return &code, nil
+}
+
+func (r *MemoryReferralRepository) FindReferralCodeByCode(_ context.Context, tenantID uuid.UUID, value string) (*domain.ReferralCode, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, code := range r.codes {
+		if code.TenantID == tenantID && code.Code == value {
+			return &code, nil
+		}
+	}
+	return nil, domain.ErrReferralCodeNotFound
+}
+
+func (r *MemoryReferralRepository) CreateReferral(_ context.Context, referral *domain.Referral) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.referrals {
+		if existing.TenantID == referral.TenantID && existing.ReferredID == referral.ReferredID {
+			return domain.ErrAlreadyReferred
+		}
+	}
+	r.referrals[referral.ID] = *referral
+	return nil
+}
+
+func (r *MemoryReferralRepository) ResolveReferral(_ context.Context, referral *domain.Referral) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.referrals[referral.ID]
+	if !ok || existing.TenantID != referral.TenantID || existing.Status != domain.ReferralPending {
+		return domain.ErrReferralNotFound
+	}
+	existing.Status = referral.Status
+	existing.BlockReason = referral.BlockReason
+	existing.District = referral.District
+	existing.ResolvedAt = referral.ResolvedAt
+	r.referrals[referral.ID] = existing
+	return nil
+}
+
+func (r *MemoryReferralRepository) FindReferralByReferred(_ context.Context, tenantID, referredID uuid.UUID) (*domain.Referral, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, referral := range r.referrals {
+		if referral.TenantID == tenantID && referral.ReferredID == referredID {
+			return &referral, nil
+		}
+	}
+	return nil, domain.ErrReferralNotFound
+}
+
+func (r *MemoryReferralRepository) CountReferrals(_ context.Context, tenantID uuid.UUID, filter domain.ReferralFilter) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, referral := range r.referrals {
+		switch {
+		case referral.TenantID != tenantID,
+			filter.ReferrerID != nil && referral.ReferrerID != *filter.ReferrerID,
+			filter.DeviceHash != "" && referral.DeviceHash != filter.DeviceHash,
+			filter.Status != "" && referral.Status != filter.Status,
+			filter.ResolvedSince != nil && (referral.ResolvedAt == nil || referral.ResolvedAt.Before(*filter.ResolvedSince)):
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func (r *MemoryReferralRepository) CountByReferrer(_ context.Context, tenantID, referrerID uuid.UUID) (domain.ReferralCounts, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var counts domain.ReferralCounts
+	for _, referral := range r.referrals {
+		if referral.TenantID == tenantID && referral.ReferrerID == referrerID {
+			addReferralCount(&counts, referral)
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemoryReferralRepository) TopReferrers(_ context.Context, tenantID uuid.UUID, limit int) ([]domain.ReferrerStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byReferrer := map[uuid.UUID]*domain.ReferrerStats{}
+	for _, referral := range r.referrals {
+		if referral.TenantID != tenantID {
+			continue
+		}
+		stats, ok := byReferrer[referral.ReferrerID]
+		if !ok {
+			stats = &domain.ReferrerStats{UserID: referral.ReferrerID}
+			byReferrer[referral.ReferrerID] = stats
+		}
+		addReferralCount(&stats.ReferralCounts, referral)
+	}
+
+	result := make([]domain.ReferrerStats, 0, len(byReferrer))
+	for _, stats := range byReferrer {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Referred != result[j].Referred {
+			return result[i].Referred > result[j].Referred
+		}
+		if result[i].Rewarded != result[j].Rewarded {
+			return result[i].Rewarded > result[j].Rewarded
+		}
+		return result[i].UserID.String() < result[j].UserID.String()
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (r *MemoryReferralRepository) CountByDistrict(_ context.Context, tenantID uuid.UUID) ([]domain.DistrictReferralStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	byDistrict := map[string]*domain.DistrictReferralStats{}
+	for _, referral := range r.referrals {
+		if referral.TenantID != tenantID {
+			continue
+		}
+		stats, ok := byDistrict[referral.District]
+		if !ok {
+			stats = &domain.DistrictReferralStats{District: referral.District}
+			byDistrict[referral.District] = stats
+		}
+		addReferralCount(&stats.ReferralCounts, referral)
+	}
+
+	result := make([]domain.DistrictReferralStats, 0, len(byDistrict))
+	for _, stats := range byDistrict {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Referred != result[j].Referred {
+			return result[i].Referred > result[j].Referred
+		}
+		return result[i].District < result[j].District
+	})
+	return result, nil
+}
+
+func addReferralCount(counts *domain.ReferralCounts, referral domain.Referral) {
+	counts.Referred++
+	switch referral.Status {
+	case domain.ReferralPending:
+		counts.Pending++
+	case domain.ReferralRewarded:
+		counts.Rewarded++
+	case domain.ReferralBlocked:
+		counts.Blocked++
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type MemoryTenantRepository struct {
+	mu      sync.RWMutex
+	tenants map[string]domain.Tenant
+}
+
+func NewMemoryTenantRepository() domain.TenantRepository {
+	defaultTenant := domain.Tenant{ID: uuid.New(), Slug: domain.DefaultTenantSlug, Name: "Default", CreatedAt: time.Now()}
+	return &MemoryTenantRepository{tenants: map[string]domain.Tenant{defaultTenant.Slug: defaultTenant}}
+}
+
+func (r *MemoryTenantRepository) CreateTenant(_ context.Context, tenant *domain.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[tenant.Slug]; ok {
+		return errors.New("tenant with this slug already exists")
+	}
+	r.tenants[tenant.Slug] = *tenant
+	return nil
+}
+
+func (r *MemoryTenantRepository) FindTenantBySlug(_ context.Context, slug string) (*domain.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenant, ok := r.tenants[slug]
+	if !ok {
+		return nil, domain.ErrTenantNotFound
+	}
+	return &tenant, nil
+}
@@ -0,0 +1,392 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"user-service/internal/domain"
+)
+
+var ErrDuplicateEmail = errors.New("user with this email already exists")
+
+type MemoryUserRepository struct {
+	mu        sync.RWMutex
+	users     map[uuid.UUID]domain.User
+	actions   []domain.UserAction
+	addresses map[uuid.UUID]domain.Address
+}
+
+func NewMemoryUserRepository() domain.UserRepository {
+	return &MemoryUserRepository{users: map[uuid.UUID]domain.User{}, addresses: map[uuid.UUID]domain.Address{}}
+}
+
+func (r *MemoryUserRepository) Create(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return errors.New("user with this id already exists")
+	}
+	for _, existing := range r.users {
+		if !existing.DeletedAt.Valid && existing.TenantID == user.TenantID && existing.Email == user.Email {
+			return ErrDuplicateEmail
+		}
+		if user.IdentityKey != nil && existing.IdentityKey != nil && *existing.IdentityKey == *user.IdentityKey {
+			return errors.New("user with this identity key already exists")
+		}
+	}
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(_ context.Context, tenantID uuid.UUID, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && user.TenantID == tenantID && user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *MemoryUserRepository) FindByIdentityKey(_ context.Context, tenantID, identityKey uuid.UUID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && user.TenantID == tenantID && user.IdentityKey != nil && *user.IdentityKey == identityKey {
+			return &user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *MemoryUserRepository) AccountVersion(_ context.Context, tenantID, identityKey uuid.UUID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var version int64
+	for _, user := range r.users {
+		if user.TenantID == tenantID && user.IdentityKey != nil && *user.IdentityKey == identityKey && user.AccountVersion > version {
+			version = user.AccountVersion
+		}
+	}
+	return version, nil
+}
+
+func (r *MemoryUserRepository) FindByID(_ context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletedAt.Valid || user.TenantID != tenantID {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) List(_ context.Context, tenantID uuid.UUID, filter domain.UserFilter) ([]domain.User, int64, error) {
+	return r.page(tenantID, filter.Limit, filter.Offset, func(user domain.User) bool {
+		switch {
+		case filter.Role != "" && user.Role != filter.Role:
+			return false
+		case filter.Name != "" && !containsFold(user.Name, filter.Name):
+			return false
+		case filter.Address != "" && !r.hasAddressLike(user, filter.Address):
+			return false
+		case filter.CreatedFrom != nil && user.CreatedAt.Before(*filter.CreatedFrom):
+			return false
+		case filter.CreatedTo != nil && !user.CreatedAt.Before(*filter.CreatedTo):
+			return false
+		}
+		return true
+	})
+}
+
+func (r *MemoryUserRepository) Search(_ context.Context, tenantID uuid.UUID, text string, limit, offset int) ([]domain.User, int64, error) {
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	return r.page(tenantID, limit, offset, func(user domain.User) bool {
+		for _, term := range terms {
+			if !containsFold(user.Name, term) && !containsFold(user.Email, term) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (r *MemoryUserRepository) Update(_ context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok || existing.DeletedAt.Valid || existing.TenantID != user.TenantID {
+		return domain.ErrUserNotFound
+	}
+	for _, other := range r.users {
+		if !other.DeletedAt.Valid && other.ID != user.ID && other.TenantID == user.TenantID && other.Email == user.Email {
+			return ErrDuplicateEmail
+		}
+	}
+	if existing.Version != user.Version {
+		return domain.ErrVersionConflict
+	}
+
+	user.Version++
+	updated := *user
+	updated.CreatedAt = existing.CreatedAt
+	r.users[user.ID] = updated
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(_ context.Context, tenantID, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeletedAt.Valid || user.TenantID != tenantID {
+		return domain.ErrUserNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.users[userID] = user
+	return nil
+}
+
+func (r *MemoryUserRepository) GetUserActions(_ context.Context, tenantID, userID uuid.UUID, filter domain.ActionFilter) ([]domain.UserAction, error) {
+	actions := r.matchActions(tenantID, userID, filter.Types, filter.Category, filter.From, filter.To)
+
+	asc := filter.Order == domain.SortAsc
+	before := func(a, b domain.UserAction) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt) == asc
+		}
+		if a.ID == b.ID {
+			return false
+		}
+		return (a.ID.String() < b.ID.String()) == asc
+	}
+	sort.Slice(actions, func(i, j int) bool { return before(actions[i], actions[j]) })
+
+	if filter.After != nil {
+		cursor := domain.UserAction{CreatedAt: filter.After.CreatedAt, ID: filter.After.ID}
+		start := sort.Search(len(actions), func(i int) bool { return before(cursor, actions[i]) })
+		actions = actions[start:]
+	}
+	if filter.Limit > 0 && filter.Limit < len(actions) {
+		actions = actions[:filter.Limit]
+	}
+	return actions, nil
+}
+
+func (r *MemoryUserRepository) CountUserActions(_ context.Context, tenantID, userID uuid.UUID, query domain.ActionSummaryQuery) ([]domain.ActionCount, error) {
+	actions := r.matchActions(tenantID, userID, query.Types, query.Category, query.From, query.To)
+	return countByPeriod(actions, query.Period, query.Location), nil
+}
+
+func (r *MemoryUserRepository) CountTenantActions(_ context.Context, tenantID uuid.UUID, period domain.AggregationPeriod, loc *time.Location, before time.Time) ([]domain.UserActionCount, error) {
+	r.mu.RLock()
+	var actions []domain.UserAction
+	for _, action := range r.actions {
+		if action.TenantID == tenantID && action.CreatedAt.Before(before) {
+			actions = append(actions, action)
+		}
+	}
+	r.mu.RUnlock()
+	return countUsersByPeriod(actions, period, loc), nil
+}
+
+func (r *MemoryUserRepository) matchActions(tenantID, userID uuid.UUID, types []domain.ActionType, category string, from, to *time.Time) []domain.UserAction {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var actions []domain.UserAction
+	for _, action := range r.actions {
+		if action.TenantID != tenantID || action.UserID != userID {
+			continue
+		}
+		if len(types) > 0 && !containsActionType(types, action.Action) {
+			continue
+		}
+		if category != "" && action.Category != category {
+			continue
+		}
+		if from != nil && action.CreatedAt.Before(*from) {
+			continue
+		}
+		if to != nil && !action.CreatedAt.Before(*to) {
+			continue
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+func (r *MemoryUserRepository) RecordUserAction(_ context.Context, action *domain.UserAction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.actions {
+		if existing.ID == action.ID {
+			return domain.ErrActionExists
+		}
+	}
+
+	r.actions = append(r.actions, *action)
+	return nil
+}
+
+func (r *MemoryUserRepository) ListAddresses(_ context.Context, tenantID, userID uuid.UUID) ([]domain.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var addresses []domain.Address
+	for _, address := range r.addresses {
+		if address.TenantID == tenantID && address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if addresses[i].IsPrimary != addresses[j].IsPrimary {
+			return addresses[i].IsPrimary
+		}
+		if !addresses[i].CreatedAt.Equal(addresses[j].CreatedAt) {
+			return addresses[i].CreatedAt.Before(addresses[j].CreatedAt)
+		}
+		return addresses[i].ID.String() < addresses[j].ID.String()
+	})
+	return addresses, nil
+}
+
+func (r *MemoryUserRepository) ListPrimaryAddresses(_ context.Context, tenantID uuid.UUID) ([]domain.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var addresses []domain.Address
+	for _, address := range r.addresses {
+		if address.TenantID == tenantID && address.IsPrimary {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].UserID.String() < addresses[j].UserID.String()
+	})
+	return addresses, nil
+}
+
+func (r *MemoryUserRepository) FindAddress(_ context.Context, tenantID, userID, addressID uuid.UUID) (*domain.Address, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	address, ok := r.addresses[addressID]
+	if !ok || address.TenantID != tenantID || address.UserID != userID {
+		return nil, domain.ErrAddressNotFound
+	}
+	return &address, nil
+}
+
+func (r *MemoryUserRepository) SaveAddress(_ context.Context, address *domain.Address) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.addresses[address.ID]; ok {
+		if existing.TenantID != address.TenantID || existing.UserID != address.UserID {
+			return domain.ErrAddressNotFound
+		}
+		address.CreatedAt = existing.CreatedAt
+	}
+	if address.IsPrimary {
+		for id, other := range r.addresses {
+			if id != address.ID && other.TenantID == address.TenantID && other.UserID == address.UserID && other.IsPrimary {
+				other.IsPrimary = false
+				r.addresses[id] = other
+			}
+		}
+	}
+	r.addresses[address.ID] = *address
+	return nil
+}
+
+func (r *MemoryUserRepository) DeleteAddress(_ context.Context, tenantID, userID, addressID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	address, ok := r.addresses[addressID]
+	if !ok || address.TenantID != tenantID || address.UserID != userID {
+		return domain.ErrAddressNotFound
+	}
+	delete(r.addresses, addressID)
+	return nil
+}
+
+// hasAddressLike вызывается из page под блокировкой чтения
+func (r *MemoryUserRepository) hasAddressLike(user domain.User, text string) bool {
+	for _, address := range r.addresses {
+		if address.TenantID != user.TenantID || address.UserID != user.ID {
+			continue
+		}
+		if containsFold(address.Street, text) || containsFold(address.City, text) || containsFold(address.PostalCode, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// page отбирает живых пользователей тенанта в том же порядке, что и PostgresUserRepository.List
+func (r *MemoryUserRepository) page(tenantID uuid.UUID, limit, offset int, match func(domain.User) bool) ([]domain.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []domain.User
+	for _, user := range r.users {
+		if !user.DeletedAt.Valid && user.TenantID == tenantID && match(user) {
+			matched = append(matched, user)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].CreatedAt.After(matched[j].CreatedAt)
+		}
+		return matched[i].ID.String() < matched[j].ID.String()
+	})
+
+	total := int64(len(matched))
+	if offset >= len(matched) {
+		return nil, total, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func containsActionType(types []domain.ActionType, actionType domain.ActionType) bool {
+	for _, t := range types {
+		if t == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(value, substr string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substr))
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"messaging"
+	"user-service/internal/domain"
+)
+
+// eventMessages упаковывает доменные события в сообщения топика users.
+// Payload — событие целиком, ключ — арендатор, чтобы события одного арендатора шли по порядку.
+func eventMessages(events []domain.Event) ([]messaging.Message, error) {
+	messages := make([]messaging.Message, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, messaging.Message{
+			ID:         event.ID.String(),
+			Topic:      domain.TopicUsers,
+			Type:       event.Type,
+			Key:        event.TenantID.String(),
+			Payload:    payload,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	return messages, nil
+}
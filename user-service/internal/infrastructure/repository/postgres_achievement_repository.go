@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"user-service/internal/domain"
+)
+
+type PostgresAchievementRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresAchievementRepository(db *gorm.DB) domain.AchievementRepository {
+	return &PostgresAchievementRepository{db: db}
+}
+
+func (r *PostgresAchievementRepository) AwardAchievement(ctx context.Context, achievement *domain.UserAchievement) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(achievement)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAchievementAwarded
+	}
+	return nil
+}
+
+func (r *PostgresAchievementRepository) ListUserAchievements(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.UserAchievement, error) {
+	var achievements []domain.UserAchievement
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("earned_at, code").
+		Find(&achievements).Error
+	return achievements, err
+}
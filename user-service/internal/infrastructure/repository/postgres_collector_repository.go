@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"user-service/internal/domain"
+)
+
+type PostgresCollectorRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresCollectorRepository(db *gorm.DB) domain.CollectorRepository {
+	return &PostgresCollectorRepository{db: db}
+}
+
+func (r *PostgresCollectorRepository) FindProfile(ctx context.Context, tenantID, userID uuid.UUID) (*domain.CollectorProfile, error) {
+	var profile domain.CollectorProfile
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND user_id = ?", tenantID, userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrCollectorProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *PostgresCollectorRepository) SaveProfile(ctx context.Context, profile *domain.CollectorProfile) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(profile).Error
+}
+
+func (r *PostgresCollectorRepository) ListShifts(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.CollectorShift, error) {
+	var shifts []domain.CollectorShift
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("weekday, starts").
+		Find(&shifts).Error
+	return shifts, err
+}
+
+// ReplaceShifts заменяет расписание целиком одной транзакцией
+func (r *PostgresCollectorRepository) ReplaceShifts(ctx context.Context, tenantID, userID uuid.UUID, shifts []domain.CollectorShift) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ? AND user_id = ?", tenantID, userID).Delete(&domain.CollectorShift{}).Error; err != nil {
+			return err
+		}
+		if len(shifts) == 0 {
+			return nil
+		}
+		return tx.Create(&shifts).Error
+	})
+}
+
+func (r *PostgresCollectorRepository) ListCertifications(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.CollectorCertification, error) {
+	var certifications []domain.CollectorCertification
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("hazard, issued_at, id").
+		Find(&certifications).Error
+	return certifications, err
+}
+
+func (r *PostgresCollectorRepository) SaveCertification(ctx context.Context, certification *domain.CollectorCertification) error {
+	return r.db.WithContext(ctx).Create(certification).Error
+}
+
+func (r *PostgresCollectorRepository) DeleteCertification(ctx context.Context, tenantID, userID, certificationID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, certificationID).
+		Delete(&domain.CollectorCertification{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCertificationNotFound
+	}
+	return nil
+}
+
+func (r *PostgresCollectorRepository) ListZones(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.ServiceZone, error) {
+	var zones []domain.ServiceZone
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("created_at, id").
+		Find(&zones).Error
+	return zones, err
+}
+
+// SaveZone обновляет зону сборщика или создаёт новую
+func (r *PostgresCollectorRepository) SaveZone(ctx context.Context, zone *domain.ServiceZone) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.ServiceZone{}).
+			Where("tenant_id = ? AND user_id = ? AND id = ?", zone.TenantID, zone.UserID, zone.ID).
+			Select("name", "boundary", "updated_at").
+			Updates(zone)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Create(zone).Error
+	})
+}
+
+func (r *PostgresCollectorRepository) DeleteZone(ctx context.Context, tenantID, userID, zoneID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, zoneID).
+		Delete(&domain.ServiceZone{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrZoneNotFound
+	}
+	return nil
+}
+
+// ZonesCovering в PostgreSQL опирается на GiST-индекс по area, в остальных базах проверяет многоугольники в Go
+func (r *PostgresCollectorRepository) ZonesCovering(ctx context.Context, tenantID uuid.UUID, point domain.GeoPoint) ([]domain.ServiceZone, error) {
+	var zones []domain.ServiceZone
+	if r.db.Dialector.Name() == "postgres" {
+		err := r.db.WithContext(ctx).
+			Where("tenant_id = ? AND ST_Covers(area, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography)", tenantID, point.Longitude, point.Latitude).
+			Order("created_at, id").
+			Find(&zones).Error
+		return zones, err
+	}
+
+	zones, err := r.ListTenantZones(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return zonesContaining(zones, point), nil
+}
+
+func (r *PostgresCollectorRepository) ListTenantZones(ctx context.Context, tenantID uuid.UUID) ([]domain.ServiceZone, error) {
+	var zones []domain.ServiceZone
+	err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at, id").Find(&zones).Error
+	return zones, err
+}
+
+func zonesContaining(zones []domain.ServiceZone, point domain.GeoPoint) []domain.ServiceZone {
+	var covering []domain.ServiceZone
+	for _, zone := range zones {
+		if zone.Boundary.Contains(point) {
+			covering = append(covering, zone)
+		}
+	}
+	return covering
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"user-service/internal/domain"
+)
+
+type PostgresHouseholdRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresHouseholdRepository(db *gorm.DB) domain.HouseholdRepository {
+	return &PostgresHouseholdRepository{db: db}
+}
+
+func (r *PostgresHouseholdRepository) CreateHousehold(ctx context.Context, household *domain.Household, owner *domain.HouseholdMembership) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(household).Error; err != nil {
+			return err
+		}
+		return insertMember(tx, owner)
+	})
+}
+
+func (r *PostgresHouseholdRepository) FindHousehold(ctx context.Context, tenantID, householdID uuid.UUID) (*domain.Household, error) {
+	var household domain.Household
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, householdID).First(&household).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrHouseholdNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &household, nil
+}
+
+func (r *PostgresHouseholdRepository) UpdateHousehold(ctx context.Context, household *domain.Household) error {
+	result := r.db.WithContext(ctx).Model(&domain.Household{}).
+		Where("tenant_id = ? AND id = ?", household.TenantID, household.ID).
+		Select("name", "address_id", "settings", "updated_at").
+		Updates(household)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrHouseholdNotFound
+	}
+	return nil
+}
+
+// DeleteHousehold удаляет домохозяйство вместе с членством и приглашениями
+func (r *PostgresHouseholdRepository) DeleteHousehold(ctx context.Context, tenantID, householdID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		scope := "tenant_id = ? AND household_id = ?"
+		if err := tx.Where(scope, tenantID, householdID).Delete(&domain.HouseholdInvitation{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where(scope, tenantID, householdID).Delete(&domain.HouseholdMembership{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("tenant_id = ? AND id = ?", tenantID, householdID).Delete(&domain.Household{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrHouseholdNotFound
+		}
+		return nil
+	})
+}
+
+func (r *PostgresHouseholdRepository) FindMembership(ctx context.Context, tenantID, userID uuid.UUID) (*domain.HouseholdMembership, error) {
+	var member domain.HouseholdMembership
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND user_id = ?", tenantID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotHouseholdMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *PostgresHouseholdRepository) ListMembers(ctx context.Context, tenantID, householdID uuid.UUID) ([]domain.HouseholdMembership, error) {
+	var members []domain.HouseholdMembership
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND household_id = ?", tenantID, householdID).
+		Order("joined_at, user_id").
+		Find(&members).Error
+	return members, err
+}
+
+// TransferOwnership сначала понижает владельца, иначе сработает уникальный индекс на единственного владельца
+func (r *PostgresHouseholdRepository) TransferOwnership(ctx context.Context, tenantID, householdID, from, to uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, change := range []struct {
+			userID uuid.UUID
+			role   domain.HouseholdRole
+		}{{from, domain.HouseholdMember}, {to, domain.HouseholdOwner}} {
+			result := tx.Model(&domain.HouseholdMembership{}).
+				Where("tenant_id = ? AND household_id = ? AND user_id = ?", tenantID, householdID, change.userID).
+				Update("role", change.role)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrNotHouseholdMember
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresHouseholdRepository) RemoveMember(ctx context.Context, tenantID, householdID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND household_id = ? AND user_id = ?", tenantID, householdID, userID).
+		Delete(&domain.HouseholdMembership{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotHouseholdMember
+	}
+	return nil
+}
+
+func (r *PostgresHouseholdRepository) CreateInvitation(ctx context.Context, invitation *domain.HouseholdInvitation) error {
+	return r.db.WithContext(ctx).Create(invitation).Error
+}
+
+func (r *PostgresHouseholdRepository) FindInvitationByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.HouseholdInvitation, error) {
+	var invitation domain.HouseholdInvitation
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND code = ?", tenantID, code).First(&invitation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// AcceptInvitation помечает приглашение использованным и добавляет жильца одной транзакцией;
+// повторное использование кода проигрывает гонку на условии accepted_at IS NULL
+func (r *PostgresHouseholdRepository) AcceptInvitation(ctx context.Context, invitation *domain.HouseholdInvitation, member *domain.HouseholdMembership) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.HouseholdInvitation{}).
+			Where("tenant_id = ? AND id = ? AND accepted_at IS NULL", invitation.TenantID, invitation.ID).
+			Updates(map[string]any{"accepted_by": invitation.AcceptedBy, "accepted_at": invitation.AcceptedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrInvitationInvalid
+		}
+		return insertMember(tx, member)
+	})
+}
+
+func insertMember(tx *gorm.DB, member *domain.HouseholdMembership) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(member)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAlreadyInHousehold
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"user-service/internal/domain"
+)
+
+type PostgresImportRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresImportRepository(db *gorm.DB) domain.ImportRepository {
+	return &PostgresImportRepository{db: db}
+}
+
+func (r *PostgresImportRepository) CreateImport(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+func (r *PostgresImportRepository) SaveImport(ctx context.Context, job *domain.ImportJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
+
+func (r *PostgresImportRepository) FindImport(ctx context.Context, tenantID, importID uuid.UUID) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, importID).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrImportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListImports возвращает последние загрузки без отчёта об ошибках: он бывает большим
+func (r *PostgresImportRepository) ListImports(ctx context.Context, tenantID uuid.UUID, limit int) ([]domain.ImportJob, error) {
+	var jobs []domain.ImportJob
+	err := r.db.WithContext(ctx).Omit("errors").Where("tenant_id = ?", tenantID).
+		Order("created_at DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// FailStaleImports: у загрузок, начатых до появления heartbeat_at, вместо него берётся created_at
+func (r *PostgresImportRepository) FailStaleImports(ctx context.Context, heartbeatBefore time.Time, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&domain.ImportJob{}).
+		Where("status IN ? AND COALESCE(heartbeat_at, created_at) < ?", []domain.ImportStatus{domain.ImportPending, domain.ImportRunning}, heartbeatBefore).
+		Updates(map[string]interface{}{"status": domain.ImportFailed, "error": reason, "finished_at": time.Now().UTC()})
+	return result.RowsAffected, result.Error
+}
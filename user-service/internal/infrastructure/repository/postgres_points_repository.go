@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"user-service/internal/domain"
+)
+
+type PostgresPointsRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPointsRepository(db *gorm.DB) domain.PointsRepository {
+	return &PostgresPointsRepository{db: db}
+}
+
+// CreateTransaction пишет операцию и все её проводки в одной транзакции базы
+func (r *PostgresPointsRepository) CreateTransaction(ctx context.Context, transaction *domain.PointsTransaction, entries []domain.PointsEntry) error {
+	if !entriesBalance(entries) {
+		return domain.ErrUnbalancedTransaction
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, entry := range entries {
+			if entry.Amount < 0 && domain.IsUserAccount(entry.Account) {
+				if err := r.checkDebit(tx, entry); err != nil {
+					return err
+				}
+			}
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(transaction)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrPointsAlreadyCredited
+		}
+		return tx.Create(&entries).Error
+	})
+}
+
+// checkDebit берёт блокировку счёта до конца транзакции и только потом считает баланс:
+// второе списание с того же счёта ждёт первое и видит уже уменьшенный остаток.
+// Строки счёта в схеме нет, поэтому блокировка рекомендательная, по хешу тенанта и счёта.
+func (r *PostgresPointsRepository) checkDebit(tx *gorm.DB, entry domain.PointsEntry) error {
+	if tx.Dialector.Name() == "postgres" {
+		key := entry.TenantID.String() + "/" + entry.Account
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", key).Error; err != nil {
+			return err
+		}
+	}
+
+	var balance int64
+	err := tx.Model(&domain.PointsEntry{}).
+		Where("tenant_id = ? AND account = ?", entry.TenantID, entry.Account).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	if err != nil {
+		return err
+	}
+	if balance+entry.Amount < 0 {
+		return domain.ErrInsufficientPoints
+	}
+	return nil
+}
+
+func (r *PostgresPointsRepository) Balance(ctx context.Context, tenantID uuid.UUID, account string) (int64, error) {
+	var balance int64
+	err := r.db.WithContext(ctx).Model(&domain.PointsEntry{}).
+		Where("tenant_id = ? AND account = ?", tenantID, account).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+func (r *PostgresPointsRepository) History(ctx context.Context, tenantID uuid.UUID, account string, limit, offset int) ([]domain.PointsHistoryItem, int64, error) {
+	query := r.db.WithContext(ctx).Table("points_entries AS e").
+		Joins("JOIN points_transactions AS t ON t.id = e.transaction_id").
+		Where("e.tenant_id = ? AND e.account = ?", tenantID, account).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []domain.PointsHistoryItem
+	err := query.
+		Select("e.*, t.kind, t.action_id, t.reason").
+		Order("e.created_at DESC, e.id").
+		Limit(limit).Offset(offset).
+		Scan(&items).Error
+	return items, total, err
+}
+
+func (r *PostgresPointsRepository) ListRules(ctx context.Context, tenantID uuid.UUID) ([]domain.EarningRule, error) {
+	var rules []domain.EarningRule
+	err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("action").Find(&rules).Error
+	return rules, err
+}
+
+func (r *PostgresPointsRepository) SaveRule(ctx context.Context, rule *domain.EarningRule) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "action"}},
+		DoUpdates: clause.AssignmentColumns([]string{"points", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func entriesBalance(entries []domain.PointsEntry) bool {
+	if len(entries) < 2 {
+		return false
+	}
+	var sum int64
+	for _, entry := range entries {
+		sum += entry.Amount
+	}
+	return sum == 0
+}
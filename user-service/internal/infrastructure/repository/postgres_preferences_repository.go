@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"messaging/outbox"
+	"user-service/internal/domain"
+)
+
+type PostgresPreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresPreferencesRepository(db *gorm.DB) domain.PreferencesRepository {
+	return &PostgresPreferencesRepository{db: db}
+}
+
+func (r *PostgresPreferencesRepository) FindPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*domain.UserPreferences, error) {
+	var preferences domain.UserPreferences
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND user_id = ?", tenantID, userID).First(&preferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrPreferencesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+func (r *PostgresPreferencesRepository) SavePreferences(ctx context.Context, preferences *domain.UserPreferences, events ...domain.Event) error {
+	messages, err := eventMessages(events)
+	if err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"document", "updated_at"}),
+		}).Create(preferences).Error
+		if err != nil {
+			return err
+		}
+		return outbox.Write(tx, messages...)
+	})
+}
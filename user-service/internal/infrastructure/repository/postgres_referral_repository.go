@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"user-service/internal/domain"
+)
+
+// Счётчики по статусам одним запросом; SUM по пустой выборке даёт NULL
+const referralCountsSelect = "COUNT(*) AS referred, " +
+	"COALESCE(SUM(CASE WHEN status = 'pending' THEN 1 ELSE 0 END), 0) AS pending, " +
+	"COALESCE(SUM(CASE WHEN status = 'rewarded' THEN 1 ELSE 0 END), 0) AS rewarded, " +
+	"COALESCE(SUM(CASE WHEN status = 'blocked' THEN 1 ELSE 0 END), 0) AS blocked"
+
+type PostgresReferralRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresReferralRepository(db *gorm.DB) domain.ReferralRepository {
+	return &PostgresReferralRepository{db: db}
+}
+
+func (r *PostgresReferralRepository) CreateReferralCode(ctx context.Context, code *domain.ReferralCode) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(code)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReferralCodeTaken
+	}
+	return nil
+}
+
+func (r *PostgresReferralRepository) FindReferralCode(ctx context.Context, tenantID, userID uuid.UUID) (*domain.ReferralCode, error) {
+	return r.findCode(ctx, "tenant_id = ? AND user_id = ?", tenantID, userID)
+}
+
+func (r *PostgresReferralRepository) FindReferralCodeByCode(ctx context.Context, tenantID uuid.UUID, code string) (*domain.ReferralCode, error) {
+	return r.findCode(ctx, "tenant_id = ? AND code = ?", tenantID, code)
+}
+
+func (r *PostgresReferralRepository) findCode(ctx context.Context, query string, args ...any) (*domain.ReferralCode, error) {
+	var code domain.ReferralCode
+	err := r.db.WithContext(ctx).Where(query, args...).First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrReferralCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *PostgresReferralRepository) CreateReferral(ctx context.Context, referral *domain.Referral) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(referral)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAlreadyReferred
+	}
+	return nil
+}
+
+func (r *PostgresReferralRepository) ResolveReferral(ctx context.Context, referral *domain.Referral) error {
+	result := r.db.WithContext(ctx).Model(&domain.Referral{}).
+		Where("id = ? AND tenant_id = ? AND status = ?", referral.ID, referral.TenantID, domain.ReferralPending).
+		Updates(map[string]any{
+			"status":       referral.Status,
+			"block_reason": referral.BlockReason,
+			"district":     referral.District,
+			"resolved_at":  referral.ResolvedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrReferralNotFound
+	}
+	return nil
+}
+
+func (r *PostgresReferralRepository) FindReferralByReferred(ctx context.Context, tenantID, referredID uuid.UUID) (*domain.Referral, error) {
+	var referral domain.Referral
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND referred_id = ?", tenantID, referredID).First(&referral).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrReferralNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &referral, nil
+}
+
+func (r *PostgresReferralRepository) CountReferrals(ctx context.Context, tenantID uuid.UUID, filter domain.ReferralFilter) (int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.Referral{}).Where("tenant_id = ?", tenantID)
+	if filter.ReferrerID != nil {
+		query = query.Where("referrer_id = ?", *filter.ReferrerID)
+	}
+	if filter.DeviceHash != "" {
+		query = query.Where("device_hash = ?", filter.DeviceHash)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ResolvedSince != nil {
+		query = query.Where("resolved_at >= ?", *filter.ResolvedSince)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *PostgresReferralRepository) CountByReferrer(ctx context.Context, tenantID, referrerID uuid.UUID) (domain.ReferralCounts, error) {
+	var counts domain.ReferralCounts
+	err := r.db.WithContext(ctx).Model(&domain.Referral{}).
+		Where("tenant_id = ? AND referrer_id = ?", tenantID, referrerID).
+		Select(referralCountsSelect).
+		Scan(&counts).Error
+	return counts, err
+}
+
+func (r *PostgresReferralRepository) TopReferrers(ctx context.Context, tenantID uuid.UUID, limit int) ([]domain.ReferrerStats, error) {
+	var rows []struct {
+		ReferrerID uuid.UUID
+		domain.ReferralCounts
+	}
+	err := r.db.WithContext(ctx).Model(&domain.Referral{}).
+		Where("tenant_id = ?", tenantID).
+		Select("referrer_id, " + referralCountsSelect).
+		Group("referrer_id").
+		Order("referred DESC, rewarded DESC, referrer_id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]domain.ReferrerStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, domain.ReferrerStats{UserID: row.ReferrerID, ReferralCounts: row.ReferralCounts})
+	}
+	return stats, nil
+}
+
+func (r *PostgresReferralRepository) CountByDistrict(ctx context.Context, tenantID uuid.UUID) ([]domain.DistrictReferralStats, error) {
+	var rows []struct {
+		District string
+		domain.ReferralCounts
+	}
+	err := r.db.WithContext(ctx).Model(&domain.Referral{}).
+		Where("tenant_id = ?", tenantID).
+		Select("COALESCE(district, '') AS district, " + referralCountsSelect).
+		Group("COALESCE(district, '')").
+		Order("referred DESC, district").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]domain.DistrictReferralStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, domain.DistrictReferralStats{District: row.District, ReferralCounts: row.ReferralCounts})
+	}
+	return stats, nil
+}
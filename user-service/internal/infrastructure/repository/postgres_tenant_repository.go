@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"errors"
+
+	"gorm.io/gorm"
+
+	"user-service/internal/domain"
+)
+
+type PostgresTenantRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresTenantRepository(db *gorm.DB) domain.TenantRepository {
+	return &PostgresTenantRepository{db: db}
+}
+
+func (r *PostgresTenantRepository) CreateTenant(ctx context.Context, tenant *domain.Tenant) error {
+	return r.db.WithContext(ctx).Create(tenant).Error
+}
+
+func (r *PostgresTenantRepository) FindTenantBySlug(ctx context.Context, slug string) (*domain.Tenant, error) {
+	var tenant domain.Tenant
+	err := r.db.WithContext(ctx).Where("slug = ?", slug).First(&tenant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrTenantNotFound
+	}
+	return &tenant, err
+}
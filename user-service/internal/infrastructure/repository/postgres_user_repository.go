@@ -1,12 +1,21 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"user-service/internal/domain"
 )
 
+const searchVector = "to_tsvector('simple', coalesce(name, '') || ' ' || coalesce(email, ''))"
+
 type PostgresUserRepository struct {
 	db *gorm.DB
 }
@@ -15,26 +24,351 @@ func NewPostgresUserRepository(db *gorm.DB) domain.UserRepository {
 	return &PostgresUserRepository{db: db}
 }
 
-func (r *PostgresUserRepository) Create(user *domain.User) error {
-	return r.db.Create(user).Error
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, tenantID uuid.UUID, email string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND email = ?", tenantID, email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, err
+}
+
+func (r *PostgresUserRepository) FindByID(ctx context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	var user domain.User
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, userID).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrUserNotFound
+	}
+	return &user, err
 }
 
-func (r *PostgresUserRepository) FindByEmail(email string) (*domain.User, error) {
+func (r *PostgresUserRepository) FindByIdentityKey(ctx context.Context, tenantID, identityKey uuid.UUID) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND identity_key = ?", tenantID, identityKey).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrUserNotFound
+	}
 	return &user, err
 }
 
-func (r *PostgresUserRepository) Update(user *domain.User) error {
-	return r.db.Save(user).Error
+func (r *PostgresUserRepository) AccountVersion(ctx context.Context, tenantID, identityKey uuid.UUID) (int64, error) {
+	var version int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&domain.User{}).
+		Where("tenant_id = ? AND identity_key = ?", tenantID, identityKey).
+		Select("COALESCE(MAX(account_version), 0)").Scan(&version).Error
+	return version, err
+}
+
+func (r *PostgresUserRepository) List(ctx context.Context, tenantID uuid.UUID, filter domain.UserFilter) ([]domain.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.User{}).Where("tenant_id = ?", tenantID)
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Name != "" {
+		query = query.Where(`LOWER(name) LIKE ? ESCAPE '\'`, likePattern(filter.Name))
+	}
+	if filter.Address != "" {
+		pattern := likePattern(filter.Address)
+		query = query.Where(`EXISTS (SELECT 1 FROM user_addresses a WHERE a.user_id = users.id AND a.tenant_id = users.tenant_id AND (
+			LOWER(a.street) LIKE ? ESCAPE '\' OR LOWER(a.city) LIKE ? ESCAPE '\' OR LOWER(a.postal_code) LIKE ? ESCAPE '\'))`,
+			pattern, pattern, pattern)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.Order("created_at DESC, id").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	return users, total, err
+}
+
+func (r *PostgresUserRepository) Search(ctx context.Context, tenantID uuid.UUID, text string, limit, offset int) ([]domain.User, int64, error) {
+	query := r.db.WithContext(ctx).Model(&domain.User{}).Where("tenant_id = ?", tenantID)
+
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	order := clause.OrderBy{Expression: clause.Expr{SQL: "created_at DESC, id"}}
+	if r.db.Dialector.Name() == "postgres" {
+		// Выражение совпадает с индексом idx_users_search
+		tsQuery := strings.Join(terms, ":* & ") + ":*"
+		query = query.Where(searchVector+" @@ to_tsquery('simple', ?)", tsQuery)
+		order = clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(" + searchVector + ", to_tsquery('simple', ?)) DESC, id",
+			Vars: []interface{}{tsQuery},
+		}}
+	} else {
+		for _, term := range terms {
+			query = query.Where(`(LOWER(name) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\')`, likePattern(term), likePattern(term))
+		}
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.Order(order).Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+// Update сохраняет пользователя, только если его версия в базе совпадает с user.Version, и увеличивает её
+func (r *PostgresUserRepository) Update(ctx context.Context, user *domain.User) error {
+	expected := user.Version
+	user.Version = expected + 1
+
+	result := r.db.WithContext(ctx).Model(&domain.User{}).
+		Where("tenant_id = ? AND id = ? AND version = ?", user.TenantID, user.ID, expected).
+		Select("*").Omit("id", "tenant_id", "created_at", "deleted_at").
+		Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	user.Version = expected
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&domain.User{}).Where("tenant_id = ? AND id = ?", user.TenantID, user.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrVersionConflict
 }
 
-func (r *PostgresUserRepository) GetUserActions(userID uuid.UUID) ([]domain.UserAction, error) {
+func (r *PostgresUserRepository) Delete(ctx context.Context, tenantID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("tenant_id = ? AND id = ?", tenantID, userID).Delete(&domain.User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) GetUserActions(ctx context.Context, tenantID, userID uuid.UUID, filter domain.ActionFilter) ([]domain.UserAction, error) {
+	query := r.actionScope(ctx, tenantID, userID, filter.Types, filter.Category, filter.From, filter.To)
+
+	direction := "DESC"
+	if filter.Order == domain.SortAsc {
+		direction = "ASC"
+	}
+	if filter.After != nil {
+		cmp := "<"
+		if filter.Order == domain.SortAsc {
+			cmp = ">"
+		}
+		query = query.Where("(created_at "+cmp+" ? OR (created_at = ? AND id "+cmp+" ?))",
+			filter.After.CreatedAt, filter.After.CreatedAt, filter.After.ID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
 	var actions []domain.UserAction
-	err := r.db.Where("user_id = ?", userID).Find(&actions).Error
+	err := query.Order("created_at " + direction + ", id " + direction).Find(&actions).Error
 	return actions, err
 }
 
-func (r *PostgresUserRepository) RecordUserAction(action *domain.UserAction) error {
-	return r.db.Create(action).Error
+func (r *PostgresUserRepository) CountUserActions(ctx context.Context, tenantID, userID uuid.UUID, q domain.ActionSummaryQuery) ([]domain.ActionCount, error) {
+	query := r.actionScope(ctx, tenantID, userID, q.Types, q.Category, q.From, q.To)
+
+	if r.db.Dialector.Name() != "postgres" {
+		var actions []domain.UserAction
+		if err := query.Select("created_at", "action").Find(&actions).Error; err != nil {
+			return nil, err
+		}
+		return countByPeriod(actions, q.Period, q.Location), nil
+	}
+
+	// date_trunc('week') начинает неделю с понедельника, как и AggregationPeriod.Start
+	var rows []struct {
+		PeriodStart time.Time
+		Action      domain.ActionType
+		Count       int64
+	}
+	err := query.
+		Select("date_trunc(?, created_at AT TIME ZONE ?) AS period_start, action, COUNT(*) AS count", string(q.Period), q.Location.String()).
+		Group("period_start, action").
+		Order("period_start, action").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]domain.ActionCount, 0, len(rows))
+	for _, row := range rows {
+		// Результат date_trunc — местное время без пояса, возвращаем ему пояс запроса
+		start := row.PeriodStart
+		counts = append(counts, domain.ActionCount{
+			PeriodStart: time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, q.Location),
+			Action:      row.Action,
+			Count:       row.Count,
+		})
+	}
+	return counts, nil
+}
+
+func (r *PostgresUserRepository) CountTenantActions(ctx context.Context, tenantID uuid.UUID, period domain.AggregationPeriod, loc *time.Location, before time.Time) ([]domain.UserActionCount, error) {
+	query := r.db.WithContext(ctx).Model(&domain.UserAction{}).Where("tenant_id = ? AND created_at < ?", tenantID, before)
+
+	if r.db.Dialector.Name() != "postgres" {
+		var actions []domain.UserAction
+		if err := query.Select("user_id", "created_at", "action").Find(&actions).Error; err != nil {
+			return nil, err
+		}
+		return countUsersByPeriod(actions, period, loc), nil
+	}
+
+	var rows []struct {
+		UserID      uuid.UUID
+		PeriodStart time.Time
+		Action      domain.ActionType
+		Count       int64
+	}
+	err := query.
+		Select("user_id, date_trunc(?, created_at AT TIME ZONE ?) AS period_start, action, COUNT(*) AS count", string(period), loc.String()).
+		Group("user_id, period_start, action").
+		Order("user_id, period_start, action").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]domain.UserActionCount, 0, len(rows))
+	for _, row := range rows {
+		start := row.PeriodStart
+		counts = append(counts, domain.UserActionCount{
+			UserID: row.UserID,
+			ActionCount: domain.ActionCount{
+				PeriodStart: time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc),
+				Action:      row.Action,
+				Count:       row.Count,
+			},
+		})
+	}
+	return counts, nil
+}
+
+func (r *PostgresUserRepository) actionScope(ctx context.Context, tenantID, userID uuid.UUID, types []domain.ActionType, category string, from, to *time.Time) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&domain.UserAction{}).Where("tenant_id = ? AND user_id = ?", tenantID, userID)
+	if len(types) > 0 {
+		query = query.Where("action IN ?", types)
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	return query
+}
+
+func (r *PostgresUserRepository) RecordUserAction(ctx context.Context, action *domain.UserAction) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(action)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrActionExists
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) ListAddresses(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.Address, error) {
+	var addresses []domain.Address
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND user_id = ?", tenantID, userID).
+		Order("is_primary DESC, created_at, id").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *PostgresUserRepository) ListPrimaryAddresses(ctx context.Context, tenantID uuid.UUID) ([]domain.Address, error) {
+	var addresses []domain.Address
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND is_primary", tenantID).Order("user_id").Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *PostgresUserRepository) FindAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) (*domain.Address, error) {
+	var address domain.Address
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, addressID).First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrAddressNotFound
+	}
+	return &address, err
+}
+
+// SaveAddress создаёт или обновляет адрес; основной адрес у пользователя может быть только один
+func (r *PostgresUserRepository) SaveAddress(ctx context.Context, address *domain.Address) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if address.IsPrimary {
+			err := tx.Model(&domain.Address{}).
+				Where("tenant_id = ? AND user_id = ? AND id <> ? AND is_primary", address.TenantID, address.UserID, address.ID).
+				Update("is_primary", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result := tx.Model(&domain.Address{}).
+			Where("tenant_id = ? AND user_id = ? AND id = ?", address.TenantID, address.UserID, address.ID).
+			Select("*").Omit("id", "tenant_id", "user_id", "created_at").
+			Updates(address)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+		return tx.Create(address).Error
+	})
+}
+
+func (r *PostgresUserRepository) DeleteAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Where("tenant_id = ? AND user_id = ? AND id = ?", tenantID, userID, addressID).Delete(&domain.Address{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAddressNotFound
+	}
+	return nil
+}
+
+func likePattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	return "%" + replacer.Replace(strings.ToLower(value)) + "%"
+}
+
+// searchTerms разбивает запрос на слова из букв и цифр, пригодные для to_tsquery
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"messaging/dedup"
+	"messaging/outbox"
+	"user-service/internal/domain"
+)
+
+type Repositories struct {
+	Users        domain.UserRepository
+	Tenants      domain.TenantRepository
+	Points       domain.PointsRepository
+	Achievements domain.AchievementRepository
+	Households   domain.HouseholdRepository
+	Collectors   domain.CollectorRepository
+	Preferences  domain.PreferencesRepository
+	Imports      domain.ImportRepository
+	Referrals    domain.ReferralRepository
+	// Неотправленные события сервиса для relay
+	Outbox outbox.Store
+	// Отметки об уже обработанных входящих сообщениях
+	Processed dedup.Store
+}
+
+func NewPostgresRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Users:        NewPostgresUserRepository(db),
+		Tenants:      NewPostgresTenantRepository(db),
+		Points:       NewPostgresPointsRepository(db),
+		Achievements: NewPostgresAchievementRepository(db),
+		Households:   NewPostgresHouseholdRepository(db),
+		Collectors:   NewPostgresCollectorRepository(db),
+		Preferences:  NewPostgresPreferencesRepository(db),
+		Imports:      NewPostgresImportRepository(db),
+		Referrals:    NewPostgresReferralRepository(db),
+		Outbox:       outbox.NewGormStore(db),
+		Processed:    dedup.NewGormStore(db),
+	}
+}
+
+func NewMemoryRepositories() Repositories {
+	events := outbox.NewMemoryStore()
+	return Repositories{
+		Users:        NewMemoryUserRepository(),
+		Tenants:      NewMemoryTenantRepository(),
+		Points:       NewMemoryPointsRepository(),
+		Achievements: NewMemoryAchievementRepository(),
+		Households:   NewMemoryHouseholdRepository(),
+		Collectors:   NewMemoryCollectorRepository(),
+		Preferences:  NewMemoryPreferencesRepository(events),
+		Imports:      NewMemoryImportRepository(),
+		Referrals:    NewMemoryReferralRepository(),
+		Outbox:       events,
+		Processed:    dedup.NewMemoryStore(),
+	}
+}
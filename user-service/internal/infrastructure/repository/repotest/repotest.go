@@ -0,0 +1,1648 @@
+// Package repotest содержит общий набор проверок, который должна проходить каждая реализация репозиториев:
+//
+//	func TestMemoryRepositories(t *testing.T) {
+//		repotest.RunSuite(t, func(t *testing.T) repository.Repositories {
+//			return repository.NewMemoryRepositories()
+//		})
+//	}
+package repotest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/repository"
+)
+
+// RunSuite запускает проверки на свежем наборе репозиториев, который возвращает newRepos
+func RunSuite(t *testing.T, newRepos func(t *testing.T) repository.Repositories) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repos repository.Repositories)
+	}{
+		{"DefaultTenantExists", testDefaultTenantExists},
+		{"CreateAndFindTenant", testCreateAndFindTenant},
+		{"CreateAndFindUser", testCreateAndFindUser},
+		{"EmailUniquePerTenant", testEmailUniquePerTenant},
+		{"UpdateUser", testUpdateUser},
+		{"FindByIdentityKey", testFindByIdentityKey},
+		{"Addresses", testAddresses},
+		{"PrimaryAddresses", testPrimaryAddresses},
+		{"UpdateVersionConflict", testUpdateVersionConflict},
+		{"CrossTenantIsolation", testCrossTenantIsolation},
+		{"UserActions", testUserActions},
+		{"ActionHistoryCursor", testActionHistoryCursor},
+		{"ActionHistoryFilters", testActionHistoryFilters},
+		{"ActionCounts", testActionCounts},
+		{"TenantActionCounts", testTenantActionCounts},
+		{"PointsLedger", testPointsLedger},
+		{"PointsHistory", testPointsHistory},
+		{"EarningRules", testEarningRules},
+		{"ActionCategory", testActionCategory},
+		{"Achievements", testAchievements},
+		{"Households", testHouseholds},
+		{"HouseholdInvitations", testHouseholdInvitations},
+		{"CollectorProfile", testCollectorProfile},
+		{"CollectorZones", testCollectorZones},
+		{"Preferences", testPreferences},
+		{"Imports", testImports},
+		{"ReferralCodes", testReferralCodes},
+		{"Referrals", testReferrals},
+		{"FindByID", testFindByID},
+		{"ListFilters", testListFilters},
+		{"ListPagination", testListPagination},
+		{"Search", testSearch},
+		{"SoftDelete", testSoftDelete},
+		{"ProcessedMessages", testProcessedMessages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepos(t))
+		})
+	}
+}
+
+func testDefaultTenantExists(t *testing.T, repos repository.Repositories) {
+	tenant, err := repos.Tenants.FindTenantBySlug(context.Background(), domain.DefaultTenantSlug)
+	if err != nil {
+		t.Fatalf("FindTenantBySlug(default): %v", err)
+	}
+	if tenant.ID == uuid.Nil {
+		t.Fatal("default tenant has no ID")
+	}
+}
+
+func testCreateAndFindTenant(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "almaty")
+
+	found, err := repos.Tenants.FindTenantBySlug(ctx, "almaty")
+	if err != nil {
+		t.Fatalf("FindTenantBySlug: %v", err)
+	}
+	if found.ID != tenant.ID {
+		t.Fatalf("FindTenantBySlug ID = %s, want %s", found.ID, tenant.ID)
+	}
+
+	if _, err := repos.Tenants.FindTenantBySlug(ctx, "missing"); !errors.Is(err, domain.ErrTenantNotFound) {
+		t.Fatalf("FindTenantBySlug(missing) error = %v, want domain.ErrTenantNotFound", err)
+	}
+
+	duplicate := &domain.Tenant{ID: uuid.New(), Slug: "almaty", Name: "Again", CreatedAt: time.Now()}
+	if err := repos.Tenants.CreateTenant(ctx, duplicate); err == nil {
+		t.Fatal("CreateTenant with duplicate slug succeeded")
+	}
+}
+
+func testCreateAndFindUser(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "shymkent")
+	user := mustCreateUser(t, repos, tenant, "resident@example.com")
+
+	found, err := repos.Users.FindByEmail(ctx, tenant.ID, "resident@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if found.ID != user.ID || found.Name != user.Name || found.TenantID != tenant.ID {
+		t.Fatalf("FindByEmail = %+v, want %+v", found, user)
+	}
+
+	if _, err := repos.Users.FindByEmail(ctx, tenant.ID, "nobody@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByEmail(missing) error = %v, want domain.ErrUserNotFound", err)
+	}
+}
+
+func testEmailUniquePerTenant(t *testing.T, repos repository.Repositories) {
+	first := mustCreateTenant(t, repos, "first")
+	second := mustCreateTenant(t, repos, "second")
+
+	mustCreateUser(t, repos, first, "shared@example.com")
+	mustCreateUser(t, repos, second, "shared@example.com")
+
+	duplicate := newUser(first, "shared@example.com")
+	if err := repos.Users.Create(context.Background(), duplicate); err == nil {
+		t.Fatal("Create with duplicate email in the same tenant succeeded")
+	}
+}
+
+func testFindByIdentityKey(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "identity")
+	other := mustCreateTenant(t, repos, "identity-other")
+
+	identityKey := uuid.New()
+	user := newUser(tenant, "linked@example.com")
+	user.IdentityKey = &identityKey
+	if err := repos.Users.Create(ctx, user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	mustCreateUser(t, repos, tenant, "unlinked@example.com")
+
+	found, err := repos.Users.FindByIdentityKey(ctx, tenant.ID, identityKey)
+	if err != nil {
+		t.Fatalf("FindByIdentityKey: %v", err)
+	}
+	if found.ID != user.ID || found.IdentityKey == nil || *found.IdentityKey != identityKey {
+		t.Fatalf("FindByIdentityKey = %+v, want user %s", found, user.ID)
+	}
+	if _, err := repos.Users.FindByIdentityKey(ctx, other.ID, identityKey); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByIdentityKey from another tenant error = %v, want ErrUserNotFound", err)
+	}
+
+	duplicate := newUser(tenant, "duplicate@example.com")
+	duplicate.IdentityKey = &identityKey
+	if err := repos.Users.Create(ctx, duplicate); err == nil {
+		t.Fatal("Create with a duplicate identity key succeeded")
+	}
+
+	found.AccountVersion = 3
+	if err := repos.Users.Update(ctx, found); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := repos.Users.Delete(ctx, tenant.ID, user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repos.Users.FindByIdentityKey(ctx, tenant.ID, identityKey); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByIdentityKey after Delete error = %v, want ErrUserNotFound", err)
+	}
+
+	// Версия учётной записи остаётся видна и после удаления профиля
+	if version, err := repos.Users.AccountVersion(ctx, tenant.ID, identityKey); err != nil || version != 3 {
+		t.Fatalf("AccountVersion after Delete = %d, %v, want 3", version, err)
+	}
+	if version, err := repos.Users.AccountVersion(ctx, other.ID, identityKey); err != nil || version != 0 {
+		t.Fatalf("AccountVersion from another tenant = %d, %v, want 0", version, err)
+	}
+}
+
+func testUpdateUser(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "update")
+	user := mustCreateUser(t, repos, tenant, "update@example.com")
+
+	user.Name = "Renamed"
+	if err := repos.Users.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	found, err := repos.Users.FindByEmail(ctx, tenant.ID, "update@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if found.Name != "Renamed" {
+		t.Fatalf("Name after update = %q, want Renamed", found.Name)
+	}
+
+	missing := newUser(tenant, "missing@example.com")
+	if err := repos.Users.Update(ctx, missing); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Update(missing) error = %v, want domain.ErrUserNotFound", err)
+	}
+}
+
+func testUpdateVersionConflict(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "versioned")
+	user := mustCreateUser(t, repos, tenant, "versioned@example.com")
+
+	stale := *user
+	user.Name = "First"
+	if err := repos.Users.Update(ctx, user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if user.Version != stale.Version+1 {
+		t.Fatalf("Version after update = %d, want %d", user.Version, stale.Version+1)
+	}
+
+	stale.Name = "Second"
+	if err := repos.Users.Update(ctx, &stale); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("Update(stale) error = %v, want domain.ErrVersionConflict", err)
+	}
+
+	found, err := repos.Users.FindByID(ctx, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Name != "First" || found.Version != user.Version {
+		t.Fatalf("stored user = (%q, v%d), want (First, v%d)", found.Name, found.Version, user.Version)
+	}
+}
+
+func testAddresses(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "addresses")
+	user := mustCreateUser(t, repos, tenant, "addresses@example.com")
+	other := mustCreateUser(t, repos, tenant, "neighbour@example.com")
+
+	home := mustSaveAddress(t, repos, user, "Abay", true)
+	work := mustSaveAddress(t, repos, user, "Dostyk", false)
+	mustSaveAddress(t, repos, other, "Tole bi", true)
+
+	addresses, err := repos.Users.ListAddresses(ctx, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("ListAddresses: %v", err)
+	}
+	if len(addresses) != 2 || addresses[0].ID != home.ID || !addresses[0].IsPrimary {
+		t.Fatalf("ListAddresses = %+v, want the primary home address first", addresses)
+	}
+	if addresses[0].Latitude == nil || *addresses[0].Latitude != *home.Latitude {
+		t.Fatalf("stored coordinates = %v, want %v", addresses[0].Latitude, *home.Latitude)
+	}
+
+	// Новый основной адрес снимает отметку с прежнего
+	work.IsPrimary = true
+	work.House = "5"
+	if err := repos.Users.SaveAddress(ctx, work); err != nil {
+		t.Fatalf("SaveAddress(update): %v", err)
+	}
+	found, err := repos.Users.FindAddress(ctx, tenant.ID, user.ID, home.ID)
+	if err != nil {
+		t.Fatalf("FindAddress: %v", err)
+	}
+	if found.IsPrimary {
+		t.Fatalf("home address is still primary after work became primary")
+	}
+	found, err = repos.Users.FindAddress(ctx, tenant.ID, user.ID, work.ID)
+	if err != nil {
+		t.Fatalf("FindAddress: %v", err)
+	}
+	if !found.IsPrimary || found.House != "5" {
+		t.Fatalf("work address = %+v, want primary with house 5", found)
+	}
+
+	if _, err := repos.Users.FindAddress(ctx, tenant.ID, other.ID, home.ID); !errors.Is(err, domain.ErrAddressNotFound) {
+		t.Fatalf("FindAddress(another user) error = %v, want domain.ErrAddressNotFound", err)
+	}
+	if err := repos.Users.DeleteAddress(ctx, tenant.ID, user.ID, home.ID); err != nil {
+		t.Fatalf("DeleteAddress: %v", err)
+	}
+	if err := repos.Users.DeleteAddress(ctx, tenant.ID, user.ID, home.ID); !errors.Is(err, domain.ErrAddressNotFound) {
+		t.Fatalf("DeleteAddress(again) error = %v, want domain.ErrAddressNotFound", err)
+	}
+}
+
+func testPrimaryAddresses(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "primary-addresses")
+	other := mustCreateTenant(t, repos, "primary-addresses-other")
+	user := mustCreateUser(t, repos, tenant, "primary@example.com")
+	stranger := mustCreateUser(t, repos, other, "primary@example.com")
+
+	home := mustSaveAddress(t, repos, user, "Abay", true)
+	mustSaveAddress(t, repos, user, "Dostyk", false)
+	mustSaveAddress(t, repos, stranger, "Tole bi", true)
+
+	addresses, err := repos.Users.ListPrimaryAddresses(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("ListPrimaryAddresses: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].ID != home.ID {
+		t.Fatalf("ListPrimaryAddresses = %+v, want only the primary address of the tenant user", addresses)
+	}
+}
+
+func testCrossTenantIsolation(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, repos, "own")
+	other := mustCreateTenant(t, repos, "other")
+	user := mustCreateUser(t, repos, own, "private@example.com")
+
+	if _, err := repos.Users.FindByEmail(ctx, other.ID, user.Email); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByEmail from another tenant error = %v, want domain.ErrUserNotFound", err)
+	}
+
+	hijack := *user
+	hijack.TenantID = other.ID
+	hijack.Name = "Hijacked"
+	if err := repos.Users.Update(ctx, &hijack); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Update from another tenant error = %v, want domain.ErrUserNotFound", err)
+	}
+
+	found, err := repos.Users.FindByEmail(ctx, own.ID, user.Email)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	if found.Name == "Hijacked" {
+		t.Fatal("Update from another tenant changed the user")
+	}
+}
+
+func testUserActions(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, repos, "actions")
+	other := mustCreateTenant(t, repos, "elsewhere")
+	user := mustCreateUser(t, repos, own, "actions@example.com")
+
+	action := &domain.UserAction{ID: uuid.New(), TenantID: own.ID, UserID: user.ID, Action: domain.ActionWasteSorted, Source: "sorting-station", CreatedAt: time.Now()}
+	if err := repos.Users.RecordUserAction(ctx, action); err != nil {
+		t.Fatalf("RecordUserAction: %v", err)
+	}
+
+	duplicate := *action
+	if err := repos.Users.RecordUserAction(ctx, &duplicate); !errors.Is(err, domain.ErrActionExists) {
+		t.Fatalf("RecordUserAction(duplicate) error = %v, want domain.ErrActionExists", err)
+	}
+
+	actions, err := repos.Users.GetUserActions(ctx, own.ID, user.ID, domain.ActionFilter{})
+	if err != nil {
+		t.Fatalf("GetUserActions: %v", err)
+	}
+	if len(actions) != 1 || actions[0].ID != action.ID || actions[0].Source != "sorting-station" {
+		t.Fatalf("GetUserActions = %+v, want the recorded action", actions)
+	}
+
+	actions, err = repos.Users.GetUserActions(ctx, other.ID, user.ID, domain.ActionFilter{})
+	if err != nil {
+		t.Fatalf("GetUserActions from another tenant: %v", err)
+	}
+	if len(actions) != 0 {
+		t.Fatalf("GetUserActions from another tenant returned %d actions", len(actions))
+	}
+}
+
+func testActionHistoryCursor(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "history")
+	user := mustCreateUser(t, repos, tenant, "history@example.com")
+
+	// Два действия в одну и ту же секунду проверяют разбор равных created_at по id
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	var want []uuid.UUID
+	for _, at := range []time.Time{base, base.Add(time.Hour), base.Add(time.Hour), base.Add(2 * time.Hour), base.Add(3 * time.Hour)} {
+		action := mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, at)
+		want = append(want, action.ID)
+	}
+	if want[1].String() > want[2].String() {
+		want[1], want[2] = want[2], want[1]
+	}
+
+	var got []uuid.UUID
+	filter := domain.ActionFilter{Order: domain.SortAsc, Limit: 2}
+	for page := 0; page < 5; page++ {
+		actions, err := repos.Users.GetUserActions(ctx, tenant.ID, user.ID, filter)
+		if err != nil {
+			t.Fatalf("GetUserActions: %v", err)
+		}
+		if len(actions) == 0 {
+			break
+		}
+		for _, action := range actions {
+			got = append(got, action.ID)
+		}
+		last := actions[len(actions)-1]
+		filter.After = &domain.ActionCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	assertIDs(t, "ascending pages", got, want)
+
+	actions, err := repos.Users.GetUserActions(ctx, tenant.ID, user.ID, domain.ActionFilter{
+		Order: domain.SortDesc,
+		After: &domain.ActionCursor{CreatedAt: base.Add(2 * time.Hour), ID: want[3]},
+	})
+	if err != nil {
+		t.Fatalf("GetUserActions(desc): %v", err)
+	}
+	got = nil
+	for _, action := range actions {
+		got = append(got, action.ID)
+	}
+	assertIDs(t, "descending after cursor", got, []uuid.UUID{want[2], want[1], want[0]})
+}
+
+func testActionHistoryFilters(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "history-filters")
+	user := mustCreateUser(t, repos, tenant, "filters@example.com")
+
+	base := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	sorted := mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, base)
+	pickup := mustRecordAction(t, repos, tenant, user, domain.ActionPickupRequested, base.Add(24*time.Hour))
+	mustRecordAction(t, repos, tenant, user, domain.ActionReportFiled, base.Add(48*time.Hour))
+
+	actions, err := repos.Users.GetUserActions(ctx, tenant.ID, user.ID, domain.ActionFilter{
+		Types: []domain.ActionType{domain.ActionWasteSorted, domain.ActionPickupRequested},
+	})
+	if err != nil {
+		t.Fatalf("GetUserActions(types): %v", err)
+	}
+	assertIDs(t, "type filter", actionIDs(actions), []uuid.UUID{pickup.ID, sorted.ID})
+
+	from, to := base.Add(time.Hour), base.Add(48*time.Hour)
+	actions, err = repos.Users.GetUserActions(ctx, tenant.ID, user.ID, domain.ActionFilter{From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("GetUserActions(range): %v", err)
+	}
+	assertIDs(t, "date range", actionIDs(actions), []uuid.UUID{pickup.ID})
+}
+
+func testActionCounts(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "counts")
+	user := mustCreateUser(t, repos, tenant, "counts@example.com")
+
+	// 2025-03-02 — воскресенье; 23:30 UTC в Алматы (UTC+5) уже понедельник 3 марта
+	mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC))
+	mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, time.Date(2025, 3, 2, 23, 30, 0, 0, time.UTC))
+	mustRecordAction(t, repos, tenant, user, domain.ActionPointVisited, time.Date(2025, 3, 4, 9, 0, 0, 0, time.UTC))
+	mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC))
+
+	almaty := mustLoadLocation(t, "Asia/Almaty")
+	counts, err := repos.Users.CountUserActions(ctx, tenant.ID, user.ID, domain.ActionSummaryQuery{
+		Period:   domain.PeriodWeek,
+		Location: almaty,
+	})
+	if err != nil {
+		t.Fatalf("CountUserActions(week): %v", err)
+	}
+
+	want := []domain.ActionCount{
+		{PeriodStart: time.Date(2025, 2, 24, 0, 0, 0, 0, almaty), Action: domain.ActionWasteSorted, Count: 1},
+		{PeriodStart: time.Date(2025, 3, 3, 0, 0, 0, 0, almaty), Action: domain.ActionPointVisited, Count: 1},
+		{PeriodStart: time.Date(2025, 3, 3, 0, 0, 0, 0, almaty), Action: domain.ActionWasteSorted, Count: 1},
+		{PeriodStart: time.Date(2025, 3, 31, 0, 0, 0, 0, almaty), Action: domain.ActionWasteSorted, Count: 1},
+	}
+	assertCounts(t, "weeks", counts, want)
+
+	counts, err = repos.Users.CountUserActions(ctx, tenant.ID, user.ID, domain.ActionSummaryQuery{
+		Types:    []domain.ActionType{domain.ActionWasteSorted},
+		Period:   domain.PeriodMonth,
+		Location: time.UTC,
+	})
+	if err != nil {
+		t.Fatalf("CountUserActions(month): %v", err)
+	}
+	assertCounts(t, "months", counts, []domain.ActionCount{
+		{PeriodStart: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Action: domain.ActionWasteSorted, Count: 2},
+		{PeriodStart: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), Action: domain.ActionWasteSorted, Count: 1},
+	})
+}
+
+// mustLoadLocation берёт пояс по имени, как сервер: Postgres получает имя пояса в AT TIME ZONE,
+// а имя вида "UTC+5" он читает по POSIX, то есть как UTC-5
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %v", name, err)
+	}
+	return loc
+}
+
+func testTenantActionCounts(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "tenant-counts")
+	other := mustCreateTenant(t, repos, "tenant-counts-other")
+	first := mustCreateUser(t, repos, tenant, "first@example.com")
+	second := mustCreateUser(t, repos, tenant, "second@example.com")
+	stranger := mustCreateUser(t, repos, other, "stranger@example.com")
+
+	almaty := mustLoadLocation(t, "Asia/Almaty")
+	mustRecordAction(t, repos, tenant, first, domain.ActionWasteSorted, time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC))
+	mustRecordAction(t, repos, tenant, first, domain.ActionWasteSorted, time.Date(2025, 3, 2, 23, 30, 0, 0, time.UTC))
+	mustRecordAction(t, repos, tenant, second, domain.ActionPointVisited, time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC))
+	mustRecordAction(t, repos, other, stranger, domain.ActionWasteSorted, time.Date(2025, 3, 2, 12, 0, 0, 0, time.UTC))
+	// Действие после границы before не учитывается
+	mustRecordAction(t, repos, tenant, second, domain.ActionPointVisited, time.Date(2025, 3, 5, 12, 0, 0, 0, time.UTC))
+
+	before := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	counts, err := repos.Users.CountTenantActions(ctx, tenant.ID, domain.PeriodDay, almaty, before)
+	if err != nil {
+		t.Fatalf("CountTenantActions: %v", err)
+	}
+
+	got := map[uuid.UUID][]domain.ActionCount{}
+	for _, count := range counts {
+		got[count.UserID] = append(got[count.UserID], count.ActionCount)
+	}
+	if len(got) != 2 {
+		t.Fatalf("CountTenantActions returned users %v, want only the two users of the tenant", got)
+	}
+	assertCounts(t, "first", got[first.ID], []domain.ActionCount{
+		{PeriodStart: time.Date(2025, 3, 2, 0, 0, 0, 0, almaty), Action: domain.ActionWasteSorted, Count: 1},
+		{PeriodStart: time.Date(2025, 3, 3, 0, 0, 0, 0, almaty), Action: domain.ActionWasteSorted, Count: 1},
+	})
+	assertCounts(t, "second", got[second.ID], []domain.ActionCount{
+		{PeriodStart: time.Date(2025, 3, 2, 0, 0, 0, 0, almaty), Action: domain.ActionPointVisited, Count: 1},
+	})
+}
+
+func testPointsLedger(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "ledger")
+	other := mustCreateTenant(t, repos, "ledger-other")
+	account := domain.UserAccount(uuid.New())
+
+	actionID := uuid.New()
+	mustTransfer(t, repos, tenant, &actionID, account, 10)
+	mustTransfer(t, repos, tenant, nil, account, -3)
+	mustTransfer(t, repos, other, nil, account, 100)
+
+	balance, err := repos.Points.Balance(ctx, tenant.ID, account)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	if balance != 7 {
+		t.Fatalf("Balance = %d, want 7", balance)
+	}
+	rewards, err := repos.Points.Balance(ctx, tenant.ID, domain.AccountRewards)
+	if err != nil {
+		t.Fatalf("Balance(rewards): %v", err)
+	}
+	if rewards != -7 {
+		t.Fatalf("rewards balance = %d, want -7", rewards)
+	}
+
+	duplicate := &domain.PointsTransaction{ID: uuid.New(), TenantID: tenant.ID, Kind: domain.PointsEarned, ActionID: &actionID, CreatedAt: time.Now()}
+	err = repos.Points.CreateTransaction(ctx, duplicate, pointsEntries(duplicate, account, 10))
+	if !errors.Is(err, domain.ErrPointsAlreadyCredited) {
+		t.Fatalf("CreateTransaction(duplicate action) error = %v, want domain.ErrPointsAlreadyCredited", err)
+	}
+
+	unbalanced := &domain.PointsTransaction{ID: uuid.New(), TenantID: tenant.ID, Kind: domain.PointsAdjusted, CreatedAt: time.Now()}
+	entries := pointsEntries(unbalanced, account, 5)
+	entries[0].Amount = -4
+	if err := repos.Points.CreateTransaction(ctx, unbalanced, entries); !errors.Is(err, domain.ErrUnbalancedTransaction) {
+		t.Fatalf("CreateTransaction(unbalanced) error = %v, want domain.ErrUnbalancedTransaction", err)
+	}
+
+	balance, err = repos.Points.Balance(ctx, tenant.ID, account)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	if balance != 7 {
+		t.Fatalf("Balance after rejected transactions = %d, want 7", balance)
+	}
+
+	overdraft := &domain.PointsTransaction{ID: uuid.New(), TenantID: tenant.ID, Kind: domain.PointsAdjusted, CreatedAt: time.Now()}
+	if err := repos.Points.CreateTransaction(ctx, overdraft, pointsEntries(overdraft, account, -8)); !errors.Is(err, domain.ErrInsufficientPoints) {
+		t.Fatalf("CreateTransaction(overdraft) error = %v, want domain.ErrInsufficientPoints", err)
+	}
+
+	// Из нескольких одновременных списаний всего баланса проходит не больше одного
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			debit := &domain.PointsTransaction{ID: uuid.New(), TenantID: tenant.ID, Kind: domain.PointsAdjusted, CreatedAt: time.Now()}
+			if repos.Points.CreateTransaction(ctx, debit, pointsEntries(debit, account, -7)) == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	balance, err = repos.Points.Balance(ctx, tenant.ID, account)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	if succeeded.Load() > 1 || balance < 0 {
+		t.Fatalf("concurrent debits: %d succeeded, balance %d; want at most one and a non-negative balance", succeeded.Load(), balance)
+	}
+}
+
+func testPointsHistory(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "points-history")
+	account := domain.UserAccount(uuid.New())
+
+	actionID := uuid.New()
+	earned := mustTransfer(t, repos, tenant, &actionID, account, 10)
+	adjusted := mustTransfer(t, repos, tenant, nil, account, 2)
+
+	items, total, err := repos.Points.History(ctx, tenant.ID, account, 10, 0)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if total != 2 || len(items) != 2 {
+		t.Fatalf("History = %d items (total %d), want 2", len(items), total)
+	}
+	if items[0].TransactionID != adjusted.ID || items[0].Amount != 2 || items[0].Kind != domain.PointsAdjusted || items[0].ActionID != nil {
+		t.Fatalf("History[0] = %+v, want the adjustment", items[0])
+	}
+	if items[1].TransactionID != earned.ID || items[1].ActionID == nil || *items[1].ActionID != actionID {
+		t.Fatalf("History[1] = %+v, want the earned transaction", items[1])
+	}
+
+	items, total, err = repos.Points.History(ctx, tenant.ID, account, 1, 1)
+	if err != nil {
+		t.Fatalf("History(page 2): %v", err)
+	}
+	if total != 2 || len(items) != 1 || items[0].TransactionID != earned.ID {
+		t.Fatalf("History(page 2) = %+v (total %d), want the earned transaction", items, total)
+	}
+}
+
+func testEarningRules(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "rules")
+
+	rule := &domain.EarningRule{TenantID: tenant.ID, Action: domain.ActionWasteSorted, Points: 25, UpdatedAt: time.Now()}
+	if err := repos.Points.SaveRule(ctx, rule); err != nil {
+		t.Fatalf("SaveRule: %v", err)
+	}
+	rule.Points = 30
+	if err := repos.Points.SaveRule(ctx, rule); err != nil {
+		t.Fatalf("SaveRule(update): %v", err)
+	}
+
+	rules, err := repos.Points.ListRules(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("ListRules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Action != domain.ActionWasteSorted || rules[0].Points != 30 {
+		t.Fatalf("ListRules = %+v, want one waste_sorted rule worth 30", rules)
+	}
+
+	rules, err = repos.Points.ListRules(ctx, mustCreateTenant(t, repos, "rules-other").ID)
+	if err != nil {
+		t.Fatalf("ListRules(other tenant): %v", err)
+	}
+	if len(rules) != 0 {
+		t.Fatalf("ListRules(other tenant) = %+v, want none", rules)
+	}
+}
+
+func testActionCategory(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "categories")
+	user := mustCreateUser(t, repos, tenant, "categories@example.com")
+
+	at := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	plastic := &domain.UserAction{ID: uuid.New(), TenantID: tenant.ID, UserID: user.ID, Action: domain.ActionWasteSorted, Source: "repotest", Category: "plastic", CreatedAt: at}
+	if err := repos.Users.RecordUserAction(ctx, plastic); err != nil {
+		t.Fatalf("RecordUserAction: %v", err)
+	}
+	mustRecordAction(t, repos, tenant, user, domain.ActionWasteSorted, at.Add(time.Hour))
+
+	actions, err := repos.Users.GetUserActions(ctx, tenant.ID, user.ID, domain.ActionFilter{Category: "plastic"})
+	if err != nil {
+		t.Fatalf("GetUserActions(category): %v", err)
+	}
+	assertIDs(t, "category filter", actionIDs(actions), []uuid.UUID{plastic.ID})
+
+	counts, err := repos.Users.CountUserActions(ctx, tenant.ID, user.ID, domain.ActionSummaryQuery{
+		Category: "plastic",
+		Period:   domain.PeriodDay,
+		Location: time.UTC,
+	})
+	if err != nil {
+		t.Fatalf("CountUserActions(category): %v", err)
+	}
+	assertCounts(t, "category counts", counts, []domain.ActionCount{
+		{PeriodStart: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), Action: domain.ActionWasteSorted, Count: 1},
+	})
+}
+
+func testAchievements(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "achievements")
+	userID := uuid.New()
+
+	first := &domain.UserAchievement{TenantID: tenant.ID, UserID: userID, Code: "first_sort", EarnedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	second := &domain.UserAchievement{TenantID: tenant.ID, UserID: userID, Code: "explorer_10", EarnedAt: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)}
+	for _, achievement := range []*domain.UserAchievement{second, first} {
+		if err := repos.Achievements.AwardAchievement(ctx, achievement); err != nil {
+			t.Fatalf("AwardAchievement(%s): %v", achievement.Code, err)
+		}
+	}
+
+	again := *first
+	again.EarnedAt = time.Now()
+	if err := repos.Achievements.AwardAchievement(ctx, &again); !errors.Is(err, domain.ErrAchievementAwarded) {
+		t.Fatalf("AwardAchievement(again) error = %v, want domain.ErrAchievementAwarded", err)
+	}
+
+	achievements, err := repos.Achievements.ListUserAchievements(ctx, tenant.ID, userID)
+	if err != nil {
+		t.Fatalf("ListUserAchievements: %v", err)
+	}
+	if len(achievements) != 2 || achievements[0].Code != "first_sort" || achievements[1].Code != "explorer_10" {
+		t.Fatalf("ListUserAchievements = %+v, want first_sort then explorer_10", achievements)
+	}
+	if !achievements[0].EarnedAt.Equal(first.EarnedAt) {
+		t.Fatalf("first_sort earned at %v, want the original award time %v", achievements[0].EarnedAt, first.EarnedAt)
+	}
+}
+
+func testHouseholds(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "households")
+	owner := mustCreateUser(t, repos, tenant, "owner@example.com")
+	member := mustCreateUser(t, repos, tenant, "member@example.com")
+	address := mustSaveAddress(t, repos, owner, "Abay Ave", true)
+
+	household := mustCreateHousehold(t, repos, owner)
+	household.AddressID = &address.ID
+	household.Settings = domain.HouseholdSettings{PickupDay: "friday", Bins: []string{"plastic", "paper"}}
+	if err := repos.Households.UpdateHousehold(ctx, household); err != nil {
+		t.Fatalf("UpdateHousehold: %v", err)
+	}
+	found, err := repos.Households.FindHousehold(ctx, tenant.ID, household.ID)
+	if err != nil {
+		t.Fatalf("FindHousehold: %v", err)
+	}
+	if found.AddressID == nil || *found.AddressID != address.ID || found.Settings.PickupDay != "friday" || len(found.Settings.Bins) != 2 {
+		t.Fatalf("FindHousehold = %+v, want the updated address and settings", found)
+	}
+
+	second := &domain.Household{ID: uuid.New(), TenantID: tenant.ID, Name: "Second"}
+	ownerAgain := &domain.HouseholdMembership{HouseholdID: second.ID, UserID: owner.ID, TenantID: tenant.ID, Role: domain.HouseholdOwner, JoinedAt: time.Now()}
+	if err := repos.Households.CreateHousehold(ctx, second, ownerAgain); !errors.Is(err, domain.ErrAlreadyInHousehold) {
+		t.Fatalf("CreateHousehold(owner again) error = %v, want domain.ErrAlreadyInHousehold", err)
+	}
+
+	invitation := mustCreateInvitation(t, repos, household, owner, "JOINCODE")
+	now := time.Now().UTC()
+	invitation.AcceptedBy, invitation.AcceptedAt = &member.ID, &now
+	joined := &domain.HouseholdMembership{HouseholdID: household.ID, UserID: member.ID, TenantID: tenant.ID, Role: domain.HouseholdMember, JoinedAt: now.Add(time.Second)}
+	if err := repos.Households.AcceptInvitation(ctx, invitation, joined); err != nil {
+		t.Fatalf("AcceptInvitation: %v", err)
+	}
+
+	if err := repos.Households.TransferOwnership(ctx, tenant.ID, household.ID, owner.ID, member.ID); err != nil {
+		t.Fatalf("TransferOwnership: %v", err)
+	}
+	members, err := repos.Households.ListMembers(ctx, tenant.ID, household.ID)
+	if err != nil {
+		t.Fatalf("ListMembers: %v", err)
+	}
+	if len(members) != 2 || members[0].UserID != owner.ID || members[0].Role != domain.HouseholdMember || members[1].Role != domain.HouseholdOwner {
+		t.Fatalf("ListMembers = %+v, want the former owner demoted and the member promoted", members)
+	}
+
+	if err := repos.Households.RemoveMember(ctx, tenant.ID, household.ID, owner.ID); err != nil {
+		t.Fatalf("RemoveMember: %v", err)
+	}
+	if _, err := repos.Households.FindMembership(ctx, tenant.ID, owner.ID); !errors.Is(err, domain.ErrNotHouseholdMember) {
+		t.Fatalf("FindMembership(removed) error = %v, want domain.ErrNotHouseholdMember", err)
+	}
+
+	if err := repos.Households.DeleteHousehold(ctx, tenant.ID, household.ID); err != nil {
+		t.Fatalf("DeleteHousehold: %v", err)
+	}
+	if _, err := repos.Households.FindHousehold(ctx, tenant.ID, household.ID); !errors.Is(err, domain.ErrHouseholdNotFound) {
+		t.Fatalf("FindHousehold(deleted) error = %v, want domain.ErrHouseholdNotFound", err)
+	}
+	if _, err := repos.Households.FindMembership(ctx, tenant.ID, member.ID); !errors.Is(err, domain.ErrNotHouseholdMember) {
+		t.Fatalf("FindMembership(after delete) error = %v, want domain.ErrNotHouseholdMember", err)
+	}
+}
+
+func testHouseholdInvitations(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "invitations")
+	other := mustCreateTenant(t, repos, "invitations-other")
+	owner := mustCreateUser(t, repos, tenant, "owner@example.com")
+	first := mustCreateUser(t, repos, tenant, "first@example.com")
+	second := mustCreateUser(t, repos, tenant, "second@example.com")
+
+	household := mustCreateHousehold(t, repos, owner)
+	invitation := mustCreateInvitation(t, repos, household, owner, "ONCEONLY")
+
+	if _, err := repos.Households.FindInvitationByCode(ctx, other.ID, "ONCEONLY"); !errors.Is(err, domain.ErrInvitationNotFound) {
+		t.Fatalf("FindInvitationByCode(other tenant) error = %v, want domain.ErrInvitationNotFound", err)
+	}
+	found, err := repos.Households.FindInvitationByCode(ctx, tenant.ID, "ONCEONLY")
+	if err != nil {
+		t.Fatalf("FindInvitationByCode: %v", err)
+	}
+	if found.ID != invitation.ID || found.AcceptedAt != nil {
+		t.Fatalf("FindInvitationByCode = %+v, want the pending invitation %s", found, invitation.ID)
+	}
+
+	for i, user := range []*domain.User{first, second} {
+		now := time.Now().UTC()
+		accepting := *invitation
+		accepting.AcceptedBy, accepting.AcceptedAt = &user.ID, &now
+		member := &domain.HouseholdMembership{HouseholdID: household.ID, UserID: user.ID, TenantID: tenant.ID, Role: domain.HouseholdMember, JoinedAt: now}
+		err := repos.Households.AcceptInvitation(ctx, &accepting, member)
+		if i == 0 && err != nil {
+			t.Fatalf("AcceptInvitation(first): %v", err)
+		}
+		if i == 1 && !errors.Is(err, domain.ErrInvitationInvalid) {
+			t.Fatalf("AcceptInvitation(second) error = %v, want domain.ErrInvitationInvalid", err)
+		}
+	}
+
+	members, err := repos.Households.ListMembers(ctx, tenant.ID, household.ID)
+	if err != nil {
+		t.Fatalf("ListMembers: %v", err)
+	}
+	if len(members) != 2 {
+		t.Fatalf("ListMembers returned %d members, want owner and the first invitee", len(members))
+	}
+}
+
+func testCollectorProfile(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "collectors")
+	collector := mustCreateUser(t, repos, tenant, "driver@example.com")
+
+	if _, err := repos.Collectors.FindProfile(ctx, tenant.ID, collector.ID); !errors.Is(err, domain.ErrCollectorProfileNotFound) {
+		t.Fatalf("FindProfile(missing) error = %v, want domain.ErrCollectorProfileNotFound", err)
+	}
+
+	profile := &domain.CollectorProfile{
+		UserID:       collector.ID,
+		TenantID:     tenant.ID,
+		Vehicle:      domain.Vehicle{Type: domain.VehicleVan, Plate: "123ABC02", CapacityKg: 800},
+		Availability: domain.CollectorAvailable,
+		UpdatedAt:    time.Now().UTC(),
+	}
+	if err := repos.Collectors.SaveProfile(ctx, profile); err != nil {
+		t.Fatalf("SaveProfile: %v", err)
+	}
+	profile.Availability = domain.CollectorOnLeave
+	profile.Vehicle = domain.Vehicle{}
+	if err := repos.Collectors.SaveProfile(ctx, profile); err != nil {
+		t.Fatalf("SaveProfile(again): %v", err)
+	}
+	found, err := repos.Collectors.FindProfile(ctx, tenant.ID, collector.ID)
+	if err != nil {
+		t.Fatalf("FindProfile: %v", err)
+	}
+	if found.Availability != domain.CollectorOnLeave || found.Vehicle.Type != "" || found.Vehicle.CapacityKg != 0 {
+		t.Fatalf("FindProfile = %+v, want the second save to replace the first", found)
+	}
+
+	shift := func(weekday time.Weekday, starts, ends string) domain.CollectorShift {
+		return domain.CollectorShift{ID: uuid.New(), TenantID: tenant.ID, UserID: collector.ID, Weekday: weekday, Starts: starts, Ends: ends}
+	}
+	if err := repos.Collectors.ReplaceShifts(ctx, tenant.ID, collector.ID, []domain.CollectorShift{shift(time.Monday, "08:00", "12:00")}); err != nil {
+		t.Fatalf("ReplaceShifts: %v", err)
+	}
+	replacement := []domain.CollectorShift{shift(time.Tuesday, "14:00", "18:00"), shift(time.Tuesday, "08:00", "12:00")}
+	if err := repos.Collectors.ReplaceShifts(ctx, tenant.ID, collector.ID, replacement); err != nil {
+		t.Fatalf("ReplaceShifts(again): %v", err)
+	}
+	shifts, err := repos.Collectors.ListShifts(ctx, tenant.ID, collector.ID)
+	if err != nil {
+		t.Fatalf("ListShifts: %v", err)
+	}
+	if len(shifts) != 2 || shifts[0].Starts != "08:00" || shifts[1].Starts != "14:00" {
+		t.Fatalf("ListShifts = %+v, want only the replacement ordered by start", shifts)
+	}
+
+	certification := &domain.CollectorCertification{
+		ID:       uuid.New(),
+		TenantID: tenant.ID,
+		UserID:   collector.ID,
+		Hazard:   domain.HazardBatteries,
+		Number:   "KZ-001",
+		IssuedAt: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+	if err := repos.Collectors.SaveCertification(ctx, certification); err != nil {
+		t.Fatalf("SaveCertification: %v", err)
+	}
+	certifications, err := repos.Collectors.ListCertifications(ctx, tenant.ID, collector.ID)
+	if err != nil {
+		t.Fatalf("ListCertifications: %v", err)
+	}
+	if len(certifications) != 1 || certifications[0].Number != "KZ-001" {
+		t.Fatalf("ListCertifications = %+v, want the saved certification", certifications)
+	}
+	if err := repos.Collectors.DeleteCertification(ctx, tenant.ID, collector.ID, certification.ID); err != nil {
+		t.Fatalf("DeleteCertification: %v", err)
+	}
+	if err := repos.Collectors.DeleteCertification(ctx, tenant.ID, collector.ID, certification.ID); !errors.Is(err, domain.ErrCertificationNotFound) {
+		t.Fatalf("DeleteCertification(again) error = %v, want domain.ErrCertificationNotFound", err)
+	}
+}
+
+func testCollectorZones(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "zones")
+	other := mustCreateTenant(t, repos, "zones-other")
+	north := mustCreateUser(t, repos, tenant, "north@example.com")
+	south := mustCreateUser(t, repos, tenant, "south@example.com")
+	stranger := mustCreateUser(t, repos, other, "north@example.com")
+
+	northZone := mustSaveZone(t, repos, north, "North", 43.25, 43.30)
+	mustSaveZone(t, repos, south, "South", 43.20, 43.25)
+	mustSaveZone(t, repos, stranger, "Elsewhere", 43.20, 43.30)
+
+	covering, err := repos.Collectors.ZonesCovering(ctx, tenant.ID, domain.GeoPoint{Latitude: 43.27, Longitude: 76.90})
+	if err != nil {
+		t.Fatalf("ZonesCovering: %v", err)
+	}
+	if len(covering) != 1 || covering[0].ID != northZone.ID {
+		t.Fatalf("ZonesCovering = %+v, want only the north zone of this tenant", covering)
+	}
+
+	tenantZones, err := repos.Collectors.ListTenantZones(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("ListTenantZones: %v", err)
+	}
+	if len(tenantZones) != 2 || tenantZones[0].TenantID != tenant.ID || tenantZones[1].TenantID != tenant.ID {
+		t.Fatalf("ListTenantZones = %+v, want the north and south zones of this tenant", tenantZones)
+	}
+
+	northZone.Name = "North-East"
+	northZone.Boundary = rectangle(43.25, 43.30, 76.95, 77.00)
+	if err := repos.Collectors.SaveZone(ctx, northZone); err != nil {
+		t.Fatalf("SaveZone(update): %v", err)
+	}
+	if covering, err = repos.Collectors.ZonesCovering(ctx, tenant.ID, domain.GeoPoint{Latitude: 43.27, Longitude: 76.90}); err != nil || len(covering) != 0 {
+		t.Fatalf("ZonesCovering after moving the zone = %+v, %v, want none", covering, err)
+	}
+
+	zones, err := repos.Collectors.ListZones(ctx, tenant.ID, north.ID)
+	if err != nil {
+		t.Fatalf("ListZones: %v", err)
+	}
+	if len(zones) != 1 || zones[0].Name != "North-East" || len(zones[0].Boundary.Coordinates) != 1 {
+		t.Fatalf("ListZones = %+v, want the updated zone", zones)
+	}
+
+	if err := repos.Collectors.DeleteZone(ctx, tenant.ID, south.ID, northZone.ID); !errors.Is(err, domain.ErrZoneNotFound) {
+		t.Fatalf("DeleteZone(other collector) error = %v, want domain.ErrZoneNotFound", err)
+	}
+	if err := repos.Collectors.DeleteZone(ctx, tenant.ID, north.ID, northZone.ID); err != nil {
+		t.Fatalf("DeleteZone: %v", err)
+	}
+}
+
+func testPreferences(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "preferences")
+	other := mustCreateTenant(t, repos, "preferences-other")
+	user := mustCreateUser(t, repos, tenant, "prefs@example.com")
+
+	if _, err := repos.Preferences.FindPreferences(ctx, tenant.ID, user.ID); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Fatalf("FindPreferences(missing) error = %v, want domain.ErrPreferencesNotFound", err)
+	}
+
+	saved := &domain.UserPreferences{UserID: user.ID, TenantID: tenant.ID, Preferences: domain.DefaultPreferences(), UpdatedAt: time.Now().UTC()}
+	if err := repos.Preferences.SavePreferences(ctx, saved); err != nil {
+		t.Fatalf("SavePreferences: %v", err)
+	}
+	saved.Preferences.Language = domain.LanguageKazakh
+	saved.Preferences.Channels = []domain.ContactChannel{domain.ChannelSMS, domain.ChannelEmail}
+	saved.Preferences.QuietHours = nil
+	event, err := domain.NewEvent(domain.EventPreferencesChanged, tenant.ID, domain.PreferencesChanged{UserID: user.ID, Changed: []string{"language"}})
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	if err := repos.Preferences.SavePreferences(ctx, saved, event); err != nil {
+		t.Fatalf("SavePreferences(again): %v", err)
+	}
+
+	pending, err := repos.Outbox.Pending(ctx, 100)
+	if err != nil {
+		t.Fatalf("Outbox.Pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].MessageID != event.ID.String() || pending[0].Topic != domain.TopicUsers || pending[0].Type != event.Type {
+		t.Fatalf("Outbox.Pending = %+v, want only the preferences event", pending)
+	}
+	if err := repos.Outbox.MarkPublished(ctx, pending[0].ID); err != nil {
+		t.Fatalf("Outbox.MarkPublished: %v", err)
+	}
+	if pending, err = repos.Outbox.Pending(ctx, 100); err != nil || len(pending) != 0 {
+		t.Fatalf("Outbox.Pending after publishing = %d records, %v", len(pending), err)
+	}
+
+	found, err := repos.Preferences.FindPreferences(ctx, tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindPreferences: %v", err)
+	}
+	got := found.Preferences
+	if got.Language != domain.LanguageKazakh || got.QuietHours != nil || len(got.Channels) != 2 || got.Channels[0] != domain.ChannelSMS {
+		t.Fatalf("FindPreferences = %+v, want the second save", got)
+	}
+	if _, err := repos.Preferences.FindPreferences(ctx, other.ID, user.ID); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Fatalf("FindPreferences(other tenant) error = %v, want domain.ErrPreferencesNotFound", err)
+	}
+}
+
+func testImports(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "imports")
+	other := mustCreateTenant(t, repos, "imports-other")
+	base := time.Now().UTC().Truncate(time.Second)
+
+	first := &domain.ImportJob{ID: uuid.New(), TenantID: tenant.ID, FileName: "first.csv", Status: domain.ImportPending, TotalRows: 3, Errors: []domain.ImportRowError{}, CreatedAt: base}
+	second := &domain.ImportJob{ID: uuid.New(), TenantID: tenant.ID, FileName: "second.xlsx", DryRun: true, Status: domain.ImportPending, Errors: []domain.ImportRowError{}, CreatedAt: base.Add(time.Minute)}
+	for _, job := range []*domain.ImportJob{first, second} {
+		if err := repos.Imports.CreateImport(ctx, job); err != nil {
+			t.Fatalf("CreateImport(%s): %v", job.FileName, err)
+		}
+	}
+
+	finished := base.Add(2 * time.Minute)
+	first.Status = domain.ImportCompleted
+	first.Processed, first.Created, first.Failed = 3, 2, 1
+	first.Errors = append(first.Errors, domain.ImportRowError{Row: 4, Email: "bad", Field: "email", Message: "email is not a valid address"})
+	first.FinishedAt = &finished
+	if err := repos.Imports.SaveImport(ctx, first); err != nil {
+		t.Fatalf("SaveImport: %v", err)
+	}
+
+	found, err := repos.Imports.FindImport(ctx, tenant.ID, first.ID)
+	if err != nil {
+		t.Fatalf("FindImport: %v", err)
+	}
+	if found.Status != domain.ImportCompleted || found.Created != 2 || found.Failed != 1 || found.FinishedAt == nil {
+		t.Fatalf("FindImport = %+v, want the saved progress", found)
+	}
+	if len(found.Errors) != 1 || found.Errors[0].Row != 4 || found.Errors[0].Field != "email" {
+		t.Fatalf("FindImport errors = %+v, want the row 4 error", found.Errors)
+	}
+	if _, err := repos.Imports.FindImport(ctx, other.ID, first.ID); !errors.Is(err, domain.ErrImportNotFound) {
+		t.Fatalf("FindImport(other tenant) error = %v, want domain.ErrImportNotFound", err)
+	}
+
+	jobs, err := repos.Imports.ListImports(ctx, tenant.ID, 10)
+	if err != nil {
+		t.Fatalf("ListImports: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != second.ID || jobs[1].ID != first.ID {
+		t.Fatalf("ListImports = %+v, want newest first", jobs)
+	}
+	if jobs, err = repos.Imports.ListImports(ctx, other.ID, 10); err != nil || len(jobs) != 0 {
+		t.Fatalf("ListImports(other tenant) = %d jobs, %v", len(jobs), err)
+	}
+
+	// second ещё pending и без heartbeat, fresh недавно сохранял прогресс, first уже завершён
+	heartbeat := base.Add(10 * time.Minute)
+	fresh := &domain.ImportJob{ID: uuid.New(), TenantID: other.ID, FileName: "fresh.csv", Status: domain.ImportRunning, Errors: []domain.ImportRowError{}, CreatedAt: base, HeartbeatAt: &heartbeat}
+	if err := repos.Imports.CreateImport(ctx, fresh); err != nil {
+		t.Fatalf("CreateImport(fresh): %v", err)
+	}
+	failed, err := repos.Imports.FailStaleImports(ctx, base.Add(5*time.Minute), "interrupted")
+	if err != nil {
+		t.Fatalf("FailStaleImports: %v", err)
+	}
+	if failed != 1 {
+		t.Fatalf("FailStaleImports = %d, want 1", failed)
+	}
+	for _, want := range []struct {
+		job    *domain.ImportJob
+		status domain.ImportStatus
+	}{{first, domain.ImportCompleted}, {second, domain.ImportFailed}, {fresh, domain.ImportRunning}} {
+		found, err := repos.Imports.FindImport(ctx, want.job.TenantID, want.job.ID)
+		if err != nil {
+			t.Fatalf("FindImport(%s): %v", want.job.FileName, err)
+		}
+		if found.Status != want.status {
+			t.Fatalf("%s status = %s, want %s", want.job.FileName, found.Status, want.status)
+		}
+		if want.status == domain.ImportFailed && (found.Error != "interrupted" || found.FinishedAt == nil) {
+			t.Fatalf("%s = %+v, want the reason and a finish time", want.job.FileName, found)
+		}
+	}
+}
+
+func testReferralCodes(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "referral-codes")
+	other := mustCreateTenant(t, repos, "referral-codes-other")
+	alice := mustCreateUser(t, repos, tenant, "alice@example.com")
+	bob := mustCreateUser(t, repos, tenant, "bob@example.com")
+	carol := mustCreateUser(t, repos, other, "carol@example.com")
+
+	if _, err := repos.Referrals.FindReferralCode(ctx, tenant.ID, alice.ID); !errors.Is(err, domain.ErrReferralCodeNotFound) {
+		t.Fatalf("FindReferralCode(missing) error = %v, want domain.ErrReferralCodeNotFound", err)
+	}
+	code := &domain.ReferralCode{UserID: alice.ID, TenantID: tenant.ID, Code: "ALICE123", CreatedAt: time.Now().UTC()}
+	if err := repos.Referrals.CreateReferralCode(ctx, code); err != nil {
+		t.Fatalf("CreateReferralCode: %v", err)
+	}
+
+	second := &domain.ReferralCode{UserID: alice.ID, TenantID: tenant.ID, Code: "ALICE456", CreatedAt: time.Now().UTC()}
+	if err := repos.Referrals.CreateReferralCode(ctx, second); !errors.Is(err, domain.ErrReferralCodeTaken) {
+		t.Fatalf("CreateReferralCode(second code for the user) error = %v, want domain.ErrReferralCodeTaken", err)
+	}
+	taken := &domain.ReferralCode{UserID: bob.ID, TenantID: tenant.ID, Code: "ALICE123", CreatedAt: time.Now().UTC()}
+	if err := repos.Referrals.CreateReferralCode(ctx, taken); !errors.Is(err, domain.ErrReferralCodeTaken) {
+		t.Fatalf("CreateReferralCode(taken code) error = %v, want domain.ErrReferralCodeTaken", err)
+	}
+	// Коды уникальны только внутри тенанта
+	elsewhere := &domain.ReferralCode{UserID: carol.ID, TenantID: other.ID, Code: "ALICE123", CreatedAt: time.Now().UTC()}
+	if err := repos.Referrals.CreateReferralCode(ctx, elsewhere); err != nil {
+		t.Fatalf("CreateReferralCode(other tenant): %v", err)
+	}
+
+	found, err := repos.Referrals.FindReferralCodeByCode(ctx, tenant.ID, "ALICE123")
+	if err != nil || found.UserID != alice.ID {
+		t.Fatalf("FindReferralCodeByCode = %+v, %v, want alice's code", found, err)
+	}
+	if found, err = repos.Referrals.FindReferralCode(ctx, tenant.ID, alice.ID); err != nil || found.Code != "ALICE123" {
+		t.Fatalf("FindReferralCode = %+v, %v, want ALICE123", found, err)
+	}
+	if _, err := repos.Referrals.FindReferralCode(ctx, other.ID, alice.ID); !errors.Is(err, domain.ErrReferralCodeNotFound) {
+		t.Fatalf("FindReferralCode(other tenant) error = %v, want domain.ErrReferralCodeNotFound", err)
+	}
+}
+
+func testReferrals(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "referrals")
+	other := mustCreateTenant(t, repos, "referrals-other")
+	alice := mustCreateUser(t, repos, tenant, "alice@example.com")
+	bob := mustCreateUser(t, repos, tenant, "bob@example.com")
+	base := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+
+	referral := func(referrer uuid.UUID, district, device string, status domain.ReferralStatus, at time.Time) *domain.Referral {
+		r := &domain.Referral{ID: uuid.New(), TenantID: tenant.ID, ReferrerID: referrer, ReferredID: uuid.New(), Code: "CODE",
+			DeviceHash: device, District: district, Status: status, CreatedAt: at}
+		if status != domain.ReferralPending {
+			r.ResolvedAt = &at
+		}
+		return r
+	}
+	first := referral(alice.ID, "050000", "device-1", domain.ReferralRewarded, base)
+	referrals := []*domain.Referral{
+		first,
+		referral(alice.ID, "050000", "device-2", domain.ReferralRewarded, base.Add(time.Minute)),
+		referral(alice.ID, "050010", "device-1", domain.ReferralBlocked, base.Add(2*time.Minute)),
+		referral(bob.ID, "", "", domain.ReferralRewarded, base.Add(3*time.Minute)),
+	}
+	for _, r := range referrals {
+		if err := repos.Referrals.CreateReferral(ctx, r); err != nil {
+			t.Fatalf("CreateReferral: %v", err)
+		}
+	}
+
+	again := referral(bob.ID, "", "", domain.ReferralRewarded, base)
+	again.ReferredID = first.ReferredID
+	if err := repos.Referrals.CreateReferral(ctx, again); !errors.Is(err, domain.ErrAlreadyReferred) {
+		t.Fatalf("CreateReferral(referred twice) error = %v, want domain.ErrAlreadyReferred", err)
+	}
+
+	found, err := repos.Referrals.FindReferralByReferred(ctx, tenant.ID, first.ReferredID)
+	if err != nil || found.ID != first.ID || found.ReferrerID != alice.ID {
+		t.Fatalf("FindReferralByReferred = %+v, %v, want the first referral", found, err)
+	}
+	if _, err := repos.Referrals.FindReferralByReferred(ctx, other.ID, first.ReferredID); !errors.Is(err, domain.ErrReferralNotFound) {
+		t.Fatalf("FindReferralByReferred(other tenant) error = %v, want domain.ErrReferralNotFound", err)
+	}
+
+	since := base.Add(30 * time.Second)
+	counts := []struct {
+		name   string
+		filter domain.ReferralFilter
+		want   int64
+	}{
+		{"all", domain.ReferralFilter{}, 4},
+		{"device", domain.ReferralFilter{DeviceHash: "device-1"}, 2},
+		{"referrer rewarded", domain.ReferralFilter{ReferrerID: &alice.ID, Status: domain.ReferralRewarded}, 2},
+		{"referrer resolved since", domain.ReferralFilter{ReferrerID: &alice.ID, ResolvedSince: &since}, 2},
+	}
+	for _, tt := range counts {
+		got, err := repos.Referrals.CountReferrals(ctx, tenant.ID, tt.filter)
+		if err != nil || got != tt.want {
+			t.Fatalf("CountReferrals(%s) = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+
+	aliceCounts, err := repos.Referrals.CountByReferrer(ctx, tenant.ID, alice.ID)
+	if err != nil || aliceCounts != (domain.ReferralCounts{Referred: 3, Rewarded: 2, Blocked: 1}) {
+		t.Fatalf("CountByReferrer = %+v, %v, want 3 referred, 2 rewarded, 1 blocked", aliceCounts, err)
+	}
+
+	top, err := repos.Referrals.TopReferrers(ctx, tenant.ID, 10)
+	if err != nil {
+		t.Fatalf("TopReferrers: %v", err)
+	}
+	if len(top) != 2 || top[0].UserID != alice.ID || top[0].Referred != 3 || top[1].UserID != bob.ID || top[1].Rewarded != 1 {
+		t.Fatalf("TopReferrers = %+v, want alice then bob", top)
+	}
+	if top, err = repos.Referrals.TopReferrers(ctx, tenant.ID, 1); err != nil || len(top) != 1 {
+		t.Fatalf("TopReferrers(limit 1) = %d rows, %v", len(top), err)
+	}
+
+	districts, err := repos.Referrals.CountByDistrict(ctx, tenant.ID)
+	if err != nil {
+		t.Fatalf("CountByDistrict: %v", err)
+	}
+	want := []domain.DistrictReferralStats{
+		{District: "050000", ReferralCounts: domain.ReferralCounts{Referred: 2, Rewarded: 2}},
+		{District: "", ReferralCounts: domain.ReferralCounts{Referred: 1, Rewarded: 1}},
+		{District: "050010", ReferralCounts: domain.ReferralCounts{Referred: 1, Blocked: 1}},
+	}
+	if len(districts) != len(want) {
+		t.Fatalf("CountByDistrict = %+v, want %+v", districts, want)
+	}
+	for i := range want {
+		if districts[i] != want[i] {
+			t.Fatalf("CountByDistrict = %+v, want %+v", districts, want)
+		}
+	}
+	if districts, err = repos.Referrals.CountByDistrict(ctx, other.ID); err != nil || len(districts) != 0 {
+		t.Fatalf("CountByDistrict(other tenant) = %d rows, %v", len(districts), err)
+	}
+
+	pending := referral(bob.ID, "", "device-3", domain.ReferralPending, base.Add(4*time.Minute))
+	if err := repos.Referrals.CreateReferral(ctx, pending); err != nil {
+		t.Fatalf("CreateReferral(pending): %v", err)
+	}
+	if bobCounts, err := repos.Referrals.CountByReferrer(ctx, tenant.ID, bob.ID); err != nil || bobCounts != (domain.ReferralCounts{Referred: 2, Pending: 1, Rewarded: 1}) {
+		t.Fatalf("CountByReferrer(bob) = %+v, %v, want 2 referred, 1 pending, 1 rewarded", bobCounts, err)
+	}
+
+	resolvedAt := time.Now().UTC().Truncate(time.Second)
+	pending.Status, pending.BlockReason, pending.District, pending.ResolvedAt = domain.ReferralBlocked, domain.ReferralBlockSameAddress, "050020", &resolvedAt
+	if err := repos.Referrals.ResolveReferral(ctx, pending); err != nil {
+		t.Fatalf("ResolveReferral: %v", err)
+	}
+	if err := repos.Referrals.ResolveReferral(ctx, pending); !errors.Is(err, domain.ErrReferralNotFound) {
+		t.Fatalf("ResolveReferral(already resolved) error = %v, want domain.ErrReferralNotFound", err)
+	}
+	resolved, err := repos.Referrals.FindReferralByReferred(ctx, tenant.ID, pending.ReferredID)
+	if err != nil || resolved.Status != domain.ReferralBlocked || resolved.BlockReason != domain.ReferralBlockSameAddress ||
+		resolved.District != "050020" || resolved.ResolvedAt == nil || !resolved.ResolvedAt.Equal(resolvedAt) {
+		t.Fatalf("FindReferralByReferred(resolved) = %+v, %v, want blocked for same_address in 050020", resolved, err)
+	}
+}
+
+func testFindByID(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, repos, "byid")
+	other := mustCreateTenant(t, repos, "notmine")
+	user := mustCreateUser(t, repos, own, "byid@example.com")
+
+	found, err := repos.Users.FindByID(ctx, own.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if found.Email != user.Email {
+		t.Fatalf("FindByID email = %q, want %q", found.Email, user.Email)
+	}
+
+	if _, err := repos.Users.FindByID(ctx, other.ID, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByID from another tenant error = %v, want domain.ErrUserNotFound", err)
+	}
+	if _, err := repos.Users.FindByID(ctx, own.ID, uuid.New()); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByID(missing) error = %v, want domain.ErrUserNotFound", err)
+	}
+}
+
+func testListFilters(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "filters")
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	seed := []struct {
+		email, name, address string
+		role                 domain.UserRole
+		created              time.Time
+	}{
+		{"anna@example.com", "Anna Ivanova", "Abay 10", domain.RoleUser, base},
+		{"boris@example.com", "Boris Petrov", "Abay 12", domain.RoleCollector, base.Add(24 * time.Hour)},
+		{"vera@example.com", "Vera Ivanova", "Dostyk 5", domain.RoleUser, base.Add(48 * time.Hour)},
+	}
+	for _, s := range seed {
+		user := newUser(tenant, s.email)
+		user.Name, user.Role = s.name, s.role
+		user.CreatedAt, user.UpdatedAt = s.created, s.created
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("Create(%q): %v", s.email, err)
+		}
+		mustSaveAddress(t, repos, user, s.address, true)
+	}
+
+	from := base.Add(12 * time.Hour)
+	to := base.Add(36 * time.Hour)
+	tests := []struct {
+		name   string
+		filter domain.UserFilter
+		want   []string
+	}{
+		{"All", domain.UserFilter{}, []string{"vera@example.com", "boris@example.com", "anna@example.com"}},
+		{"Role", domain.UserFilter{Role: domain.RoleUser}, []string{"vera@example.com", "anna@example.com"}},
+		{"Name", domain.UserFilter{Name: "ivanova"}, []string{"vera@example.com", "anna@example.com"}},
+		{"Address", domain.UserFilter{Address: "abay"}, []string{"boris@example.com", "anna@example.com"}},
+		{"CreatedRange", domain.UserFilter{CreatedFrom: &from, CreatedTo: &to}, []string{"boris@example.com"}},
+		{"Combined", domain.UserFilter{Role: domain.RoleUser, Address: "abay"}, []string{"anna@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Limit = domain.MaxPageLimit
+			users, total, err := repos.Users.List(ctx, tenant.ID, tt.filter)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			assertEmails(t, users, tt.want)
+			if total != int64(len(tt.want)) {
+				t.Fatalf("List total = %d, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
+
+func testListPagination(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "pages")
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		user := newUser(tenant, email)
+		user.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("Create(%q): %v", email, err)
+		}
+	}
+
+	users, total, err := repos.Users.List(ctx, tenant.ID, domain.UserFilter{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("List total = %d, want 3", total)
+	}
+	assertEmails(t, users, []string{"b@example.com", "a@example.com"})
+}
+
+func testSearch(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	tenant := mustCreateTenant(t, repos, "search")
+	other := mustCreateTenant(t, repos, "searchother")
+
+	for _, u := range []struct{ email, name string }{
+		{"anna.k@example.com", "Anna Karimova"},
+		{"boris@example.com", "Boris Annenkov"},
+		{"vera@example.com", "Vera Smirnova"},
+	} {
+		user := newUser(tenant, u.email)
+		user.Name = u.name
+		if err := repos.Users.Create(ctx, user); err != nil {
+			t.Fatalf("Create(%q): %v", u.email, err)
+		}
+	}
+	outsider := newUser(other, "anna@other.example.com")
+	outsider.Name = "Anna Outsider"
+	if err := repos.Users.Create(ctx, outsider); err != nil {
+		t.Fatalf("Create outsider: %v", err)
+	}
+
+	users, total, err := repos.Users.Search(ctx, tenant.ID, "ann", domain.MaxPageLimit, 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if total != 2 || len(users) != 2 {
+		t.Fatalf("Search(ann) returned %d of %d users, want 2", len(users), total)
+	}
+
+	users, _, err = repos.Users.Search(ctx, tenant.ID, "vera smirn", domain.MaxPageLimit, 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	assertEmails(t, users, []string{"vera@example.com"})
+
+	users, _, err = repos.Users.Search(ctx, tenant.ID, "outsider", domain.MaxPageLimit, 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	assertEmails(t, users, nil)
+}
+
+func testSoftDelete(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	own := mustCreateTenant(t, repos, "delete")
+	other := mustCreateTenant(t, repos, "deleteother")
+	user := mustCreateUser(t, repos, own, "gone@example.com")
+
+	if err := repos.Users.Delete(ctx, other.ID, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Delete from another tenant error = %v, want domain.ErrUserNotFound", err)
+	}
+	if err := repos.Users.Delete(ctx, own.ID, user.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repos.Users.Delete(ctx, own.ID, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("second Delete error = %v, want domain.ErrUserNotFound", err)
+	}
+
+	if _, err := repos.Users.FindByID(ctx, own.ID, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("FindByID after delete error = %v, want domain.ErrUserNotFound", err)
+	}
+	users, total, err := repos.Users.List(ctx, own.ID, domain.UserFilter{Limit: domain.MaxPageLimit})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if total != 0 || len(users) != 0 {
+		t.Fatalf("List after delete returned %d users", len(users))
+	}
+}
+
+func assertEmails(t *testing.T, users []domain.User, want []string) {
+	t.Helper()
+	if len(users) != len(want) {
+		t.Fatalf("got %d users, want %d (%v)", len(users), len(want), want)
+	}
+	for i, user := range users {
+		if user.Email != want[i] {
+			t.Fatalf("user[%d] = %q, want %q", i, user.Email, want[i])
+		}
+	}
+}
+
+func assertIDs(t *testing.T, label string, got, want []uuid.UUID) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d ids %v, want %v", label, len(got), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", label, got, want)
+		}
+	}
+}
+
+func assertCounts(t *testing.T, label string, got, want []domain.ActionCount) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %+v, want %+v", label, got, want)
+	}
+	for i := range want {
+		if !got[i].PeriodStart.Equal(want[i].PeriodStart) || got[i].Action != want[i].Action || got[i].Count != want[i].Count {
+			t.Fatalf("%s: got %+v, want %+v", label, got, want)
+		}
+	}
+}
+
+func actionIDs(actions []domain.UserAction) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(actions))
+	for _, action := range actions {
+		ids = append(ids, action.ID)
+	}
+	return ids
+}
+
+func mustRecordAction(t *testing.T, repos repository.Repositories, tenant *domain.Tenant, user *domain.User, actionType domain.ActionType, at time.Time) *domain.UserAction {
+	t.Helper()
+	action := &domain.UserAction{ID: uuid.New(), TenantID: tenant.ID, UserID: user.ID, Action: actionType, Source: "repotest", CreatedAt: at}
+	if err := repos.Users.RecordUserAction(context.Background(), action); err != nil {
+		t.Fatalf("RecordUserAction: %v", err)
+	}
+	return action
+}
+
+// mustTransfer переводит amount баллов со счёта вознаграждений на account
+func mustTransfer(t *testing.T, repos repository.Repositories, tenant *domain.Tenant, actionID *uuid.UUID, account string, amount int64) *domain.PointsTransaction {
+	t.Helper()
+	// Разные created_at делают порядок истории однозначным
+	time.Sleep(2 * time.Millisecond)
+	transaction := &domain.PointsTransaction{ID: uuid.New(), TenantID: tenant.ID, Kind: domain.PointsAdjusted, ActionID: actionID, CreatedAt: time.Now().UTC()}
+	if actionID != nil {
+		transaction.Kind = domain.PointsEarned
+	}
+	if err := repos.Points.CreateTransaction(context.Background(), transaction, pointsEntries(transaction, account, amount)); err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	return transaction
+}
+
+func pointsEntries(transaction *domain.PointsTransaction, account string, amount int64) []domain.PointsEntry {
+	return []domain.PointsEntry{
+		{ID: uuid.New(), TenantID: transaction.TenantID, TransactionID: transaction.ID, Account: domain.AccountRewards, Amount: -amount, CreatedAt: transaction.CreatedAt},
+		{ID: uuid.New(), TenantID: transaction.TenantID, TransactionID: transaction.ID, Account: account, Amount: amount, CreatedAt: transaction.CreatedAt},
+	}
+}
+
+func mustSaveAddress(t *testing.T, repos repository.Repositories, user *domain.User, street string, primary bool) *domain.Address {
+	t.Helper()
+	lat, lon := 43.238949, 76.889709
+	now := time.Now().UTC().Truncate(time.Second)
+	address := &domain.Address{
+		ID:        uuid.New(),
+		TenantID:  user.TenantID,
+		UserID:    user.ID,
+		Street:    street,
+		City:      "Almaty",
+		Latitude:  &lat,
+		Longitude: &lon,
+		IsPrimary: primary,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repos.Users.SaveAddress(context.Background(), address); err != nil {
+		t.Fatalf("SaveAddress: %v", err)
+	}
+	return address
+}
+
+func mustCreateHousehold(t *testing.T, repos repository.Repositories, owner *domain.User) *domain.Household {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	household := &domain.Household{ID: uuid.New(), TenantID: owner.TenantID, Name: "Home", CreatedAt: now, UpdatedAt: now}
+	membership := &domain.HouseholdMembership{HouseholdID: household.ID, UserID: owner.ID, TenantID: owner.TenantID, Role: domain.HouseholdOwner, JoinedAt: now}
+	if err := repos.Households.CreateHousehold(context.Background(), household, membership); err != nil {
+		t.Fatalf("CreateHousehold: %v", err)
+	}
+	return household
+}
+
+func mustCreateInvitation(t *testing.T, repos repository.Repositories, household *domain.Household, inviter *domain.User, code string) *domain.HouseholdInvitation {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	invitation := &domain.HouseholdInvitation{
+		ID:          uuid.New(),
+		TenantID:    household.TenantID,
+		HouseholdID: household.ID,
+		Code:        code,
+		InvitedBy:   inviter.ID,
+		ExpiresAt:   now.Add(time.Hour),
+		CreatedAt:   now,
+	}
+	if err := repos.Households.CreateInvitation(context.Background(), invitation); err != nil {
+		t.Fatalf("CreateInvitation: %v", err)
+	}
+	return invitation
+}
+
+func mustSaveZone(t *testing.T, repos repository.Repositories, collector *domain.User, name string, south, north float64) *domain.ServiceZone {
+	t.Helper()
+	now := time.Now().UTC().Truncate(time.Second)
+	zone := &domain.ServiceZone{
+		ID:        uuid.New(),
+		TenantID:  collector.TenantID,
+		UserID:    collector.ID,
+		Name:      name,
+		Boundary:  rectangle(south, north, 76.85, 76.95),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repos.Collectors.SaveZone(context.Background(), zone); err != nil {
+		t.Fatalf("SaveZone(%s): %v", name, err)
+	}
+	return zone
+}
+
+func rectangle(south, north, west, east float64) domain.GeoPolygon {
+	return domain.GeoPolygon{Type: "Polygon", Coordinates: [][][2]float64{{
+		{west, south}, {east, south}, {east, north}, {west, north}, {west, south},
+	}}}
+}
+
+func mustCreateTenant(t *testing.T, repos repository.Repositories, slug string) *domain.Tenant {
+	t.Helper()
+	tenant := &domain.Tenant{ID: uuid.New(), Slug: slug, Name: slug, CreatedAt: time.Now()}
+	if err := repos.Tenants.CreateTenant(context.Background(), tenant); err != nil {
+		t.Fatalf("CreateTenant(%q): %v", slug, err)
+	}
+	return tenant
+}
+
+func mustCreateUser(t *testing.T, repos repository.Repositories, tenant *domain.Tenant, email string) *domain.User {
+	t.Helper()
+	user := newUser(tenant, email)
+	if err := repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create(%q): %v", email, err)
+	}
+	return user
+}
+
+func newUser(tenant *domain.Tenant, email string) *domain.User {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &domain.User{
+		ID:        uuid.New(),
+		TenantID:  tenant.ID,
+		Email:     email,
+		Name:      "Resident",
+		Role:      domain.RoleUser,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+}
+
+// Отметки обработанных сообщений чистятся по сроку, а не по потребителю
+func testProcessedMessages(t *testing.T, repos repository.Repositories) {
+	ctx := context.Background()
+	for _, id := range []string{"m-1", "m-2"} {
+		if err := repos.Processed.Mark(ctx, "accounts", id); err != nil {
+			t.Fatalf("Mark(%s): %v", id, err)
+		}
+	}
+	if err := repos.Processed.Mark(ctx, "accounts", "m-1"); err != nil {
+		t.Fatalf("Mark(repeat): %v", err)
+	}
+
+	purged, err := repos.Processed.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil || purged != 0 {
+		t.Fatalf("Purge(hour ago) = %d, %v; want nothing purged", purged, err)
+	}
+	if seen, err := repos.Processed.Seen(ctx, "accounts", "m-1"); err != nil || !seen {
+		t.Fatalf("Seen(m-1) = %v, %v; want the mark kept", seen, err)
+	}
+
+	purged, err = repos.Processed.Purge(ctx, time.Now().Add(time.Minute))
+	if err != nil || purged != 2 {
+		t.Fatalf("Purge(now) = %d, %v; want 2 marks purged", purged, err)
+	}
+	if seen, err := repos.Processed.Seen(ctx, "accounts", "m-1"); err != nil || seen {
+		t.Fatalf("Seen(m-1) after purge = %v, %v; want the mark gone", seen, err)
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"user-service/internal/domain"
+)
+
+// Данные жителя читают только он сам и администратор тенанта
+func TestPerUserReadsRequireOwner(t *testing.T) {
+	paths := []string{
+		"/addresses",
+		"/preferences",
+		"/actions",
+		"/actions/summary",
+		"/points",
+		"/points/history",
+		"/achievements",
+		"/stats",
+	}
+	admin := &domain.User{Email: "admin@example.com", Role: domain.RoleAdmin}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			srv := newTestServer(t, Config{})
+			user := srv.mustCreateUser(t, "resident@example.com")
+			neighbour := srv.mustCreateUser(t, "neighbour@example.com")
+			url := "/users/" + user.ID.String() + path
+
+			expectStatus(t, srv.do(t, http.MethodGet, url, user, "", nil), http.StatusOK)
+			expectStatus(t, srv.do(t, http.MethodGet, url, admin, "", nil), http.StatusOK)
+			expectStatus(t, srv.do(t, http.MethodGet, url, neighbour, "", nil), http.StatusForbidden)
+		})
+	}
+}
+
+// Лента открывается только владельцу; для него ответ не завершается, поэтому проверяются отказы
+func TestStreamUserActionsRequiresOwner(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+	neighbour := srv.mustCreateUser(t, "neighbour@example.com")
+	url := "/users/" + user.ID.String() + "/actions/stream"
+
+	expectStatus(t, srv.do(t, http.MethodGet, url, neighbour, "", nil), http.StatusForbidden)
+	expectStatus(t, srv.do(t, http.MethodGet, url, nil, "", nil), http.StatusForbidden)
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// receiveAccountEvent принимает события user.* от auth-service.
+// Запрос подписывается общим секретом ACCOUNT_EVENTS_SECRET тем же способом, что и приём действий.
+func (s *UserServer) receiveAccountEvent(w http.ResponseWriter, r *http.Request) {
+	if s.AccountEventsSecret == "" {
+		http.Error(w, "Account events are disabled", http.StatusNotFound)
+		return
+	}
+
+	body, err := verifySignedBody(r, []byte(s.AccountEventsSecret), time.Now())
+	if err != nil {
+		if errors.Is(err, errInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var event domain.AccountEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.AccountService.ApplyAccountEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to apply account event %s %s (request %s): %v", event.Type, event.ID, requestctx.RequestID(r.Context()), err)
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	if user == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/requestctx"
+)
+
+func (s *UserServer) listAchievementDefinitions(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(s.AchievementService.Definitions())
+}
+
+// getUserAchievements отдаёт выданные значки и прогресс по остальным; серии считаются в часовом поясе tz
+func (s *UserServer) getUserAchievements(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := parseLocationParam(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	overview, err := s.AchievementService.Overview(r.Context(), requestctx.Tenant(r.Context()).ID, userID, loc)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(overview)
+}
+
+// evaluateUserAchievements пересчитывает все достижения, например после изменения каталога
+func (s *UserServer) evaluateUserAchievements(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := parseLocationParam(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	awarded, err := s.AchievementService.Evaluate(r.Context(), requestctx.Tenant(r.Context()).ID, userID, loc)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"awarded": awarded})
+}
+
+func parseLocationParam(query url.Values) (*time.Location, error) {
+	tz := query.Get("tz")
+	if tz == "" {
+		return nil, nil
+	}
+	// "Local" — пояс самого сервера, к тому же его имя уходит в AT TIME ZONE запросов к Postgres
+	loc, err := time.LoadLocation(tz)
+	if err != nil || tz == "Local" {
+		return nil, fmt.Errorf("invalid tz %q", tz)
+	}
+	return loc, nil
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// ingestAction принимает действия пользователей от других сервисов.
+// Запрос подписывается общим секретом ACTION_INGEST_SECRET, см. verifySignedBody.
+func (s *UserServer) ingestAction(w http.ResponseWriter, r *http.Request) {
+	if s.ActionIngestSecret == "" {
+		http.Error(w, "Action ingest is disabled", http.StatusNotFound)
+		return
+	}
+
+	body, err := verifySignedBody(r, []byte(s.ActionIngestSecret), time.Now())
+	if err != nil {
+		if errors.Is(err, errInvalidSignature) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var event domain.ActionEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	action, err := s.ActionService.IngestAction(r.Context(), event)
+	if err != nil {
+		if errors.Is(err, domain.ErrActionExists) {
+			// Повторная доставка того же события — не ошибка для отправителя
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": event.ID, "duplicate": true})
+			return
+		}
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(action)
+}
+
+// getUserActions отдаёт историю постранично: next_cursor из ответа передаётся в параметре cursor
+func (s *UserServer) getUserActions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := domain.ActionFilter{
+		Types:    parseActionTypes(query["type"]),
+		Category: query.Get("category"),
+		Order:    domain.SortOrder(query.Get("order")),
+	}
+	if filter.From, err = parseTimeParam(query, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTimeParam(query, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, _, err = parsePageParams(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if cursor := query.Get("cursor"); cursor != "" {
+		if filter.After, err = domain.ParseActionCursor(cursor); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := s.ActionService.ListActions(r.Context(), requestctx.Tenant(r.Context()).ID, userID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+// getUserActionSummary считает действия по типам за день, неделю или месяц в часовом поясе tz
+func (s *UserServer) getUserActionSummary(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	summaryQuery, err := parseSummaryQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := s.ActionService.SummarizeActions(r.Context(), requestctx.Tenant(r.Context()).ID, userID, summaryQuery)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(summary)
+}
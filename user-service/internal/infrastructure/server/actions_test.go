@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+const testIngestSecret = "ingest-secret"
+
+func TestIngestAction(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		secret     string
+		timestamp  time.Time
+		body       func(userID uuid.UUID) string
+		tamper     bool
+		wantStatus int
+		wantStored bool
+	}{
+		{name: "signed event", secret: testIngestSecret, timestamp: now, body: wasteSortedEvent, wantStatus: http.StatusCreated, wantStored: true},
+		{name: "wrong secret", secret: "other", timestamp: now, body: wasteSortedEvent, wantStatus: http.StatusUnauthorized},
+		{name: "tampered body", secret: testIngestSecret, timestamp: now, body: wasteSortedEvent, tamper: true, wantStatus: http.StatusUnauthorized},
+		{name: "expired timestamp", secret: testIngestSecret, timestamp: now.Add(-10 * time.Minute), body: wasteSortedEvent, wantStatus: http.StatusUnauthorized},
+		{name: "timestamp from the future", secret: testIngestSecret, timestamp: now.Add(10 * time.Minute), body: wasteSortedEvent, wantStatus: http.StatusUnauthorized},
+		{
+			name:      "unknown action",
+			secret:    testIngestSecret,
+			timestamp: now,
+			body: func(userID uuid.UUID) string {
+				return fmt.Sprintf(`{"id":%q,"user_id":%q,"action":"flew_away","source":"map-service"}`, uuid.NewString(), userID)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "missing source",
+			secret:    testIngestSecret,
+			timestamp: now,
+			body: func(userID uuid.UUID) string {
+				return fmt.Sprintf(`{"id":%q,"user_id":%q,"action":"waste_sorted"}`, uuid.NewString(), userID)
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "unknown user",
+			secret:    testIngestSecret,
+			timestamp: now,
+			body: func(uuid.UUID) string {
+				return wasteSortedEvent(uuid.New())
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, Config{ActionIngestSecret: testIngestSecret})
+			user := srv.mustCreateUser(t, "resident@example.com")
+
+			body := tt.body(user.ID)
+			timestamp := strconv.FormatInt(tt.timestamp.Unix(), 10)
+			headers := map[string]string{
+				SignatureTimestampHeader: timestamp,
+				SignatureHeader:          signBody([]byte(tt.secret), timestamp, []byte(body)),
+			}
+			if tt.tamper {
+				body = body[:len(body)-1] + `,"details":"x"}`
+			}
+
+			rec := srv.do(t, http.MethodPost, "/actions/ingest", nil, body, headers)
+			expectStatus(t, rec, tt.wantStatus)
+			want := 0
+			if tt.wantStored {
+				want = 1
+			}
+			if stored := srv.countActions(t, user); stored != want {
+				t.Fatalf("stored %d actions, want %d", stored, want)
+			}
+		})
+	}
+}
+
+// Повторная доставка того же события не создаёт второе действие
+func TestIngestActionDuplicate(t *testing.T) {
+	srv := newTestServer(t, Config{ActionIngestSecret: testIngestSecret})
+	user := srv.mustCreateUser(t, "resident@example.com")
+	body := wasteSortedEvent(user.ID)
+
+	for i, want := range []int{http.StatusCreated, http.StatusOK} {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		rec := srv.do(t, http.MethodPost, "/actions/ingest", nil, body, map[string]string{
+			SignatureTimestampHeader: timestamp,
+			SignatureHeader:          signBody([]byte(testIngestSecret), timestamp, []byte(body)),
+		})
+		if rec.Code != want {
+			t.Fatalf("delivery %d status = %d, want %d", i+1, rec.Code, want)
+		}
+	}
+	if stored := srv.countActions(t, user); stored != 1 {
+		t.Fatalf("stored %d actions, want 1", stored)
+	}
+}
+
+func TestIngestActionDisabledWithoutSecret(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	rec := srv.do(t, http.MethodPost, "/actions/ingest", nil, wasteSortedEvent(user.ID), nil)
+	expectStatus(t, rec, http.StatusNotFound)
+}
+
+// Изменение профиля попадает в историю действий с перечнем изменённых полей
+func TestProfileUpdateIsRecorded(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	expectStatus(t, srv.do(t, http.MethodPatch, "/users/"+user.ID.String(), user, `{"name":"Alice"}`, nil), http.StatusOK)
+	// Без изменений запись в истории не появляется
+	expectStatus(t, srv.do(t, http.MethodPatch, "/users/"+user.ID.String(), user, `{"name":"Alice"}`, nil), http.StatusOK)
+
+	actions, err := srv.repos.Users.GetUserActions(context.Background(), srv.tenant.ID, user.ID, domain.ActionFilter{})
+	if err != nil {
+		t.Fatalf("GetUserActions: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Action != domain.ActionProfileUpdated || actions[0].Details != `{"fields":["name"]}` {
+		t.Fatalf("actions = %+v, want one profile_updated with the name field", actions)
+	}
+}
+
+func wasteSortedEvent(userID uuid.UUID) string {
+	return fmt.Sprintf(`{"id":%q,"user_id":%q,"action":"waste_sorted","source":"map-service","category":"Plastic"}`, uuid.NewString(), userID)
+}
+
+func (s *testServer) countActions(t *testing.T, user *domain.User) int {
+	t.Helper()
+	actions, err := s.repos.Users.GetUserActions(context.Background(), s.tenant.ID, user.ID, domain.ActionFilter{})
+	if err != nil {
+		t.Fatalf("GetUserActions: %v", err)
+	}
+	return len(actions)
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/requestctx"
+)
+
+func (s *UserServer) listAddresses(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	addresses, err := s.AddressService.ListAddresses(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(addresses)
+}
+
+func (s *UserServer) addAddress(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var request addressRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	address := request.address()
+
+	if err := s.AddressService.AddAddress(r.Context(), requestctx.Tenant(r.Context()).ID, userID, address); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(address)
+}
+
+func (s *UserServer) updateAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	var request addressRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+	address := request.address()
+	address.ID = addressID
+
+	if err := s.AddressService.UpdateAddress(r.Context(), requestctx.Tenant(r.Context()).ID, userID, address); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(address)
+}
+
+func (s *UserServer) deleteAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.AddressService.DeleteAddress(r.Context(), requestctx.Tenant(r.Context()).ID, userID, addressID); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *UserServer) setPrimaryAddress(w http.ResponseWriter, r *http.Request) {
+	userID, addressID, ok := addressParams(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := s.AddressService.SetPrimaryAddress(r.Context(), requestctx.Tenant(r.Context()).ID, userID, addressID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(address)
+}
+
+func addressParams(w http.ResponseWriter, r *http.Request) (userID, addressID uuid.UUID, ok bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	addressID, err = uuid.Parse(chi.URLParam(r, "addressID"))
+	if err != nil {
+		http.Error(w, "Invalid address ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, addressID, true
+}
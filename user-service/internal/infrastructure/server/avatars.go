@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/imaging"
+	"user-service/internal/requestctx"
+)
+
+// putAvatar принимает картинку полем file формы multipart/form-data или телом запроса.
+// Тип определяется по содержимому, заголовку Content-Type клиента сервис не доверяет.
+func (s *UserServer) putAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	data, err := readAvatarFile(w, r)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	images, err := imaging.Avatar(data, domain.AvatarSizes)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrUnsupportedImage) {
+			status = http.StatusUnsupportedMediaType
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	user, err := s.AvatarService.SetAvatar(r.Context(), requestctx.Tenant(r.Context()).ID, userID, images)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	json.NewEncoder(w).Encode(user)
+}
+
+func (s *UserServer) deleteAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.AvatarService.DeleteAvatar(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	json.NewEncoder(w).Encode(user)
+}
+
+// getAvatar отдаёт миниатюру без авторизации, чтобы её можно было показать тегом img.
+// По одному адресу всегда лежат одни и те же байты, поэтому кэш не истекает.
+func (s *UserServer) getAvatar(w http.ResponseWriter, r *http.Request) {
+	avatarID, err := uuid.Parse(chi.URLParam(r, "avatarID"))
+	if err != nil {
+		http.Error(w, "Invalid avatar ID", http.StatusBadRequest)
+		return
+	}
+	size, err := strconv.Atoi(strings.TrimSuffix(chi.URLParam(r, "file"), ".jpg"))
+	if err != nil || !strings.HasSuffix(chi.URLParam(r, "file"), ".jpg") {
+		http.Error(w, "Avatar file must be <size>.jpg", http.StatusNotFound)
+		return
+	}
+
+	etag := strconv.Quote(fmt.Sprintf("%s-%d", avatarID, size))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	blob, err := s.AvatarService.GetAvatar(r.Context(), avatarID, size)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", blob.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(blob.Data)))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(blob.Data)
+}
+
+// readAvatarFile читает не больше domain.MaxAvatarBytes; превышение возвращает *http.MaxBytesError
+func readAvatarFile(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAvatarBytes)
+		return readAvatarBody(r.Body)
+	}
+
+	// Запас на заголовки формы сверх размера самого файла
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAvatarBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("image must be at most %d MB: %w", domain.MaxAvatarBytes>>20, tooLarge)
+		}
+		return nil, errors.New("multipart form must contain a file field")
+	}
+	defer file.Close()
+	if header.Size > domain.MaxAvatarBytes {
+		return nil, fmt.Errorf("image must be at most %d MB: %w", domain.MaxAvatarBytes>>20, &http.MaxBytesError{Limit: domain.MaxAvatarBytes})
+	}
+	return readAvatarBody(file)
+}
+
+func readAvatarBody(body io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, fmt.Errorf("image must be at most %d MB: %w", domain.MaxAvatarBytes>>20, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("image is empty")
+	}
+	return data, nil
+}
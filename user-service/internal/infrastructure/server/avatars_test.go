@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/blob"
+)
+
+func TestPutAvatar(t *testing.T) {
+	picture := testPNG(t, 300, 200)
+	tests := []struct {
+		name        string
+		body        func(t *testing.T) (string, []byte)
+		asOther     bool
+		wantStatus  int
+		wantVersion int64
+	}{
+		{name: "raw body", body: func(*testing.T) (string, []byte) { return "image/png", picture }, wantStatus: http.StatusOK, wantVersion: 2},
+		{name: "client content type is ignored", body: func(*testing.T) (string, []byte) { return "text/plain", picture }, wantStatus: http.StatusOK, wantVersion: 2},
+		{name: "multipart form", body: func(t *testing.T) (string, []byte) { return multipartFile(t, "file", picture) }, wantStatus: http.StatusOK, wantVersion: 2},
+		{name: "multipart without file field", body: func(t *testing.T) (string, []byte) { return multipartFile(t, "photo", picture) }, wantStatus: http.StatusBadRequest, wantVersion: 1},
+		{name: "empty body", body: func(*testing.T) (string, []byte) { return "image/png", nil }, wantStatus: http.StatusBadRequest, wantVersion: 1},
+		{name: "not an image", body: func(*testing.T) (string, []byte) { return "image/png", []byte("hello") }, wantStatus: http.StatusUnsupportedMediaType, wantVersion: 1},
+		{name: "too large", body: func(*testing.T) (string, []byte) { return "image/png", make([]byte, domain.MaxAvatarBytes+1) }, wantStatus: http.StatusRequestEntityTooLarge, wantVersion: 1},
+		{name: "another resident", asOther: true, body: func(*testing.T) (string, []byte) { return "image/png", picture }, wantStatus: http.StatusForbidden, wantVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newAvatarTestServer(t)
+			user := srv.mustCreateUser(t, "resident@example.com")
+			actor := user
+			if tt.asOther {
+				actor = srv.mustCreateUser(t, "neighbour@example.com")
+			}
+
+			contentType, body := tt.body(t)
+			rec := srv.putAvatar(t, user, actor, contentType, body)
+			expectStatus(t, rec, tt.wantStatus)
+
+			stored := srv.mustFindUser(t, user)
+			if stored.Version != tt.wantVersion || (stored.AvatarID != nil) != (tt.wantStatus == http.StatusOK) {
+				t.Fatalf("stored version/avatar = %d/%v, want version %d", stored.Version, stored.AvatarID, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// Миниатюры отдаются без авторизации, кэшируются навсегда и удаляются вместе с аватаром
+func TestGetAvatar(t *testing.T) {
+	srv := newAvatarTestServer(t)
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	rec := srv.putAvatar(t, user, user, "image/png", testPNG(t, 300, 200))
+	expectStatus(t, rec, http.StatusOK)
+	var profile struct {
+		AvatarURL        string         `json:"avatar_url"`
+		AvatarThumbnails map[int]string `json:"avatar_thumbnails"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&profile); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(profile.AvatarThumbnails) != len(domain.AvatarSizes) || profile.AvatarURL != profile.AvatarThumbnails[domain.AvatarSizes[len(domain.AvatarSizes)-1]] {
+		t.Fatalf("profile links = %+v, want every size and avatar_url on the largest", profile)
+	}
+
+	for _, size := range domain.AvatarSizes {
+		rec := srv.do(t, http.MethodGet, profile.AvatarThumbnails[size], nil, "", nil)
+		expectStatus(t, rec, http.StatusOK)
+		if rec.Header().Get("Content-Type") != "image/jpeg" || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("size %d headers = %v", size, rec.Header())
+		}
+		config, err := jpegConfig(rec.Body.Bytes())
+		if err != nil || config.Width != size || config.Height != size {
+			t.Errorf("size %d thumbnail = %+v, %v; want %dx%d", size, config, err, size, size)
+		}
+
+		etag := rec.Header().Get("ETag")
+		expectStatus(t, srv.do(t, http.MethodGet, profile.AvatarThumbnails[size], nil, "", map[string]string{"If-None-Match": etag}), http.StatusNotModified)
+	}
+
+	avatarID := srv.mustFindUser(t, user).AvatarID.String()
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/avatars/" + avatarID + "/100.jpg", wantStatus: http.StatusBadRequest},
+		{path: "/avatars/" + avatarID + "/64.png", wantStatus: http.StatusNotFound},
+		{path: "/avatars/not-a-uuid/64.jpg", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		expectStatus(t, srv.do(t, http.MethodGet, tt.path, nil, "", nil), tt.wantStatus)
+	}
+
+	expectStatus(t, srv.do(t, http.MethodDelete, "/users/"+user.ID.String()+"/avatar", user, "", nil), http.StatusOK)
+	expectStatus(t, srv.do(t, http.MethodGet, profile.AvatarURL, nil, "", nil), http.StatusNotFound)
+}
+
+func newAvatarTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	return newTestServer(t, Config{Blobs: store})
+}
+
+func (s *testServer) putAvatar(t *testing.T, user, actor *domain.User, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPut, "/users/"+user.ID.String()+"/avatar", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	setActor(req, actor)
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func multipartFile(t *testing.T, field string, data []byte) (string, []byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile(field, "avatar.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+	return form.FormDataContentType(), buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func jpegConfig(data []byte) (image.Config, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	return config, err
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// listCollectors фильтрует по availability, vehicle_type, hazard, on_shift и точке lat/lon внутри зоны обслуживания
+func (s *UserServer) listCollectors(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.CollectorFilter{
+		Availability: domain.CollectorAvailability(query.Get("availability")),
+		VehicleType:  domain.VehicleType(query.Get("vehicle_type")),
+		Hazard:       query.Get("hazard"),
+		OnShift:      query.Get("on_shift") == "true",
+	}
+
+	var err error
+	if filter.Point, err = parsePointParams(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collectors, err := s.CollectorService.ListCollectors(r.Context(), requestctx.Tenant(r.Context()).ID, filter)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(collectors)
+}
+
+func (s *UserServer) getCollector(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.CollectorService.GetCollector(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+// updateCollectorProfile заменяет машину и доступность сборщика
+func (s *UserServer) updateCollectorProfile(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var profile domain.CollectorProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.CollectorService.UpdateProfile(r.Context(), requestctx.Tenant(r.Context()).ID, userID, &profile)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+func (s *UserServer) replaceCollectorShifts(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var shifts []domain.CollectorShift
+	if err := json.NewDecoder(r.Body).Decode(&shifts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	shifts, err = s.CollectorService.ReplaceShifts(r.Context(), requestctx.Tenant(r.Context()).ID, userID, shifts)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+	if shifts == nil {
+		shifts = []domain.CollectorShift{}
+	}
+
+	json.NewEncoder(w).Encode(shifts)
+}
+
+func (s *UserServer) addCollectorCertification(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var certification domain.CollectorCertification
+	if err := json.NewDecoder(r.Body).Decode(&certification); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.CollectorService.AddCertification(r.Context(), requestctx.Tenant(r.Context()).ID, userID, &certification); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(certification)
+}
+
+func (s *UserServer) deleteCollectorCertification(w http.ResponseWriter, r *http.Request) {
+	userID, certificationID, ok := collectorItemParams(w, r, "certificationID", "Invalid certification ID")
+	if !ok {
+		return
+	}
+
+	if err := s.CollectorService.DeleteCertification(r.Context(), requestctx.Tenant(r.Context()).ID, userID, certificationID); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *UserServer) addCollectorZone(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var zone domain.ServiceZone
+	if err := json.NewDecoder(r.Body).Decode(&zone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.CollectorService.AddZone(r.Context(), requestctx.Tenant(r.Context()).ID, userID, &zone); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(zone)
+}
+
+func (s *UserServer) updateCollectorZone(w http.ResponseWriter, r *http.Request) {
+	userID, zoneID, ok := collectorItemParams(w, r, "zoneID", "Invalid zone ID")
+	if !ok {
+		return
+	}
+
+	var zone domain.ServiceZone
+	if err := json.NewDecoder(r.Body).Decode(&zone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	zone.ID = zoneID
+
+	if err := s.CollectorService.UpdateZone(r.Context(), requestctx.Tenant(r.Context()).ID, userID, &zone); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(zone)
+}
+
+func (s *UserServer) deleteCollectorZone(w http.ResponseWriter, r *http.Request) {
+	userID, zoneID, ok := collectorItemParams(w, r, "zoneID", "Invalid zone ID")
+	if !ok {
+		return
+	}
+
+	if err := s.CollectorService.DeleteZone(r.Context(), requestctx.Tenant(r.Context()).ID, userID, zoneID); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func collectorItemParams(w http.ResponseWriter, r *http.Request, param, invalid string) (userID, itemID uuid.UUID, ok bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	itemID, err = uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		http.Error(w, invalid, http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, itemID, true
+}
+
+// parsePointParams читает пару lat/lon; без обоих параметров точки нет
+func parsePointParams(query url.Values) (*domain.GeoPoint, error) {
+	latValue, lonValue := query.Get("lat"), query.Get("lon")
+	if latValue == "" && lonValue == "" {
+		return nil, nil
+	}
+
+	lat, err := strconv.ParseFloat(latValue, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("invalid lat %q", latValue)
+	}
+	lon, err := strconv.ParseFloat(lonValue, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("invalid lon %q", lonValue)
+	}
+	return &domain.GeoPoint{Latitude: lat, Longitude: lon}, nil
+}
+
+// parseBBoxParam читает bbox=запад,юг,восток,север в градусах; прямоугольник через антимеридиан не поддерживается
+func parseBBoxParam(query url.Values) (*domain.GeoBounds, error) {
+	value := query.Get("bbox")
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid bbox %q: expected west,south,east,north", value)
+	}
+	var sides [4]float64
+	for i, part := range parts {
+		side, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox %q: expected west,south,east,north", value)
+		}
+		sides[i] = side
+	}
+
+	bounds := &domain.GeoBounds{West: sides[0], South: sides[1], East: sides[2], North: sides[3]}
+	if bounds.West < -180 || bounds.East > 180 || bounds.South < -90 || bounds.North > 90 ||
+		bounds.West > bounds.East || bounds.South > bounds.North {
+		return nil, fmt.Errorf("invalid bbox %q: sides are out of range or in the wrong order", value)
+	}
+	return bounds, nil
+}
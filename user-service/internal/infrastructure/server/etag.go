@@ -0,0 +1,49 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"user-service/internal/domain"
+)
+
+func userETag(user *domain.User) string {
+	return strconv.Quote(strconv.FormatInt(user.Version, 10))
+}
+
+// parseIfMatch возвращает ожидаемую версию из If-Match; 0 — заголовка нет или указан "*"
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, errors.New("If-Match with multiple entity tags is not supported")
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, errors.New("invalid If-Match header")
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		return 0, errors.New("invalid If-Match header")
+	}
+	return version, nil
+}
+
+// decodePatchValue разбирает значение поля merge patch: null очищает поле, иначе ожидается строка
+func decodePatchValue(field string, raw json.RawMessage) (*string, error) {
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("field %q must be a string or null", field)
+	}
+	return &value, nil
+}
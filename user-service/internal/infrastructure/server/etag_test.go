@@ -0,0 +1,128 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"user-service/internal/domain"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{header: "", want: 0},
+		{header: "*", want: 0},
+		{header: `"3"`, want: 3},
+		{header: ` "3" `, want: 3},
+		{header: `W/"7"`, want: 7},
+		{header: "3", wantErr: true},
+		{header: `"0"`, wantErr: true},
+		{header: `"-1"`, wantErr: true},
+		{header: `"abc"`, wantErr: true},
+		{header: `"1", "2"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseIfMatch(tt.header)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseIfMatch(%q) = %d, %v; want %d, error %v", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestPatchUserProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		ifMatch     string
+		body        string
+		asOther     bool
+		wantStatus  int
+		wantName    string
+		wantVersion int64
+	}{
+		{name: "sets field", body: `{"name":"Alice"}`, wantStatus: http.StatusOK, wantName: "Alice", wantVersion: 2},
+		{name: "merge patch media type", contentType: "application/merge-patch+json", body: `{"name":"Alice"}`, wantStatus: http.StatusOK, wantName: "Alice", wantVersion: 2},
+		{name: "null clears field", body: `{"name":null}`, wantStatus: http.StatusOK, wantName: "", wantVersion: 2},
+		{name: "empty patch keeps fields", body: `{}`, wantStatus: http.StatusOK, wantName: "Resident", wantVersion: 2},
+		{name: "matching If-Match", ifMatch: `"1"`, body: `{"name":"Alice"}`, wantStatus: http.StatusOK, wantName: "Alice", wantVersion: 2},
+		{name: "stale If-Match", ifMatch: `"5"`, body: `{"name":"Alice"}`, wantStatus: http.StatusPreconditionFailed, wantName: "Resident", wantVersion: 1},
+		{name: "malformed If-Match", ifMatch: "1", body: `{"name":"Alice"}`, wantStatus: http.StatusBadRequest, wantName: "Resident", wantVersion: 1},
+		{name: "read-only field", body: `{"email":"other@example.com"}`, wantStatus: http.StatusBadRequest, wantName: "Resident", wantVersion: 1},
+		{name: "non-string value", body: `{"name":42}`, wantStatus: http.StatusBadRequest, wantName: "Resident", wantVersion: 1},
+		{name: "not an object", body: `["name"]`, wantStatus: http.StatusBadRequest, wantName: "Resident", wantVersion: 1},
+		{name: "unsupported media type", contentType: "text/plain", body: `{"name":"Alice"}`, wantStatus: http.StatusUnsupportedMediaType, wantName: "Resident", wantVersion: 1},
+		{name: "another resident", asOther: true, body: `{"name":"Alice"}`, wantStatus: http.StatusForbidden, wantName: "Resident", wantVersion: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, Config{})
+			user := srv.mustCreateUser(t, "resident@example.com")
+			actor := user
+			if tt.asOther {
+				actor = srv.mustCreateUser(t, "neighbour@example.com")
+			}
+
+			headers := map[string]string{}
+			if tt.contentType != "" {
+				headers["Content-Type"] = tt.contentType
+			}
+			if tt.ifMatch != "" {
+				headers["If-Match"] = tt.ifMatch
+			}
+			rec := srv.do(t, http.MethodPatch, "/users/"+user.ID.String(), actor, tt.body, headers)
+			expectStatus(t, rec, tt.wantStatus)
+
+			if tt.wantStatus == http.StatusOK {
+				if etag := rec.Header().Get("ETag"); etag != userETag(&domain.User{Version: tt.wantVersion}) {
+					t.Errorf("ETag = %s, want version %d", etag, tt.wantVersion)
+				}
+				var got domain.User
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if got.Name != tt.wantName {
+					t.Errorf("response name = %q, want %q", got.Name, tt.wantName)
+				}
+			}
+
+			stored := srv.mustFindUser(t, user)
+			if stored.Name != tt.wantName || stored.Version != tt.wantVersion {
+				t.Errorf("stored name/version = %q/%d, want %q/%d", stored.Name, stored.Version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// PUT заменяет все редактируемые поля: отсутствующее в теле поле очищается
+func TestPutUserProfileReplacesEditableFields(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	rec := srv.do(t, http.MethodPut, "/users/"+user.ID.String(), user, `{"email":"ignored@example.com"}`, map[string]string{"If-Match": `"1"`})
+	expectStatus(t, rec, http.StatusOK)
+
+	stored := srv.mustFindUser(t, user)
+	if stored.Name != "" || stored.Email != user.Email {
+		t.Fatalf("stored name/email = %q/%q, want cleared name and unchanged email", stored.Name, stored.Email)
+	}
+
+	rec = srv.do(t, http.MethodPut, "/users/"+user.ID.String(), user, `{"name":"Alice"}`, map[string]string{"If-Match": `"1"`})
+	expectStatus(t, rec, http.StatusPreconditionFailed)
+}
+
+func TestGetUserProfileReturnsETag(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	rec := srv.do(t, http.MethodGet, "/users/"+user.ID.String(), user, "", nil)
+	expectStatus(t, rec, http.StatusOK)
+	if etag := rec.Header().Get("ETag"); etag != `"1"` {
+		t.Fatalf("ETag = %s, want \"1\"", etag)
+	}
+}
@@ -0,0 +1,267 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+func (s *UserServer) createHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+
+	var household domain.Household
+	if err := json.NewDecoder(r.Body).Decode(&household); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.HouseholdService.CreateHousehold(r.Context(), requestctx.Tenant(r.Context()).ID, actor, &household)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(details)
+}
+
+func (s *UserServer) getHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	details, err := s.HouseholdService.GetHousehold(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, actor)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+func (s *UserServer) getUserHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.HouseholdService.GetUserHousehold(r.Context(), requestctx.Tenant(r.Context()).ID, userID, actor)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+// updateHousehold заменяет название, общий адрес и настройки вывоза целиком
+func (s *UserServer) updateHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var household domain.Household
+	if err := json.NewDecoder(r.Body).Decode(&household); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	household.ID = householdID
+
+	details, err := s.HouseholdService.UpdateHousehold(r.Context(), requestctx.Tenant(r.Context()).ID, actor, &household)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+func (s *UserServer) deleteHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.HouseholdService.DeleteHousehold(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, actor); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// inviteToHousehold выпускает код приглашения; email в теле необязателен
+func (s *UserServer) inviteToHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	invitation, err := s.HouseholdService.Invite(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, actor, req.Email)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+func (s *UserServer) joinHousehold(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.HouseholdService.Join(r.Context(), requestctx.Tenant(r.Context()).ID, actor, req.Code)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+func (s *UserServer) removeHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.HouseholdService.RemoveMember(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, userID, actor); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *UserServer) transferHouseholdOwnership(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	details, err := s.HouseholdService.TransferOwnership(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, req.UserID, actor)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(details)
+}
+
+// getHouseholdSummary принимает те же параметры, что и сводка действий пользователя
+func (s *UserServer) getHouseholdSummary(w http.ResponseWriter, r *http.Request) {
+	actor, ok := householdActor(w, r)
+	if !ok {
+		return
+	}
+	householdID, ok := householdParam(w, r)
+	if !ok {
+		return
+	}
+
+	summaryQuery, err := parseSummaryQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := s.HouseholdService.Summary(r.Context(), requestctx.Tenant(r.Context()).ID, householdID, actor, summaryQuery)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(summary)
+}
+
+// householdActor требует аутентифицированного пользователя шлюза
+func householdActor(w http.ResponseWriter, r *http.Request) (domain.HouseholdActor, bool) {
+	actor := requestctx.ActorFrom(r.Context())
+	if actor == nil {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return domain.HouseholdActor{}, false
+	}
+	return domain.HouseholdActor{IdentityKey: actor.IdentityKey, Admin: actor.Role == domain.RoleAdmin}, true
+}
+
+func householdParam(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	householdID, err := uuid.Parse(chi.URLParam(r, "householdID"))
+	if err != nil {
+		http.Error(w, "Invalid household ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return householdID, true
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/spreadsheet"
+	"user-service/internal/requestctx"
+)
+
+const maxImportFile = 20 << 20
+
+// startImport принимает файл полем file формы multipart/form-data или телом запроса с типом text/csv
+// либо XLSX. Параметры dry_run и invite включают пробный прогон и приглашения через auth-service.
+func (s *UserServer) startImport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	dryRun, err := parseBoolParam(query.Get("dry_run"))
+	if err != nil {
+		http.Error(w, "Invalid dry_run", http.StatusBadRequest)
+		return
+	}
+	invite, err := parseBoolParam(query.Get("invite"))
+	if err != nil {
+		http.Error(w, "Invalid invite", http.StatusBadRequest)
+		return
+	}
+
+	fileName, records, err := readImportFile(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, spreadsheet.ErrUnsupportedFormat) {
+			status = http.StatusUnsupportedMediaType
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	job, err := s.ImportService.StartImport(r.Context(), requestctx.Tenant(r.Context()), domain.ImportRequest{
+		FileName: fileName,
+		Records:  records,
+		DryRun:   dryRun,
+		Invite:   invite,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Location", "/imports/"+job.ID.String())
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+func (s *UserServer) listImports(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := s.ImportService.ListImports(r.Context(), requestctx.Tenant(r.Context()).ID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(jobs)
+}
+
+func (s *UserServer) getImport(w http.ResponseWriter, r *http.Request) {
+	importID, err := uuid.Parse(chi.URLParam(r, "importID"))
+	if err != nil {
+		http.Error(w, "Invalid import ID", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.ImportService.GetImport(r.Context(), requestctx.Tenant(r.Context()).ID, importID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(job)
+}
+
+func readImportFile(r *http.Request) (string, [][]string, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxImportFile+1<<20)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		format, err := spreadsheet.DetectFormat("", r.Header.Get("Content-Type"))
+		if err != nil {
+			return "", nil, err
+		}
+		data, err := spreadsheet.ReadAll(r.Body, maxImportFile)
+		if err != nil {
+			return "", nil, err
+		}
+		records, err := spreadsheet.Read(format, data)
+		return "", records, err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return "", nil, errors.New("multipart form must contain a file field")
+	}
+	defer file.Close()
+
+	format, err := spreadsheet.DetectFormat(header.Filename, header.Header.Get("Content-Type"))
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := spreadsheet.ReadAll(file, maxImportFile)
+	if err != nil {
+		return "", nil, err
+	}
+	records, err := spreadsheet.Read(format, data)
+	return header.Filename, records, err
+}
+
+func parseBoolParam(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// requestContext переносит идентификатор запроса и пользователя из заголовков шлюза в контекст.
+// Заголовки пользователя без токена шлюза отклоняются: иначе их мог бы подставить любой клиент сети.
+func (s *UserServer) requestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestctx.RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestctx.RequestIDHeader, requestID)
+
+		ctx := requestctx.WithRequestID(r.Context(), requestID)
+		if authUserID := r.Header.Get(requestctx.UserIDHeader); authUserID != "" {
+			if !s.fromGateway(r) {
+				http.Error(w, "Invalid gateway token", http.StatusUnauthorized)
+				return
+			}
+			// Некорректный ключ равносилен отсутствующему: такой пользователь не владеет ни одним профилем
+			identityKey, _ := uuid.Parse(r.Header.Get(requestctx.UserIdentityKeyHeader))
+			ctx = requestctx.WithActor(ctx, &requestctx.Actor{
+				AuthUserID:  authUserID,
+				Email:       r.Header.Get(requestctx.UserEmailHeader),
+				Role:        domain.UserRole(r.Header.Get(requestctx.UserRoleHeader)),
+				IdentityKey: identityKey,
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *UserServer) fromGateway(r *http.Request) bool {
+	if s.GatewayToken == "" {
+		return true
+	}
+	token := r.Header.Get(requestctx.GatewayTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.GatewayToken)) == 1
+}
+
+// Тенант определяется шлюзом по токену и передаётся в заголовке
+func (s *UserServer) tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, err := s.TenantService.ResolveTenant(r.Context(), r.Header.Get(requestctx.TenantHeader))
+		if err != nil {
+			if errors.Is(err, domain.ErrTenantNotFound) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(requestctx.WithTenant(r.Context(), tenant)))
+	})
+}
+
+// requireRole пропускает только запросы от пользователей с одной из указанных ролей
+func requireRole(roles ...domain.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			actor := requestctx.ActorFrom(r.Context())
+			if actor == nil {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+			for _, role := range roles {
+				if actor.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		})
+	}
+}
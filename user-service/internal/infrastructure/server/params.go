@@ -0,0 +1,84 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"user-service/internal/domain"
+)
+
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	return parseTimeParamIn(query, name, time.UTC)
+}
+
+// parseTimeParamIn трактует дату без времени как полночь в часовом поясе loc
+func parseTimeParamIn(query url.Values, name string, loc *time.Location) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		// Допускаем и просто дату
+		if t, err = time.ParseInLocation(time.DateOnly, value, loc); err != nil {
+			return nil, fmt.Errorf("invalid %s: expected RFC 3339 timestamp or YYYY-MM-DD", name)
+		}
+	}
+	return &t, nil
+}
+
+func parsePageParams(query url.Values) (limit, offset int, err error) {
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return 0, 0, errors.New("invalid limit")
+		}
+	}
+	if value := query.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, errors.New("invalid offset")
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseActionTypes принимает и повторяющийся параметр type, и список через запятую
+func parseActionTypes(values []string) []domain.ActionType {
+	var types []domain.ActionType
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				types = append(types, domain.ActionType(part))
+			}
+		}
+	}
+	return types
+}
+
+// parseSummaryQuery читает type, category, period, tz и границы from/to в этом часовом поясе
+func parseSummaryQuery(query url.Values) (domain.ActionSummaryQuery, error) {
+	summaryQuery := domain.ActionSummaryQuery{
+		Types:    parseActionTypes(query["type"]),
+		Category: query.Get("category"),
+		Period:   domain.AggregationPeriod(query.Get("period")),
+	}
+
+	var err error
+	if summaryQuery.Location, err = parseLocationParam(query); err != nil {
+		return summaryQuery, err
+	}
+	if summaryQuery.From, err = parseTimeParamIn(query, "from", summaryQuery.Location); err != nil {
+		return summaryQuery, err
+	}
+	if summaryQuery.To, err = parseTimeParamIn(query, "to", summaryQuery.Location); err != nil {
+		return summaryQuery, err
+	}
+	return summaryQuery, nil
+}
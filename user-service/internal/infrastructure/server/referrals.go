@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// getReferral возвращает код пользователя и счётчики его приглашений; код выпускается при первом запросе
+func (s *UserServer) getReferral(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := s.ReferralService.GetReferralSummary(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (s *UserServer) getReferralStats(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := parsePageParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.ReferralService.ReferralStats(r.Context(), requestctx.Tenant(r.Context()).ID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// referralObserver передаёт действия сервису приглашений, который создаётся после actionService
+type referralObserver struct {
+	service domain.ReferralService
+}
+
+func (o *referralObserver) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	if o.service != nil {
+		o.service.ActionRecorded(ctx, action)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/validation"
+)
+
+// Тела запросов отделены от доменных типов: клиент не может задать id, tenant_id, даты и версию,
+// а правила проверки описаны тегами validate рядом с полями.
+
+type createUserRequest struct {
+	Email       string          `json:"email" validate:"required,max=254,email"`
+	Name        string          `json:"name" validate:"max=200"`
+	Role        string          `json:"role" validate:"oneof=user admin collector"`
+	IdentityKey string          `json:"identity_key" validate:"uuid"`
+	Address     *addressRequest `json:"address"`
+}
+
+func (r createUserRequest) user() *domain.User {
+	user := &domain.User{
+		Email: strings.TrimSpace(r.Email),
+		Name:  strings.TrimSpace(r.Name),
+		Role:  domain.UserRole(r.Role),
+	}
+	if r.IdentityKey != "" {
+		key := uuid.MustParse(r.IdentityKey)
+		user.IdentityKey = &key
+	}
+	return user
+}
+
+// addressRequest — тело добавления и замены адреса; координаты передаются только парой
+type addressRequest struct {
+	Label      string   `json:"label" validate:"max=50"`
+	Street     string   `json:"street" validate:"required,max=200"`
+	House      string   `json:"house" validate:"max=200"`
+	Apartment  string   `json:"apartment" validate:"max=200"`
+	City       string   `json:"city" validate:"required,max=200"`
+	PostalCode string   `json:"postal_code" validate:"max=200"`
+	Latitude   *float64 `json:"lat" validate:"required_with=lon,min=-90,max=90"`
+	Longitude  *float64 `json:"lon" validate:"required_with=lat,min=-180,max=180"`
+	IsPrimary  bool     `json:"is_primary"`
+}
+
+func (r addressRequest) address() *domain.Address {
+	return &domain.Address{
+		Label:      r.Label,
+		Street:     r.Street,
+		House:      r.House,
+		Apartment:  r.Apartment,
+		City:       r.City,
+		PostalCode: r.PostalCode,
+		Latitude:   r.Latitude,
+		Longitude:  r.Longitude,
+		IsPrimary:  r.IsPrimary,
+	}
+}
+
+type validationErrorResponse struct {
+	Error  string                  `json:"error"`
+	Fields []validation.FieldError `json:"fields"`
+}
+
+// decodeRequest читает JSON-объект без неизвестных полей и проверяет его по тегам validate.
+// При ошибке ответ уже записан и возвращается false.
+func decodeRequest(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(dst)
+	if err == nil {
+		err = validation.Struct(dst)
+	}
+	if err == nil {
+		return true
+	}
+
+	var fieldErrs validation.Errors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &fieldErrs):
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		fieldErrs = validation.Errors{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type.Kind())}}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json не экспортирует тип этой ошибки, имя поля есть только в тексте
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		fieldErrs = validation.Errors{{Field: field, Message: "is not allowed"}}
+	default:
+		http.Error(w, "Request body must be a JSON object: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(validationErrorResponse{Error: "validation failed", Fields: fieldErrs})
+	return false
+}
+
+func jsonTypeName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
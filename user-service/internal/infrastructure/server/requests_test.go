@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/validation"
+)
+
+func TestCreateUserValidation(t *testing.T) {
+	identityKey := uuid.NewString()
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields []validation.FieldError
+	}{
+		{name: "email only", body: `{"email":"new@example.com"}`, wantStatus: http.StatusCreated},
+		{
+			name:       "full body with address",
+			body:       `{"email":"new@example.com","name":"Alice","role":"collector","identity_key":"` + identityKey + `","address":{"city":"Almaty","street":"Abay","lat":43.2,"lon":76.9}}`,
+			wantStatus: http.StatusCreated,
+		},
+		{name: "missing email", body: `{"name":"Alice"}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "email", Message: "is required"}}},
+		{name: "invalid email", body: `{"email":"alice"}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "email", Message: "must be a valid email address"}}},
+		{name: "unknown role", body: `{"email":"new@example.com","role":"root"}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "role", Message: "must be one of: user, admin, collector"}}},
+		{name: "bad identity key", body: `{"email":"new@example.com","identity_key":"42"}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "identity_key", Message: "must be a UUID"}}},
+		{name: "client sets id", body: `{"email":"new@example.com","id":"` + identityKey + `"}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "id", Message: "is not allowed"}}},
+		{name: "wrong type", body: `{"email":"new@example.com","name":42}`, wantStatus: http.StatusBadRequest, wantFields: []validation.FieldError{{Field: "name", Message: "must be a string"}}},
+		{
+			name:       "nested address errors",
+			body:       `{"email":"new@example.com","address":{"street":"Abay","lat":43.2}}`,
+			wantStatus: http.StatusBadRequest,
+			wantFields: []validation.FieldError{{Field: "address.city", Message: "is required"}, {Field: "address.lon", Message: "is required when lat is set"}},
+		},
+		{name: "not JSON", body: `email=new@example.com`, wantStatus: http.StatusBadRequest},
+	}
+
+	admin := &domain.User{Email: "admin@example.com", Role: domain.RoleAdmin}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, Config{})
+
+			rec := srv.do(t, http.MethodPost, "/users", admin, tt.body, nil)
+			expectStatus(t, rec, tt.wantStatus)
+
+			if tt.wantFields != nil {
+				var response validationErrorResponse
+				if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if len(response.Fields) != len(tt.wantFields) {
+					t.Fatalf("fields = %+v, want %+v", response.Fields, tt.wantFields)
+				}
+				for i := range response.Fields {
+					if response.Fields[i] != tt.wantFields[i] {
+						t.Errorf("field %d = %+v, want %+v", i, response.Fields[i], tt.wantFields[i])
+					}
+				}
+			}
+
+			users, _, err := srv.repos.Users.List(context.Background(), srv.tenant.ID, domain.UserFilter{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			// Отклонённое тело не создаёт профиль
+			if created := len(users) == 1; created != (tt.wantStatus == http.StatusCreated) {
+				t.Fatalf("tenant has %d users after status %d", len(users), rec.Code)
+			}
+		})
+	}
+}
+
+func TestCreateUserRequiresAdmin(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	resident := srv.mustCreateUser(t, "resident@example.com")
+
+	rec := srv.do(t, http.MethodPost, "/users", resident, `{"email":"new@example.com"}`, nil)
+	expectStatus(t, rec, http.StatusForbidden)
+}
+
+func TestAddAddressValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantField  string
+	}{
+		{name: "valid", body: `{"city":"Almaty","street":"Abay","house":"10"}`, wantStatus: http.StatusCreated},
+		{name: "missing street", body: `{"city":"Almaty"}`, wantStatus: http.StatusBadRequest, wantField: "street"},
+		{name: "label too long", body: `{"city":"Almaty","street":"Abay","label":"` + strings.Repeat("x", 51) + `"}`, wantStatus: http.StatusBadRequest, wantField: "label"},
+		{name: "latitude out of range", body: `{"city":"Almaty","street":"Abay","lat":91,"lon":76.9}`, wantStatus: http.StatusBadRequest, wantField: "lat"},
+		{name: "longitude without latitude", body: `{"city":"Almaty","street":"Abay","lon":76.9}`, wantStatus: http.StatusBadRequest, wantField: "lat"},
+		{name: "client sets user_id", body: `{"city":"Almaty","street":"Abay","user_id":"x"}`, wantStatus: http.StatusBadRequest, wantField: "user_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, Config{})
+			user := srv.mustCreateUser(t, "resident@example.com")
+
+			rec := srv.do(t, http.MethodPost, "/users/"+user.ID.String()+"/addresses", user, tt.body, nil)
+			expectStatus(t, rec, tt.wantStatus)
+			if tt.wantField == "" {
+				return
+			}
+			var response validationErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if len(response.Fields) != 1 || response.Fields[0].Field != tt.wantField {
+				t.Fatalf("fields = %+v, want one error on %s", response.Fields, tt.wantField)
+			}
+		})
+	}
+}
@@ -2,29 +2,115 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"messaging"
 	"user-service/internal/domain"
+	"user-service/internal/infrastructure/events"
+	"user-service/internal/infrastructure/geocoding"
 	"user-service/internal/infrastructure/repository"
+	"user-service/internal/requestctx"
 	"user-service/internal/usecase"
 )
 
 type UserServer struct {
-	Router      *chi.Mux
-	UserService domain.UserService
+	Router              *chi.Mux
+	UserService         domain.UserService
+	TenantService       domain.TenantService
+	ActionService       domain.ActionService
+	PointsService       domain.PointsService
+	AchievementService  domain.AchievementService
+	AddressService      domain.AddressService
+	HouseholdService    domain.HouseholdService
+	CollectorService    domain.CollectorService
+	PreferencesService  domain.PreferencesService
+	AccountService      domain.AccountService
+	ImportService       domain.ImportService
+	ReferralService     domain.ReferralService
+	UserStatsService    domain.UserStatsService
+	AvatarService       domain.AvatarService
+	ActionFeed          *events.ActionFeed
+	PlatformAdminKey    string
+	ActionIngestSecret  string
+	AccountEventsSecret string
+	GatewayToken        string
 }
 
-func NewUserServer(db *gorm.DB) *UserServer {
-	userRepo := repository.NewPostgresUserRepository(db)
-	userService := usecase.NewUserService(userRepo)
+// Config — настройки сервера из окружения; пустые секреты отключают соответствующие эндпоинты
+type Config struct {
+	PlatformAdminKey    string
+	ActionIngestSecret  string
+	AccountEventsSecret string
+	// Общий со шлюзом токен: заголовкам X-User-* верим только вместе с ним.
+	// Пустой токен отключает проверку, так можно только при закрытом от внешней сети сервисе.
+	GatewayToken string
+	// Часовой пояс, в котором считаются серии, если запрос не указал свой
+	DefaultLocation *time.Location
+	// Геокодер адресов; по умолчанию встроенный офлайн-справочник
+	Geocoder domain.Geocoder
+	// Приглашения жителей при импорте; без него импорт с invite=true отклоняется
+	Inviter domain.Inviter
+	// Хранилище миниатюр аватаров; без него загрузка аватаров отклоняется
+	Blobs domain.BlobStore
+	// Брокер, через который лента действий и кэш статистики узнают о действиях всех экземпляров;
+	// подписки на топик user-actions оформляет main. Без брокера они видят только действия этого экземпляра.
+	Events messaging.Publisher
+}
+
+func NewUserServer(repos repository.Repositories, cfg Config) *UserServer {
+	pointsService := usecase.NewPointsService(repos.Points, repos.Users)
+	achievementService := usecase.NewAchievementService(repos.Achievements, repos.Users, domain.DefaultAchievements, cfg.DefaultLocation)
+	actionFeed := events.NewActionFeed()
+	statsService := usecase.NewUserStatsService(repos.Users, cfg.DefaultLocation)
+	// Сервис приглашений записывает награды через actionService и сам следит за его действиями,
+	// поэтому наблюдатель получает его после создания actionService
+	referralActivity := &referralObserver{}
+	observers := []domain.ActionObserver{pointsService, achievementService, referralActivity}
+	if cfg.Events != nil {
+		observers = append(observers, events.ActionPublisher(cfg.Events))
+	} else {
+		observers = append(observers, actionFeed, statsService)
+	}
+	actionService := usecase.NewActionService(repos.Users, repos.Tenants, observers...)
+	userService := usecase.NewUserService(repos.Users, actionService)
+	tenantService := usecase.NewTenantService(repos.Tenants)
+
+	geocoder := cfg.Geocoder
+	if geocoder == nil {
+		geocoder = geocoding.DefaultGazetteer()
+	}
+	referralService := usecase.NewReferralService(repos.Referrals, repos.Users, actionService)
+	referralActivity.service = referralService
+	addressService := usecase.NewAddressService(repos.Users, geocoder, referralService)
 
 	srv := &UserServer{
-		Router:      chi.NewRouter(),
-		UserService: userService,
+		Router:              chi.NewRouter(),
+		UserService:         userService,
+		TenantService:       tenantService,
+		ActionService:       actionService,
+		PointsService:       pointsService,
+		AchievementService:  achievementService,
+		AddressService:      addressService,
+		HouseholdService:    usecase.NewHouseholdService(repos.Households, repos.Users, repos.Points),
+		CollectorService:    usecase.NewCollectorService(repos.Collectors, repos.Users, cfg.DefaultLocation),
+		PreferencesService:  usecase.NewPreferencesService(repos.Preferences, repos.Users),
+		AccountService:      usecase.NewAccountService(repos.Users, repos.Tenants, referralService),
+		ImportService:       usecase.NewImportService(repos.Users, addressService, repos.Imports, cfg.Inviter),
+		ReferralService:     referralService,
+		UserStatsService:    statsService,
+		AvatarService:       usecase.NewAvatarService(repos.Users, cfg.Blobs, actionService),
+		ActionFeed:          actionFeed,
+		PlatformAdminKey:    cfg.PlatformAdminKey,
+		ActionIngestSecret:  cfg.ActionIngestSecret,
+		AccountEventsSecret: cfg.AccountEventsSecret,
+		GatewayToken:        cfg.GatewayToken,
 	}
 
 	srv.setupRoutes()
@@ -32,27 +118,150 @@ func NewUserServer(db *gorm.DB) *UserServer {
 }
 
 func (s *UserServer) setupRoutes() {
-	s.Router.Post("/users", s.createUser)
-	s.Router.Get("/users/{id}", s.getUserProfile)
-	s.Router.Put("/users/{id}", s.updateUserProfile)
-	s.Router.Get("/users/{id}/actions", s.getUserActions)
+	s.Router.Use(s.requestContext)
+
+	s.Router.Post("/tenants", s.createTenant)
+	s.Router.Post("/actions/ingest", s.ingestAction)
+	s.Router.Post("/events/accounts", s.receiveAccountEvent)
+	s.Router.Get("/achievements", s.listAchievementDefinitions)
+	s.Router.Get("/avatars/{avatarID}/{file}", s.getAvatar)
+
+	s.Router.Group(func(r chi.Router) {
+		r.Use(s.tenantMiddleware)
+
+		r.Get("/users/{id}", s.getUserProfile)
+		r.Put("/users/{id}", s.updateUserProfile)
+		r.Patch("/users/{id}", s.patchUserProfile)
+		r.Get("/users/{id}/actions", s.getUserActions)
+		r.Get("/users/{id}/actions/summary", s.getUserActionSummary)
+		r.Get("/users/{id}/actions/stream", s.streamUserActions)
+		r.Get("/users/{id}/stats", s.getUserStats)
+		r.Put("/users/{id}/avatar", s.putAvatar)
+		r.Delete("/users/{id}/avatar", s.deleteAvatar)
+		r.Get("/users/{id}/points", s.getPointsBalance)
+		r.Get("/users/{id}/points/history", s.getPointsHistory)
+		r.Get("/users/{id}/achievements", s.getUserAchievements)
+		r.Get("/users/{id}/addresses", s.listAddresses)
+		r.Post("/users/{id}/addresses", s.addAddress)
+		r.Put("/users/{id}/addresses/{addressID}", s.updateAddress)
+		r.Delete("/users/{id}/addresses/{addressID}", s.deleteAddress)
+		r.Post("/users/{id}/addresses/{addressID}/primary", s.setPrimaryAddress)
+		r.Get("/users/{id}/preferences", s.getPreferences)
+		r.Put("/users/{id}/preferences", s.updatePreferences)
+		r.Get("/users/{id}/household", s.getUserHousehold)
+		r.Get("/users/{id}/referral", s.getReferral)
+		r.Post("/households", s.createHousehold)
+		r.Post("/households/join", s.joinHousehold)
+		r.Get("/households/{householdID}", s.getHousehold)
+		r.Put("/households/{householdID}", s.updateHousehold)
+		r.Delete("/households/{householdID}", s.deleteHousehold)
+		r.Post("/households/{householdID}/invitations", s.inviteToHousehold)
+		r.Post("/households/{householdID}/owner", s.transferHouseholdOwnership)
+		r.Delete("/households/{householdID}/members/{userID}", s.removeHouseholdMember)
+		r.Get("/households/{householdID}/summary", s.getHouseholdSummary)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(domain.RoleAdmin))
+
+			// Обычные профили появляются из событий auth-service; вручную их заводит только админ
+			r.Post("/users", s.createUser)
+			r.Get("/users", s.listUsers)
+			r.Get("/users/search", s.searchUsers)
+			r.Delete("/users/{id}", s.deleteUser)
+			r.Post("/imports", s.startImport)
+			r.Get("/imports", s.listImports)
+			r.Get("/imports/{importID}", s.getImport)
+			r.Get("/referrals/stats", s.getReferralStats)
+			r.Get("/stats/users", s.getTenantStats)
+			r.Post("/users/{id}/points/adjustments", s.adjustPoints)
+			r.Get("/points/rules", s.listEarningRules)
+			r.Put("/points/rules/{action}", s.setEarningRule)
+			r.Post("/users/{id}/achievements/evaluate", s.evaluateUserAchievements)
+			r.Get("/collectors", s.listCollectors)
+			r.Get("/collectors/{id}", s.getCollector)
+			r.Put("/collectors/{id}", s.updateCollectorProfile)
+			r.Put("/collectors/{id}/shifts", s.replaceCollectorShifts)
+			r.Post("/collectors/{id}/certifications", s.addCollectorCertification)
+			r.Delete("/collectors/{id}/certifications/{certificationID}", s.deleteCollectorCertification)
+			r.Post("/collectors/{id}/zones", s.addCollectorZone)
+			r.Put("/collectors/{id}/zones/{zoneID}", s.updateCollectorZone)
+			r.Delete("/collectors/{id}/zones/{zoneID}", s.deleteCollectorZone)
+			r.Get("/zones/export", s.exportZones)
+		})
+	})
 }
 
 func (s *UserServer) Routes() http.Handler {
 	return s.Router
 }
 
-func (s *UserServer) createUser(w http.ResponseWriter, r *http.Request) {
-	var user domain.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+func userErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrAddressNotFound), errors.Is(err, gorm.ErrRecordNotFound),
+		errors.Is(err, domain.ErrHouseholdNotFound), errors.Is(err, domain.ErrInvitationNotFound),
+		errors.Is(err, domain.ErrZoneNotFound), errors.Is(err, domain.ErrCertificationNotFound), errors.Is(err, domain.ErrImportNotFound),
+		errors.Is(err, domain.ErrReferralCodeNotFound), errors.Is(err, domain.ErrAvatarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCrossTenantAccess), errors.Is(err, domain.ErrNotHouseholdMember), errors.Is(err, domain.ErrNotHouseholdOwner),
+		errors.Is(err, domain.ErrNotReferralOwner), errors.Is(err, domain.ErrNotProfileOwner):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrInsufficientPoints), errors.Is(err, domain.ErrAlreadyInHousehold),
+		errors.Is(err, domain.ErrInvitationInvalid), errors.Is(err, domain.ErrOwnerMustTransfer), errors.Is(err, domain.ErrNotCollector),
+		errors.Is(err, domain.ErrAlreadyReferred), errors.Is(err, domain.ErrProfileEmailTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrTenantNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrImportsStopped):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (s *UserServer) createTenant(w http.ResponseWriter, r *http.Request) {
+	if s.PlatformAdminKey == "" || r.Header.Get("X-Platform-Admin-Key") != s.PlatformAdminKey {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	var tenant domain.Tenant
+	if err := json.NewDecoder(r.Body).Decode(&tenant); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := s.UserService.CreateUser(&user); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := s.TenantService.CreateTenant(r.Context(), &tenant); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tenant)
+}
+
+// createUser создаёт профиль и, если в теле есть address, его первый адрес
+func (s *UserServer) createUser(w http.ResponseWriter, r *http.Request) {
+	var request createUserRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	tenantID := requestctx.Tenant(r.Context()).ID
+	user := request.user()
+	if err := s.UserService.CreateUser(r.Context(), tenantID, user); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
 		return
 	}
+	if request.Address != nil {
+		if err := s.AddressService.AddAddress(r.Context(), tenantID, user.ID, request.Address.address()); err != nil {
+			http.Error(w, "user "+user.ID.String()+" was created without the address: "+err.Error(), userErrorStatus(err))
+			return
+		}
+	}
 
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
@@ -65,15 +274,17 @@ func (s *UserServer) getUserProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := s.UserService.GetUserProfile(userID)
+	user, err := s.UserService.GetUserProfile(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), userErrorStatus(err))
 		return
 	}
 
+	w.Header().Set("ETag", userETag(user))
 	json.NewEncoder(w).Encode(user)
 }
 
+// updateUserProfile заменяет редактируемые поля целиком: отсутствующие в теле поля очищаются
 func (s *UserServer) updateUserProfile(w http.ResponseWriter, r *http.Request) {
 	userID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
@@ -81,33 +292,142 @@ func (s *UserServer) updateUserProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var user domain.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+	expectedVersion, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	user.ID = userID
-	if err := s.UserService.UpdateUserProfile(&user); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	json.NewEncoder(w).Encode(user)
+	// Поля только для чтения (id, email, role, даты) из тела PUT игнорируются
+	patch := domain.UserPatch{}
+	for _, field := range domain.EditableUserFields {
+		patch[field] = nil
+		if raw, ok := body[field]; ok {
+			if patch[field], err = decodePatchValue(field, raw); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	s.applyUserPatch(w, r, userID, patch, expectedVersion)
 }
 
-func (s *UserServer) getUserActions(w http.ResponseWriter, r *http.Request) {
+// patchUserProfile применяет JSON Merge Patch (RFC 7386) к редактируемым полям профиля
+func (s *UserServer) patchUserProfile(w http.ResponseWriter, r *http.Request) {
 	userID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
 
-	actions, err := s.UserService.GetUserActionHistory(userID)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		http.Error(w, "Content-Type must be application/merge-patch+json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Merge patch must be a JSON object", http.StatusBadRequest)
+		return
+	}
+
+	patch := domain.UserPatch{}
+	for field, raw := range body {
+		if patch[field], err = decodePatchValue(field, raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.applyUserPatch(w, r, userID, patch, expectedVersion)
+}
+
+func (s *UserServer) applyUserPatch(w http.ResponseWriter, r *http.Request, userID uuid.UUID, patch domain.UserPatch, expectedVersion int64) {
+	user, err := s.UserService.UpdateUserProfile(r.Context(), requestctx.Tenant(r.Context()).ID, userID, patch, expectedVersion)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("ETag", userETag(user))
+	json.NewEncoder(w).Encode(user)
+}
+
+func (s *UserServer) listUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := domain.UserFilter{
+		Role:    domain.UserRole(query.Get("role")),
+		Name:    query.Get("name"),
+		Address: query.Get("address"),
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTimeParam(query, "created_from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.CreatedTo, err = parseTimeParam(query, "created_to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, filter.Offset, err = parsePageParams(query); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.UserService.ListUsers(r.Context(), requestctx.Tenant(r.Context()).ID, filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *UserServer) searchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, offset, err := parsePageParams(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.UserService.SearchUsers(r.Context(), requestctx.Tenant(r.Context()).ID, query.Get("q"), limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(page)
+}
+
+func (s *UserServer) deleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.UserService.DeleteUser(r.Context(), requestctx.Tenant(r.Context()).ID, userID); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
 		return
 	}
 
-	json.NewEncoder(w).Encode(actions)
+	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/repository"
+	"user-service/internal/requestctx"
+)
+
+// testServer — сервер на репозиториях в памяти и тенант по умолчанию
+type testServer struct {
+	*UserServer
+	repos  repository.Repositories
+	tenant *domain.Tenant
+}
+
+func newTestServer(t *testing.T, cfg Config) *testServer {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	tenant, err := repos.Tenants.FindTenantBySlug(context.Background(), domain.DefaultTenantSlug)
+	if err != nil {
+		t.Fatalf("FindTenantBySlug: %v", err)
+	}
+	return &testServer{UserServer: NewUserServer(repos, cfg), repos: repos, tenant: tenant}
+}
+
+func (s *testServer) mustCreateUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	now := time.Now()
+	identityKey := uuid.New()
+	user := &domain.User{
+		ID:          uuid.New(),
+		TenantID:    s.tenant.ID,
+		IdentityKey: &identityKey,
+		Email:       email,
+		Name:        "Resident",
+		Role:        domain.RoleUser,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+	}
+	if err := s.repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user
+}
+
+func (s *testServer) mustFindUser(t *testing.T, user *domain.User) *domain.User {
+	t.Helper()
+	found, err := s.repos.Users.FindByID(context.Background(), s.tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	return found
+}
+
+// do выполняет запрос от имени actor так, как его передал бы шлюз; nil actor — анонимный запрос
+func (s *testServer) do(t *testing.T, method, path string, actor *domain.User, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	setActor(req, actor)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rec := httptest.NewRecorder()
+	s.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+// setActor проставляет заголовки, которыми шлюз передаёт проверенного пользователя
+func setActor(req *http.Request, actor *domain.User) {
+	if actor == nil {
+		return
+	}
+	req.Header.Set(requestctx.UserIDHeader, "1")
+	req.Header.Set(requestctx.UserEmailHeader, actor.Email)
+	req.Header.Set(requestctx.UserRoleHeader, string(actor.Role))
+	if actor.IdentityKey != nil {
+		req.Header.Set(requestctx.UserIdentityKeyHeader, actor.IdentityKey.String())
+	}
+}
+
+func expectStatus(t *testing.T, rec *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if rec.Code != want {
+		t.Fatalf("status = %d (%s), want %d", rec.Code, strings.TrimSpace(rec.Body.String()), want)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+
+	// Подписи старше этого окна отклоняются, чтобы перехваченный запрос нельзя было повторить позже
+	signatureMaxAge = 5 * time.Minute
+	maxIngestBody   = 64 << 10
+)
+
+var errInvalidSignature = errors.New("invalid request signature")
+
+// signBody вычисляет подпись "sha256=<hex>" от "<timestamp>.<тело запроса>"
+func signBody(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignedBody читает тело запроса и проверяет его подпись общим секретом
+func verifySignedBody(r *http.Request, secret []byte, now time.Time) ([]byte, error) {
+	timestamp := r.Header.Get(SignatureTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureMaxAge || age < -signatureMaxAge {
+		return nil, errInvalidSignature
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxIngestBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxIngestBody {
+		return nil, errors.New("request body too large")
+	}
+
+	signature := strings.TrimSpace(r.Header.Get(SignatureHeader))
+	if !hmac.Equal([]byte(signature), []byte(signBody(secret, timestamp, body))) {
+		return nil, errInvalidSignature
+	}
+	return body, nil
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+func (s *UserServer) getUserStats(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.UserStatsService.GetUserStats(r.Context(), requestctx.Tenant(r.Context()).ID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// getTenantStats — сводка для панели администратора города: period, from и to задают окно
+// активных пользователей, cohorts — число месячных когорт, districts — длину рейтинга районов
+func (s *UserServer) getTenantStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	statsQuery := domain.TenantStatsQuery{Period: domain.AggregationPeriod(query.Get("period"))}
+
+	var err error
+	if statsQuery.From, err = parseTimeParam(query, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if statsQuery.To, err = parseTimeParam(query, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name, target := range map[string]*int{"cohorts": &statsQuery.Cohorts, "districts": &statsQuery.TopDistricts} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		if *target, err = strconv.Atoi(value); err != nil || *target < 0 {
+			http.Error(w, fmt.Sprintf("invalid %s: expected a non-negative integer", name), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := s.UserStatsService.GetTenantStats(r.Context(), requestctx.Tenant(r.Context()).ID, statsQuery)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
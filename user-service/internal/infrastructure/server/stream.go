@@ -0,0 +1,137 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	// Комментарий раз в столько времени не даёт прокси закрыть молчащее соединение
+	streamHeartbeat = 15 * time.Second
+	// Сколько действий дочитывается из истории после переподключения; более старое клиент
+	// перечитывает через GET /users/{id}/actions
+	maxStreamReplay  = 1000
+	streamReplayPage = 100
+)
+
+// streamUserActions отдаёт новые действия пользователя как Server-Sent Events. id события — курсор
+// истории, поэтому после обрыва EventSource присылает его в Last-Event-ID, и пропущенное
+// дочитывается из базы. Действия, записанные задним числом во время обрыва, в дочитку не попадают.
+func (s *UserServer) streamUserActions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	var resumeAfter *domain.ActionCursor
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		if resumeAfter, err = domain.ParseActionCursor(lastEventID); err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	tenantID := requestctx.Tenant(r.Context()).ID
+	if _, err := s.UserService.GetOwnProfile(r.Context(), tenantID, userID); err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	// Подписка раньше дочитки, чтобы не потерять действия, записанные между ними
+	live, unsubscribe := s.ActionFeed.Subscribe(tenantID, userID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	sent := map[uuid.UUID]bool{}
+	if resumeAfter != nil {
+		replayed, err := s.replayActions(w, r, tenantID, userID, resumeAfter)
+		if err != nil {
+			// Ответ уже начат, поэтому ошибка передаётся событием; клиент переподключится с тем же Last-Event-ID
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+			flusher.Flush()
+			return
+		}
+		sent = replayed
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case action, ok := <-live:
+			if !ok {
+				// Клиент не успевал читать и отключён от ленты; при переподключении он дочитает историю
+				return
+			}
+			if sent[action.ID] {
+				continue
+			}
+			if err := writeActionEvent(w, action); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// replayActions дочитывает историю после курсора по возрастанию и возвращает ID отправленных действий
+func (s *UserServer) replayActions(w http.ResponseWriter, r *http.Request, tenantID, userID uuid.UUID, after *domain.ActionCursor) (map[uuid.UUID]bool, error) {
+	sent := map[uuid.UUID]bool{}
+	for len(sent) < maxStreamReplay {
+		page, err := s.ActionService.ListActions(r.Context(), tenantID, userID, domain.ActionFilter{
+			Order: domain.SortAsc,
+			After: after,
+			Limit: streamReplayPage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, action := range page.Actions {
+			if err := writeActionEvent(w, action); err != nil {
+				return nil, err
+			}
+			sent[action.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		if after, err = domain.ParseActionCursor(page.NextCursor); err != nil {
+			return nil, err
+		}
+	}
+	return sent, nil
+}
+
+func writeActionEvent(w http.ResponseWriter, action domain.UserAction) error {
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	cursor := domain.ActionCursor{CreatedAt: action.CreatedAt, ID: action.ID}.Encode()
+	_, err = fmt.Fprintf(w, "id: %s\nevent: action\ndata: %s\n\n", cursor, data)
+	return err
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+// sseEvent — событие ленты; блоки без event (retry, комментарии) читатель пропускает
+type sseEvent struct {
+	id, event, data string
+}
+
+// openStream подключается к ленте пользователя и читает события в фоне до конца теста
+func (s *testServer) openStream(t *testing.T, user *domain.User, lastEventID string) (*http.Response, <-chan sseEvent) {
+	t.Helper()
+	httpServer := httptest.NewServer(s.Router)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		httpServer.Close()
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/users/"+user.ID.String()+"/actions/stream", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	setActor(req, user)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var current sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if current.event != "" {
+					events <- current
+				}
+				current = sseEvent{}
+				continue
+			}
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				current.id = value
+			case "event":
+				current.event = value
+			case "data":
+				current.data = value
+			}
+		}
+	}()
+	return resp, events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatalf("stream closed before the next event")
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event within 5s")
+	}
+	return sseEvent{}
+}
+
+// expectActionEvent проверяет, что событие несёт действие action, а его id — курсор этого действия
+func expectActionEvent(t *testing.T, event sseEvent, action domain.UserAction) {
+	t.Helper()
+	var got domain.UserAction
+	if err := json.Unmarshal([]byte(event.data), &got); err != nil {
+		t.Fatalf("decode event data %q: %v", event.data, err)
+	}
+	if event.event != "action" || got.ID != action.ID {
+		t.Fatalf("event %s with action %s, want action %s", event.event, got.ID, action.ID)
+	}
+	if want := (domain.ActionCursor{CreatedAt: action.CreatedAt, ID: action.ID}).Encode(); event.id != want {
+		t.Fatalf("event id = %q, want the action cursor %q", event.id, want)
+	}
+}
+
+// После обрыва лента дочитывает действия после Last-Event-ID и продолжает живыми событиями
+func TestStreamUserActionsResumesAfterLastEventID(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+
+	var history []domain.UserAction
+	for i := 3; i > 0; i-- {
+		action := domain.UserAction{ID: uuid.New(), TenantID: srv.tenant.ID, UserID: user.ID, Action: domain.ActionWasteSorted,
+			Source: "test", CreatedAt: time.Now().UTC().Add(-time.Duration(i) * time.Minute)}
+		if err := srv.repos.Users.RecordUserAction(context.Background(), &action); err != nil {
+			t.Fatalf("RecordUserAction: %v", err)
+		}
+		history = append(history, action)
+	}
+
+	lastSeen := domain.ActionCursor{CreatedAt: history[0].CreatedAt, ID: history[0].ID}.Encode()
+	resp, events := srv.openStream(t, user, lastSeen)
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("stream response = %d %s, want 200 text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	expectActionEvent(t, nextEvent(t, events), history[1])
+	expectActionEvent(t, nextEvent(t, events), history[2])
+
+	if err := srv.ActionService.RecordAction(context.Background(), srv.tenant.ID, user.ID, domain.ActionPointVisited, ""); err != nil {
+		t.Fatalf("RecordAction: %v", err)
+	}
+	live := nextEvent(t, events)
+	var action domain.UserAction
+	if err := json.Unmarshal([]byte(live.data), &action); err != nil {
+		t.Fatalf("decode live event: %v", err)
+	}
+	if action.Action != domain.ActionPointVisited {
+		t.Fatalf("live event action = %s, want %s", action.Action, domain.ActionPointVisited)
+	}
+	expectActionEvent(t, live, action)
+}
+
+// Без Last-Event-ID история не дочитывается: лента начинается с новых действий
+func TestStreamUserActionsWithoutLastEventID(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+	if err := srv.ActionService.RecordAction(context.Background(), srv.tenant.ID, user.ID, domain.ActionWasteSorted, ""); err != nil {
+		t.Fatalf("RecordAction: %v", err)
+	}
+
+	_, events := srv.openStream(t, user, "")
+	if err := srv.ActionService.RecordAction(context.Background(), srv.tenant.ID, user.ID, domain.ActionPointVisited, ""); err != nil {
+		t.Fatalf("RecordAction: %v", err)
+	}
+	var action domain.UserAction
+	if err := json.Unmarshal([]byte(nextEvent(t, events).data), &action); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if action.Action != domain.ActionPointVisited {
+		t.Fatalf("first event action = %s, want only the new %s", action.Action, domain.ActionPointVisited)
+	}
+}
+
+func TestStreamUserActionsRejectsInvalidLastEventID(t *testing.T) {
+	srv := newTestServer(t, Config{})
+	user := srv.mustCreateUser(t, "resident@example.com")
+	url := "/users/" + user.ID.String() + "/actions/stream"
+
+	expectStatus(t, srv.do(t, http.MethodGet, url, user, "", map[string]string{"Last-Event-ID": "not-a-cursor"}), http.StatusBadRequest)
+}
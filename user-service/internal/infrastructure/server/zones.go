@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+type zoneFeature struct {
+	Type       string            `json:"type"`
+	ID         uuid.UUID         `json:"id"`
+	Geometry   domain.GeoPolygon `json:"geometry"`
+	Properties zoneProperties    `json:"properties"`
+}
+
+type zoneProperties struct {
+	Name        string    `json:"name"`
+	CollectorID uuid.UUID `json:"collector_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type zoneCollection struct {
+	Type     string        `json:"type"`
+	Features []zoneFeature `json:"features"`
+}
+
+// exportZones отдаёт зоны обслуживания всех сборщиков как GeoJSON FeatureCollection.
+// Шлюз публикует его как /map/zones/export рядом с выгрузкой пунктов приёма из map-service.
+func (s *UserServer) exportZones(w http.ResponseWriter, r *http.Request) {
+	bounds, err := parseBBoxParam(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	zones, err := s.CollectorService.ListTenantZones(r.Context(), requestctx.Tenant(r.Context()).ID, bounds)
+	if err != nil {
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	collection := zoneCollection{Type: "FeatureCollection", Features: make([]zoneFeature, 0, len(zones))}
+	for _, zone := range zones {
+		collection.Features = append(collection.Features, zoneFeature{
+			Type:     "Feature",
+			ID:       zone.ID,
+			Geometry: zone.Boundary,
+			Properties: zoneProperties{
+				Name:        zone.Name,
+				CollectorID: zone.UserID,
+				CreatedAt:   zone.CreatedAt,
+				UpdatedAt:   zone.UpdatedAt,
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(collection)
+}
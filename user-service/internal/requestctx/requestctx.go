@@ -0,0 +1,65 @@
+package requestctx
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	TenantHeader    = "X-Tenant"
+	UserIDHeader    = "X-User-ID"
+	UserEmailHeader = "X-User-Email"
+	UserRoleHeader  = "X-User-Role"
+	// Постоянный ключ учётной записи auth-service; по нему профиль сопоставляется с пользователем
+	UserIdentityKeyHeader = "X-User-Identity-Key"
+	// Токен, которым шлюз подтверждает заголовки X-User-*
+	GatewayTokenHeader = "X-Gateway-Token"
+)
+
+// Actor — аутентифицированный пользователь, от имени которого шлюз выполняет запрос.
+// Email не подтверждается auth-service, поэтому владельца профиля определяет только IdentityKey.
+type Actor struct {
+	AuthUserID  string
+	Email       string
+	Role        domain.UserRole
+	IdentityKey uuid.UUID
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	tenantKey
+	actorKey
+)
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+func WithTenant(ctx context.Context, tenant *domain.Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+func Tenant(ctx context.Context) *domain.Tenant {
+	tenant, _ := ctx.Value(tenantKey).(*domain.Tenant)
+	return tenant
+}
+
+func WithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFrom(ctx context.Context) *Actor {
+	actor, _ := ctx.Value(actorKey).(*Actor)
+	return actor
+}
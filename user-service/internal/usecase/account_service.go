@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+type AccountServiceImpl struct {
+	users   domain.UserRepository
+	tenants domain.TenantRepository
+	// Привязывает нового жителя к пригласившему по коду из регистрации; nil — код игнорируется
+	referrals domain.ReferralService
+}
+
+func NewAccountService(users domain.UserRepository, tenants domain.TenantRepository, referrals domain.ReferralService) domain.AccountService {
+	return &AccountServiceImpl{users: users, tenants: tenants, referrals: referrals}
+}
+
+// ApplyAccountEvent приводит профиль к состоянию учётной записи из события.
+// Повторная доставка того же события ничего не меняет, а событие с версией не новее
+// уже применённой отбрасывается: так опоздавшее событие не откатит профиль и не воскресит удалённый.
+func (s *AccountServiceImpl) ApplyAccountEvent(ctx context.Context, event domain.AccountEvent) (*domain.User, error) {
+	if err := validateAccountEvent(event); err != nil {
+		return nil, err
+	}
+
+	slug := event.Tenant
+	if slug == "" {
+		slug = domain.DefaultTenantSlug
+	}
+	tenant, err := s.tenants.FindTenantBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Version > 0 {
+		applied, err := s.users.AccountVersion(ctx, tenant.ID, event.IdentityKey)
+		if err != nil {
+			return nil, err
+		}
+		if event.Version <= applied {
+			// Регистрация опоздала за более новым событием, которое уже создало профиль, но код пригласившего есть только в ней
+			if event.Type == domain.EventAccountRegistered {
+				if user, err := s.users.FindByIdentityKey(ctx, tenant.ID, event.IdentityKey); err == nil {
+					s.attributeReferral(ctx, user, event)
+				}
+			}
+			return nil, nil
+		}
+	}
+
+	user, err := s.users.FindByIdentityKey(ctx, tenant.ID, event.IdentityKey)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if event.Type == domain.EventAccountDeleted {
+		if user == nil {
+			return nil, nil
+		}
+		// Версия сохраняется в удалённом профиле, чтобы опоздавшие события его не вернули
+		if event.Version > user.AccountVersion {
+			user.AccountVersion = event.Version
+			if err := s.users.Update(ctx, user); err != nil {
+				return nil, err
+			}
+		}
+		return nil, s.users.Delete(ctx, tenant.ID, user.ID)
+	}
+
+	if user == nil {
+		// Профиль, заведённый раньше учётной записи, находится по ключу из приглашения.
+		// По email не привязываем: auth-service его не подтверждает, и чужой профиль достался бы
+		// тому, кто первым зарегистрировался на этот адрес.
+		_, err := s.users.FindByEmail(ctx, tenant.ID, event.Email)
+		switch {
+		case err == nil:
+			return nil, domain.ErrProfileEmailTaken
+		case !errors.Is(err, domain.ErrUserNotFound):
+			return nil, err
+		}
+		user, err := s.createFromAccount(ctx, tenant.ID, event)
+		if err != nil {
+			return nil, err
+		}
+		s.attributeReferral(ctx, user, event)
+		return user, nil
+	}
+
+	if user.InvitedAt == nil && user.Email == event.Email && user.Role == event.Role && user.AccountVersion >= event.Version {
+		return user, nil
+	}
+	// Приглашение принято: ключ больше не зарезервирован, а принадлежит учётной записи
+	user.InvitedAt = nil
+	user.Email = event.Email
+	user.Role = event.Role
+	if event.Version > user.AccountVersion {
+		user.AccountVersion = event.Version
+	}
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *AccountServiceImpl) createFromAccount(ctx context.Context, tenantID uuid.UUID, event domain.AccountEvent) (*domain.User, error) {
+	identityKey := event.IdentityKey
+	now := time.Now()
+	user := &domain.User{
+		ID:             uuid.New(),
+		IdentityKey:    &identityKey,
+		TenantID:       tenantID,
+		Email:          event.Email,
+		Role:           event.Role,
+		AccountVersion: event.Version,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Version:        1,
+	}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// attributeReferral привязывает профиль к пригласившему по коду из регистрации.
+// Неизвестный код или повторная привязка не должны останавливать обработку события, поэтому ошибка только логируется.
+func (s *AccountServiceImpl) attributeReferral(ctx context.Context, user *domain.User, event domain.AccountEvent) {
+	if s.referrals == nil || event.Referral == nil || event.Referral.Code == "" {
+		return
+	}
+	_, err := s.referrals.AttributeReferral(ctx, user.TenantID, user.ID, *event.Referral)
+	if err != nil && !errors.Is(err, domain.ErrAlreadyReferred) {
+		log.Printf("Referral from account event %s not attributed (request %s): %v", event.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+func validateAccountEvent(event domain.AccountEvent) error {
+	switch event.Type {
+	case domain.EventAccountRegistered, domain.EventAccountEmailChanged, domain.EventAccountRoleChanged, domain.EventAccountDeleted:
+	default:
+		return fmt.Errorf("%w: unknown account event %q", domain.ErrInvalidInput, event.Type)
+	}
+
+	switch {
+	case event.ID == uuid.Nil:
+		return fmt.Errorf("%w: event id is required", domain.ErrInvalidInput)
+	case event.IdentityKey == uuid.Nil:
+		return fmt.Errorf("%w: identity_key is required", domain.ErrInvalidInput)
+	case event.Type == domain.EventAccountDeleted:
+		return nil
+	case event.Email == "":
+		return fmt.Errorf("%w: email is required", domain.ErrInvalidInput)
+	case event.Role != domain.RoleUser && event.Role != domain.RoleAdmin && event.Role != domain.RoleCollector:
+		return fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, event.Role)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+func TestApplyAccountEventLinking(t *testing.T) {
+	tests := []struct {
+		name      string
+		prepare   func(t *testing.T, f *fixture, key uuid.UUID) *domain.User
+		wantErr   error
+		wantSame  bool
+		wantUsers int
+	}{
+		{
+			name:      "new account creates a profile",
+			prepare:   func(*testing.T, *fixture, uuid.UUID) *domain.User { return nil },
+			wantUsers: 1,
+		},
+		{
+			name: "invited profile is linked by the reserved key",
+			prepare: func(t *testing.T, f *fixture, key uuid.UUID) *domain.User {
+				user := f.mustCreateUser(t, "resident@example.com")
+				invitedAt := time.Now()
+				user.IdentityKey, user.InvitedAt = &key, &invitedAt
+				if err := f.Users.Update(context.Background(), user); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+				return user
+			},
+			wantSame:  true,
+			wantUsers: 1,
+		},
+		{
+			name: "profile with the same email is not taken over",
+			prepare: func(t *testing.T, f *fixture, _ uuid.UUID) *domain.User {
+				user := f.mustCreateUser(t, "resident@example.com")
+				user.IdentityKey = nil
+				if err := f.Users.Update(context.Background(), user); err != nil {
+					t.Fatalf("Update: %v", err)
+				}
+				return user
+			},
+			wantErr:   domain.ErrProfileEmailTaken,
+			wantUsers: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			service := NewAccountService(f.Users, f.Tenants, nil)
+			key := uuid.New()
+			existing := tt.prepare(t, f, key)
+
+			event := domain.AccountEvent{ID: uuid.New(), Type: domain.EventAccountRegistered, IdentityKey: key, Email: "resident@example.com", Role: domain.RoleUser, Version: 1}
+			user, err := service.ApplyAccountEvent(context.Background(), event)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyAccountEvent error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if user.IdentityKey == nil || *user.IdentityKey != key || user.InvitedAt != nil {
+					t.Fatalf("profile = %+v, want linked to %s without a pending invitation", user, key)
+				}
+				if tt.wantSame && user.ID != existing.ID {
+					t.Fatalf("linked profile %s, want the invited profile %s", user.ID, existing.ID)
+				}
+			}
+
+			_, total, err := f.Users.List(context.Background(), f.tenant.ID, domain.UserFilter{Limit: domain.MaxPageLimit})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if total != int64(tt.wantUsers) {
+				t.Fatalf("tenant has %d profiles, want %d", total, tt.wantUsers)
+			}
+		})
+	}
+}
+
+// События применяются в любом порядке: побеждает старшая версия, повтор ничего не меняет,
+// а опоздавшее событие не воскрешает удалённый профиль
+func TestApplyAccountEventVersions(t *testing.T) {
+	key := uuid.New()
+	event := func(eventType string, version int64, email string, role domain.UserRole) domain.AccountEvent {
+		return domain.AccountEvent{ID: uuid.New(), Type: eventType, IdentityKey: key, Email: email, Role: role, Version: version}
+	}
+	registered := event(domain.EventAccountRegistered, 1, "old@example.com", domain.RoleUser)
+	emailChanged := event(domain.EventAccountEmailChanged, 2, "new@example.com", domain.RoleUser)
+	roleChanged := event(domain.EventAccountRoleChanged, 3, "new@example.com", domain.RoleCollector)
+	deleted := event(domain.EventAccountDeleted, 4, "", "")
+
+	tests := []struct {
+		name      string
+		events    []domain.AccountEvent
+		wantEmail string
+		wantRole  domain.UserRole
+		deleted   bool
+	}{
+		{name: "in order", events: []domain.AccountEvent{registered, emailChanged, roleChanged}, wantEmail: "new@example.com", wantRole: domain.RoleCollector},
+		{name: "newest first", events: []domain.AccountEvent{roleChanged, registered, emailChanged}, wantEmail: "new@example.com", wantRole: domain.RoleCollector},
+		{name: "redelivery", events: []domain.AccountEvent{registered, emailChanged, emailChanged, registered}, wantEmail: "new@example.com", wantRole: domain.RoleUser},
+		{name: "deleted", events: []domain.AccountEvent{registered, emailChanged, deleted}, deleted: true},
+		{name: "late events after delete", events: []domain.AccountEvent{registered, deleted, emailChanged, roleChanged}, deleted: true},
+		{
+			name:      "unversioned events always apply",
+			events:    []domain.AccountEvent{event(domain.EventAccountRegistered, 0, "old@example.com", domain.RoleUser), event(domain.EventAccountEmailChanged, 0, "new@example.com", domain.RoleUser)},
+			wantEmail: "new@example.com",
+			wantRole:  domain.RoleUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			service := NewAccountService(f.Users, f.Tenants, nil)
+			for _, e := range tt.events {
+				if _, err := service.ApplyAccountEvent(context.Background(), e); err != nil {
+					t.Fatalf("ApplyAccountEvent(%s v%d): %v", e.Type, e.Version, err)
+				}
+			}
+
+			user, err := f.Users.FindByIdentityKey(context.Background(), f.tenant.ID, key)
+			if tt.deleted {
+				if !errors.Is(err, domain.ErrUserNotFound) {
+					t.Fatalf("FindByIdentityKey = %+v, %v; want the profile deleted", user, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindByIdentityKey: %v", err)
+			}
+			if user.Email != tt.wantEmail || user.Role != tt.wantRole {
+				t.Fatalf("profile email/role = %s/%s, want %s/%s", user.Email, user.Role, tt.wantEmail, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestApplyAccountEventValidation(t *testing.T) {
+	valid := domain.AccountEvent{ID: uuid.New(), Type: domain.EventAccountRegistered, IdentityKey: uuid.New(), Email: "resident@example.com", Role: domain.RoleUser}
+	tests := []struct {
+		name    string
+		change  func(e *domain.AccountEvent)
+		wantErr error
+	}{
+		{name: "unknown type", change: func(e *domain.AccountEvent) { e.Type = "user.renamed" }, wantErr: domain.ErrInvalidInput},
+		{name: "no id", change: func(e *domain.AccountEvent) { e.ID = uuid.Nil }, wantErr: domain.ErrInvalidInput},
+		{name: "no identity key", change: func(e *domain.AccountEvent) { e.IdentityKey = uuid.Nil }, wantErr: domain.ErrInvalidInput},
+		{name: "no email", change: func(e *domain.AccountEvent) { e.Email = "" }, wantErr: domain.ErrInvalidInput},
+		{name: "unknown role", change: func(e *domain.AccountEvent) { e.Role = "root" }, wantErr: domain.ErrInvalidInput},
+		{name: "unknown tenant", change: func(e *domain.AccountEvent) { e.Tenant = "nowhere" }, wantErr: domain.ErrTenantNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			event := valid
+			tt.change(&event)
+			if _, err := NewAccountService(f.Users, f.Tenants, nil).ApplyAccountEvent(context.Background(), event); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyAccountEvent error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
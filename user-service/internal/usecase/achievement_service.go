@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+type AchievementServiceImpl struct {
+	achievements domain.AchievementRepository
+	users        domain.UserRepository
+	definitions  []domain.AchievementDefinition
+	location     *time.Location
+	now          func() time.Time
+}
+
+// NewAchievementService считает серии в часовом поясе location, если запрос не указал свой
+func NewAchievementService(achievements domain.AchievementRepository, users domain.UserRepository, definitions []domain.AchievementDefinition, location *time.Location) *AchievementServiceImpl {
+	if location == nil {
+		location = time.UTC
+	}
+	return &AchievementServiceImpl{
+		achievements: achievements,
+		users:        users,
+		definitions:  definitions,
+		location:     location,
+		now:          time.Now,
+	}
+}
+
+func (s *AchievementServiceImpl) Definitions() []domain.AchievementDefinition {
+	return s.definitions
+}
+
+// ActionRecorded пересчитывает только достижения, на которые влияет тип и категория нового действия
+func (s *AchievementServiceImpl) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	_, err := s.evaluate(ctx, action.TenantID, action.UserID, s.location, func(definition domain.AchievementDefinition) bool {
+		return definition.Action == action.Action && (definition.Category == "" || definition.Category == action.Category)
+	})
+	if err != nil {
+		log.Printf("Failed to evaluate achievements for user %s (request %s): %v", action.UserID, requestctx.RequestID(ctx), err)
+	}
+}
+
+func (s *AchievementServiceImpl) Evaluate(ctx context.Context, tenantID, userID uuid.UUID, loc *time.Location) ([]domain.UserAchievement, error) {
+	if _, err := s.users.FindByID(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, tenantID, userID, s.locationOr(loc), func(domain.AchievementDefinition) bool { return true })
+}
+
+func (s *AchievementServiceImpl) Overview(ctx context.Context, tenantID, userID uuid.UUID, loc *time.Location) (*domain.AchievementOverview, error) {
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+	loc = s.locationOr(loc)
+
+	earned, err := s.earnedAt(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &domain.AchievementOverview{
+		Timezone:   loc.String(),
+		Earned:     []domain.AchievementProgress{},
+		InProgress: []domain.AchievementProgress{},
+	}
+	for _, definition := range s.definitions {
+		progress, err := s.progress(ctx, tenantID, userID, definition, loc)
+		if err != nil {
+			return nil, err
+		}
+		if at, ok := earned[definition.Code]; ok {
+			progress.EarnedAt = &at
+			overview.Earned = append(overview.Earned, *progress)
+		} else {
+			overview.InProgress = append(overview.InProgress, *progress)
+		}
+	}
+	return overview, nil
+}
+
+// evaluate выдаёт значки за выполненные, но ещё не выданные достижения и возвращает новые
+func (s *AchievementServiceImpl) evaluate(ctx context.Context, tenantID, userID uuid.UUID, loc *time.Location, affected func(domain.AchievementDefinition) bool) ([]domain.UserAchievement, error) {
+	earned, err := s.earnedAt(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var awarded []domain.UserAchievement
+	for _, definition := range s.definitions {
+		if _, ok := earned[definition.Code]; ok || !affected(definition) {
+			continue
+		}
+
+		progress, err := s.progress(ctx, tenantID, userID, definition, loc)
+		if err != nil {
+			return awarded, err
+		}
+		if progress.Progress < definition.Target {
+			continue
+		}
+
+		achievement := domain.UserAchievement{TenantID: tenantID, UserID: userID, Code: definition.Code, EarnedAt: s.now().UTC()}
+		if err := s.achievements.AwardAchievement(ctx, &achievement); err != nil {
+			if errors.Is(err, domain.ErrAchievementAwarded) {
+				continue
+			}
+			return awarded, err
+		}
+		awarded = append(awarded, achievement)
+	}
+	return awarded, nil
+}
+
+// progress для серии — самая длинная серия, чтобы прерванная после выполнения серия не отнимала прогресс
+func (s *AchievementServiceImpl) progress(ctx context.Context, tenantID, userID uuid.UUID, definition domain.AchievementDefinition, loc *time.Location) (*domain.AchievementProgress, error) {
+	period := definition.Period
+	if definition.Kind == domain.AchievementCount {
+		period = domain.PeriodMonth
+	}
+
+	counts, err := s.users.CountUserActions(ctx, tenantID, userID, domain.ActionSummaryQuery{
+		Types:    []domain.ActionType{definition.Action},
+		Category: definition.Category,
+		Period:   period,
+		Location: loc,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &domain.AchievementProgress{AchievementDefinition: definition}
+	if definition.Kind == domain.AchievementCount {
+		for _, count := range counts {
+			progress.Progress += count.Count
+		}
+	} else {
+		progress.CurrentStreak, progress.LongestStreak = streaks(counts, definition, s.now().In(loc), loc)
+		progress.Progress = progress.LongestStreak
+	}
+	if progress.Progress > definition.Target {
+		progress.Progress = definition.Target
+	}
+	return progress, nil
+}
+
+// streaks считает серии подряд идущих периодов. Текущая серия не прерывается, пока не закончился
+// текущий период: если на этой неделе действий ещё не было, серия продолжается с прошлой недели.
+func streaks(counts []domain.ActionCount, definition domain.AchievementDefinition, now time.Time, loc *time.Location) (current, longest int64) {
+	minPerPeriod := definition.MinPerPeriod
+	if minPerPeriod <= 0 {
+		minPerPeriod = 1
+	}
+
+	var run int64
+	var last time.Time
+	for _, count := range counts {
+		if count.Count < minPerPeriod {
+			continue
+		}
+		start := count.PeriodStart.In(loc)
+		if run > 0 && definition.Period.Next(last).Equal(start) {
+			run++
+		} else {
+			run = 1
+		}
+		last = start
+		if run > longest {
+			longest = run
+		}
+	}
+
+	thisPeriod := definition.Period.Start(now, loc)
+	if run > 0 && (last.Equal(thisPeriod) || definition.Period.Next(last).Equal(thisPeriod)) {
+		current = run
+	}
+	return current, longest
+}
+
+func (s *AchievementServiceImpl) earnedAt(ctx context.Context, tenantID, userID uuid.UUID) (map[string]time.Time, error) {
+	achievements, err := s.achievements.ListUserAchievements(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	earned := make(map[string]time.Time, len(achievements))
+	for _, achievement := range achievements {
+		earned[achievement.Code] = achievement.EarnedAt
+	}
+	return earned, nil
+}
+
+func (s *AchievementServiceImpl) locationOr(loc *time.Location) *time.Location {
+	if loc == nil {
+		return s.location
+	}
+	return loc
+}
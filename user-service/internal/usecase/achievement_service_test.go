@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"user-service/internal/domain"
+)
+
+func TestStreaks(t *testing.T) {
+	almaty := time.FixedZone("UTC+5", 5*60*60)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	daily := domain.AchievementDefinition{Kind: domain.AchievementStreak, Period: domain.PeriodDay, MinPerPeriod: 1}
+	weekly := domain.AchievementDefinition{Kind: domain.AchievementStreak, Period: domain.PeriodWeek, MinPerPeriod: 2}
+
+	day := func(loc *time.Location, month time.Month, d int, count int64) domain.ActionCount {
+		return domain.ActionCount{PeriodStart: time.Date(2025, month, d, 0, 0, 0, 0, loc), Count: count}
+	}
+	tests := []struct {
+		name        string
+		definition  domain.AchievementDefinition
+		counts      []domain.ActionCount
+		now         time.Time
+		loc         *time.Location
+		wantCurrent int64
+		wantLongest int64
+	}{
+		{name: "no actions", definition: daily, now: time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC), loc: time.UTC},
+		{
+			name:        "run ending today",
+			definition:  daily,
+			counts:      []domain.ActionCount{day(time.UTC, 3, 8, 1), day(time.UTC, 3, 9, 1), day(time.UTC, 3, 10, 1)},
+			now:         time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			wantCurrent: 3, wantLongest: 3,
+		},
+		{
+			name:        "today not done yet keeps the run",
+			definition:  daily,
+			counts:      []domain.ActionCount{day(time.UTC, 3, 8, 1), day(time.UTC, 3, 9, 1)},
+			now:         time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			wantCurrent: 2, wantLongest: 2,
+		},
+		{
+			name:        "missed day breaks the run",
+			definition:  daily,
+			counts:      []domain.ActionCount{day(time.UTC, 3, 5, 1), day(time.UTC, 3, 6, 1), day(time.UTC, 3, 7, 1), day(time.UTC, 3, 9, 1)},
+			now:         time.Date(2025, 3, 12, 12, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			wantCurrent: 0, wantLongest: 3,
+		},
+		{
+			name:        "period below minimum does not count",
+			definition:  weekly,
+			counts:      []domain.ActionCount{day(time.UTC, 3, 3, 2), day(time.UTC, 3, 10, 1), day(time.UTC, 3, 17, 3)},
+			now:         time.Date(2025, 3, 18, 12, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			wantCurrent: 1, wantLongest: 1,
+		},
+		{
+			name:        "consecutive weeks",
+			definition:  weekly,
+			counts:      []domain.ActionCount{day(time.UTC, 3, 3, 2), day(time.UTC, 3, 10, 5), day(time.UTC, 3, 17, 2)},
+			now:         time.Date(2025, 3, 24, 9, 0, 0, 0, time.UTC),
+			loc:         time.UTC,
+			wantCurrent: 3, wantLongest: 3,
+		},
+		{
+			name:        "days follow the local calendar",
+			definition:  daily,
+			counts:      []domain.ActionCount{day(almaty, 3, 9, 1), day(almaty, 3, 10, 1)},
+			now:         time.Date(2025, 3, 10, 20, 0, 0, 0, time.UTC), // уже 11 марта в UTC+5
+			loc:         almaty,
+			wantCurrent: 2, wantLongest: 2,
+		},
+		{
+			// 30 марта в Берлине длится 23 часа
+			name:        "run across a DST switch",
+			definition:  daily,
+			counts:      []domain.ActionCount{day(berlin, 3, 29, 1), day(berlin, 3, 30, 1), day(berlin, 3, 31, 1)},
+			now:         time.Date(2025, 3, 31, 18, 0, 0, 0, berlin),
+			loc:         berlin,
+			wantCurrent: 3, wantLongest: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := streaks(tt.counts, tt.definition, tt.now, tt.loc)
+			if current != tt.wantCurrent || longest != tt.wantLongest {
+				t.Fatalf("streaks = %d/%d, want %d/%d", current, longest, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
+
+func TestEvaluateAchievements(t *testing.T) {
+	almaty := time.FixedZone("UTC+5", 5*60*60)
+	definitions := []domain.AchievementDefinition{
+		{Code: "sort_3", Kind: domain.AchievementCount, Action: domain.ActionWasteSorted, Target: 3},
+		{Code: "plastic_2", Kind: domain.AchievementCount, Action: domain.ActionWasteSorted, Category: "plastic", Target: 2},
+		{Code: "daily_2", Kind: domain.AchievementStreak, Action: domain.ActionWasteSorted, Period: domain.PeriodDay, MinPerPeriod: 1, Target: 2},
+	}
+
+	type recorded struct {
+		at       time.Time
+		category string
+	}
+	// Вечер 1 марта по UTC — это уже 2 марта в UTC+5
+	evening := recorded{at: time.Date(2025, 3, 1, 20, 0, 0, 0, time.UTC), category: "glass"}
+	nextDay := recorded{at: time.Date(2025, 3, 2, 10, 0, 0, 0, time.UTC), category: "plastic"}
+	tests := []struct {
+		name    string
+		actions []recorded
+		loc     *time.Location
+		want    []string
+	}{
+		{name: "nothing yet", loc: time.UTC},
+		{name: "two UTC days make a streak", actions: []recorded{evening, nextDay}, loc: time.UTC, want: []string{"daily_2"}},
+		{name: "same local day is not a streak", actions: []recorded{evening, nextDay}, loc: almaty},
+		{
+			name:    "count and category",
+			actions: []recorded{nextDay, {at: nextDay.at.Add(time.Hour), category: "plastic"}, {at: nextDay.at.Add(2 * time.Hour), category: "paper"}},
+			loc:     time.UTC,
+			want:    []string{"sort_3", "plastic_2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t)
+			service := NewAchievementService(f.Achievements, f.Users, definitions, tt.loc)
+			service.now = func() time.Time { return time.Date(2025, 3, 2, 18, 0, 0, 0, time.UTC) }
+			user := f.mustCreateUser(t, "resident@example.com")
+			for _, r := range tt.actions {
+				action := f.action(user, domain.ActionWasteSorted, r.at)
+				action.Category = r.category
+				if err := f.Users.RecordUserAction(ctx, &action); err != nil {
+					t.Fatalf("RecordUserAction: %v", err)
+				}
+			}
+
+			awarded, err := service.Evaluate(ctx, f.tenant.ID, user.ID, nil)
+			if err != nil {
+				t.Fatalf("Evaluate: %v", err)
+			}
+			var got []string
+			for _, achievement := range awarded {
+				got = append(got, achievement.Code)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("awarded %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("awarded %v, want %v", got, tt.want)
+				}
+			}
+
+			// Выданный значок не выдаётся повторно
+			again, err := service.Evaluate(ctx, f.tenant.ID, user.ID, nil)
+			if err != nil || len(again) != 0 {
+				t.Fatalf("second Evaluate = %v, %v; want nothing new", again, err)
+			}
+		})
+	}
+}
+
+func TestAchievementOverview(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	definitions := []domain.AchievementDefinition{
+		{Code: "sort_1", Kind: domain.AchievementCount, Action: domain.ActionWasteSorted, Target: 1},
+		{Code: "sort_5", Kind: domain.AchievementCount, Action: domain.ActionWasteSorted, Target: 5},
+	}
+	service := NewAchievementService(f.Achievements, f.Users, definitions, time.UTC)
+	user := f.mustCreateUser(t, "resident@example.com")
+	for i := 0; i < 2; i++ {
+		action := f.action(user, domain.ActionWasteSorted, time.Now().Add(-time.Duration(i)*time.Hour))
+		if err := f.Users.RecordUserAction(ctx, &action); err != nil {
+			t.Fatalf("RecordUserAction: %v", err)
+		}
+		service.ActionRecorded(ctx, action)
+	}
+
+	overview, err := service.Overview(actorContext(user), f.tenant.ID, user.ID, nil)
+	if err != nil {
+		t.Fatalf("Overview: %v", err)
+	}
+	if overview.Timezone != "UTC" || len(overview.Earned) != 1 || len(overview.InProgress) != 1 {
+		t.Fatalf("overview = %+v, want one earned and one in progress", overview)
+	}
+	if earned := overview.Earned[0]; earned.Code != "sort_1" || earned.EarnedAt == nil || earned.Progress != 1 {
+		t.Errorf("earned = %+v, want sort_1 with progress capped at the target", earned)
+	}
+	if pending := overview.InProgress[0]; pending.Code != "sort_5" || pending.Progress != 2 {
+		t.Errorf("in progress = %+v, want sort_5 at 2/5", pending)
+	}
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+// Насколько время события может опережать часы сервиса
+const maxActionClockSkew = 5 * time.Minute
+
+type ActionServiceImpl struct {
+	users     domain.UserRepository
+	tenants   domain.TenantRepository
+	observers []domain.ActionObserver
+}
+
+func NewActionService(users domain.UserRepository, tenants domain.TenantRepository, observers ...domain.ActionObserver) domain.ActionService {
+	return &ActionServiceImpl{users: users, tenants: tenants, observers: observers}
+}
+
+func (s *ActionServiceImpl) RecordAction(ctx context.Context, tenantID, userID uuid.UUID, action domain.ActionType, details string) error {
+	if !action.Valid() {
+		return fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, action)
+	}
+
+	return s.record(ctx, &domain.UserAction{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		UserID:    userID,
+		Action:    action,
+		Source:    domain.ActionSourceUserService,
+		Details:   details,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+// IngestAction записывает действие из другого сервиса; повтор события с тем же ID возвращает domain.ErrActionExists
+func (s *ActionServiceImpl) IngestAction(ctx context.Context, event domain.ActionEvent) (*domain.UserAction, error) {
+	switch {
+	case event.ID == uuid.Nil:
+		return nil, fmt.Errorf("%w: event id is required", domain.ErrInvalidInput)
+	case event.UserID == uuid.Nil:
+		return nil, fmt.Errorf("%w: user_id is required", domain.ErrInvalidInput)
+	case !event.Action.Valid():
+		return nil, fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, event.Action)
+	case event.Source == "":
+		return nil, fmt.Errorf("%w: source is required", domain.ErrInvalidInput)
+	}
+
+	occurredAt := event.OccurredAt.UTC()
+	if occurredAt.IsZero() {
+		occurredAt = time.Now().UTC()
+	}
+	if occurredAt.After(time.Now().Add(maxActionClockSkew)) {
+		return nil, fmt.Errorf("%w: occurred_at is in the future", domain.ErrInvalidInput)
+	}
+
+	slug := event.Tenant
+	if slug == "" {
+		slug = domain.DefaultTenantSlug
+	}
+	tenant, err := s.tenants.FindTenantBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Действие должно принадлежать существующему пользователю того же тенанта
+	if _, err := s.users.FindByID(ctx, tenant.ID, event.UserID); err != nil {
+		return nil, err
+	}
+
+	action := &domain.UserAction{
+		ID:        event.ID,
+		TenantID:  tenant.ID,
+		UserID:    event.UserID,
+		Action:    event.Action,
+		Source:    event.Source,
+		Category:  strings.ToLower(strings.TrimSpace(event.Category)),
+		Details:   event.Details,
+		CreatedAt: occurredAt,
+	}
+	if err := s.record(ctx, action); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// record сохраняет действие и уведомляет наблюдателей; дубликат наблюдателям не передаётся
+func (s *ActionServiceImpl) record(ctx context.Context, action *domain.UserAction) error {
+	if err := s.users.RecordUserAction(ctx, action); err != nil {
+		return err
+	}
+	for _, observer := range s.observers {
+		observer.ActionRecorded(ctx, *action)
+	}
+	return nil
+}
+
+// ListActions и SummarizeActions доступны только самому пользователю и админу
+func (s *ActionServiceImpl) ListActions(ctx context.Context, tenantID, userID uuid.UUID, filter domain.ActionFilter) (*domain.ActionPage, error) {
+	if err := validateActionRange(filter.Types, filter.From, filter.To); err != nil {
+		return nil, err
+	}
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+	switch filter.Order {
+	case "":
+		filter.Order = domain.SortDesc
+	case domain.SortAsc, domain.SortDesc:
+	default:
+		return nil, fmt.Errorf("%w: order must be asc or desc", domain.ErrInvalidInput)
+	}
+
+	limit, _ := normalizePage(filter.Limit, 0)
+	// Лишняя запись показывает, есть ли следующая страница
+	filter.Limit = limit + 1
+
+	actions, err := s.users.GetUserActions(ctx, tenantID, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ActionPage{Actions: actions}
+	if len(actions) > limit {
+		page.Actions = actions[:limit]
+		last := page.Actions[limit-1]
+		page.NextCursor = domain.ActionCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Actions == nil {
+		page.Actions = []domain.UserAction{}
+	}
+	return page, nil
+}
+
+func (s *ActionServiceImpl) SummarizeActions(ctx context.Context, tenantID, userID uuid.UUID, query domain.ActionSummaryQuery) (*domain.ActionSummary, error) {
+	query, err := normalizeSummaryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.users.CountUserActions(ctx, tenantID, userID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildActionSummary(query, counts), nil
+}
+
+func normalizeSummaryQuery(query domain.ActionSummaryQuery) (domain.ActionSummaryQuery, error) {
+	if err := validateActionRange(query.Types, query.From, query.To); err != nil {
+		return query, err
+	}
+	if query.Period == "" {
+		query.Period = domain.PeriodMonth
+	}
+	if !query.Period.Valid() {
+		return query, fmt.Errorf("%w: period must be day, week or month", domain.ErrInvalidInput)
+	}
+	if query.Location == nil {
+		query.Location = time.UTC
+	}
+	return query, nil
+}
+
+// buildActionSummary раскладывает счётчики, упорядоченные по началу периода, по корзинам
+func buildActionSummary(query domain.ActionSummaryQuery, counts []domain.ActionCount) *domain.ActionSummary {
+	summary := &domain.ActionSummary{
+		Period:   query.Period,
+		Timezone: query.Location.String(),
+		Buckets:  []domain.ActionBucket{},
+		Totals:   map[domain.ActionType]int64{},
+	}
+	for _, count := range counts {
+		n := len(summary.Buckets)
+		if n == 0 || !summary.Buckets[n-1].Start.Equal(count.PeriodStart) {
+			summary.Buckets = append(summary.Buckets, domain.ActionBucket{Start: count.PeriodStart, Counts: map[domain.ActionType]int64{}})
+			n++
+		}
+		bucket := &summary.Buckets[n-1]
+		bucket.Counts[count.Action] += count.Count
+		bucket.Total += count.Count
+		summary.Totals[count.Action] += count.Count
+	}
+	return summary
+}
+
+func validateActionRange(types []domain.ActionType, from, to *time.Time) error {
+	for _, actionType := range types {
+		if !actionType.Valid() {
+			return fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, actionType)
+		}
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+	return nil
+}
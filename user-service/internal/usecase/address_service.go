@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	maxAddressField = 200
+	maxAddressLabel = 50
+)
+
+type AddressServiceImpl struct {
+	users    domain.UserRepository
+	geocoder domain.Geocoder
+	// Первый адрес может открыть отложенную награду за приглашение; nil — приглашения не учитываются
+	referrals domain.ReferralService
+}
+
+func NewAddressService(users domain.UserRepository, geocoder domain.Geocoder, referrals domain.ReferralService) domain.AddressService {
+	return &AddressServiceImpl{users: users, geocoder: geocoder, referrals: referrals}
+}
+
+// ListAddresses отдаёт адреса только самому пользователю или админу: это домашний адрес жителя
+func (s *AddressServiceImpl) ListAddresses(ctx context.Context, tenantID, userID uuid.UUID) ([]domain.Address, error) {
+	if err := s.checkOwner(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	addresses, err := s.users.ListAddresses(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []domain.Address{}
+	}
+	return addresses, nil
+}
+
+// AddAddress сохраняет новый адрес; первый адрес пользователя становится основным
+func (s *AddressServiceImpl) AddAddress(ctx context.Context, tenantID, userID uuid.UUID, address *domain.Address) error {
+	if err := s.checkOwner(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	if err := s.prepare(ctx, address); err != nil {
+		return err
+	}
+
+	existing, err := s.users.ListAddresses(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+
+	address.ID = uuid.New()
+	address.TenantID = tenantID
+	address.UserID = userID
+	address.IsPrimary = address.IsPrimary || len(existing) == 0
+	address.CreatedAt = time.Now().UTC()
+	address.UpdatedAt = address.CreatedAt
+
+	if err := s.users.SaveAddress(ctx, address); err != nil {
+		return err
+	}
+	if len(existing) == 0 && s.referrals != nil {
+		// Адрес уже сохранён, поэтому ошибка награды только логируется
+		if err := s.referrals.ReleaseReward(ctx, tenantID, userID); err != nil {
+			log.Printf("Failed to release referral reward for user %s (request %s): %v", userID, requestctx.RequestID(ctx), err)
+		}
+	}
+	return nil
+}
+
+// UpdateAddress заменяет поля адреса целиком; основной адрес меняется только через SetPrimaryAddress
+func (s *AddressServiceImpl) UpdateAddress(ctx context.Context, tenantID, userID uuid.UUID, address *domain.Address) error {
+	if err := s.checkOwner(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	existing, err := s.users.FindAddress(ctx, tenantID, userID, address.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.prepare(ctx, address); err != nil {
+		return err
+	}
+
+	address.TenantID = tenantID
+	address.UserID = userID
+	address.IsPrimary = existing.IsPrimary
+	address.CreatedAt = existing.CreatedAt
+	address.UpdatedAt = time.Now().UTC()
+
+	return s.users.SaveAddress(ctx, address)
+}
+
+// DeleteAddress удаляет адрес; если он был основным, основным становится самый старый из оставшихся
+func (s *AddressServiceImpl) DeleteAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) error {
+	if err := s.checkOwner(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	address, err := s.users.FindAddress(ctx, tenantID, userID, addressID)
+	if err != nil {
+		return err
+	}
+	if err := s.users.DeleteAddress(ctx, tenantID, userID, addressID); err != nil {
+		return err
+	}
+	if !address.IsPrimary {
+		return nil
+	}
+
+	remaining, err := s.users.ListAddresses(ctx, tenantID, userID)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	next := remaining[0]
+	next.IsPrimary = true
+	next.UpdatedAt = time.Now().UTC()
+	return s.users.SaveAddress(ctx, &next)
+}
+
+func (s *AddressServiceImpl) SetPrimaryAddress(ctx context.Context, tenantID, userID, addressID uuid.UUID) (*domain.Address, error) {
+	if err := s.checkOwner(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	address, err := s.users.FindAddress(ctx, tenantID, userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+	if address.IsPrimary {
+		return address, nil
+	}
+
+	address.IsPrimary = true
+	address.UpdatedAt = time.Now().UTC()
+	if err := s.users.SaveAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// checkOwner: адреса видит и меняет только сам пользователь или админ, они же участвуют в проверке приглашений
+func (s *AddressServiceImpl) checkOwner(ctx context.Context, tenantID, userID uuid.UUID) error {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return domain.ErrNotProfileOwner
+	}
+	return nil
+}
+
+// prepare проверяет поля и проставляет координаты: явно переданные сохраняются как есть,
+// иначе адрес геокодируется; ненайденный адрес сохраняется без координат
+func (s *AddressServiceImpl) prepare(ctx context.Context, address *domain.Address) error {
+	address.Label = strings.TrimSpace(address.Label)
+	address.Street = strings.TrimSpace(address.Street)
+	address.House = strings.TrimSpace(address.House)
+	address.Apartment = strings.TrimSpace(address.Apartment)
+	address.City = strings.TrimSpace(address.City)
+	address.PostalCode = strings.TrimSpace(address.PostalCode)
+
+	switch {
+	case address.Street == "":
+		return fmt.Errorf("%w: street is required", domain.ErrInvalidInput)
+	case address.City == "":
+		return fmt.Errorf("%w: city is required", domain.ErrInvalidInput)
+	case len(address.Label) > maxAddressLabel:
+		return fmt.Errorf("%w: label must be at most %d characters", domain.ErrInvalidInput, maxAddressLabel)
+	}
+	for name, value := range map[string]string{"street": address.Street, "house": address.House, "apartment": address.Apartment, "city": address.City, "postal_code": address.PostalCode} {
+		if len(value) > maxAddressField {
+			return fmt.Errorf("%w: %s must be at most %d characters", domain.ErrInvalidInput, name, maxAddressField)
+		}
+	}
+
+	if (address.Latitude == nil) != (address.Longitude == nil) {
+		return fmt.Errorf("%w: lat and lon must be set together", domain.ErrInvalidInput)
+	}
+	if address.Latitude != nil {
+		if *address.Latitude < -90 || *address.Latitude > 90 || *address.Longitude < -180 || *address.Longitude > 180 {
+			return fmt.Errorf("%w: coordinates are out of range", domain.ErrInvalidInput)
+		}
+		address.GeoPrecision = domain.GeoPrecisionManual
+		return nil
+	}
+
+	address.GeoPrecision = ""
+	result, err := s.geocoder.Geocode(ctx, *address)
+	if errors.Is(err, domain.ErrLocationNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("geocoding failed: %w", err)
+	}
+
+	address.Latitude = &result.Point.Latitude
+	address.Longitude = &result.Point.Longitude
+	address.GeoPrecision = result.Precision
+	if address.PostalCode == "" {
+		address.PostalCode = result.PostalCode
+	}
+	return nil
+}
+
+// sameAddress сравнивает адреса без учёта регистра, координаты и индекс не учитываются
+func sameAddress(a, b domain.Address) bool {
+	return strings.EqualFold(a.City, b.City) && strings.EqualFold(a.Street, b.Street) &&
+		strings.EqualFold(a.House, b.House) && strings.EqualFold(a.Apartment, b.Apartment)
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+type AvatarServiceImpl struct {
+	users   domain.UserRepository
+	blobs   domain.BlobStore
+	actions domain.ActionService
+}
+
+// NewAvatarService: blobs может быть nil, тогда загрузка картинок отклоняется
+func NewAvatarService(users domain.UserRepository, blobs domain.BlobStore, actions domain.ActionService) domain.AvatarService {
+	return &AvatarServiceImpl{users: users, blobs: blobs, actions: actions}
+}
+
+// SetAvatar сначала сохраняет миниатюры под новым идентификатором и только потом меняет профиль,
+// поэтому по старым ссылкам картинка отдаётся до самого переключения
+func (s *AvatarServiceImpl) SetAvatar(ctx context.Context, tenantID, userID uuid.UUID, images domain.AvatarImages) (*domain.User, error) {
+	if s.blobs == nil {
+		return nil, fmt.Errorf("%w: avatar storage is not configured", domain.ErrInvalidInput)
+	}
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	for _, size := range domain.AvatarSizes {
+		if len(images[size]) == 0 {
+			return nil, fmt.Errorf("%w: %dpx thumbnail is missing", domain.ErrInvalidInput, size)
+		}
+	}
+
+	avatarID := uuid.New()
+	for _, size := range domain.AvatarSizes {
+		blob := domain.Blob{ContentType: "image/jpeg", Data: images[size]}
+		if err := s.blobs.Put(ctx, domain.AvatarBlobKey(avatarID, size), blob); err != nil {
+			s.deleteBlobs(ctx, avatarID)
+			return nil, err
+		}
+	}
+
+	previous := user.AvatarID
+	user.AvatarID = &avatarID
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(ctx, user); err != nil {
+		s.deleteBlobs(ctx, avatarID)
+		return nil, err
+	}
+
+	if previous != nil {
+		s.deleteBlobs(ctx, *previous)
+	}
+	s.recordAvatarChange(ctx, user)
+	return user, nil
+}
+
+func (s *AvatarServiceImpl) DeleteAvatar(ctx context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	if user.AvatarID == nil {
+		return nil, domain.ErrAvatarNotFound
+	}
+
+	previous := *user.AvatarID
+	user.AvatarID = nil
+	user.UpdatedAt = time.Now()
+	if err := s.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.deleteBlobs(ctx, previous)
+	s.recordAvatarChange(ctx, user)
+	return user, nil
+}
+
+func (s *AvatarServiceImpl) GetAvatar(ctx context.Context, avatarID uuid.UUID, size int) (*domain.Blob, error) {
+	if !containsSize(domain.AvatarSizes, size) {
+		return nil, fmt.Errorf("%w: size must be one of %v", domain.ErrInvalidInput, domain.AvatarSizes)
+	}
+	if s.blobs == nil {
+		return nil, domain.ErrAvatarNotFound
+	}
+	blob, err := s.blobs.Get(ctx, domain.AvatarBlobKey(avatarID, size))
+	if errors.Is(err, domain.ErrBlobNotFound) {
+		return nil, domain.ErrAvatarNotFound
+	}
+	return blob, err
+}
+
+// deleteBlobs убирает миниатюры; забытый файл ни на что не влияет, поэтому ошибка только логируется
+func (s *AvatarServiceImpl) deleteBlobs(ctx context.Context, avatarID uuid.UUID) {
+	if s.blobs == nil {
+		return
+	}
+	for _, size := range domain.AvatarSizes {
+		if err := s.blobs.Delete(ctx, domain.AvatarBlobKey(avatarID, size)); err != nil {
+			log.Printf("Failed to delete avatar %s (%dpx) (request %s): %v", avatarID, size, requestctx.RequestID(ctx), err)
+		}
+	}
+}
+
+func (s *AvatarServiceImpl) recordAvatarChange(ctx context.Context, user *domain.User) {
+	if err := s.actions.RecordAction(ctx, user.TenantID, user.ID, domain.ActionProfileUpdated, `{"fields":["avatar"]}`); err != nil {
+		log.Printf("Failed to record avatar change for user %s (request %s): %v", user.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+func containsSize(sizes []int, size int) bool {
+	for _, candidate := range sizes {
+		if candidate == size {
+			return true
+		}
+	}
+	return false
+}
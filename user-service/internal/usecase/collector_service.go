@@ -0,0 +1,384 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+const (
+	maxZoneName          = 100
+	maxZonePoints        = 1000
+	maxAvailabilityNote  = 200
+	maxCertificateNumber = 50
+	maxShiftsPerWeek     = 21
+)
+
+type CollectorServiceImpl struct {
+	collectors domain.CollectorRepository
+	users      domain.UserRepository
+	location   *time.Location
+}
+
+// NewCollectorService сверяет смены с текущим временем в часовом поясе location
+func NewCollectorService(collectors domain.CollectorRepository, users domain.UserRepository, location *time.Location) domain.CollectorService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &CollectorServiceImpl{collectors: collectors, users: users, location: location}
+}
+
+// ListCollectors перебирает всех сборщиков тенанта: их немного, а фильтры по допускам и сменам считаются в Go
+func (s *CollectorServiceImpl) ListCollectors(ctx context.Context, tenantID uuid.UUID, filter domain.CollectorFilter) ([]domain.CollectorDetails, error) {
+	var inZone map[uuid.UUID]bool
+	if filter.Point != nil {
+		zones, err := s.collectors.ZonesCovering(ctx, tenantID, *filter.Point)
+		if err != nil {
+			return nil, err
+		}
+		inZone = map[uuid.UUID]bool{}
+		for _, zone := range zones {
+			inZone[zone.UserID] = true
+		}
+	}
+
+	now := time.Now()
+	collectors := []domain.CollectorDetails{}
+	for offset := 0; ; offset += domain.MaxPageLimit {
+		users, _, err := s.users.List(ctx, tenantID, domain.UserFilter{Role: domain.RoleCollector, Limit: domain.MaxPageLimit, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
+		for i := range users {
+			if inZone != nil && !inZone[users[i].ID] {
+				continue
+			}
+			details, err := s.details(ctx, &users[i], now)
+			if err != nil {
+				return nil, err
+			}
+			if matchesCollector(details, filter, now) {
+				collectors = append(collectors, *details)
+			}
+		}
+		if len(users) < domain.MaxPageLimit {
+			return collectors, nil
+		}
+	}
+}
+
+func (s *CollectorServiceImpl) GetCollector(ctx context.Context, tenantID, userID uuid.UUID) (*domain.CollectorDetails, error) {
+	user, err := s.collector(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.details(ctx, user, time.Now())
+}
+
+// UpdateProfile заменяет машину и доступность сборщика
+func (s *CollectorServiceImpl) UpdateProfile(ctx context.Context, tenantID, userID uuid.UUID, profile *domain.CollectorProfile) (*domain.CollectorDetails, error) {
+	user, err := s.collector(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareCollectorProfile(profile); err != nil {
+		return nil, err
+	}
+
+	profile.UserID = userID
+	profile.TenantID = tenantID
+	profile.UpdatedAt = time.Now().UTC()
+	if err := s.collectors.SaveProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	return s.details(ctx, user, time.Now())
+}
+
+// ReplaceShifts заменяет недельное расписание; смены одного дня не должны пересекаться
+func (s *CollectorServiceImpl) ReplaceShifts(ctx context.Context, tenantID, userID uuid.UUID, shifts []domain.CollectorShift) ([]domain.CollectorShift, error) {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	if len(shifts) > maxShiftsPerWeek {
+		return nil, fmt.Errorf("%w: at most %d shifts per week", domain.ErrInvalidInput, maxShiftsPerWeek)
+	}
+
+	for i := range shifts {
+		shift := &shifts[i]
+		if err := validateShift(*shift); err != nil {
+			return nil, err
+		}
+		for _, other := range shifts[:i] {
+			if other.Weekday == shift.Weekday && shift.Starts < other.Ends && other.Starts < shift.Ends {
+				return nil, fmt.Errorf("%w: shifts on %s overlap", domain.ErrInvalidInput, shift.Weekday)
+			}
+		}
+		shift.ID = uuid.New()
+		shift.TenantID = tenantID
+		shift.UserID = userID
+	}
+
+	if err := s.collectors.ReplaceShifts(ctx, tenantID, userID, shifts); err != nil {
+		return nil, err
+	}
+	return s.collectors.ListShifts(ctx, tenantID, userID)
+}
+
+func (s *CollectorServiceImpl) AddCertification(ctx context.Context, tenantID, userID uuid.UUID, certification *domain.CollectorCertification) error {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return err
+	}
+
+	certification.Hazard = strings.ToLower(strings.TrimSpace(certification.Hazard))
+	certification.Number = strings.TrimSpace(certification.Number)
+	switch {
+	case !containsString(domain.HazardClasses, certification.Hazard):
+		return fmt.Errorf("%w: hazard must be one of %s", domain.ErrInvalidInput, strings.Join(domain.HazardClasses, ", "))
+	case certification.Number == "":
+		return fmt.Errorf("%w: number is required", domain.ErrInvalidInput)
+	case len(certification.Number) > maxCertificateNumber:
+		return fmt.Errorf("%w: number must be at most %d characters", domain.ErrInvalidInput, maxCertificateNumber)
+	case certification.IssuedAt.IsZero():
+		return fmt.Errorf("%w: issued_at is required", domain.ErrInvalidInput)
+	case certification.ExpiresAt != nil && !certification.ExpiresAt.After(certification.IssuedAt):
+		return fmt.Errorf("%w: expires_at must be after issued_at", domain.ErrInvalidInput)
+	}
+
+	certification.ID = uuid.New()
+	certification.TenantID = tenantID
+	certification.UserID = userID
+	return s.collectors.SaveCertification(ctx, certification)
+}
+
+func (s *CollectorServiceImpl) DeleteCertification(ctx context.Context, tenantID, userID, certificationID uuid.UUID) error {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	return s.collectors.DeleteCertification(ctx, tenantID, userID, certificationID)
+}
+
+func (s *CollectorServiceImpl) AddZone(ctx context.Context, tenantID, userID uuid.UUID, zone *domain.ServiceZone) error {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	if err := prepareZone(zone); err != nil {
+		return err
+	}
+
+	zone.ID = uuid.New()
+	zone.TenantID = tenantID
+	zone.UserID = userID
+	zone.CreatedAt = time.Now().UTC()
+	zone.UpdatedAt = zone.CreatedAt
+	return s.collectors.SaveZone(ctx, zone)
+}
+
+func (s *CollectorServiceImpl) UpdateZone(ctx context.Context, tenantID, userID uuid.UUID, zone *domain.ServiceZone) error {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	zones, err := s.collectors.ListZones(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	var existing *domain.ServiceZone
+	for i := range zones {
+		if zones[i].ID == zone.ID {
+			existing = &zones[i]
+		}
+	}
+	if existing == nil {
+		return domain.ErrZoneNotFound
+	}
+	if err := prepareZone(zone); err != nil {
+		return err
+	}
+
+	zone.TenantID = tenantID
+	zone.UserID = userID
+	zone.CreatedAt = existing.CreatedAt
+	zone.UpdatedAt = time.Now().UTC()
+	return s.collectors.SaveZone(ctx, zone)
+}
+
+func (s *CollectorServiceImpl) DeleteZone(ctx context.Context, tenantID, userID, zoneID uuid.UUID) error {
+	if _, err := s.collector(ctx, tenantID, userID); err != nil {
+		return err
+	}
+	return s.collectors.DeleteZone(ctx, tenantID, userID, zoneID)
+}
+
+func (s *CollectorServiceImpl) ListTenantZones(ctx context.Context, tenantID uuid.UUID, bounds *domain.GeoBounds) ([]domain.ServiceZone, error) {
+	zones, err := s.collectors.ListTenantZones(ctx, tenantID)
+	if err != nil || bounds == nil {
+		return zones, err
+	}
+
+	var overlapping []domain.ServiceZone
+	for _, zone := range zones {
+		if zone.Boundary.Bounds().Overlaps(*bounds) {
+			overlapping = append(overlapping, zone)
+		}
+	}
+	return overlapping, nil
+}
+
+// collector загружает пользователя и проверяет, что он сборщик
+func (s *CollectorServiceImpl) collector(ctx context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != domain.RoleCollector {
+		return nil, domain.ErrNotCollector
+	}
+	return user, nil
+}
+
+func (s *CollectorServiceImpl) details(ctx context.Context, user *domain.User, now time.Time) (*domain.CollectorDetails, error) {
+	profile, err := s.collectors.FindProfile(ctx, user.TenantID, user.ID)
+	switch {
+	case errors.Is(err, domain.ErrCollectorProfileNotFound):
+		// Профиль ещё не заполняли: сборщик доступен, машины нет
+		profile = &domain.CollectorProfile{UserID: user.ID, TenantID: user.TenantID, Availability: domain.CollectorAvailable}
+	case err != nil:
+		return nil, err
+	}
+
+	details := &domain.CollectorDetails{User: user, Profile: *profile}
+	if details.Shifts, err = s.collectors.ListShifts(ctx, user.TenantID, user.ID); err != nil {
+		return nil, err
+	}
+	if details.Certifications, err = s.collectors.ListCertifications(ctx, user.TenantID, user.ID); err != nil {
+		return nil, err
+	}
+	if details.Zones, err = s.collectors.ListZones(ctx, user.TenantID, user.ID); err != nil {
+		return nil, err
+	}
+	if details.Shifts == nil {
+		details.Shifts = []domain.CollectorShift{}
+	}
+	if details.Certifications == nil {
+		details.Certifications = []domain.CollectorCertification{}
+	}
+	if details.Zones == nil {
+		details.Zones = []domain.ServiceZone{}
+	}
+
+	local := now.In(s.location)
+	for _, shift := range details.Shifts {
+		if shift.Covers(local) {
+			details.OnShift = true
+		}
+	}
+	return details, nil
+}
+
+func matchesCollector(details *domain.CollectorDetails, filter domain.CollectorFilter, now time.Time) bool {
+	switch {
+	case filter.Availability != "" && details.Profile.Availability != filter.Availability:
+		return false
+	case filter.VehicleType != "" && details.Profile.Vehicle.Type != filter.VehicleType:
+		return false
+	case filter.OnShift && !details.OnShift:
+		return false
+	}
+	if filter.Hazard == "" {
+		return true
+	}
+	for _, certification := range details.Certifications {
+		if certification.Hazard == filter.Hazard && certification.ValidAt(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func prepareCollectorProfile(profile *domain.CollectorProfile) error {
+	vehicle := &profile.Vehicle
+	vehicle.Plate = strings.ToUpper(strings.TrimSpace(vehicle.Plate))
+	switch {
+	case vehicle.Type == "" && (vehicle.Plate != "" || vehicle.CapacityKg != 0 || vehicle.VolumeM3 != 0):
+		return fmt.Errorf("%w: vehicle type is required", domain.ErrInvalidInput)
+	case vehicle.Type != "" && !vehicle.Type.Valid():
+		return fmt.Errorf("%w: unknown vehicle type %q", domain.ErrInvalidInput, vehicle.Type)
+	case vehicle.CapacityKg < 0 || vehicle.VolumeM3 < 0:
+		return fmt.Errorf("%w: vehicle capacity must not be negative", domain.ErrInvalidInput)
+	}
+
+	if profile.Availability == "" {
+		profile.Availability = domain.CollectorAvailable
+	}
+	if !profile.Availability.Valid() {
+		return fmt.Errorf("%w: availability must be available, unavailable or on_leave", domain.ErrInvalidInput)
+	}
+	profile.AvailabilityNote = strings.TrimSpace(profile.AvailabilityNote)
+	if len(profile.AvailabilityNote) > maxAvailabilityNote {
+		return fmt.Errorf("%w: availability_note must be at most %d characters", domain.ErrInvalidInput, maxAvailabilityNote)
+	}
+	return nil
+}
+
+func validateShift(shift domain.CollectorShift) error {
+	if shift.Weekday < time.Sunday || shift.Weekday > time.Saturday {
+		return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6", domain.ErrInvalidInput)
+	}
+	for _, clock := range []string{shift.Starts, shift.Ends} {
+		if _, err := time.Parse("15:04", clock); err != nil || len(clock) != 5 {
+			return fmt.Errorf("%w: shift time %q must be HH:MM", domain.ErrInvalidInput, clock)
+		}
+	}
+	if shift.Starts >= shift.Ends {
+		return fmt.Errorf("%w: shift must end after it starts", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+func prepareZone(zone *domain.ServiceZone) error {
+	zone.Name = strings.TrimSpace(zone.Name)
+	if zone.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if len(zone.Name) > maxZoneName {
+		return fmt.Errorf("%w: name must be at most %d characters", domain.ErrInvalidInput, maxZoneName)
+	}
+	return validatePolygon(zone.Boundary)
+}
+
+// validatePolygon проверяет GeoJSON Polygon: замкнутые кольца не короче четырёх точек в допустимых координатах
+func validatePolygon(polygon domain.GeoPolygon) error {
+	if polygon.Type != "Polygon" {
+		return fmt.Errorf("%w: boundary must be a GeoJSON Polygon", domain.ErrInvalidInput)
+	}
+	if len(polygon.Coordinates) == 0 {
+		return fmt.Errorf("%w: boundary has no rings", domain.ErrInvalidInput)
+	}
+
+	points := 0
+	for _, ring := range polygon.Coordinates {
+		if len(ring) < 4 {
+			return fmt.Errorf("%w: each ring needs at least 4 positions", domain.ErrInvalidInput)
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return fmt.Errorf("%w: each ring must be closed", domain.ErrInvalidInput)
+		}
+		for _, position := range ring {
+			lon, lat := position[0], position[1]
+			if math.IsNaN(lon) || math.IsNaN(lat) || lon < -180 || lon > 180 || lat < -90 || lat > 90 {
+				return fmt.Errorf("%w: position [%g, %g] is out of range", domain.ErrInvalidInput, lon, lat)
+			}
+		}
+		points += len(ring)
+	}
+	if points > maxZonePoints {
+		return fmt.Errorf("%w: boundary must have at most %d positions", domain.ErrInvalidInput, maxZonePoints)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"user-service/internal/domain"
+)
+
+func (f *fixture) mustCreateCollector(t *testing.T, email string) *domain.User {
+	t.Helper()
+	user := f.mustCreateUser(t, email)
+	user.Role = domain.RoleCollector
+	if err := f.Users.Update(context.Background(), user); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return f.mustFindUser(t, user)
+}
+
+func (f *fixture) mustFindUser(t *testing.T, user *domain.User) *domain.User {
+	t.Helper()
+	found, err := f.Users.FindByID(context.Background(), f.tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	return found
+}
+
+// square — замкнутый квадрат со стороной size градусов и юго-западным углом в (lon, lat)
+func square(lon, lat, size float64) domain.GeoPolygon {
+	return domain.GeoPolygon{Type: "Polygon", Coordinates: [][][2]float64{{
+		{lon, lat}, {lon + size, lat}, {lon + size, lat + size}, {lon, lat + size}, {lon, lat},
+	}}}
+}
+
+func TestReplaceShiftsValidation(t *testing.T) {
+	f := newFixture(t)
+	collectors := NewCollectorService(f.Collectors, f.Users, time.UTC)
+	collector := f.mustCreateCollector(t, "collector@example.com")
+	resident := f.mustCreateUser(t, "resident@example.com")
+
+	tooMany := make([]domain.CollectorShift, maxShiftsPerWeek+1)
+	for i := range tooMany {
+		tooMany[i] = domain.CollectorShift{Weekday: time.Weekday(i % 7), Starts: fmt.Sprintf("%02d:00", i/7), Ends: fmt.Sprintf("%02d:30", i/7)}
+	}
+
+	tests := []struct {
+		name    string
+		shifts  []domain.CollectorShift
+		wantErr bool
+	}{
+		{name: "same hours on different days", shifts: []domain.CollectorShift{
+			{Weekday: time.Monday, Starts: "08:00", Ends: "12:00"},
+			{Weekday: time.Tuesday, Starts: "08:00", Ends: "12:00"},
+		}},
+		{name: "back to back on one day", shifts: []domain.CollectorShift{
+			{Weekday: time.Monday, Starts: "08:00", Ends: "12:00"},
+			{Weekday: time.Monday, Starts: "12:00", Ends: "16:00"},
+		}},
+		{name: "overlapping on one day", shifts: []domain.CollectorShift{
+			{Weekday: time.Monday, Starts: "08:00", Ends: "12:00"},
+			{Weekday: time.Monday, Starts: "11:00", Ends: "16:00"},
+		}, wantErr: true},
+		{name: "ends before it starts", shifts: []domain.CollectorShift{{Weekday: time.Friday, Starts: "18:00", Ends: "09:00"}}, wantErr: true},
+		{name: "clock without leading zero", shifts: []domain.CollectorShift{{Weekday: time.Friday, Starts: "9:00", Ends: "17:00"}}, wantErr: true},
+		{name: "weekday out of range", shifts: []domain.CollectorShift{{Weekday: 7, Starts: "09:00", Ends: "17:00"}}, wantErr: true},
+		{name: "too many shifts", shifts: tooMany, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved, err := collectors.ReplaceShifts(context.Background(), f.tenant.ID, collector.ID, tt.shifts)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("ReplaceShifts error = %v, want domain.ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReplaceShifts: %v", err)
+			}
+			if len(saved) != len(tt.shifts) {
+				t.Fatalf("ReplaceShifts saved %d shifts, want %d", len(saved), len(tt.shifts))
+			}
+		})
+	}
+
+	if _, err := collectors.ReplaceShifts(context.Background(), f.tenant.ID, resident.ID, nil); !errors.Is(err, domain.ErrNotCollector) {
+		t.Fatalf("ReplaceShifts(resident) error = %v, want domain.ErrNotCollector", err)
+	}
+}
+
+// Смена сверяется с часами в поясе сервиса, а не в UTC
+func TestCollectorOnShift(t *testing.T) {
+	f := newFixture(t)
+	// Пояс, в котором сейчас около полудня: смена 11:00–13:00 идёт при любом времени запуска теста
+	now := time.Now().UTC()
+	offset := 12*60*60 - (now.Hour()*60*60 + now.Minute()*60)
+	location := time.FixedZone("service", offset)
+	collectors := NewCollectorService(f.Collectors, f.Users, location)
+
+	working := f.mustCreateCollector(t, "working@example.com")
+	resting := f.mustCreateCollector(t, "resting@example.com")
+	today := now.In(location).Weekday()
+	if _, err := collectors.ReplaceShifts(context.Background(), f.tenant.ID, working.ID, []domain.CollectorShift{{Weekday: today, Starts: "11:00", Ends: "13:00"}}); err != nil {
+		t.Fatalf("ReplaceShifts: %v", err)
+	}
+	if _, err := collectors.ReplaceShifts(context.Background(), f.tenant.ID, resting.ID, []domain.CollectorShift{{Weekday: (today + 1) % 7, Starts: "11:00", Ends: "13:00"}}); err != nil {
+		t.Fatalf("ReplaceShifts: %v", err)
+	}
+
+	onShift, err := collectors.ListCollectors(context.Background(), f.tenant.ID, domain.CollectorFilter{OnShift: true})
+	if err != nil {
+		t.Fatalf("ListCollectors: %v", err)
+	}
+	if len(onShift) != 1 || onShift[0].User.ID != working.ID {
+		t.Fatalf("ListCollectors(on shift) = %d collectors, want only the working one", len(onShift))
+	}
+}
+
+func TestCollectorZones(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	collectors := NewCollectorService(f.Collectors, f.Users, time.UTC)
+	collector := f.mustCreateCollector(t, "collector@example.com")
+
+	open := square(76.9, 43.2, 0.1)
+	open.Coordinates[0] = open.Coordinates[0][:4]
+	tests := []struct {
+		name string
+		zone domain.ServiceZone
+	}{
+		{name: "no name", zone: domain.ServiceZone{Boundary: square(76.9, 43.2, 0.1)}},
+		{name: "not a polygon", zone: domain.ServiceZone{Name: "Centre", Boundary: domain.GeoPolygon{Type: "Point"}}},
+		{name: "no rings", zone: domain.ServiceZone{Name: "Centre", Boundary: domain.GeoPolygon{Type: "Polygon"}}},
+		{name: "ring not closed", zone: domain.ServiceZone{Name: "Centre", Boundary: open}},
+		{name: "latitude out of range", zone: domain.ServiceZone{Name: "Centre", Boundary: square(76.9, 89.95, 0.1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := collectors.AddZone(ctx, f.tenant.ID, collector.ID, &tt.zone); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("AddZone error = %v, want domain.ErrInvalidInput", err)
+			}
+		})
+	}
+
+	zone := &domain.ServiceZone{Name: " Centre ", Boundary: square(76.9, 43.2, 0.1)}
+	if err := collectors.AddZone(ctx, f.tenant.ID, collector.ID, zone); err != nil {
+		t.Fatalf("AddZone: %v", err)
+	}
+	if zone.Name != "Centre" {
+		t.Fatalf("zone name = %q, want it trimmed", zone.Name)
+	}
+
+	inside := &domain.GeoPoint{Latitude: 43.25, Longitude: 76.95}
+	outside := &domain.GeoPoint{Latitude: 43.25, Longitude: 77.5}
+	for _, tt := range []struct {
+		name  string
+		point *domain.GeoPoint
+		want  int
+	}{
+		{name: "inside", point: inside, want: 1},
+		{name: "outside", point: outside, want: 0},
+	} {
+		found, err := collectors.ListCollectors(ctx, f.tenant.ID, domain.CollectorFilter{Point: tt.point})
+		if err != nil {
+			t.Fatalf("ListCollectors(%s): %v", tt.name, err)
+		}
+		if len(found) != tt.want {
+			t.Fatalf("ListCollectors(%s) = %d collectors, want %d", tt.name, len(found), tt.want)
+		}
+	}
+
+	moved := &domain.ServiceZone{ID: zone.ID, Name: "Centre", Boundary: square(77.4, 43.2, 0.2)}
+	if err := collectors.UpdateZone(ctx, f.tenant.ID, collector.ID, moved); err != nil {
+		t.Fatalf("UpdateZone: %v", err)
+	}
+	if found, err := collectors.ListCollectors(ctx, f.tenant.ID, domain.CollectorFilter{Point: outside}); err != nil || len(found) != 1 {
+		t.Fatalf("ListCollectors after the zone moved = %d collectors, %v; want the collector", len(found), err)
+	}
+}
@@ -0,0 +1,489 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	maxHouseholdName  = 100
+	maxHouseholdBins  = 10
+	maxPickupNotes    = 500
+	invitationTTL     = 7 * 24 * time.Hour
+	invitationCodeLen = 8
+	// Без 0/O и 1/I, чтобы код можно было продиктовать
+	invitationAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var pickupDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// householdActor — действующий пользователь с найденным профилем; id пуст, если профиля нет
+type householdActor struct {
+	id    uuid.UUID
+	admin bool
+}
+
+type HouseholdServiceImpl struct {
+	households domain.HouseholdRepository
+	users      domain.UserRepository
+	points     domain.PointsRepository
+}
+
+func NewHouseholdService(households domain.HouseholdRepository, users domain.UserRepository, points domain.PointsRepository) domain.HouseholdService {
+	return &HouseholdServiceImpl{households: households, users: users, points: points}
+}
+
+// CreateHousehold заводит домохозяйство, владельцем которого становится действующий пользователь
+func (s *HouseholdServiceImpl) CreateHousehold(ctx context.Context, tenantID uuid.UUID, actor domain.HouseholdActor, household *domain.Household) (*domain.HouseholdDetails, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if actingUser.id == uuid.Nil {
+		return nil, fmt.Errorf("%w: acting user has no profile", domain.ErrInvalidInput)
+	}
+	if _, err := s.users.FindByID(ctx, tenantID, actingUser.id); err != nil {
+		return nil, err
+	}
+	if err := prepareHousehold(household); err != nil {
+		return nil, err
+	}
+	if household.AddressID != nil {
+		if _, err := s.users.FindAddress(ctx, tenantID, actingUser.id, *household.AddressID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	household.ID = uuid.New()
+	household.TenantID = tenantID
+	household.CreatedAt = now
+	household.UpdatedAt = now
+	owner := &domain.HouseholdMembership{
+		HouseholdID: household.ID,
+		UserID:      actingUser.id,
+		TenantID:    tenantID,
+		Role:        domain.HouseholdOwner,
+		JoinedAt:    now,
+	}
+	if err := s.households.CreateHousehold(ctx, household, owner); err != nil {
+		return nil, err
+	}
+	return s.details(ctx, household)
+}
+
+func (s *HouseholdServiceImpl) GetHousehold(ctx context.Context, tenantID, householdID uuid.UUID, actor domain.HouseholdActor) (*domain.HouseholdDetails, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	household, err := s.authorize(ctx, tenantID, householdID, actingUser, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.details(ctx, household)
+}
+
+func (s *HouseholdServiceImpl) GetUserHousehold(ctx context.Context, tenantID, userID uuid.UUID, actor domain.HouseholdActor) (*domain.HouseholdDetails, error) {
+	if _, err := s.users.FindByID(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	membership, err := s.households.FindMembership(ctx, tenantID, userID)
+	if errors.Is(err, domain.ErrNotHouseholdMember) {
+		return nil, domain.ErrHouseholdNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetHousehold(ctx, tenantID, membership.HouseholdID, actor)
+}
+
+// UpdateHousehold заменяет название, общий адрес и настройки; адрес должен принадлежать одному из жильцов
+func (s *HouseholdServiceImpl) UpdateHousehold(ctx context.Context, tenantID uuid.UUID, actor domain.HouseholdActor, household *domain.Household) (*domain.HouseholdDetails, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.authorize(ctx, tenantID, household.ID, actingUser, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepareHousehold(household); err != nil {
+		return nil, err
+	}
+	if household.AddressID != nil {
+		if _, err := s.memberAddress(ctx, tenantID, household.ID, *household.AddressID); err != nil {
+			return nil, err
+		}
+	}
+
+	household.TenantID = tenantID
+	household.CreatedAt = existing.CreatedAt
+	household.UpdatedAt = time.Now().UTC()
+	if err := s.households.UpdateHousehold(ctx, household); err != nil {
+		return nil, err
+	}
+	return s.details(ctx, household)
+}
+
+func (s *HouseholdServiceImpl) DeleteHousehold(ctx context.Context, tenantID, householdID uuid.UUID, actor domain.HouseholdActor) error {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return err
+	}
+	if _, err := s.authorize(ctx, tenantID, householdID, actingUser, true); err != nil {
+		return err
+	}
+	return s.households.DeleteHousehold(ctx, tenantID, householdID)
+}
+
+// Invite выпускает одноразовый код; с email принять приглашение может только пользователь с этим адресом
+func (s *HouseholdServiceImpl) Invite(ctx context.Context, tenantID, householdID uuid.UUID, actor domain.HouseholdActor, email string) (*domain.HouseholdInvitation, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, tenantID, householdID, actingUser, true); err != nil {
+		return nil, err
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email != "" && !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: email is not valid", domain.ErrInvalidInput)
+	}
+
+	code, err := invitationCode()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	invitation := &domain.HouseholdInvitation{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		HouseholdID: householdID,
+		Code:        code,
+		Email:       email,
+		InvitedBy:   actingUser.id,
+		ExpiresAt:   now.Add(invitationTTL),
+		CreatedAt:   now,
+	}
+	if err := s.households.CreateInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func (s *HouseholdServiceImpl) Join(ctx context.Context, tenantID uuid.UUID, actor domain.HouseholdActor, code string) (*domain.HouseholdDetails, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if actingUser.id == uuid.Nil {
+		return nil, fmt.Errorf("%w: acting user has no profile", domain.ErrInvalidInput)
+	}
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, fmt.Errorf("%w: code is required", domain.ErrInvalidInput)
+	}
+	user, err := s.users.FindByID(ctx, tenantID, actingUser.id)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.households.FindInvitationByCode(ctx, tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if invitation.AcceptedAt != nil || now.After(invitation.ExpiresAt) {
+		return nil, domain.ErrInvitationInvalid
+	}
+	if invitation.Email != "" && !strings.EqualFold(invitation.Email, user.Email) {
+		return nil, domain.ErrInvitationInvalid
+	}
+	household, err := s.households.FindHousehold(ctx, tenantID, invitation.HouseholdID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.AcceptedBy = &user.ID
+	invitation.AcceptedAt = &now
+	member := &domain.HouseholdMembership{
+		HouseholdID: household.ID,
+		UserID:      user.ID,
+		TenantID:    tenantID,
+		Role:        domain.HouseholdMember,
+		JoinedAt:    now,
+	}
+	if err := s.households.AcceptInvitation(ctx, invitation, member); err != nil {
+		return nil, err
+	}
+	return s.details(ctx, household)
+}
+
+// RemoveMember исключает жильца; участник может выйти сам, владелец — только последним,
+// и тогда домохозяйство удаляется
+func (s *HouseholdServiceImpl) RemoveMember(ctx context.Context, tenantID, householdID, userID uuid.UUID, actor domain.HouseholdActor) error {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return err
+	}
+	household, err := s.authorize(ctx, tenantID, householdID, actingUser, userID != actingUser.id)
+	if err != nil {
+		return err
+	}
+	members, err := s.households.ListMembers(ctx, tenantID, household.ID)
+	if err != nil {
+		return err
+	}
+
+	target := findMember(members, userID)
+	if target == nil {
+		return domain.ErrNotHouseholdMember
+	}
+	if target.Role != domain.HouseholdOwner {
+		return s.households.RemoveMember(ctx, tenantID, household.ID, userID)
+	}
+	if len(members) > 1 {
+		return domain.ErrOwnerMustTransfer
+	}
+	return s.households.DeleteHousehold(ctx, tenantID, household.ID)
+}
+
+func (s *HouseholdServiceImpl) TransferOwnership(ctx context.Context, tenantID, householdID, userID uuid.UUID, actor domain.HouseholdActor) (*domain.HouseholdDetails, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	household, err := s.authorize(ctx, tenantID, householdID, actingUser, true)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.households.ListMembers(ctx, tenantID, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var owner uuid.UUID
+	for _, member := range members {
+		if member.Role == domain.HouseholdOwner {
+			owner = member.UserID
+		}
+	}
+	if findMember(members, userID) == nil {
+		return nil, domain.ErrNotHouseholdMember
+	}
+	if owner != userID {
+		if err := s.households.TransferOwnership(ctx, tenantID, household.ID, owner, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.details(ctx, household)
+}
+
+// Summary складывает действия и баллы всех нынешних жильцов
+func (s *HouseholdServiceImpl) Summary(ctx context.Context, tenantID, householdID uuid.UUID, actor domain.HouseholdActor, query domain.ActionSummaryQuery) (*domain.HouseholdSummary, error) {
+	actingUser, err := s.actingUser(ctx, tenantID, actor)
+	if err != nil {
+		return nil, err
+	}
+	household, err := s.authorize(ctx, tenantID, householdID, actingUser, false)
+	if err != nil {
+		return nil, err
+	}
+	query, err = normalizeSummaryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.households.ListMembers(ctx, tenantID, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.HouseholdSummary{HouseholdID: household.ID, Members: []domain.PointsBalance{}}
+	var counts []domain.ActionCount
+	for _, member := range members {
+		memberCounts, err := s.users.CountUserActions(ctx, tenantID, member.UserID, query)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, memberCounts...)
+
+		balance, err := s.points.Balance(ctx, tenantID, domain.UserAccount(member.UserID))
+		if err != nil {
+			return nil, err
+		}
+		summary.Points += balance
+		summary.Members = append(summary.Members, domain.PointsBalance{UserID: member.UserID, Balance: balance})
+	}
+	sort.SliceStable(counts, func(i, j int) bool { return counts[i].PeriodStart.Before(counts[j].PeriodStart) })
+	summary.Actions = buildActionSummary(query, counts)
+	return summary, nil
+}
+
+func (s *HouseholdServiceImpl) actingUser(ctx context.Context, tenantID uuid.UUID, actor domain.HouseholdActor) (householdActor, error) {
+	actingUser := householdActor{admin: actor.Admin}
+	if actor.IdentityKey == uuid.Nil {
+		return actingUser, nil
+	}
+	user, err := s.users.FindByIdentityKey(ctx, tenantID, actor.IdentityKey)
+	switch {
+	case err == nil:
+		actingUser.id = user.ID
+	case !errors.Is(err, domain.ErrUserNotFound):
+		return actingUser, err
+	}
+	return actingUser, nil
+}
+
+// authorize находит домохозяйство и проверяет членство действующего пользователя;
+// ownerOnly требует роли владельца, администратор тенанта проходит всегда
+func (s *HouseholdServiceImpl) authorize(ctx context.Context, tenantID, householdID uuid.UUID, actingUser householdActor, ownerOnly bool) (*domain.Household, error) {
+	household, err := s.households.FindHousehold(ctx, tenantID, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	membership, err := s.households.FindMembership(ctx, tenantID, actingUser.id)
+	if err != nil && !errors.Is(err, domain.ErrNotHouseholdMember) {
+		return nil, err
+	}
+	if membership != nil && membership.HouseholdID != household.ID {
+		membership = nil
+	}
+
+	switch {
+	case actingUser.admin:
+	case membership == nil:
+		return nil, domain.ErrNotHouseholdMember
+	case ownerOnly && membership.Role != domain.HouseholdOwner:
+		return nil, domain.ErrNotHouseholdOwner
+	}
+	return household, nil
+}
+
+func (s *HouseholdServiceImpl) details(ctx context.Context, household *domain.Household) (*domain.HouseholdDetails, error) {
+	members, err := s.households.ListMembers(ctx, household.TenantID, household.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &domain.HouseholdDetails{Household: *household, Members: []domain.HouseholdMemberView{}}
+	for _, member := range members {
+		view := domain.HouseholdMemberView{HouseholdMembership: member}
+		user, err := s.users.FindByID(ctx, household.TenantID, member.UserID)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			view.Email = user.Email
+			view.Name = user.Name
+		}
+		details.Members = append(details.Members, view)
+	}
+
+	if household.AddressID != nil {
+		address, err := s.memberAddress(ctx, household.TenantID, household.ID, *household.AddressID)
+		switch {
+		case err == nil:
+			details.Address = address
+		case errors.Is(err, domain.ErrAddressNotFound):
+			// Адрес принадлежал жильцу, который уже ушёл
+			log.Printf("Household %s address %s is no longer available (request %s)", household.ID, *household.AddressID, requestctx.RequestID(ctx))
+		default:
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
+// memberAddress ищет адрес среди адресов жильцов: общий адрес всегда чей-то личный
+func (s *HouseholdServiceImpl) memberAddress(ctx context.Context, tenantID, householdID, addressID uuid.UUID) (*domain.Address, error) {
+	members, err := s.households.ListMembers(ctx, tenantID, householdID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		address, err := s.users.FindAddress(ctx, tenantID, member.UserID, addressID)
+		if err == nil {
+			return address, nil
+		}
+		if !errors.Is(err, domain.ErrAddressNotFound) {
+			return nil, err
+		}
+	}
+	return nil, domain.ErrAddressNotFound
+}
+
+func prepareHousehold(household *domain.Household) error {
+	household.Name = strings.TrimSpace(household.Name)
+	if household.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if len(household.Name) > maxHouseholdName {
+		return fmt.Errorf("%w: name must be at most %d characters", domain.ErrInvalidInput, maxHouseholdName)
+	}
+
+	settings := &household.Settings
+	settings.PickupDay = strings.ToLower(strings.TrimSpace(settings.PickupDay))
+	if settings.PickupDay != "" && !containsString(pickupDays, settings.PickupDay) {
+		return fmt.Errorf("%w: pickup_day must be a day of the week", domain.ErrInvalidInput)
+	}
+	settings.PickupNotes = strings.TrimSpace(settings.PickupNotes)
+	if len(settings.PickupNotes) > maxPickupNotes {
+		return fmt.Errorf("%w: pickup_notes must be at most %d characters", domain.ErrInvalidInput, maxPickupNotes)
+	}
+
+	var bins []string
+	for _, bin := range settings.Bins {
+		bin = strings.ToLower(strings.TrimSpace(bin))
+		if bin == "" || containsString(bins, bin) {
+			continue
+		}
+		bins = append(bins, bin)
+	}
+	if len(bins) > maxHouseholdBins {
+		return fmt.Errorf("%w: at most %d bins are allowed", domain.ErrInvalidInput, maxHouseholdBins)
+	}
+	settings.Bins = bins
+	return nil
+}
+
+func findMember(members []domain.HouseholdMembership, userID uuid.UUID) *domain.HouseholdMembership {
+	for i := range members {
+		if members[i].UserID == userID {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func invitationCode() (string, error) {
+	buf := make([]byte, invitationCodeLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = invitationAlphabet[int(b)%len(invitationAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+func householdActorOf(user *domain.User) domain.HouseholdActor {
+	return domain.HouseholdActor{IdentityKey: *user.IdentityKey}
+}
+
+func mustCreateHousehold(t *testing.T, households domain.HouseholdService, f *fixture, owner *domain.User) *domain.HouseholdDetails {
+	t.Helper()
+	details, err := households.CreateHousehold(context.Background(), f.tenant.ID, householdActorOf(owner), &domain.Household{Name: "Flat 12"})
+	if err != nil {
+		t.Fatalf("CreateHousehold: %v", err)
+	}
+	return details
+}
+
+func mustInvite(t *testing.T, households domain.HouseholdService, f *fixture, householdID uuid.UUID, inviter *domain.User, email string) string {
+	t.Helper()
+	invitation, err := households.Invite(context.Background(), f.tenant.ID, householdID, householdActorOf(inviter), email)
+	if err != nil {
+		t.Fatalf("Invite: %v", err)
+	}
+	return invitation.Code
+}
+
+func memberRoles(details *domain.HouseholdDetails) map[uuid.UUID]domain.HouseholdRole {
+	roles := map[uuid.UUID]domain.HouseholdRole{}
+	for _, member := range details.Members {
+		roles[member.UserID] = member.Role
+	}
+	return roles
+}
+
+func TestHouseholdInviteAndJoin(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	households := NewHouseholdService(f.Households, f.Users, f.Points)
+	owner := f.mustCreateUser(t, "owner@example.com")
+	member := f.mustCreateUser(t, "member@example.com")
+	stranger := f.mustCreateUser(t, "stranger@example.com")
+	household := mustCreateHousehold(t, households, f, owner)
+
+	code := mustInvite(t, households, f, household.ID, owner, "Member@Example.com")
+	if _, err := households.Join(ctx, f.tenant.ID, householdActorOf(stranger), code); !errors.Is(err, domain.ErrInvitationInvalid) {
+		t.Fatalf("Join(another email) error = %v, want domain.ErrInvitationInvalid", err)
+	}
+
+	// Код принимается без учёта регистра и пробелов, как его продиктовали
+	details, err := households.Join(ctx, f.tenant.ID, householdActorOf(member), " "+strings.ToLower(code)+" ")
+	if err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if roles := memberRoles(details); len(roles) != 2 || roles[member.ID] != domain.HouseholdMember || roles[owner.ID] != domain.HouseholdOwner {
+		t.Fatalf("members after join = %v, want the owner and one member", roles)
+	}
+
+	if _, err := households.Join(ctx, f.tenant.ID, householdActorOf(stranger), code); !errors.Is(err, domain.ErrInvitationInvalid) {
+		t.Fatalf("Join(used code) error = %v, want domain.ErrInvitationInvalid", err)
+	}
+
+	// Жилец состоит только в одном домохозяйстве
+	other := mustCreateHousehold(t, households, f, stranger)
+	open := mustInvite(t, households, f, other.ID, stranger, "")
+	if _, err := households.Join(ctx, f.tenant.ID, householdActorOf(member), open); !errors.Is(err, domain.ErrAlreadyInHousehold) {
+		t.Fatalf("Join(second household) error = %v, want domain.ErrAlreadyInHousehold", err)
+	}
+
+	expired := &domain.HouseholdInvitation{ID: uuid.New(), TenantID: f.tenant.ID, HouseholdID: household.ID, Code: "EXPIRED2",
+		InvitedBy: owner.ID, ExpiresAt: time.Now().UTC().Add(-time.Minute), CreatedAt: time.Now().UTC().Add(-invitationTTL)}
+	if err := f.Households.CreateInvitation(ctx, expired); err != nil {
+		t.Fatalf("CreateInvitation: %v", err)
+	}
+	latecomer := f.mustCreateUser(t, "late@example.com")
+	if _, err := households.Join(ctx, f.tenant.ID, householdActorOf(latecomer), expired.Code); !errors.Is(err, domain.ErrInvitationInvalid) {
+		t.Fatalf("Join(expired code) error = %v, want domain.ErrInvitationInvalid", err)
+	}
+
+	if _, err := households.Invite(ctx, f.tenant.ID, household.ID, householdActorOf(member), ""); !errors.Is(err, domain.ErrNotHouseholdOwner) {
+		t.Fatalf("Invite(by member) error = %v, want domain.ErrNotHouseholdOwner", err)
+	}
+}
+
+func TestHouseholdOwnershipTransfer(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	households := NewHouseholdService(f.Households, f.Users, f.Points)
+	owner := f.mustCreateUser(t, "owner@example.com")
+	member := f.mustCreateUser(t, "member@example.com")
+	outsider := f.mustCreateUser(t, "outsider@example.com")
+	household := mustCreateHousehold(t, households, f, owner)
+	if _, err := households.Join(ctx, f.tenant.ID, householdActorOf(member), mustInvite(t, households, f, household.ID, owner, "")); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	if err := households.RemoveMember(ctx, f.tenant.ID, household.ID, owner.ID, householdActorOf(owner)); !errors.Is(err, domain.ErrOwnerMustTransfer) {
+		t.Fatalf("RemoveMember(owner with members) error = %v, want domain.ErrOwnerMustTransfer", err)
+	}
+	if _, err := households.TransferOwnership(ctx, f.tenant.ID, household.ID, owner.ID, householdActorOf(member)); !errors.Is(err, domain.ErrNotHouseholdOwner) {
+		t.Fatalf("TransferOwnership(by member) error = %v, want domain.ErrNotHouseholdOwner", err)
+	}
+	if _, err := households.TransferOwnership(ctx, f.tenant.ID, household.ID, outsider.ID, householdActorOf(owner)); !errors.Is(err, domain.ErrNotHouseholdMember) {
+		t.Fatalf("TransferOwnership(to outsider) error = %v, want domain.ErrNotHouseholdMember", err)
+	}
+
+	details, err := households.TransferOwnership(ctx, f.tenant.ID, household.ID, member.ID, householdActorOf(owner))
+	if err != nil {
+		t.Fatalf("TransferOwnership: %v", err)
+	}
+	if roles := memberRoles(details); roles[member.ID] != domain.HouseholdOwner || roles[owner.ID] != domain.HouseholdMember {
+		t.Fatalf("roles after transfer = %v, want the member as the only owner", roles)
+	}
+
+	// Прежний владелец теперь обычный жилец и может уйти, а последний владелец уходит вместе с домохозяйством
+	if err := households.RemoveMember(ctx, f.tenant.ID, household.ID, owner.ID, householdActorOf(owner)); err != nil {
+		t.Fatalf("RemoveMember(former owner): %v", err)
+	}
+	if err := households.RemoveMember(ctx, f.tenant.ID, household.ID, member.ID, householdActorOf(member)); err != nil {
+		t.Fatalf("RemoveMember(last owner): %v", err)
+	}
+	if _, err := f.Households.FindHousehold(ctx, f.tenant.ID, household.ID); !errors.Is(err, domain.ErrHouseholdNotFound) {
+		t.Fatalf("FindHousehold after the last owner left error = %v, want domain.ErrHouseholdNotFound", err)
+	}
+}
+
+func TestHouseholdAccess(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	households := NewHouseholdService(f.Households, f.Users, f.Points)
+	owner := f.mustCreateUser(t, "owner@example.com")
+	outsider := f.mustCreateUser(t, "outsider@example.com")
+	household := mustCreateHousehold(t, households, f, owner)
+
+	tests := []struct {
+		name    string
+		actor   domain.HouseholdActor
+		wantErr error
+	}{
+		{name: "owner", actor: householdActorOf(owner)},
+		{name: "tenant admin", actor: domain.HouseholdActor{Admin: true}},
+		{name: "resident outside the household", actor: householdActorOf(outsider), wantErr: domain.ErrNotHouseholdMember},
+		{name: "account without a profile", actor: domain.HouseholdActor{IdentityKey: uuid.New()}, wantErr: domain.ErrNotHouseholdMember},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := households.GetHousehold(ctx, f.tenant.ID, household.ID, tt.actor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetHousehold error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	maxImportName  = 200
+	maxImportEmail = 254
+	// Прогресс сохраняется раз в столько строк, чтобы не писать в базу на каждой
+	importProgressEvery = 100
+	// Но не реже этого, чтобы медленные строки с приглашениями не выглядели брошенной загрузкой
+	importHeartbeatEvery = 30 * time.Second
+	defaultImportsLimit  = 20
+	staleImportReason    = "import was interrupted by a service restart; upload the file again"
+	stoppedImportReason  = "import was interrupted by a service shutdown; upload the file again"
+)
+
+type ImportServiceImpl struct {
+	users     domain.UserRepository
+	addresses domain.AddressService
+	imports   domain.ImportRepository
+	inviter   domain.Inviter
+
+	mu      sync.Mutex
+	stopped bool
+	cancels map[uuid.UUID]context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewImportService: inviter может быть nil, тогда загрузка с приглашениями отклоняется
+func NewImportService(users domain.UserRepository, addresses domain.AddressService, imports domain.ImportRepository, inviter domain.Inviter) domain.ImportService {
+	return &ImportServiceImpl{users: users, addresses: addresses, imports: imports, inviter: inviter, cancels: map[uuid.UUID]context.CancelFunc{}}
+}
+
+func (s *ImportServiceImpl) StartImport(ctx context.Context, tenant *domain.Tenant, request domain.ImportRequest) (*domain.ImportJob, error) {
+	if request.Invite && s.inviter == nil {
+		return nil, fmt.Errorf("%w: invitations are not configured", domain.ErrInvalidInput)
+	}
+	rows, err := importRows(request.Records)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &domain.ImportJob{
+		ID:          uuid.New(),
+		TenantID:    tenant.ID,
+		FileName:    request.FileName,
+		DryRun:      request.DryRun,
+		Invite:      request.Invite,
+		Status:      domain.ImportPending,
+		TotalRows:   len(rows),
+		Errors:      []domain.ImportRowError{},
+		CreatedAt:   now,
+		HeartbeatAt: &now,
+	}
+	if actor := requestctx.ActorFrom(ctx); actor != nil {
+		job.CreatedBy = actor.Email
+	}
+
+	// Загрузка переживает запрос, но сохраняет его идентификатор для логов; остановить её может только Shutdown
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if !s.track(job.ID, cancel) {
+		cancel()
+		return nil, domain.ErrImportsStopped
+	}
+	if err := s.imports.CreateImport(ctx, job); err != nil {
+		s.untrack(job.ID)
+		return nil, err
+	}
+
+	running := *job
+	go func() {
+		defer s.untrack(running.ID)
+		s.run(runCtx, tenant, &running, rows)
+	}()
+	return job, nil
+}
+
+// FailStaleImports закрывает загрузки, чей экземпляр упал, не сохранив итог; сам файл не хранится,
+// поэтому загрузку не продолжить, а повторная загрузка того же файла ничего не дублирует
+func (s *ImportServiceImpl) FailStaleImports(ctx context.Context) (int64, error) {
+	return s.imports.FailStaleImports(ctx, time.Now().UTC().Add(-domain.ImportHeartbeatTimeout), staleImportReason)
+}
+
+func (s *ImportServiceImpl) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	for _, cancel := range s.cancels {
+		cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *ImportServiceImpl) track(id uuid.UUID, cancel context.CancelFunc) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.cancels[id] = cancel
+	s.running.Add(1)
+	return true
+}
+
+func (s *ImportServiceImpl) untrack(id uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+		delete(s.cancels, id)
+		s.running.Done()
+	}
+}
+
+func (s *ImportServiceImpl) GetImport(ctx context.Context, tenantID, importID uuid.UUID) (*domain.ImportJob, error) {
+	return s.imports.FindImport(ctx, tenantID, importID)
+}
+
+func (s *ImportServiceImpl) ListImports(ctx context.Context, tenantID uuid.UUID, limit int) ([]domain.ImportJob, error) {
+	if limit <= 0 || limit > domain.MaxPageLimit {
+		limit = defaultImportsLimit
+	}
+	jobs, err := s.imports.ListImports(ctx, tenantID, limit)
+	if jobs == nil {
+		jobs = []domain.ImportJob{}
+	}
+	return jobs, err
+}
+
+// run прерывается только между строками: отменённый ctx останавливает цикл, а строка и итог
+// сохраняются без отмены, чтобы отчёт совпадал с тем, что записано в базу
+func (s *ImportServiceImpl) run(ctx context.Context, tenant *domain.Tenant, job *domain.ImportJob, rows []domain.ImportRow) {
+	stop := ctx
+	ctx = context.WithoutCancel(ctx)
+
+	started := time.Now().UTC()
+	job.Status = domain.ImportRunning
+	job.StartedAt = &started
+	s.save(ctx, job)
+
+	defer func() {
+		if r := recover(); r != nil {
+			job.Error = fmt.Sprintf("import stopped: %v", r)
+		}
+		finished := time.Now().UTC()
+		job.FinishedAt = &finished
+		job.Status = domain.ImportCompleted
+		if job.Error != "" {
+			job.Status = domain.ImportFailed
+		}
+		s.save(ctx, job)
+		log.Printf("Import %s finished with status %s: %d created, %d updated, %d unchanged, %d failed (request %s)",
+			job.ID, job.Status, job.Created, job.Updated, job.Unchanged, job.Failed, requestctx.RequestID(ctx))
+	}()
+
+	seen := map[string]int{}
+	for _, row := range rows {
+		if stop.Err() != nil {
+			job.Error = stoppedImportReason
+			return
+		}
+		s.importRow(ctx, tenant, job, row, seen)
+		job.Processed++
+		if job.Processed%importProgressEvery == 0 || time.Since(*job.HeartbeatAt) >= importHeartbeatEvery {
+			s.save(ctx, job)
+		}
+	}
+}
+
+// importRow проверяет строку и, если это не пробный прогон, создаёт или обновляет жителя по email.
+// Ошибка адреса или приглашения не отменяет уже сохранённый профиль, а попадает в отчёт.
+func (s *ImportServiceImpl) importRow(ctx context.Context, tenant *domain.Tenant, job *domain.ImportJob, row domain.ImportRow, seen map[string]int) {
+	if rowErr := validateImportRow(&row); rowErr != nil {
+		job.Failed++
+		addImportError(job, *rowErr)
+		return
+	}
+	key := strings.ToLower(row.Email)
+	if first, ok := seen[key]; ok {
+		job.Failed++
+		addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Field: "email", Message: fmt.Sprintf("duplicate of row %d", first)})
+		return
+	}
+	seen[key] = row.Row
+
+	user, err := s.users.FindByEmail(ctx, tenant.ID, row.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		job.Failed++
+		addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Message: err.Error()})
+		return
+	}
+
+	created := user == nil
+	changed := false
+	if created {
+		user = &domain.User{ID: uuid.New(), TenantID: tenant.ID, Email: row.Email, Name: row.Name, Role: domain.RoleUser, Version: 1}
+		if !job.DryRun {
+			user.CreatedAt = time.Now()
+			user.UpdatedAt = user.CreatedAt
+			if err := s.users.Create(ctx, user); err != nil {
+				job.Failed++
+				addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Message: err.Error()})
+				return
+			}
+		}
+	} else if row.Name != "" && row.Name != user.Name {
+		changed = true
+		if !job.DryRun {
+			user.Name = row.Name
+			user.UpdatedAt = time.Now()
+			if err := s.users.Update(ctx, user); err != nil {
+				job.Failed++
+				addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Field: "name", Message: err.Error()})
+				return
+			}
+		}
+	}
+
+	if address := importAddress(row); address != nil {
+		added, err := s.addAddress(ctx, tenant.ID, user, address, created, job.DryRun)
+		if err != nil {
+			addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Field: "address", Message: err.Error()})
+		}
+		changed = changed || added
+	}
+
+	switch {
+	case created:
+		job.Created++
+	case changed:
+		job.Updated++
+	default:
+		job.Unchanged++
+	}
+
+	// Профиль с ключом, но без InvitedAt, уже связан с учётной записью
+	if job.Invite && (user.IdentityKey == nil || user.InvitedAt != nil) {
+		s.invite(ctx, tenant, job, row, user)
+	}
+}
+
+// addAddress добавляет адрес, если у жителя ещё нет такого же; повторная загрузка файла адреса не дублирует
+func (s *ImportServiceImpl) addAddress(ctx context.Context, tenantID uuid.UUID, user *domain.User, address *domain.Address, created, dryRun bool) (bool, error) {
+	if !created {
+		existing, err := s.users.ListAddresses(ctx, tenantID, user.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, known := range existing {
+			if sameAddress(known, *address) {
+				return false, nil
+			}
+		}
+	}
+	if dryRun {
+		return true, nil
+	}
+	if err := s.addresses.AddAddress(ctx, tenantID, user.ID, address); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// invite резервирует за профилем ключ будущей учётной записи: событие о регистрации по приглашению
+// найдёт профиль по этому ключу, а не по email, который auth-service не подтверждает
+func (s *ImportServiceImpl) invite(ctx context.Context, tenant *domain.Tenant, job *domain.ImportJob, row domain.ImportRow, user *domain.User) {
+	if job.DryRun {
+		job.Invited++
+		return
+	}
+	identityKey := uuid.New()
+	if user.IdentityKey != nil {
+		identityKey = *user.IdentityKey
+	}
+	reserved, err := s.inviter.Invite(ctx, tenant.Slug, row.Email, row.Name, identityKey)
+	switch {
+	case err == nil:
+		job.Invited++
+	case errors.Is(err, domain.ErrAlreadyRegistered):
+		return
+	default:
+		addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Field: "invitation", Message: err.Error()})
+		return
+	}
+
+	if user.IdentityKey != nil && *user.IdentityKey == reserved && user.InvitedAt != nil {
+		return
+	}
+	now := time.Now()
+	user.IdentityKey = &reserved
+	user.InvitedAt = &now
+	user.UpdatedAt = now
+	if err := s.users.Update(ctx, user); err != nil {
+		addImportError(job, domain.ImportRowError{Row: row.Row, Email: row.Email, Field: "invitation", Message: err.Error()})
+	}
+}
+
+func (s *ImportServiceImpl) save(ctx context.Context, job *domain.ImportJob) {
+	heartbeat := time.Now().UTC()
+	job.HeartbeatAt = &heartbeat
+	if err := s.imports.SaveImport(ctx, job); err != nil {
+		log.Printf("Failed to save progress of import %s (request %s): %v", job.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+// importRows сопоставляет заголовок с известными колонками и пропускает пустые строки
+func importRows(records [][]string) ([]domain.ImportRow, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", domain.ErrInvalidInput)
+	}
+
+	columns := map[string]int{}
+	for i, cell := range records[0] {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		if name == "" || !containsString(domain.ImportColumns, name) {
+			continue
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("%w: column %q appears twice", domain.ErrInvalidInput, name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("%w: email column is required, known columns are %s", domain.ErrInvalidInput, strings.Join(domain.ImportColumns, ", "))
+	}
+
+	var rows []domain.ImportRow
+	for i, record := range records[1:] {
+		cell := func(name string) string {
+			index, ok := columns[name]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		row := domain.ImportRow{
+			Row:        i + 2,
+			Email:      cell("email"),
+			Name:       cell("name"),
+			City:       cell("city"),
+			Street:     cell("street"),
+			House:      cell("house"),
+			Apartment:  cell("apartment"),
+			PostalCode: cell("postal_code"),
+		}
+		if row == (domain.ImportRow{Row: row.Row}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: file has no rows", domain.ErrInvalidInput)
+	}
+	if len(rows) > domain.MaxImportRows {
+		return nil, fmt.Errorf("%w: file has %d rows, at most %d are allowed", domain.ErrInvalidInput, len(rows), domain.MaxImportRows)
+	}
+	return rows, nil
+}
+
+func validateImportRow(row *domain.ImportRow) *domain.ImportRowError {
+	fail := func(field, message string) *domain.ImportRowError {
+		return &domain.ImportRowError{Row: row.Row, Email: row.Email, Field: field, Message: message}
+	}
+
+	switch {
+	case row.Email == "":
+		return fail("email", "email is required")
+	case len(row.Email) > maxImportEmail:
+		return fail("email", fmt.Sprintf("email must be at most %d characters", maxImportEmail))
+	}
+	if parsed, err := mail.ParseAddress(row.Email); err != nil || parsed.Address != row.Email {
+		return fail("email", "email is not a valid address")
+	}
+	if len(row.Name) > maxImportName {
+		return fail("name", fmt.Sprintf("name must be at most %d characters", maxImportName))
+	}
+
+	if importAddress(*row) == nil {
+		return nil
+	}
+	switch {
+	case row.Street == "":
+		return fail("street", "street is required when an address is given")
+	case row.City == "":
+		return fail("city", "city is required when an address is given")
+	}
+	for field, value := range map[string]string{"street": row.Street, "house": row.House, "apartment": row.Apartment, "city": row.City, "postal_code": row.PostalCode} {
+		if len(value) > maxAddressField {
+			return fail(field, fmt.Sprintf("%s must be at most %d characters", field, maxAddressField))
+		}
+	}
+	return nil
+}
+
+// importAddress возвращает nil, если в строке нет ни одного поля адреса
+func importAddress(row domain.ImportRow) *domain.Address {
+	if row.City == "" && row.Street == "" && row.House == "" && row.Apartment == "" && row.PostalCode == "" {
+		return nil
+	}
+	return &domain.Address{City: row.City, Street: row.Street, House: row.House, Apartment: row.Apartment, PostalCode: row.PostalCode}
+}
+
+func addImportError(job *domain.ImportJob, rowErr domain.ImportRowError) {
+	if len(job.Errors) < domain.MaxImportErrors {
+		job.Errors = append(job.Errors, rowErr)
+	}
+}
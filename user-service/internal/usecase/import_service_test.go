@@ -0,0 +1,494 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+func TestImportRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		records [][]string
+		want    []domain.ImportRow
+		wantErr bool
+	}{
+		{name: "empty file", wantErr: true},
+		{name: "no email column", records: [][]string{{"name"}, {"Alice"}}, wantErr: true},
+		{name: "column twice", records: [][]string{{"email", "Email"}, {"a@example.com", "b@example.com"}}, wantErr: true},
+		{name: "header only", records: [][]string{{"email"}}, wantErr: true},
+		{name: "only blank rows", records: [][]string{{"email", "name"}, {"", " "}}, wantErr: true},
+		{name: "spellings of one column", records: [][]string{{"email", "Postal Code", "postal-code "}, {"a@example.com", "050000", ""}}, wantErr: true},
+		{
+			name:    "header normalised",
+			records: [][]string{{"\ufeffEmail", " Name ", "Postal Code"}, {" a@example.com ", " Alice ", "050000"}},
+			want:    []domain.ImportRow{{Row: 2, Email: "a@example.com", Name: "Alice", PostalCode: "050000"}},
+		},
+		{
+			name:    "unknown columns, blank and short rows",
+			records: [][]string{{"email", "notes", "name"}, {"a@example.com", "x", "Alice"}, {"", "only notes", ""}, {"b@example.com"}},
+			want: []domain.ImportRow{
+				{Row: 2, Email: "a@example.com", Name: "Alice"},
+				{Row: 4, Email: "b@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := importRows(tt.records)
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidInput) {
+					t.Fatalf("importRows error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("importRows: %v", err)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("importRows = %+v, want %+v", rows, tt.want)
+			}
+			for i := range rows {
+				if rows[i] != tt.want[i] {
+					t.Errorf("row %d = %+v, want %+v", i, rows[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateImportRow(t *testing.T) {
+	tests := []struct {
+		name      string
+		row       domain.ImportRow
+		wantField string
+	}{
+		{name: "email only", row: domain.ImportRow{Email: "a@example.com"}},
+		{name: "full address", row: domain.ImportRow{Email: "a@example.com", Name: "Alice", City: "Almaty", Street: "Abay", House: "10"}},
+		{name: "missing email", row: domain.ImportRow{Name: "Alice"}, wantField: "email"},
+		{name: "not an address", row: domain.ImportRow{Email: "alice"}, wantField: "email"},
+		{name: "display name form", row: domain.ImportRow{Email: "Alice <a@example.com>"}, wantField: "email"},
+		{name: "email too long", row: domain.ImportRow{Email: strings.Repeat("a", maxImportEmail) + "@example.com"}, wantField: "email"},
+		{name: "name too long", row: domain.ImportRow{Email: "a@example.com", Name: strings.Repeat("x", maxImportName+1)}, wantField: "name"},
+		{name: "house without street", row: domain.ImportRow{Email: "a@example.com", City: "Almaty", House: "10"}, wantField: "street"},
+		{name: "street without city", row: domain.ImportRow{Email: "a@example.com", Street: "Abay"}, wantField: "city"},
+		{name: "address field too long", row: domain.ImportRow{Email: "a@example.com", City: "Almaty", Street: "Abay", Apartment: strings.Repeat("9", maxAddressField+1)}, wantField: "apartment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rowErr := validateImportRow(&tt.row)
+			switch {
+			case tt.wantField == "" && rowErr != nil:
+				t.Fatalf("validateImportRow = %+v, want no error", rowErr)
+			case tt.wantField != "" && (rowErr == nil || rowErr.Field != tt.wantField):
+				t.Fatalf("validateImportRow = %+v, want an error on %s", rowErr, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestStartImport(t *testing.T) {
+	type counts struct{ created, updated, unchanged, failed, invited int }
+	tests := []struct {
+		name          string
+		records       [][]string
+		dryRun        bool
+		invite        bool
+		want          counts
+		wantErrors    []string
+		wantUsers     int
+		wantKnownName string
+		wantAddresses int
+		wantInvites   []string
+	}{
+		{
+			name:          "new resident",
+			records:       [][]string{{"email", "name"}, {"new@example.com", "Newcomer"}},
+			want:          counts{created: 1},
+			wantUsers:     2,
+			wantKnownName: "Resident",
+		},
+		{
+			name:          "rename existing resident",
+			records:       [][]string{{"email", "name"}, {"known@example.com", "Alice"}},
+			want:          counts{updated: 1},
+			wantUsers:     1,
+			wantKnownName: "Alice",
+		},
+		{
+			name:          "same data is unchanged",
+			records:       [][]string{{"email", "name"}, {"known@example.com", "Resident"}},
+			want:          counts{unchanged: 1},
+			wantUsers:     1,
+			wantKnownName: "Resident",
+		},
+		{
+			name:          "empty name keeps the profile name",
+			records:       [][]string{{"email", "name"}, {"known@example.com", ""}},
+			want:          counts{unchanged: 1},
+			wantUsers:     1,
+			wantKnownName: "Resident",
+		},
+		{
+			name:          "address added to existing resident",
+			records:       [][]string{{"email", "city", "street"}, {"known@example.com", "Almaty", "Abay"}},
+			want:          counts{updated: 1},
+			wantUsers:     1,
+			wantKnownName: "Resident",
+			wantAddresses: 1,
+		},
+		{
+			name:          "invalid rows are reported",
+			records:       [][]string{{"email", "city", "street"}, {"not-an-email"}, {"new@example.com"}, {"NEW@example.com"}, {"other@example.com", "Almaty", ""}},
+			want:          counts{created: 1, failed: 3},
+			wantErrors:    []string{"email", "email", "street"},
+			wantUsers:     2,
+			wantKnownName: "Resident",
+		},
+		{
+			name:          "dry run writes nothing",
+			records:       [][]string{{"email", "name", "city", "street"}, {"new@example.com", "Newcomer"}, {"known@example.com", "Alice", "Almaty", "Abay"}},
+			dryRun:        true,
+			want:          counts{created: 1, updated: 1},
+			wantUsers:     1,
+			wantKnownName: "Resident",
+		},
+		{
+			name:          "invitations",
+			records:       [][]string{{"email"}, {"new@example.com"}, {"registered@example.com"}, {"broken@example.com"}},
+			invite:        true,
+			want:          counts{created: 3, invited: 1},
+			wantErrors:    []string{"invitation"},
+			wantUsers:     4,
+			wantKnownName: "Resident",
+			wantInvites:   []string{"new@example.com", "registered@example.com", "broken@example.com"},
+		},
+		{
+			name:          "dry run only counts invitations",
+			records:       [][]string{{"email"}, {"new@example.com"}},
+			dryRun:        true,
+			invite:        true,
+			want:          counts{created: 1, invited: 1},
+			wantUsers:     1,
+			wantKnownName: "Resident",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := adminContext()
+			f := newFixture(t)
+			known := f.mustCreateUser(t, "known@example.com")
+			inviter := &fakeInviter{errors: map[string]error{
+				"registered@example.com": domain.ErrAlreadyRegistered,
+				"broken@example.com":     errors.New("auth-service is unavailable"),
+			}}
+			service := f.importService(inviter)
+
+			job, err := service.StartImport(ctx, f.tenant, domain.ImportRequest{FileName: "residents.csv", Records: tt.records, DryRun: tt.dryRun, Invite: tt.invite})
+			if err != nil {
+				t.Fatalf("StartImport: %v", err)
+			}
+			if job.CreatedBy != "admin@example.com" {
+				t.Errorf("CreatedBy = %q, want the admin email", job.CreatedBy)
+			}
+			job = waitImport(t, service, f, job.ID)
+
+			if job.Status != domain.ImportCompleted || job.Processed != job.TotalRows {
+				t.Fatalf("job = %s with %d/%d rows, want completed with every row processed", job.Status, job.Processed, job.TotalRows)
+			}
+			got := counts{created: job.Created, updated: job.Updated, unchanged: job.Unchanged, failed: job.Failed, invited: job.Invited}
+			if got != tt.want {
+				t.Errorf("counts = %+v, want %+v", got, tt.want)
+			}
+			var fields []string
+			for _, rowErr := range job.Errors {
+				fields = append(fields, rowErr.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.wantErrors, ",") {
+				t.Errorf("error fields = %v, want %v (%+v)", fields, tt.wantErrors, job.Errors)
+			}
+
+			users, _, err := f.Users.List(context.Background(), f.tenant.ID, domain.UserFilter{})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(users) != tt.wantUsers {
+				t.Errorf("tenant has %d users, want %d", len(users), tt.wantUsers)
+			}
+			stored, err := f.Users.FindByID(context.Background(), f.tenant.ID, known.ID)
+			if err != nil {
+				t.Fatalf("FindByID: %v", err)
+			}
+			if stored.Name != tt.wantKnownName {
+				t.Errorf("known resident name = %q, want %q", stored.Name, tt.wantKnownName)
+			}
+			addresses, err := f.Users.ListAddresses(context.Background(), f.tenant.ID, known.ID)
+			if err != nil {
+				t.Fatalf("ListAddresses: %v", err)
+			}
+			if len(addresses) != tt.wantAddresses {
+				t.Errorf("known resident has %d addresses, want %d", len(addresses), tt.wantAddresses)
+			}
+			if invited := inviter.emails(); strings.Join(invited, ",") != strings.Join(tt.wantInvites, ",") {
+				t.Errorf("invited %v, want %v", invited, tt.wantInvites)
+			}
+		})
+	}
+}
+
+// Повторная загрузка того же файла ничего не дублирует
+func TestReimportIsUnchanged(t *testing.T) {
+	ctx := adminContext()
+	f := newFixture(t)
+	service := f.importService(nil)
+	records := [][]string{{"email", "name", "city", "street", "house"}, {"new@example.com", "Newcomer", "Almaty", "Abay", "10"}}
+
+	for i, want := range []struct{ created, unchanged int }{{created: 1}, {unchanged: 1}} {
+		job, err := service.StartImport(ctx, f.tenant, domain.ImportRequest{Records: records})
+		if err != nil {
+			t.Fatalf("StartImport: %v", err)
+		}
+		job = waitImport(t, service, f, job.ID)
+		if job.Created != want.created || job.Unchanged != want.unchanged || job.Updated != 0 {
+			t.Fatalf("run %d counts = %d created, %d updated, %d unchanged; want %+v", i+1, job.Created, job.Updated, job.Unchanged, want)
+		}
+	}
+
+	user, err := f.Users.FindByEmail(context.Background(), f.tenant.ID, "new@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	addresses, err := f.Users.ListAddresses(context.Background(), f.tenant.ID, user.ID)
+	if err != nil || len(addresses) != 1 {
+		t.Fatalf("ListAddresses = %v, %v; want one address", addresses, err)
+	}
+}
+
+// Приглашение резервирует за профилем ключ учётной записи: регистрация по приглашению
+// привязывает именно этот профиль, а повторная загрузка приглашает с тем же ключом
+func TestImportInvitationReservesIdentityKey(t *testing.T) {
+	ctx := adminContext()
+	f := newFixture(t)
+	inviter := &fakeInviter{}
+	service := f.importService(inviter)
+	records := [][]string{{"email", "name"}, {"new@example.com", "Newcomer"}}
+
+	var reserved uuid.UUID
+	for run := 1; run <= 2; run++ {
+		job, err := service.StartImport(ctx, f.tenant, domain.ImportRequest{Records: records, Invite: true})
+		if err != nil {
+			t.Fatalf("StartImport: %v", err)
+		}
+		if job = waitImport(t, service, f, job.ID); job.Invited != 1 {
+			t.Fatalf("run %d invited %d, want 1 (%+v)", run, job.Invited, job.Errors)
+		}
+		user, err := f.Users.FindByEmail(context.Background(), f.tenant.ID, "new@example.com")
+		if err != nil {
+			t.Fatalf("FindByEmail: %v", err)
+		}
+		if user.IdentityKey == nil || user.InvitedAt == nil {
+			t.Fatalf("run %d profile = %+v, want a reserved identity key", run, user)
+		}
+		if run == 2 && *user.IdentityKey != reserved {
+			t.Fatalf("second run reserved %s, want the same key %s", user.IdentityKey, reserved)
+		}
+		reserved = *user.IdentityKey
+	}
+
+	event := domain.AccountEvent{ID: uuid.New(), Type: domain.EventAccountRegistered, IdentityKey: reserved, Email: "new@example.com", Role: domain.RoleUser, Version: 1}
+	linked, err := NewAccountService(f.Users, f.Tenants, nil).ApplyAccountEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("ApplyAccountEvent: %v", err)
+	}
+	if linked.Name != "Newcomer" || linked.InvitedAt != nil {
+		t.Fatalf("linked profile = %+v, want the imported profile without a pending invitation", linked)
+	}
+
+	// Связанный профиль больше не приглашается
+	job, err := service.StartImport(ctx, f.tenant, domain.ImportRequest{Records: records, Invite: true})
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+	if job = waitImport(t, service, f, job.ID); job.Invited != 0 || len(inviter.emails()) != 2 {
+		t.Fatalf("import after registration invited %d (%v), want nobody", job.Invited, inviter.emails())
+	}
+}
+
+func TestStartImportRejectsRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request domain.ImportRequest
+		inviter domain.Inviter
+	}{
+		{name: "invite without inviter", request: domain.ImportRequest{Records: [][]string{{"email"}, {"a@example.com"}}, Invite: true}},
+		{name: "no email column", request: domain.ImportRequest{Records: [][]string{{"name"}, {"Alice"}}}, inviter: &fakeInviter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			service := f.importService(tt.inviter)
+
+			if _, err := service.StartImport(adminContext(), f.tenant, tt.request); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("StartImport error = %v, want ErrInvalidInput", err)
+			}
+			// Отклонённый файл не оставляет загрузки в истории
+			jobs, err := service.ListImports(context.Background(), f.tenant.ID, 0)
+			if err != nil || len(jobs) != 0 {
+				t.Fatalf("ListImports = %v, %v; want no imports", jobs, err)
+			}
+		})
+	}
+}
+
+// Shutdown дожидается текущей строки, останавливает загрузку и не принимает новые
+func TestImportShutdown(t *testing.T) {
+	f := newFixture(t)
+	inviter := &fakeInviter{started: make(chan struct{}), release: make(chan struct{})}
+	service := f.importService(inviter)
+
+	job, err := service.StartImport(adminContext(), f.tenant, domain.ImportRequest{
+		Records: [][]string{{"email"}, {"first@example.com"}, {"second@example.com"}},
+		Invite:  true,
+	})
+	if err != nil {
+		t.Fatalf("StartImport: %v", err)
+	}
+	<-inviter.started
+
+	done := make(chan error, 1)
+	go func() { done <- service.Shutdown(context.Background()) }()
+	for !service.isStopped() {
+		time.Sleep(time.Millisecond)
+	}
+	close(inviter.release)
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	stored, err := service.GetImport(context.Background(), f.tenant.ID, job.ID)
+	if err != nil {
+		t.Fatalf("GetImport: %v", err)
+	}
+	if stored.Status != domain.ImportFailed || stored.Error != stoppedImportReason || stored.Processed != 1 || stored.Invited != 1 {
+		t.Fatalf("job = %+v, want failed after the first row", stored)
+	}
+	if _, err := service.StartImport(adminContext(), f.tenant, domain.ImportRequest{Records: [][]string{{"email"}, {"a@example.com"}}}); !errors.Is(err, domain.ErrImportsStopped) {
+		t.Fatalf("StartImport after Shutdown error = %v, want ErrImportsStopped", err)
+	}
+}
+
+func TestFailStaleImports(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	service := f.importService(nil)
+
+	stale := time.Now().UTC().Add(-2 * domain.ImportHeartbeatTimeout)
+	fresh := time.Now().UTC()
+	jobs := map[string]*domain.ImportJob{
+		"stale":    {ID: uuid.New(), TenantID: f.tenant.ID, Status: domain.ImportRunning, CreatedAt: stale, HeartbeatAt: &stale},
+		"fresh":    {ID: uuid.New(), TenantID: f.tenant.ID, Status: domain.ImportRunning, CreatedAt: fresh, HeartbeatAt: &fresh},
+		"finished": {ID: uuid.New(), TenantID: f.tenant.ID, Status: domain.ImportCompleted, CreatedAt: stale, HeartbeatAt: &stale},
+	}
+	for _, job := range jobs {
+		if err := f.Imports.CreateImport(ctx, job); err != nil {
+			t.Fatalf("CreateImport: %v", err)
+		}
+	}
+
+	failed, err := service.FailStaleImports(ctx)
+	if err != nil || failed != 1 {
+		t.Fatalf("FailStaleImports = %d, %v; want 1", failed, err)
+	}
+	for name, wantStatus := range map[string]domain.ImportStatus{"stale": domain.ImportFailed, "fresh": domain.ImportRunning, "finished": domain.ImportCompleted} {
+		stored, err := f.Imports.FindImport(ctx, f.tenant.ID, jobs[name].ID)
+		if err != nil {
+			t.Fatalf("FindImport(%s): %v", name, err)
+		}
+		if stored.Status != wantStatus {
+			t.Errorf("%s import status = %s, want %s", name, stored.Status, wantStatus)
+		}
+	}
+}
+
+// fakeInviter запоминает приглашённых; started/release позволяют задержать первое приглашение
+type fakeInviter struct {
+	errors  map[string]error
+	started chan struct{}
+	release chan struct{}
+
+	mu      sync.Mutex
+	invited []string
+}
+
+func (i *fakeInviter) Invite(_ context.Context, _, email, _ string, identityKey uuid.UUID) (uuid.UUID, error) {
+	i.mu.Lock()
+	i.invited = append(i.invited, email)
+	first := len(i.invited) == 1
+	i.mu.Unlock()
+	if first && i.started != nil {
+		close(i.started)
+		<-i.release
+	}
+	if err := i.errors[email]; err != nil {
+		return uuid.Nil, err
+	}
+	return identityKey, nil
+}
+
+func (i *fakeInviter) emails() []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]string(nil), i.invited...)
+}
+
+// noGeocoder не знает ни одного адреса, адреса сохраняются без координат
+type noGeocoder struct{}
+
+func (noGeocoder) Geocode(context.Context, domain.Address) (*domain.GeocodeResult, error) {
+	return nil, domain.ErrLocationNotFound
+}
+
+func (f *fixture) importService(inviter domain.Inviter) *ImportServiceImpl {
+	return NewImportService(f.Users, NewAddressService(f.Users, noGeocoder{}, nil), f.Imports, inviter).(*ImportServiceImpl)
+}
+
+func (s *ImportServiceImpl) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+// adminContext — запрос администратора, как его передаёт шлюз
+func adminContext() context.Context {
+	return requestctx.WithActor(context.Background(), &requestctx.Actor{AuthUserID: "1", Email: "admin@example.com", Role: domain.RoleAdmin})
+}
+
+func waitImport(t *testing.T, service *ImportServiceImpl, f *fixture, id uuid.UUID) *domain.ImportJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := service.GetImport(context.Background(), f.tenant.ID, id)
+		if err != nil {
+			t.Fatalf("GetImport: %v", err)
+		}
+		if job.Status == domain.ImportCompleted || job.Status == domain.ImportFailed {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("import is still %s", job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const maxAdjustmentReason = 500
+
+type PointsServiceImpl struct {
+	points domain.PointsRepository
+	users  domain.UserRepository
+}
+
+func NewPointsService(points domain.PointsRepository, users domain.UserRepository) *PointsServiceImpl {
+	return &PointsServiceImpl{points: points, users: users}
+}
+
+// ActionRecorded начисляет баллы за новое действие; повторная доставка того же действия игнорируется
+func (s *PointsServiceImpl) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	_, err := s.CreditAction(ctx, action)
+	if err != nil && !errors.Is(err, domain.ErrPointsAlreadyCredited) {
+		log.Printf("Failed to credit points for action %s (request %s): %v", action.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+// CreditAction списывает баллы со счёта вознаграждений на счёт пользователя по правилу для типа действия.
+// Если правило даёт 0 баллов, операция не создаётся и возвращается nil.
+func (s *PointsServiceImpl) CreditAction(ctx context.Context, action domain.UserAction) (*domain.PointsTransaction, error) {
+	points, err := s.pointsFor(ctx, action.TenantID, action.Action)
+	if err != nil {
+		return nil, err
+	}
+	if points <= 0 {
+		return nil, nil
+	}
+
+	actionID := action.ID
+	transaction := &domain.PointsTransaction{
+		ID:        uuid.New(),
+		TenantID:  action.TenantID,
+		Kind:      domain.PointsEarned,
+		ActionID:  &actionID,
+		Reason:    string(action.Action),
+		CreatedBy: action.Source,
+		CreatedAt: time.Now().UTC(),
+	}
+	entries := transfer(transaction, domain.AccountRewards, domain.UserAccount(action.UserID), points)
+	if err := s.points.CreateTransaction(ctx, transaction, entries); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (s *PointsServiceImpl) Balance(ctx context.Context, tenantID, userID uuid.UUID) (*domain.PointsBalance, error) {
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.points.Balance(ctx, tenantID, domain.UserAccount(userID))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PointsBalance{UserID: userID, Balance: balance}, nil
+}
+
+func (s *PointsServiceImpl) History(ctx context.Context, tenantID, userID uuid.UUID, limit, offset int) (*domain.PointsHistoryPage, error) {
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	limit, offset = normalizePage(limit, offset)
+	items, total, err := s.points.History(ctx, tenantID, domain.UserAccount(userID), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []domain.PointsHistoryItem{}
+	}
+	return &domain.PointsHistoryPage{Items: items, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+// Adjust начисляет (amount > 0) или списывает (amount < 0) баллы вручную; причина обязательна и попадает в историю.
+// Хватает ли баллов на списание, проверяет репозиторий в той же транзакции, что и запись.
+func (s *PointsServiceImpl) Adjust(ctx context.Context, tenantID, userID uuid.UUID, amount int64, reason string) (*domain.PointsTransaction, error) {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case amount == 0:
+		return nil, fmt.Errorf("%w: amount must not be zero", domain.ErrInvalidInput)
+	case reason == "":
+		return nil, fmt.Errorf("%w: reason is required", domain.ErrInvalidInput)
+	case len(reason) > maxAdjustmentReason:
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", domain.ErrInvalidInput, maxAdjustmentReason)
+	}
+
+	if _, err := s.users.FindByID(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+
+	transaction := &domain.PointsTransaction{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Kind:      domain.PointsAdjusted,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+	if actor := requestctx.ActorFrom(ctx); actor != nil {
+		transaction.CreatedBy = actor.AuthUserID
+	}
+
+	entries := transfer(transaction, domain.AccountAdjustments, domain.UserAccount(userID), amount)
+	if err := s.points.CreateTransaction(ctx, transaction, entries); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// ListRules возвращает действующие правила: заданные тенантом поверх DefaultEarningRules
+func (s *PointsServiceImpl) ListRules(ctx context.Context, tenantID uuid.UUID) ([]domain.EarningRule, error) {
+	custom, err := s.points.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	byAction := map[domain.ActionType]domain.EarningRule{}
+	for _, rule := range custom {
+		byAction[rule.Action] = rule
+	}
+
+	rules := make([]domain.EarningRule, 0, len(domain.ActionTypes))
+	for _, actionType := range domain.ActionTypes {
+		rule, ok := byAction[actionType]
+		if !ok {
+			rule = domain.EarningRule{TenantID: tenantID, Action: actionType, Points: domain.DefaultEarningRules[actionType], IsDefault: true}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *PointsServiceImpl) SetRule(ctx context.Context, tenantID uuid.UUID, action domain.ActionType, points int64) (*domain.EarningRule, error) {
+	if !action.Valid() {
+		return nil, fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, action)
+	}
+	if points < 0 {
+		return nil, fmt.Errorf("%w: points must not be negative", domain.ErrInvalidInput)
+	}
+
+	rule := &domain.EarningRule{TenantID: tenantID, Action: action, Points: points, UpdatedAt: time.Now().UTC()}
+	if err := s.points.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *PointsServiceImpl) pointsFor(ctx context.Context, tenantID uuid.UUID, action domain.ActionType) (int64, error) {
+	rules, err := s.points.ListRules(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	for _, rule := range rules {
+		if rule.Action == action {
+			return rule.Points, nil
+		}
+	}
+	return domain.DefaultEarningRules[action], nil
+}
+
+// transfer составляет пару проводок: amount уходит со счёта from на счёт to
+func transfer(transaction *domain.PointsTransaction, from, to string, amount int64) []domain.PointsEntry {
+	return []domain.PointsEntry{
+		{ID: uuid.New(), TenantID: transaction.TenantID, TransactionID: transaction.ID, Account: from, Amount: -amount, CreatedAt: transaction.CreatedAt},
+		{ID: uuid.New(), TenantID: transaction.TenantID, TransactionID: transaction.ID, Account: to, Amount: amount, CreatedAt: transaction.CreatedAt},
+	}
+}
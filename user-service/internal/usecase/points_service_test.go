@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+func TestCreditAction(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       map[domain.ActionType]int64
+		actions     []domain.ActionType
+		wantBalance int64
+		wantCredits int
+	}{
+		{name: "default rule", actions: []domain.ActionType{domain.ActionWasteSorted}, wantBalance: 10, wantCredits: 1},
+		{name: "several actions", actions: []domain.ActionType{domain.ActionWasteSorted, domain.ActionPointVisited, domain.ActionReportFiled}, wantBalance: 45, wantCredits: 3},
+		{name: "zero-point action creates no transaction", actions: []domain.ActionType{domain.ActionProfileUpdated}, wantBalance: 0, wantCredits: 0},
+		{name: "tenant rule overrides default", rules: map[domain.ActionType]int64{domain.ActionWasteSorted: 3}, actions: []domain.ActionType{domain.ActionWasteSorted, domain.ActionWasteSorted}, wantBalance: 6, wantCredits: 2},
+		{name: "tenant rule switches action off", rules: map[domain.ActionType]int64{domain.ActionPointVisited: 0}, actions: []domain.ActionType{domain.ActionPointVisited}, wantBalance: 0, wantCredits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t)
+			points := NewPointsService(f.Points, f.Users)
+			user := f.mustCreateUser(t, "resident@example.com")
+			for action, value := range tt.rules {
+				if _, err := points.SetRule(ctx, f.tenant.ID, action, value); err != nil {
+					t.Fatalf("SetRule: %v", err)
+				}
+			}
+
+			credits := 0
+			for _, actionType := range tt.actions {
+				transaction, err := points.CreditAction(ctx, f.action(user, actionType, time.Now()))
+				if err != nil {
+					t.Fatalf("CreditAction(%s): %v", actionType, err)
+				}
+				if transaction != nil {
+					credits++
+				}
+			}
+
+			assertBalance(t, points, f, user, tt.wantBalance)
+			if credits != tt.wantCredits {
+				t.Errorf("credited %d transactions, want %d", credits, tt.wantCredits)
+			}
+			// Двойная запись: счёт вознаграждений уменьшился ровно на начисленное
+			rewards, err := f.Points.Balance(ctx, f.tenant.ID, domain.AccountRewards)
+			if err != nil {
+				t.Fatalf("Balance(rewards): %v", err)
+			}
+			if rewards != -tt.wantBalance {
+				t.Errorf("rewards account = %d, want %d", rewards, -tt.wantBalance)
+			}
+		})
+	}
+}
+
+// Повторная доставка действия не начисляет баллы второй раз
+func TestCreditActionOnce(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	points := NewPointsService(f.Points, f.Users)
+	user := f.mustCreateUser(t, "resident@example.com")
+	action := f.action(user, domain.ActionWasteSorted, time.Now())
+
+	if _, err := points.CreditAction(ctx, action); err != nil {
+		t.Fatalf("CreditAction: %v", err)
+	}
+	if _, err := points.CreditAction(ctx, action); !errors.Is(err, domain.ErrPointsAlreadyCredited) {
+		t.Fatalf("second CreditAction error = %v, want ErrPointsAlreadyCredited", err)
+	}
+	points.ActionRecorded(ctx, action)
+	assertBalance(t, points, f, user, 10)
+}
+
+func TestAdjust(t *testing.T) {
+	tests := []struct {
+		name        string
+		amounts     []int64
+		reason      string
+		wantErr     error
+		wantBalance int64
+	}{
+		{name: "credit", amounts: []int64{25}, reason: "event bonus", wantBalance: 25},
+		{name: "debit within balance", amounts: []int64{25, -20}, reason: "correction", wantBalance: 5},
+		{name: "debit to zero", amounts: []int64{25, -25}, reason: "correction", wantBalance: 0},
+		{name: "overdraft", amounts: []int64{25, -26}, reason: "correction", wantErr: domain.ErrInsufficientPoints, wantBalance: 25},
+		{name: "debit of empty account", amounts: []int64{-1}, reason: "correction", wantErr: domain.ErrInsufficientPoints, wantBalance: 0},
+		{name: "zero amount", amounts: []int64{0}, reason: "correction", wantErr: domain.ErrInvalidInput, wantBalance: 0},
+		{name: "blank reason", amounts: []int64{10}, reason: "   ", wantErr: domain.ErrInvalidInput, wantBalance: 0},
+		{name: "reason too long", amounts: []int64{10}, reason: strings.Repeat("x", maxAdjustmentReason+1), wantErr: domain.ErrInvalidInput, wantBalance: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t)
+			points := NewPointsService(f.Points, f.Users)
+			user := f.mustCreateUser(t, "resident@example.com")
+
+			var err error
+			for _, amount := range tt.amounts {
+				if _, err = points.Adjust(ctx, f.tenant.ID, user.ID, amount, tt.reason); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Adjust error = %v, want %v", err, tt.wantErr)
+			}
+			assertBalance(t, points, f, user, tt.wantBalance)
+		})
+	}
+}
+
+func TestAdjustUnknownUser(t *testing.T) {
+	f := newFixture(t)
+	points := NewPointsService(f.Points, f.Users)
+
+	if _, err := points.Adjust(context.Background(), f.tenant.ID, uuid.New(), 10, "bonus"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("Adjust error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestSetRuleValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  domain.ActionType
+		points  int64
+		wantErr error
+	}{
+		{name: "valid", action: domain.ActionWasteSorted, points: 7},
+		{name: "zero switches action off", action: domain.ActionWasteSorted, points: 0},
+		{name: "negative", action: domain.ActionWasteSorted, points: -1, wantErr: domain.ErrInvalidInput},
+		{name: "unknown action", action: "flew_away", points: 5, wantErr: domain.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			points := NewPointsService(f.Points, f.Users)
+
+			_, err := points.SetRule(context.Background(), f.tenant.ID, tt.action, tt.points)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetRule error = %v, want %v", err, tt.wantErr)
+			}
+
+			rules, err := points.ListRules(context.Background(), f.tenant.ID)
+			if err != nil {
+				t.Fatalf("ListRules: %v", err)
+			}
+			if len(rules) != len(domain.ActionTypes) {
+				t.Fatalf("ListRules returned %d rules, want one per action type", len(rules))
+			}
+			for _, rule := range rules {
+				if rule.Action == tt.action && tt.wantErr == nil && (rule.Points != tt.points || rule.IsDefault) {
+					t.Errorf("rule for %s = %+v, want custom %d", tt.action, rule, tt.points)
+				}
+			}
+		})
+	}
+}
+
+func assertBalance(t *testing.T, points *PointsServiceImpl, f *fixture, user *domain.User, want int64) {
+	t.Helper()
+	balance, err := points.Balance(actorContext(user), f.tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("Balance: %v", err)
+	}
+	if balance.Balance != want {
+		t.Fatalf("balance = %d, want %d", balance.Balance, want)
+	}
+}
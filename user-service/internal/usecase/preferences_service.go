@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+type PreferencesServiceImpl struct {
+	preferences domain.PreferencesRepository
+	users       domain.UserRepository
+}
+
+func NewPreferencesService(preferences domain.PreferencesRepository, users domain.UserRepository) domain.PreferencesService {
+	return &PreferencesServiceImpl{preferences: preferences, users: users}
+}
+
+// GetPreferences доступен самому пользователю и админу: каналы уведомлений и тихие часы — личные данные жителя
+func (s *PreferencesServiceImpl) GetPreferences(ctx context.Context, tenantID, userID uuid.UUID) (*domain.UserPreferences, error) {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	return s.current(ctx, tenantID, userID)
+}
+
+// UpdatePreferences сохраняет документ целиком; если что-то действительно изменилось,
+// вместе с ним в outbox записывается событие
+func (s *PreferencesServiceImpl) UpdatePreferences(ctx context.Context, tenantID, userID uuid.UUID, preferences domain.Preferences) (*domain.UserPreferences, error) {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	if err := normalizePreferences(&preferences); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.current(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	changed := changedPreferences(previous.Preferences, preferences)
+	if len(changed) == 0 && !previous.IsDefault {
+		return previous, nil
+	}
+
+	saved := &domain.UserPreferences{
+		UserID:      userID,
+		TenantID:    tenantID,
+		Preferences: preferences,
+		UpdatedAt:   time.Now().UTC(),
+	}
+	var events []domain.Event
+	if len(changed) > 0 {
+		event, err := domain.NewEvent(domain.EventPreferencesChanged, tenantID, domain.PreferencesChanged{
+			UserID:   userID,
+			Changed:  changed,
+			Previous: previous.Preferences,
+			Current:  preferences,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := s.preferences.SavePreferences(ctx, saved, events...); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *PreferencesServiceImpl) current(ctx context.Context, tenantID, userID uuid.UUID) (*domain.UserPreferences, error) {
+	preferences, err := s.preferences.FindPreferences(ctx, tenantID, userID)
+	if errors.Is(err, domain.ErrPreferencesNotFound) {
+		return &domain.UserPreferences{UserID: userID, TenantID: tenantID, Preferences: domain.DefaultPreferences(), IsDefault: true}, nil
+	}
+	return preferences, err
+}
+
+func normalizePreferences(preferences *domain.Preferences) error {
+	if !containsLanguage(preferences.Language) {
+		return fmt.Errorf("%w: language must be ru, kk or en", domain.ErrInvalidInput)
+	}
+	if preferences.Units != domain.UnitsMetric && preferences.Units != domain.UnitsImperial {
+		return fmt.Errorf("%w: units must be metric or imperial", domain.ErrInvalidInput)
+	}
+
+	channels := []domain.ContactChannel{}
+	for _, channel := range preferences.Channels {
+		if !containsChannel(domain.ContactChannels, channel) {
+			return fmt.Errorf("%w: unknown contact channel %q", domain.ErrInvalidInput, channel)
+		}
+		if !containsChannel(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	preferences.Channels = channels
+
+	if quiet := preferences.QuietHours; quiet != nil {
+		for _, clock := range []string{quiet.Start, quiet.End} {
+			if _, err := time.Parse("15:04", clock); err != nil || len(clock) != 5 {
+				return fmt.Errorf("%w: quiet hours time %q must be HH:MM", domain.ErrInvalidInput, clock)
+			}
+		}
+		if quiet.Start == quiet.End {
+			return fmt.Errorf("%w: quiet hours must not start and end at the same time", domain.ErrInvalidInput)
+		}
+	}
+
+	if preferences.ReminderLeadMinutes < 0 || preferences.ReminderLeadMinutes > domain.MaxReminderLeadMinutes {
+		return fmt.Errorf("%w: reminder_lead_minutes must be between 0 and %d", domain.ErrInvalidInput, domain.MaxReminderLeadMinutes)
+	}
+	return nil
+}
+
+// changedPreferences перечисляет JSON-имена изменившихся полей
+func changedPreferences(previous, current domain.Preferences) []string {
+	var changed []string
+	if previous.Language != current.Language {
+		changed = append(changed, "language")
+	}
+	if previous.Units != current.Units {
+		changed = append(changed, "units")
+	}
+	if !sameChannels(previous.Channels, current.Channels) {
+		changed = append(changed, "channels")
+	}
+	if (previous.QuietHours == nil) != (current.QuietHours == nil) ||
+		previous.QuietHours != nil && *previous.QuietHours != *current.QuietHours {
+		changed = append(changed, "quiet_hours")
+	}
+	if previous.ReminderLeadMinutes != current.ReminderLeadMinutes {
+		changed = append(changed, "reminder_lead_minutes")
+	}
+	return changed
+}
+
+func sameChannels(a, b []domain.ContactChannel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsLanguage(language domain.Language) bool {
+	for _, known := range domain.Languages {
+		if language == known {
+			return true
+		}
+	}
+	return false
+}
+
+func containsChannel(channels []domain.ContactChannel, channel domain.ContactChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
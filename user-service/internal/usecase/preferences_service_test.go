@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"user-service/internal/domain"
+)
+
+// preferenceEvents читает из outbox события об изменении настроек в порядке записи
+func (f *fixture) preferenceEvents(t *testing.T) []domain.PreferencesChanged {
+	t.Helper()
+	records, err := f.Outbox.Pending(context.Background(), 100)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	var changes []domain.PreferencesChanged
+	for _, record := range records {
+		if record.Type != domain.EventPreferencesChanged {
+			continue
+		}
+		var event domain.Event
+		if err := json.Unmarshal([]byte(record.Payload), &event); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		var change domain.PreferencesChanged
+		if err := json.Unmarshal(event.Payload, &change); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func TestGetPreferencesDefaults(t *testing.T) {
+	f := newFixture(t)
+	preferences := NewPreferencesService(f.Preferences, f.Users)
+	resident := f.mustCreateUser(t, "resident@example.com")
+	neighbour := f.mustCreateUser(t, "neighbour@example.com")
+
+	got, err := preferences.GetPreferences(actorContext(resident), f.tenant.ID, resident.ID)
+	if err != nil {
+		t.Fatalf("GetPreferences: %v", err)
+	}
+	if !got.IsDefault || !reflect.DeepEqual(got.Preferences, domain.DefaultPreferences()) {
+		t.Fatalf("GetPreferences = %+v, want the defaults", got)
+	}
+
+	if _, err := preferences.GetPreferences(adminContext(), f.tenant.ID, resident.ID); err != nil {
+		t.Fatalf("GetPreferences(admin): %v", err)
+	}
+	if _, err := preferences.GetPreferences(actorContext(neighbour), f.tenant.ID, resident.ID); !errors.Is(err, domain.ErrNotProfileOwner) {
+		t.Fatalf("GetPreferences(neighbour) error = %v, want domain.ErrNotProfileOwner", err)
+	}
+}
+
+func TestUpdatePreferencesEvents(t *testing.T) {
+	f := newFixture(t)
+	preferences := NewPreferencesService(f.Preferences, f.Users)
+	resident := f.mustCreateUser(t, "resident@example.com")
+	ctx := actorContext(resident)
+
+	// Сохранение значений по умолчанию фиксирует документ, но ничего не меняет
+	saved, err := preferences.UpdatePreferences(ctx, f.tenant.ID, resident.ID, domain.DefaultPreferences())
+	if err != nil {
+		t.Fatalf("UpdatePreferences(defaults): %v", err)
+	}
+	if saved.IsDefault {
+		t.Fatalf("saved preferences are still marked as defaults")
+	}
+	if events := f.preferenceEvents(t); len(events) != 0 {
+		t.Fatalf("saving the defaults wrote %d events, want none", len(events))
+	}
+
+	update := domain.DefaultPreferences()
+	update.Language = domain.LanguageEnglish
+	update.Channels = []domain.ContactChannel{domain.ChannelEmail, domain.ChannelEmail, domain.ChannelPush}
+	saved, err = preferences.UpdatePreferences(ctx, f.tenant.ID, resident.ID, update)
+	if err != nil {
+		t.Fatalf("UpdatePreferences: %v", err)
+	}
+	if want := []domain.ContactChannel{domain.ChannelEmail, domain.ChannelPush}; !reflect.DeepEqual(saved.Preferences.Channels, want) {
+		t.Fatalf("channels = %v, want %v", saved.Preferences.Channels, want)
+	}
+
+	// Повтор того же документа событий не добавляет
+	if _, err := preferences.UpdatePreferences(ctx, f.tenant.ID, resident.ID, update); err != nil {
+		t.Fatalf("UpdatePreferences(repeat): %v", err)
+	}
+	events := f.preferenceEvents(t)
+	if len(events) != 1 {
+		t.Fatalf("wrote %d events, want 1", len(events))
+	}
+	event := events[0]
+	if event.UserID != resident.ID || !reflect.DeepEqual(event.Changed, []string{"language", "channels"}) {
+		t.Fatalf("event = %+v, want language and channels changed for the resident", event)
+	}
+	if event.Previous.Language != domain.LanguageRussian || event.Current.Language != domain.LanguageEnglish {
+		t.Fatalf("event languages = %s -> %s, want ru -> en", event.Previous.Language, event.Current.Language)
+	}
+}
+
+func TestUpdatePreferencesValidation(t *testing.T) {
+	f := newFixture(t)
+	preferences := NewPreferencesService(f.Preferences, f.Users)
+	resident := f.mustCreateUser(t, "resident@example.com")
+	neighbour := f.mustCreateUser(t, "neighbour@example.com")
+
+	tests := []struct {
+		name   string
+		modify func(p *domain.Preferences)
+	}{
+		{name: "unknown language", modify: func(p *domain.Preferences) { p.Language = "de" }},
+		{name: "unknown units", modify: func(p *domain.Preferences) { p.Units = "parsecs" }},
+		{name: "unknown channel", modify: func(p *domain.Preferences) { p.Channels = []domain.ContactChannel{"pigeon"} }},
+		{name: "quiet hours without leading zero", modify: func(p *domain.Preferences) { p.QuietHours = &domain.QuietHours{Start: "9:00", End: "18:00"} }},
+		{name: "empty quiet hours", modify: func(p *domain.Preferences) { p.QuietHours = &domain.QuietHours{Start: "22:00", End: "22:00"} }},
+		{name: "reminder too early", modify: func(p *domain.Preferences) { p.ReminderLeadMinutes = domain.MaxReminderLeadMinutes + 1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update := domain.DefaultPreferences()
+			tt.modify(&update)
+			if _, err := preferences.UpdatePreferences(actorContext(resident), f.tenant.ID, resident.ID, update); !errors.Is(err, domain.ErrInvalidInput) {
+				t.Fatalf("UpdatePreferences error = %v, want domain.ErrInvalidInput", err)
+			}
+		})
+	}
+
+	if _, err := preferences.UpdatePreferences(actorContext(neighbour), f.tenant.ID, resident.ID, domain.DefaultPreferences()); !errors.Is(err, domain.ErrNotProfileOwner) {
+		t.Fatalf("UpdatePreferences(neighbour) error = %v, want domain.ErrNotProfileOwner", err)
+	}
+	if events := f.preferenceEvents(t); len(events) != 0 {
+		t.Fatalf("rejected updates wrote %d events, want none", len(events))
+	}
+}
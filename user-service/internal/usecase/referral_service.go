@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	maxReferralDeviceID = 200
+	// Столько раз пробуется новый код, если случайный уже занят
+	referralCodeAttempts = 3
+	defaultTopReferrers  = 20
+)
+
+type ReferralServiceImpl struct {
+	referrals domain.ReferralRepository
+	users     domain.UserRepository
+	actions   domain.ActionService
+}
+
+func NewReferralService(referrals domain.ReferralRepository, users domain.UserRepository, actions domain.ActionService) domain.ReferralService {
+	return &ReferralServiceImpl{referrals: referrals, users: users, actions: actions}
+}
+
+func (s *ReferralServiceImpl) GetReferralSummary(ctx context.Context, tenantID, userID uuid.UUID) (*domain.ReferralSummary, error) {
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReferralActor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	code, err := s.referralCode(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.referrals.CountByReferrer(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &domain.ReferralSummary{UserID: userID, Code: code.Code, ReferralCounts: counts}
+	referral, err := s.referrals.FindReferralByReferred(ctx, tenantID, userID)
+	switch {
+	case err == nil:
+		summary.ReferredBy = &referral.ReferrerID
+	case !errors.Is(err, domain.ErrReferralNotFound):
+		return nil, err
+	}
+	return summary, nil
+}
+
+// AttributeReferral записывает приглашение при создании профиля из события регистрации.
+// Награда здесь не выдаётся: у нового жителя ещё нет ни адреса, ни действий, и проверять ограничения не на чем.
+func (s *ReferralServiceImpl) AttributeReferral(ctx context.Context, tenantID, userID uuid.UUID, claim domain.ReferralClaim) (*domain.Referral, error) {
+	code := strings.ToUpper(strings.TrimSpace(claim.Code))
+	deviceID := strings.TrimSpace(claim.DeviceID)
+	switch {
+	case code == "":
+		return nil, fmt.Errorf("%w: code is required", domain.ErrInvalidInput)
+	case len(deviceID) > maxReferralDeviceID:
+		return nil, fmt.Errorf("%w: device_id must be at most %d characters", domain.ErrInvalidInput, maxReferralDeviceID)
+	}
+
+	user, err := s.users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	referrerCode, err := s.referrals.FindReferralCodeByCode(ctx, tenantID, code)
+	if err != nil {
+		return nil, err
+	}
+	if referrerCode.UserID == user.ID {
+		return nil, fmt.Errorf("%w: own referral code cannot be applied", domain.ErrInvalidInput)
+	}
+	referrer, err := s.users.FindByID(ctx, tenantID, referrerCode.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrReferralCodeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	referral := &domain.Referral{
+		ID:         uuid.New(),
+		TenantID:   tenantID,
+		ReferrerID: referrer.ID,
+		ReferredID: user.ID,
+		Code:       code,
+		Status:     domain.ReferralPending,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if deviceID != "" {
+		sum := sha256.Sum256([]byte(deviceID))
+		referral.DeviceHash = hex.EncodeToString(sum[:])
+	}
+	if err := s.referrals.CreateReferral(ctx, referral); err != nil {
+		return nil, err
+	}
+	return referral, nil
+}
+
+// ReleaseReward выдаёт награду, только когда у приглашённого есть адрес и хотя бы одно собственное действие:
+// к этому моменту проверка общего адреса имеет смысл, а пустые учётные записи награды не приносят.
+// Устройство, общий адрес и месячный лимит проверяются на момент решения, а не регистрации.
+func (s *ReferralServiceImpl) ReleaseReward(ctx context.Context, tenantID, userID uuid.UUID) error {
+	referral, err := s.referrals.FindReferralByReferred(ctx, tenantID, userID)
+	if errors.Is(err, domain.ErrReferralNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if referral.Status != domain.ReferralPending {
+		return nil
+	}
+
+	userAddresses, err := s.users.ListAddresses(ctx, tenantID, userID)
+	if err != nil || len(userAddresses) == 0 {
+		return err
+	}
+	actions, err := s.users.GetUserActions(ctx, tenantID, userID, domain.ActionFilter{Types: residentActionTypes(), Limit: 1})
+	if err != nil || len(actions) == 0 {
+		return err
+	}
+
+	var referrerAddresses []domain.Address
+	referrerGone := false
+	if _, err := s.users.FindByID(ctx, tenantID, referral.ReferrerID); errors.Is(err, domain.ErrUserNotFound) {
+		referrerGone = true
+	} else if err != nil {
+		return err
+	} else if referrerAddresses, err = s.users.ListAddresses(ctx, tenantID, referral.ReferrerID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	referral.District = addressDistrict(userAddresses)
+	if referral.District == "" {
+		referral.District = addressDistrict(referrerAddresses)
+	}
+	reason := domain.ReferralBlockReferrerDeleted
+	if !referrerGone {
+		if reason, err = s.blockReason(ctx, referral, userAddresses, referrerAddresses, now); err != nil {
+			return err
+		}
+	}
+	referral.Status = domain.ReferralRewarded
+	if reason != "" {
+		referral.Status = domain.ReferralBlocked
+		referral.BlockReason = reason
+	}
+	referral.ResolvedAt = &now
+
+	// Адрес и действие могут прийти одновременно: награду выдаёт тот, кто первым перевёл приглашение из pending
+	err = s.referrals.ResolveReferral(ctx, referral)
+	if errors.Is(err, domain.ErrReferralNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if referral.Status == domain.ReferralRewarded {
+		s.reward(ctx, referral)
+	}
+	return nil
+}
+
+// ActionRecorded пробует выдать награду за приглашение автора действия; награды самой системы активностью не считаются
+func (s *ReferralServiceImpl) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	if action.Action == domain.ActionReferralRewarded {
+		return
+	}
+	if err := s.ReleaseReward(ctx, action.TenantID, action.UserID); err != nil {
+		log.Printf("Failed to release referral reward for user %s (request %s): %v", action.UserID, requestctx.RequestID(ctx), err)
+	}
+}
+
+func (s *ReferralServiceImpl) ReferralStats(ctx context.Context, tenantID uuid.UUID, limit int) (*domain.ReferralStats, error) {
+	if limit <= 0 || limit > domain.MaxPageLimit {
+		limit = defaultTopReferrers
+	}
+
+	referrers, err := s.referrals.TopReferrers(ctx, tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range referrers {
+		user, err := s.users.FindByID(ctx, tenantID, referrers[i].UserID)
+		switch {
+		case err == nil:
+			referrers[i].Email = user.Email
+		case !errors.Is(err, domain.ErrUserNotFound):
+			return nil, err
+		}
+	}
+	districts, err := s.referrals.CountByDistrict(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.ReferralStats{Referrers: referrers, Districts: districts}
+	if stats.Referrers == nil {
+		stats.Referrers = []domain.ReferrerStats{}
+	}
+	if stats.Districts == nil {
+		stats.Districts = []domain.DistrictReferralStats{}
+	}
+	return stats, nil
+}
+
+func (s *ReferralServiceImpl) blockReason(ctx context.Context, referral *domain.Referral, userAddresses, referrerAddresses []domain.Address, now time.Time) (string, error) {
+	if referral.DeviceHash != "" {
+		sameDevice, err := s.referrals.CountReferrals(ctx, referral.TenantID, domain.ReferralFilter{DeviceHash: referral.DeviceHash})
+		if err != nil {
+			return "", err
+		}
+		// Само приглашение тоже записано с этим устройством
+		if sameDevice > 1 {
+			return domain.ReferralBlockSameDevice, nil
+		}
+	}
+
+	for _, own := range userAddresses {
+		for _, theirs := range referrerAddresses {
+			if sameAddress(own, theirs) {
+				return domain.ReferralBlockSameAddress, nil
+			}
+		}
+	}
+
+	monthAgo := now.AddDate(0, -1, 0)
+	rewarded, err := s.referrals.CountReferrals(ctx, referral.TenantID, domain.ReferralFilter{
+		ReferrerID:    &referral.ReferrerID,
+		Status:        domain.ReferralRewarded,
+		ResolvedSince: &monthAgo,
+	})
+	if err != nil {
+		return "", err
+	}
+	if rewarded >= domain.MaxReferralRewardsPerMonth {
+		return domain.ReferralBlockMonthlyLimit, nil
+	}
+	return "", nil
+}
+
+// reward начисляет пригласившему действие-награду. ResolveReferral переводит приглашение из pending
+// только один раз, поэтому награда не выдаётся дважды; приглашение уже сохранено, так что ошибка только логируется.
+func (s *ReferralServiceImpl) reward(ctx context.Context, referral *domain.Referral) {
+	err := s.actions.RecordAction(ctx, referral.TenantID, referral.ReferrerID, domain.ActionReferralRewarded, "referred "+referral.ReferredID.String())
+	if err != nil {
+		log.Printf("Failed to reward referral %s (request %s): %v", referral.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+// residentActionTypes — действия, которые считаются активностью жителя
+func residentActionTypes() []domain.ActionType {
+	types := make([]domain.ActionType, 0, len(domain.ActionTypes))
+	for _, actionType := range domain.ActionTypes {
+		if actionType != domain.ActionReferralRewarded {
+			types = append(types, actionType)
+		}
+	}
+	return types
+}
+
+// referralCode возвращает код пользователя, выпуская его при первом обращении
+func (s *ReferralServiceImpl) referralCode(ctx context.Context, tenantID, userID uuid.UUID) (*domain.ReferralCode, error) {
+	for attempt := 0; attempt < referralCodeAttempts; attempt++ {
+		existing, err := s.referrals.FindReferralCode(ctx, tenantID, userID)
+		if err == nil {
+			return existing, nil
+		}
+		if !errors.Is(err, domain.ErrReferralCodeNotFound) {
+			return nil, err
+		}
+
+		value, err := invitationCode()
+		if err != nil {
+			return nil, err
+		}
+		code := &domain.ReferralCode{UserID: userID, TenantID: tenantID, Code: value, CreatedAt: time.Now().UTC()}
+		err = s.referrals.CreateReferralCode(ctx, code)
+		if err == nil {
+			return code, nil
+		}
+		// Код занят другим пользователем или параллельный запрос уже выпустил код этому
+		if !errors.Is(err, domain.ErrReferralCodeTaken) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to issue a referral code after %d attempts", referralCodeAttempts)
+}
+
+func authorizeReferralActor(ctx context.Context, user *domain.User) error {
+	if !actorOwnsProfile(ctx, user) {
+		return domain.ErrNotReferralOwner
+	}
+	return nil
+}
+
+// addressDistrict — почтовый индекс основного адреса, а без него город
+func addressDistrict(addresses []domain.Address) string {
+	if len(addresses) == 0 {
+		return ""
+	}
+	address := addresses[0]
+	for _, candidate := range addresses {
+		if candidate.IsPrimary {
+			address = candidate
+			break
+		}
+	}
+	if address.PostalCode != "" {
+		return address.PostalCode
+	}
+	return address.City
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+// referralHarness собирает сервисы так же, как NewUserServer: приглашения следят за действиями и первым адресом
+type referralHarness struct {
+	*fixture
+	referrals domain.ReferralService
+	actions   domain.ActionService
+	addresses domain.AddressService
+	accounts  domain.AccountService
+}
+
+// lateObserver подключает сервис приглашений к actionService, который нужен ему самому для наград
+type lateObserver struct {
+	observer domain.ActionObserver
+}
+
+func (o *lateObserver) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	o.observer.ActionRecorded(ctx, action)
+}
+
+func newReferralHarness(t *testing.T) *referralHarness {
+	t.Helper()
+	f := newFixture(t)
+	late := &lateObserver{}
+	actions := NewActionService(f.Users, f.Tenants, late)
+	referrals := NewReferralService(f.Referrals, f.Users, actions)
+	late.observer = referrals
+	return &referralHarness{
+		fixture:   f,
+		referrals: referrals,
+		actions:   actions,
+		addresses: NewAddressService(f.Users, noGeocoder{}, referrals),
+		accounts:  NewAccountService(f.Users, f.Tenants, referrals),
+	}
+}
+
+func (h *referralHarness) code(t *testing.T, referrer *domain.User) string {
+	t.Helper()
+	summary, err := h.referrals.GetReferralSummary(actorContext(referrer), h.tenant.ID, referrer.ID)
+	if err != nil {
+		t.Fatalf("GetReferralSummary: %v", err)
+	}
+	return summary.Code
+}
+
+// register создаёт профиль из события регистрации с кодом пригласившего
+func (h *referralHarness) register(t *testing.T, email, code, device string) *domain.User {
+	t.Helper()
+	event := domain.AccountEvent{
+		ID:          uuid.New(),
+		Type:        domain.EventAccountRegistered,
+		IdentityKey: uuid.New(),
+		Email:       email,
+		Role:        domain.RoleUser,
+		Version:     1,
+		Referral:    &domain.ReferralClaim{Code: code, DeviceID: device},
+	}
+	user, err := h.accounts.ApplyAccountEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("ApplyAccountEvent: %v", err)
+	}
+	return user
+}
+
+func (h *referralHarness) addAddress(t *testing.T, user *domain.User, street, apartment string) {
+	t.Helper()
+	address := &domain.Address{City: "Almaty", Street: street, House: "10", Apartment: apartment}
+	if err := h.addresses.AddAddress(actorContext(user), h.tenant.ID, user.ID, address); err != nil {
+		t.Fatalf("AddAddress: %v", err)
+	}
+}
+
+func (h *referralHarness) act(t *testing.T, user *domain.User) {
+	t.Helper()
+	if err := h.actions.RecordAction(context.Background(), h.tenant.ID, user.ID, domain.ActionWasteSorted, ""); err != nil {
+		t.Fatalf("RecordAction: %v", err)
+	}
+}
+
+func (h *referralHarness) referral(t *testing.T, user *domain.User) *domain.Referral {
+	t.Helper()
+	referral, err := h.Referrals.FindReferralByReferred(context.Background(), h.tenant.ID, user.ID)
+	if err != nil {
+		t.Fatalf("FindReferralByReferred: %v", err)
+	}
+	return referral
+}
+
+func (h *referralHarness) rewards(t *testing.T, referrer *domain.User) int {
+	t.Helper()
+	actions, err := h.Users.GetUserActions(context.Background(), h.tenant.ID, referrer.ID,
+		domain.ActionFilter{Types: []domain.ActionType{domain.ActionReferralRewarded}, Limit: domain.MaxPageLimit})
+	if err != nil {
+		t.Fatalf("GetUserActions: %v", err)
+	}
+	return len(actions)
+}
+
+// Награда ждёт, пока у приглашённого появятся и адрес, и собственное действие, в любом порядке
+func TestReferralRewardWaitsForActivity(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+	}{
+		{name: "address then action", steps: []string{"address", "action"}},
+		{name: "action then address", steps: []string{"action", "address"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newReferralHarness(t)
+			referrer := h.mustCreateUser(t, "referrer@example.com")
+			user := h.register(t, "resident@example.com", h.code(t, referrer), "phone-1")
+
+			if referral := h.referral(t, user); referral.Status != domain.ReferralPending {
+				t.Fatalf("status after registration = %s, want pending", referral.Status)
+			}
+			for i, step := range tt.steps {
+				if step == "address" {
+					h.addAddress(t, user, "Abay", "1")
+				} else {
+					h.act(t, user)
+				}
+				want := domain.ReferralPending
+				if i == len(tt.steps)-1 {
+					want = domain.ReferralRewarded
+				}
+				if referral := h.referral(t, user); referral.Status != want {
+					t.Fatalf("status after %s = %s, want %s", step, referral.Status, want)
+				}
+			}
+
+			h.act(t, user)
+			h.addAddress(t, user, "Dostyk", "2")
+			if got := h.rewards(t, referrer); got != 1 {
+				t.Fatalf("referrer got %d rewards, want 1", got)
+			}
+			referral := h.referral(t, user)
+			if referral.ResolvedAt == nil || referral.District != "Almaty" {
+				t.Fatalf("referral = %+v, want resolved with the resident's district", referral)
+			}
+		})
+	}
+}
+
+// Ограничения проверяются, когда награда выдаётся, а не когда житель зарегистрировался
+func TestReferralRewardLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare выполняется до регистрации приглашённого
+		prepare func(t *testing.T, h *referralHarness, referrer *domain.User)
+		// deleteReferrer удаляет пригласившего между регистрацией и активностью приглашённого
+		deleteReferrer bool
+		street         string
+		wantStatus     domain.ReferralStatus
+		wantReason     string
+	}{
+		{
+			name:       "no limits hit",
+			wantStatus: domain.ReferralRewarded,
+		},
+		{
+			name: "device already used in another referral",
+			prepare: func(t *testing.T, h *referralHarness, referrer *domain.User) {
+				other := h.mustCreateUser(t, "other@example.com")
+				h.register(t, "first@example.com", h.code(t, other), "phone-1")
+			},
+			wantStatus: domain.ReferralBlocked,
+			wantReason: domain.ReferralBlockSameDevice,
+		},
+		{
+			name: "shared address with the referrer",
+			prepare: func(t *testing.T, h *referralHarness, referrer *domain.User) {
+				h.addAddress(t, referrer, "Abay", "1")
+			},
+			street:     "Abay",
+			wantStatus: domain.ReferralBlocked,
+			wantReason: domain.ReferralBlockSameAddress,
+		},
+		{
+			name: "monthly limit reached",
+			prepare: func(t *testing.T, h *referralHarness, referrer *domain.User) {
+				seedRewardedReferrals(t, h, referrer, domain.MaxReferralRewardsPerMonth, time.Now().UTC().Add(-time.Hour))
+			},
+			wantStatus: domain.ReferralBlocked,
+			wantReason: domain.ReferralBlockMonthlyLimit,
+		},
+		{
+			name: "rewards older than a month do not count",
+			prepare: func(t *testing.T, h *referralHarness, referrer *domain.User) {
+				seedRewardedReferrals(t, h, referrer, domain.MaxReferralRewardsPerMonth, time.Now().UTC().AddDate(0, -2, 0))
+			},
+			wantStatus: domain.ReferralRewarded,
+		},
+		{
+			name:           "referrer deleted before the reward",
+			deleteReferrer: true,
+			wantStatus:     domain.ReferralBlocked,
+			wantReason:     domain.ReferralBlockReferrerDeleted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newReferralHarness(t)
+			referrer := h.mustCreateUser(t, "referrer@example.com")
+			if tt.prepare != nil {
+				tt.prepare(t, h, referrer)
+			}
+			rewardsBefore := h.rewards(t, referrer)
+
+			user := h.register(t, "resident@example.com", h.code(t, referrer), "phone-1")
+			if tt.deleteReferrer {
+				if err := h.Users.Delete(context.Background(), h.tenant.ID, referrer.ID); err != nil {
+					t.Fatalf("Delete: %v", err)
+				}
+			}
+			street := tt.street
+			if street == "" {
+				street = "Dostyk"
+			}
+			h.addAddress(t, user, street, "1")
+			h.act(t, user)
+
+			referral := h.referral(t, user)
+			if referral.Status != tt.wantStatus || referral.BlockReason != tt.wantReason {
+				t.Fatalf("referral = %s/%q, want %s/%q", referral.Status, referral.BlockReason, tt.wantStatus, tt.wantReason)
+			}
+			wantRewards := rewardsBefore
+			if tt.wantStatus == domain.ReferralRewarded {
+				wantRewards++
+			}
+			if got := h.rewards(t, referrer); !tt.deleteReferrer && got != wantRewards {
+				t.Fatalf("referrer has %d rewards, want %d", got, wantRewards)
+			}
+		})
+	}
+}
+
+func TestReferralAttributionFromAccountEvent(t *testing.T) {
+	t.Run("unknown code still creates the profile", func(t *testing.T) {
+		h := newReferralHarness(t)
+		user := h.register(t, "resident@example.com", "NOPE1234", "")
+		if _, err := h.Referrals.FindReferralByReferred(context.Background(), h.tenant.ID, user.ID); !errors.Is(err, domain.ErrReferralNotFound) {
+			t.Fatalf("FindReferralByReferred error = %v, want domain.ErrReferralNotFound", err)
+		}
+	})
+
+	t.Run("registration delivered after a newer event", func(t *testing.T) {
+		h := newReferralHarness(t)
+		referrer := h.mustCreateUser(t, "referrer@example.com")
+		key := uuid.New()
+		changed := domain.AccountEvent{ID: uuid.New(), Type: domain.EventAccountEmailChanged, IdentityKey: key, Email: "new@example.com", Role: domain.RoleUser, Version: 2}
+		registered := domain.AccountEvent{ID: uuid.New(), Type: domain.EventAccountRegistered, IdentityKey: key, Email: "old@example.com", Role: domain.RoleUser, Version: 1,
+			Referral: &domain.ReferralClaim{Code: h.code(t, referrer)}}
+		for _, event := range []domain.AccountEvent{changed, registered} {
+			if _, err := h.accounts.ApplyAccountEvent(context.Background(), event); err != nil {
+				t.Fatalf("ApplyAccountEvent(%s): %v", event.Type, err)
+			}
+		}
+
+		user, err := h.Users.FindByIdentityKey(context.Background(), h.tenant.ID, key)
+		if err != nil {
+			t.Fatalf("FindByIdentityKey: %v", err)
+		}
+		if referral := h.referral(t, user); referral.ReferrerID != referrer.ID || referral.Status != domain.ReferralPending {
+			t.Fatalf("referral = %+v, want pending from the referrer", referral)
+		}
+	})
+}
+
+func seedRewardedReferrals(t *testing.T, h *referralHarness, referrer *domain.User, n int, resolvedAt time.Time) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		referral := &domain.Referral{ID: uuid.New(), TenantID: h.tenant.ID, ReferrerID: referrer.ID, ReferredID: uuid.New(),
+			Code: "SEED", Status: domain.ReferralRewarded, CreatedAt: resolvedAt, ResolvedAt: &resolvedAt}
+		if err := h.Referrals.CreateReferral(context.Background(), referral); err != nil {
+			t.Fatalf("CreateReferral: %v", err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+)
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+type TenantServiceImpl struct {
+	repo domain.TenantRepository
+}
+
+func NewTenantService(repo domain.TenantRepository) domain.TenantService {
+	return &TenantServiceImpl{repo: repo}
+}
+
+func (s *TenantServiceImpl) CreateTenant(ctx context.Context, tenant *domain.Tenant) error {
+	if !tenantSlugPattern.MatchString(tenant.Slug) {
+		return errors.New("invalid tenant slug")
+	}
+	if tenant.Name == "" {
+		tenant.Name = tenant.Slug
+	}
+
+	tenant.ID = uuid.New()
+	tenant.CreatedAt = time.Now()
+
+	return s.repo.CreateTenant(ctx, tenant)
+}
+
+func (s *TenantServiceImpl) ResolveTenant(ctx context.Context, slug string) (*domain.Tenant, error) {
+	if slug == "" {
+		slug = domain.DefaultTenantSlug
+	}
+	return s.repo.FindTenantBySlug(ctx, slug)
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/infrastructure/repository"
+	"user-service/internal/requestctx"
+)
+
+// fixture — репозитории в памяти и тенант по умолчанию для проверок сервисов
+type fixture struct {
+	repository.Repositories
+	tenant *domain.Tenant
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	repos := repository.NewMemoryRepositories()
+	tenant, err := repos.Tenants.FindTenantBySlug(context.Background(), domain.DefaultTenantSlug)
+	if err != nil {
+		t.Fatalf("FindTenantBySlug: %v", err)
+	}
+	return &fixture{Repositories: repos, tenant: tenant}
+}
+
+func (f *fixture) mustCreateUser(t *testing.T, email string) *domain.User {
+	t.Helper()
+	now := time.Now()
+	identityKey := uuid.New()
+	user := &domain.User{
+		ID:          uuid.New(),
+		TenantID:    f.tenant.ID,
+		IdentityKey: &identityKey,
+		Email:       email,
+		Name:        "Resident",
+		Role:        domain.RoleUser,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Version:     1,
+	}
+	if err := f.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return user
+}
+
+// actorContext — запрос от имени жителя, как его передал бы шлюз
+func actorContext(user *domain.User) context.Context {
+	actor := &requestctx.Actor{AuthUserID: "1", Email: user.Email, Role: user.Role}
+	if user.IdentityKey != nil {
+		actor.IdentityKey = *user.IdentityKey
+	}
+	return requestctx.WithActor(context.Background(), actor)
+}
+
+func (f *fixture) action(user *domain.User, actionType domain.ActionType, at time.Time) domain.UserAction {
+	return domain.UserAction{
+		ID:        uuid.New(),
+		TenantID:  f.tenant.ID,
+		UserID:    user.ID,
+		Action:    actionType,
+		Source:    "test",
+		CreatedAt: at,
+	}
+}
@@ -1,43 +1,192 @@
 package usecase
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/mail"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
 	"user-service/internal/domain"
+	"user-service/internal/requestctx"
 )
 
+const maxUserName = 200
+
 type UserServiceImpl struct {
-	repo domain.UserRepository
+	repo    domain.UserRepository
+	actions domain.ActionService
 }
 
-func NewUserService(repo domain.UserRepository) domain.UserService {
-	return &UserServiceImpl{repo: repo}
+func NewUserService(repo domain.UserRepository, actions domain.ActionService) domain.UserService {
+	return &UserServiceImpl{repo: repo, actions: actions}
 }
 
-func (s *UserServiceImpl) CreateUser(user *domain.User) error {
-	if user.Email == "" {
-		return errors.New("email is required")
+func (s *UserServiceImpl) CreateUser(ctx context.Context, tenantID uuid.UUID, user *domain.User) error {
+	user.Email = strings.TrimSpace(user.Email)
+	if parsed, err := mail.ParseAddress(user.Email); err != nil || parsed.Address != user.Email {
+		return fmt.Errorf("%w: email is not a valid address", domain.ErrInvalidInput)
+	}
+	switch user.Role {
+	case "":
+		user.Role = domain.RoleUser
+	case domain.RoleUser, domain.RoleAdmin, domain.RoleCollector:
+	default:
+		return fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, user.Role)
 	}
 
 	user.ID = uuid.New()
+	user.TenantID = tenantID
+	user.Version = 1
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
-	return s.repo.Create(user)
+	return s.repo.Create(ctx, user)
 }
 
-func (s *UserServiceImpl) GetUserProfile(userID uuid.UUID) (*domain.User, error) {
-	return s.repo.FindByEmail(userID.String())
+func (s *UserServiceImpl) GetUserProfile(ctx context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.repo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantID {
+		return nil, domain.ErrCrossTenantAccess
+	}
+	return user, nil
 }
 
-func (s *UserServiceImpl) UpdateUserProfile(user *domain.User) error {
+// UpdateUserProfile применяет patch к редактируемым полям; expectedVersion = 0 отключает проверку версии
+func (s *UserServiceImpl) UpdateUserProfile(ctx context.Context, tenantID, userID uuid.UUID, patch domain.UserPatch, expectedVersion int64) (*domain.User, error) {
+	user, err := s.repo.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	if expectedVersion != 0 && user.Version != expectedVersion {
+		return nil, domain.ErrVersionConflict
+	}
+
+	var changed []string
+	for field, value := range patch {
+		var v string
+		if value != nil {
+			v = *value
+		}
+
+		var target *string
+		switch field {
+		case "name":
+			target = &user.Name
+			if len([]rune(v)) > maxUserName {
+				return nil, fmt.Errorf("%w: name must be at most %d characters", domain.ErrInvalidInput, maxUserName)
+			}
+		default:
+			return nil, fmt.Errorf("%w: field %q is not editable", domain.ErrInvalidInput, field)
+		}
+		if *target != v {
+			*target = v
+			changed = append(changed, field)
+		}
+	}
+
 	user.UpdatedAt = time.Now()
-	return s.repo.Update(user)
+	if err := s.repo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if len(changed) > 0 {
+		s.recordProfileUpdate(ctx, user, changed)
+	}
+	return user, nil
 }
 
-func (s *UserServiceImpl) GetUserActionHistory(userID uuid.UUID) ([]domain.UserAction, error) {
-	return s.repo.GetUserActions(userID)
+// Ошибка записи истории не отменяет уже сохранённое изменение профиля
+func (s *UserServiceImpl) recordProfileUpdate(ctx context.Context, user *domain.User, fields []string) {
+	sort.Strings(fields)
+	details, _ := json.Marshal(map[string][]string{"fields": fields})
+
+	if err := s.actions.RecordAction(ctx, user.TenantID, user.ID, domain.ActionProfileUpdated, string(details)); err != nil {
+		log.Printf("Failed to record profile update for user %s (request %s): %v", user.ID, requestctx.RequestID(ctx), err)
+	}
+}
+
+func (s *UserServiceImpl) ListUsers(ctx context.Context, tenantID uuid.UUID, filter domain.UserFilter) (*domain.UserPage, error) {
+	filter.Limit, filter.Offset = normalizePage(filter.Limit, filter.Offset)
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", domain.ErrInvalidInput)
+	}
+
+	users, total, err := s.repo.List(ctx, tenantID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UserPage{Users: users, Total: total, Limit: filter.Limit, Offset: filter.Offset}, nil
+}
+
+func (s *UserServiceImpl) SearchUsers(ctx context.Context, tenantID uuid.UUID, query string, limit, offset int) (*domain.UserPage, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("%w: search query is required", domain.ErrInvalidInput)
+	}
+	limit, offset = normalizePage(limit, offset)
+
+	users, total, err := s.repo.Search(ctx, tenantID, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.UserPage{Users: users, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, tenantID, userID uuid.UUID) error {
+	return s.repo.Delete(ctx, tenantID, userID)
+}
+
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = domain.DefaultPageLimit
+	}
+	if limit > domain.MaxPageLimit {
+		limit = domain.MaxPageLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// GetOwnProfile возвращает профиль только самому пользователю или админу; так проверяется доступ
+// к данным, которые читаются не через сервисы (лента действий)
+func (s *UserServiceImpl) GetOwnProfile(ctx context.Context, tenantID, userID uuid.UUID) (*domain.User, error) {
+	return findOwnProfile(ctx, s.repo, tenantID, userID)
+}
+
+// findOwnProfile находит профиль и проверяет, что запрос пришёл от его владельца или админа
+func findOwnProfile(ctx context.Context, users domain.UserRepository, tenantID, userID uuid.UUID) (*domain.User, error) {
+	user, err := users.FindByID(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !actorOwnsProfile(ctx, user) {
+		return nil, domain.ErrNotProfileOwner
+	}
+	return user, nil
+}
+
+// actorOwnsProfile: запрос пришёл от самого пользователя или от администратора.
+// Сравнивается ключ учётной записи, а не email: auth-service не подтверждает адреса.
+func actorOwnsProfile(ctx context.Context, user *domain.User) bool {
+	actor := requestctx.ActorFrom(ctx)
+	if actor == nil {
+		return false
+	}
+	if actor.Role == domain.RoleAdmin {
+		return true
+	}
+	return actor.IdentityKey != uuid.Nil && user.IdentityKey != nil && *user.IdentityKey == actor.IdentityKey
 }
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+// Владельца профиля определяет ключ учётной записи: email в auth-service не подтверждается
+func TestActorOwnsProfile(t *testing.T) {
+	f := newFixture(t)
+	owner := f.mustCreateUser(t, "resident@example.com")
+	unlinked := f.mustCreateUser(t, "unlinked@example.com")
+	unlinked.IdentityKey = nil
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		profile *domain.User
+		want    bool
+	}{
+		{name: "owner", ctx: actorContext(owner), profile: owner, want: true},
+		{name: "same email, another account", ctx: requestctx.WithActor(context.Background(), &requestctx.Actor{AuthUserID: "2", Email: owner.Email, Role: domain.RoleUser, IdentityKey: uuid.New()}), profile: owner},
+		{name: "same email, no identity key", ctx: requestctx.WithActor(context.Background(), &requestctx.Actor{AuthUserID: "2", Email: owner.Email, Role: domain.RoleUser}), profile: owner},
+		{name: "profile without identity key", ctx: requestctx.WithActor(context.Background(), &requestctx.Actor{AuthUserID: "2", Email: unlinked.Email, Role: domain.RoleUser}), profile: unlinked},
+		{name: "admin", ctx: adminContext(), profile: owner, want: true},
+		{name: "anonymous", ctx: context.Background(), profile: owner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := actorOwnsProfile(tt.ctx, tt.profile); got != tt.want {
+				t.Fatalf("actorOwnsProfile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
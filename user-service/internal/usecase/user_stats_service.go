@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"user-service/internal/domain"
+	"user-service/internal/requestctx"
+)
+
+const (
+	// Кэш тенанта пересобирается из базы не реже этого: так исправляются пропущенные
+	// или посчитанные дважды действия и подхватываются новые основные адреса
+	statsRebuildInterval = time.Hour
+	defaultStatsPeriods  = 12
+	maxStatsPeriods      = 366
+	defaultStatsCohorts  = 6
+	maxStatsCohorts      = 24
+	defaultTopDistricts  = 10
+	// Столько разных отчётов тенанта хранится одновременно; при переполнении кэш отчётов сбрасывается
+	maxCachedReports = 32
+)
+
+// userActivity — активность одного жителя по дням
+type userActivity struct {
+	totals map[domain.ActionType]int64
+	// Ключ — начало дня (Unix) в часовом поясе сервиса
+	days map[int64]int64
+}
+
+type tenantStatsCache struct {
+	mu      sync.Mutex
+	builtAt time.Time
+	// Пересборка читает из базы действия, созданные до cutoff, а ActionRecorded добавляет только
+	// более поздние: так действие не теряется и не считается дважды, в каком бы порядке ни пришли
+	// событие и пересборка
+	cutoff    time.Time
+	totals    map[domain.ActionType]int64
+	users     map[uuid.UUID]*userActivity
+	districts map[uuid.UUID]string
+	// version растёт с каждым учтённым действием; отчёт тенанта пересчитывается, только если она изменилась
+	version int64
+	reports map[reportKey]tenantReport
+}
+
+// reportKey — нормализованный TenantStatsQuery без указателей, пригодный для ключа map
+type reportKey struct {
+	period       domain.AggregationPeriod
+	from, to     int64
+	cohorts, top int
+}
+
+type tenantReport struct {
+	version int64
+	stats   *domain.TenantStats
+}
+
+type UserStatsServiceImpl struct {
+	users    domain.UserRepository
+	location *time.Location
+
+	mu      sync.Mutex
+	tenants map[uuid.UUID]*tenantStatsCache
+}
+
+func NewUserStatsService(users domain.UserRepository, location *time.Location) domain.UserStatsService {
+	if location == nil {
+		location = time.UTC
+	}
+	return &UserStatsServiceImpl{users: users, location: location, tenants: map[uuid.UUID]*tenantStatsCache{}}
+}
+
+func (s *UserStatsServiceImpl) GetUserStats(ctx context.Context, tenantID, userID uuid.UUID) (*domain.UserStats, error) {
+	if _, err := findOwnProfile(ctx, s.users, tenantID, userID); err != nil {
+		return nil, err
+	}
+	cache, err := s.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer cache.mu.Unlock()
+
+	now := time.Now()
+	stats := &domain.UserStats{
+		UserID:     userID,
+		Totals:     map[domain.ActionType]int64{},
+		District:   cache.districts[userID],
+		ComputedAt: now.UTC(),
+	}
+	activity := cache.users[userID]
+	if activity == nil {
+		return stats, nil
+	}
+
+	monthAgo := domain.PeriodDay.Start(now, s.location).AddDate(0, 0, -29).Unix()
+	for action, count := range activity.totals {
+		stats.Totals[action] = count
+		stats.Total += count
+	}
+	for day := range activity.days {
+		stats.ActiveDays++
+		if day >= monthAgo {
+			stats.ActiveDays30++
+		}
+		start := time.Unix(day, 0).In(s.location)
+		if stats.FirstActive == nil || start.Before(*stats.FirstActive) {
+			stats.FirstActive = &start
+		}
+		if stats.LastActive == nil || start.After(*stats.LastActive) {
+			stats.LastActive = &start
+		}
+	}
+	return stats, nil
+}
+
+func (s *UserStatsServiceImpl) GetTenantStats(ctx context.Context, tenantID uuid.UUID, query domain.TenantStatsQuery) (*domain.TenantStats, error) {
+	if err := s.normalizeQuery(&query); err != nil {
+		return nil, err
+	}
+	cache, err := s.tenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer cache.mu.Unlock()
+
+	key := reportKey{period: query.Period, from: query.From.Unix(), to: query.To.Unix(), cohorts: query.Cohorts, top: query.TopDistricts}
+	if report, ok := cache.reports[key]; ok && report.version == cache.version {
+		return report.stats, nil
+	}
+	if len(cache.reports) >= maxCachedReports {
+		cache.reports = map[reportKey]tenantReport{}
+	}
+	stats := s.report(cache, query)
+	cache.reports[key] = tenantReport{version: cache.version, stats: stats}
+	return stats, nil
+}
+
+// ActionRecorded дополняет кэш тенанта, если он уже построен; иначе действие попадёт в кэш при построении.
+// Действия старше последней пересборки уже посчитаны ею и пропускаются.
+func (s *UserStatsServiceImpl) ActionRecorded(ctx context.Context, action domain.UserAction) {
+	s.mu.Lock()
+	cache := s.tenants[action.TenantID]
+	s.mu.Unlock()
+	if cache == nil {
+		return
+	}
+
+	cache.mu.Lock()
+	built := cache.users != nil
+	_, known := cache.users[action.UserID]
+	cache.mu.Unlock()
+	if !built {
+		return
+	}
+
+	// Адрес нового жителя запрашивается вне блокировки, чтобы не задерживать чтение отчётов
+	var district string
+	if !known {
+		addresses, err := s.users.ListAddresses(ctx, action.TenantID, action.UserID)
+		if err != nil {
+			log.Printf("Failed to load district of user %s for stats (request %s): %v", action.UserID, requestctx.RequestID(ctx), err)
+		}
+		district = addressDistrict(addresses)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.users == nil || action.CreatedAt.Before(cache.cutoff) {
+		return
+	}
+	if !known && district != "" {
+		cache.districts[action.UserID] = district
+	}
+	cache.add(action.UserID, action.Action, domain.PeriodDay.Start(action.CreatedAt, s.location), 1)
+}
+
+// tenant возвращает кэш тенанта под его блокировкой, при необходимости строя его заново
+func (s *UserStatsServiceImpl) tenant(ctx context.Context, tenantID uuid.UUID) (*tenantStatsCache, error) {
+	s.mu.Lock()
+	cache := s.tenants[tenantID]
+	if cache == nil {
+		cache = &tenantStatsCache{}
+		s.tenants[tenantID] = cache
+	}
+	s.mu.Unlock()
+
+	cache.mu.Lock()
+	if !cache.builtAt.IsZero() && time.Since(cache.builtAt) < statsRebuildInterval {
+		return cache, nil
+	}
+	if err := s.build(ctx, tenantID, cache); err != nil {
+		cache.mu.Unlock()
+		return nil, err
+	}
+	return cache, nil
+}
+
+// build вызывается под cache.mu и заменяет содержимое кэша данными из базы
+func (s *UserStatsServiceImpl) build(ctx context.Context, tenantID uuid.UUID, cache *tenantStatsCache) error {
+	cutoff := time.Now()
+	counts, err := s.users.CountTenantActions(ctx, tenantID, domain.PeriodDay, s.location, cutoff)
+	if err != nil {
+		return err
+	}
+	addresses, err := s.users.ListPrimaryAddresses(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	cache.totals = map[domain.ActionType]int64{}
+	cache.users = map[uuid.UUID]*userActivity{}
+	cache.districts = map[uuid.UUID]string{}
+	cache.reports = map[reportKey]tenantReport{}
+	for _, count := range counts {
+		cache.add(count.UserID, count.Action, count.PeriodStart, count.Count)
+	}
+	for _, address := range addresses {
+		if district := addressDistrict([]domain.Address{address}); district != "" {
+			cache.districts[address.UserID] = district
+		}
+	}
+	cache.builtAt = time.Now()
+	cache.cutoff = cutoff
+	cache.version++
+	return nil
+}
+
+func (c *tenantStatsCache) add(userID uuid.UUID, action domain.ActionType, day time.Time, count int64) {
+	activity := c.users[userID]
+	if activity == nil {
+		activity = &userActivity{totals: map[domain.ActionType]int64{}, days: map[int64]int64{}}
+		c.users[userID] = activity
+	}
+	activity.totals[action] += count
+	activity.days[day.Unix()] += count
+	c.totals[action] += count
+	c.version++
+}
+
+// normalizeQuery по умолчанию показывает последние 12 недель и полгода когорт
+func (s *UserStatsServiceImpl) normalizeQuery(query *domain.TenantStatsQuery) error {
+	if query.Period == "" {
+		query.Period = domain.PeriodWeek
+	}
+	if !query.Period.Valid() {
+		return fmt.Errorf("%w: period must be day, week or month", domain.ErrInvalidInput)
+	}
+
+	to := query.Period.Next(query.Period.Start(time.Now(), s.location))
+	if query.To != nil {
+		to = query.Period.Start(*query.To, s.location)
+	}
+	from := to
+	for i := 0; i < defaultStatsPeriods; i++ {
+		from = from.AddDate(0, 0, -1)
+		from = query.Period.Start(from, s.location)
+	}
+	if query.From != nil {
+		from = query.Period.Start(*query.From, s.location)
+	}
+	if !from.Before(to) {
+		return fmt.Errorf("%w: from must be before to", domain.ErrInvalidInput)
+	}
+	periods := 0
+	for start := from; start.Before(to); start = query.Period.Next(start) {
+		if periods++; periods > maxStatsPeriods {
+			return fmt.Errorf("%w: at most %d periods can be requested", domain.ErrInvalidInput, maxStatsPeriods)
+		}
+	}
+	// Границы приводятся к UTC, чтобы одинаковые запросы попадали в один отчёт кэша
+	from, to = from.UTC(), to.UTC()
+	query.From, query.To = &from, &to
+
+	switch {
+	case query.Cohorts <= 0:
+		query.Cohorts = defaultStatsCohorts
+	case query.Cohorts > maxStatsCohorts:
+		query.Cohorts = maxStatsCohorts
+	}
+	if query.TopDistricts <= 0 || query.TopDistricts > domain.MaxPageLimit {
+		query.TopDistricts = defaultTopDistricts
+	}
+	return nil
+}
+
+// report вызывается под cache.mu; query уже нормализован
+func (s *UserStatsServiceImpl) report(cache *tenantStatsCache, query domain.TenantStatsQuery) *domain.TenantStats {
+	stats := &domain.TenantStats{
+		Totals:     map[domain.ActionType]int64{},
+		Users:      len(cache.users),
+		Period:     query.Period,
+		Timezone:   s.location.String(),
+		ComputedAt: time.Now().UTC(),
+	}
+	for action, count := range cache.totals {
+		stats.Totals[action] = count
+		stats.Total += count
+	}
+
+	from, to := query.From.In(s.location), query.To.In(s.location)
+	buckets := map[int64]map[uuid.UUID]struct{}{}
+	// Первый и все активные месяцы каждого жителя — основа когорт удержания
+	firstMonth := map[uuid.UUID]time.Time{}
+	activeMonths := map[uuid.UUID]map[int64]struct{}{}
+	districtUsers := map[string]*domain.DistrictActivity{}
+
+	for userID, activity := range cache.users {
+		activeMonths[userID] = map[int64]struct{}{}
+		var actions int64
+		for day, count := range activity.days {
+			start := time.Unix(day, 0).In(s.location)
+			actions += count
+
+			month := domain.PeriodMonth.Start(start, s.location)
+			activeMonths[userID][month.Unix()] = struct{}{}
+			if first, ok := firstMonth[userID]; !ok || month.Before(first) {
+				firstMonth[userID] = month
+			}
+
+			if !start.Before(from) && start.Before(to) {
+				bucket := query.Period.Start(start, s.location).Unix()
+				if buckets[bucket] == nil {
+					buckets[bucket] = map[uuid.UUID]struct{}{}
+				}
+				buckets[bucket][userID] = struct{}{}
+			}
+		}
+
+		if district := cache.districts[userID]; district != "" {
+			if districtUsers[district] == nil {
+				districtUsers[district] = &domain.DistrictActivity{District: district}
+			}
+			districtUsers[district].Users++
+			districtUsers[district].Actions += actions
+		}
+	}
+
+	stats.ActiveUsers = []domain.ActiveUsersBucket{}
+	for start := from; start.Before(to); start = query.Period.Next(start) {
+		stats.ActiveUsers = append(stats.ActiveUsers, domain.ActiveUsersBucket{Start: start, Users: len(buckets[start.Unix()])})
+	}
+
+	stats.Cohorts = retentionCohorts(firstMonth, activeMonths, query.Cohorts, s.location)
+
+	stats.Districts = make([]domain.DistrictActivity, 0, len(districtUsers))
+	for _, district := range districtUsers {
+		stats.Districts = append(stats.Districts, *district)
+	}
+	sort.Slice(stats.Districts, func(i, j int) bool {
+		a, b := stats.Districts[i], stats.Districts[j]
+		if a.Actions != b.Actions {
+			return a.Actions > b.Actions
+		}
+		return a.District < b.District
+	})
+	if len(stats.Districts) > query.TopDistricts {
+		stats.Districts = stats.Districts[:query.TopDistricts]
+	}
+	return stats
+}
+
+// retentionCohorts строит когорты за последние count месяцев, включая текущий
+func retentionCohorts(firstMonth map[uuid.UUID]time.Time, activeMonths map[uuid.UUID]map[int64]struct{}, count int, loc *time.Location) []domain.RetentionCohort {
+	current := domain.PeriodMonth.Start(time.Now(), loc)
+	cohorts := make([]domain.RetentionCohort, count)
+	for i := range cohorts {
+		month := current.AddDate(0, i-count+1, 0)
+		cohorts[i] = domain.RetentionCohort{Month: month, Active: make([]int, count-i)}
+	}
+
+	for userID, first := range firstMonth {
+		index := -1
+		for i := range cohorts {
+			if cohorts[i].Month.Equal(first) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+		cohort := &cohorts[index]
+		cohort.Size++
+		for offset := range cohort.Active {
+			if _, ok := activeMonths[userID][cohort.Month.AddDate(0, offset, 0).Unix()]; ok {
+				cohort.Active[offset]++
+			}
+		}
+	}
+	return cohorts
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"user-service/internal/domain"
+)
+
+// recordAction сохраняет действие в базе и, если notify, сообщает о нём статистике, как это делает actionService
+func (f *fixture) recordAction(t *testing.T, stats domain.UserStatsService, user *domain.User, at time.Time, notify bool) {
+	t.Helper()
+	action := f.action(user, domain.ActionWasteSorted, at)
+	if err := f.Users.RecordUserAction(context.Background(), &action); err != nil {
+		t.Fatalf("RecordUserAction: %v", err)
+	}
+	if notify {
+		stats.ActionRecorded(context.Background(), action)
+	}
+}
+
+func userTotal(t *testing.T, stats domain.UserStatsService, user *domain.User) int64 {
+	t.Helper()
+	summary, err := stats.GetUserStats(actorContext(user), user.TenantID, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserStats: %v", err)
+	}
+	return summary.Total
+}
+
+// Пересборка считает действия до своей отсечки, ActionRecorded — после неё: каждое действие учитывается один раз
+func TestUserStatsRebuildAndLiveUpdates(t *testing.T) {
+	f := newFixture(t)
+	service := NewUserStatsService(f.Users, time.UTC)
+	stats := service.(*UserStatsServiceImpl)
+	user := f.mustCreateUser(t, "resident@example.com")
+
+	// Событие до первого построения кэша ничего не добавляет: действие придёт из базы
+	f.recordAction(t, stats, user, time.Now().Add(-time.Minute), true)
+	if got := userTotal(t, stats, user); got != 1 {
+		t.Fatalf("total after the first build = %d, want 1", got)
+	}
+
+	f.recordAction(t, stats, user, time.Now(), true)
+	if got := userTotal(t, stats, user); got != 2 {
+		t.Fatalf("total after a live action = %d, want 2", got)
+	}
+
+	// Запоздавшее событие о действии до отсечки уже посчитано пересборкой
+	f.recordAction(t, stats, user, time.Now().Add(-2*time.Minute), false)
+	stale := f.action(user, domain.ActionWasteSorted, time.Now().Add(-3*time.Minute))
+	stats.ActionRecorded(context.Background(), stale)
+	if got := userTotal(t, stats, user); got != 2 {
+		t.Fatalf("total after a stale event = %d, want 2", got)
+	}
+
+	// Очередная пересборка подхватывает действие, о котором событие так и не пришло
+	stats.tenants[f.tenant.ID].builtAt = time.Now().Add(-statsRebuildInterval)
+	if got := userTotal(t, stats, user); got != 3 {
+		t.Fatalf("total after the rebuild = %d, want 3", got)
+	}
+}
+
+// Отчёт тенанта берётся из кэша, пока не учтено новое действие
+func TestTenantStatsReportCache(t *testing.T) {
+	f := newFixture(t)
+	stats := NewUserStatsService(f.Users, time.UTC)
+	user := f.mustCreateUser(t, "resident@example.com")
+	f.recordAction(t, stats, user, time.Now().Add(-time.Minute), false)
+
+	first, err := stats.GetTenantStats(context.Background(), f.tenant.ID, domain.TenantStatsQuery{})
+	if err != nil {
+		t.Fatalf("GetTenantStats: %v", err)
+	}
+	again, err := stats.GetTenantStats(context.Background(), f.tenant.ID, domain.TenantStatsQuery{})
+	if err != nil {
+		t.Fatalf("GetTenantStats: %v", err)
+	}
+	if again != first {
+		t.Fatalf("unchanged tenant stats were recomputed")
+	}
+
+	f.recordAction(t, stats, user, time.Now(), true)
+	updated, err := stats.GetTenantStats(context.Background(), f.tenant.ID, domain.TenantStatsQuery{})
+	if err != nil {
+		t.Fatalf("GetTenantStats: %v", err)
+	}
+	if updated.Total != 2 || first.Total != 1 {
+		t.Fatalf("tenant totals = %d then %d, want 1 then 2", first.Total, updated.Total)
+	}
+}
@@ -0,0 +1,194 @@
+// Package validation проверяет тела запросов по тегам `validate` и возвращает ошибки по полям.
+//
+// Правила перечисляются через запятую:
+//
+//	required        — строка не пустая после обрезки пробелов, указатель не nil
+//	required_with=f — обязательно, если соседнее поле с json-именем f задано
+//	min=N, max=N    — для строк длина в символах, для чисел границы значения
+//	email           — адрес вида user@example.org без отображаемого имени
+//	uuid            — строка в формате UUID
+//	oneof=a b       — одно из перечисленных значений
+//
+// Пустые необязательные строки и nil-указатели остальными правилами не проверяются.
+// Вложенные структуры и указатели на них проверяются рекурсивно, имя поля берётся из тега json.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError — нарушение правила в одном поле; Field — путь через точку, как в JSON
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors — все нарушения в запросе в порядке объявления полей
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Struct проверяет структуру или указатель на неё; при нарушениях возвращает Errors
+func Struct(value any) error {
+	var errs Errors
+	validateStruct(reflect.Indirect(reflect.ValueOf(value)), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, errs *Errors) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fieldValue := value.Field(i)
+		if message := checkRules(value, fieldValue, field.Tag.Get("validate")); message != "" {
+			*errs = append(*errs, FieldError{Field: name, Message: message})
+			continue
+		}
+		validateStruct(reflect.Indirect(fieldValue), name, errs)
+	}
+}
+
+// checkRules возвращает описание первого нарушенного правила; parent нужен для required_with
+func checkRules(parent, value reflect.Value, tag string) string {
+	if tag == "" {
+		return ""
+	}
+	rules := strings.Split(tag, ",")
+
+	if isEmpty(value) {
+		for _, rule := range rules {
+			name, arg, _ := strings.Cut(rule, "=")
+			if name == "required" {
+				return "is required"
+			}
+			if name == "required_with" && !isEmpty(siblingField(parent, arg)) {
+				return "is required when " + arg + " is set"
+			}
+		}
+		return ""
+	}
+	value = reflect.Indirect(value)
+
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required", "required_with":
+		case "min", "max":
+			if message := checkBound(value, name, arg); message != "" {
+				return message
+			}
+		case "email":
+			s := value.String()
+			if parsed, err := mail.ParseAddress(s); err != nil || parsed.Address != s {
+				return "must be a valid email address"
+			}
+		case "uuid":
+			if _, err := uuid.Parse(value.String()); err != nil {
+				return "must be a UUID"
+			}
+		case "oneof":
+			allowed := strings.Fields(arg)
+			if !containsRule(allowed, value.String()) {
+				return "must be one of: " + strings.Join(allowed, ", ")
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q", rule))
+		}
+	}
+	return ""
+}
+
+// checkBound сравнивает длину строки или значение числа с границей правила min или max
+func checkBound(value reflect.Value, rule, arg string) string {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: bad %s rule %q", rule, arg))
+	}
+
+	var actual float64
+	var unit string
+	switch value.Kind() {
+	case reflect.String:
+		actual, unit = float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return ""
+	}
+
+	switch {
+	case rule == "min" && actual < limit:
+		return fmt.Sprintf("must be at least %s%s", arg, unit)
+	case rule == "max" && actual > limit:
+		return fmt.Sprintf("must be at most %s%s", arg, unit)
+	}
+	return ""
+}
+
+// isEmpty: nil-указатель или строка из одних пробелов; числа и структуры пустыми не считаются
+func isEmpty(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	if value.Kind() == reflect.Pointer {
+		return value.IsNil()
+	}
+	return value.Kind() == reflect.String && strings.TrimSpace(value.String()) == ""
+}
+
+func siblingField(parent reflect.Value, name string) reflect.Value {
+	for i := 0; i < parent.NumField(); i++ {
+		if jsonName(parent.Type().Field(i)) == name {
+			return parent.Field(i)
+		}
+	}
+	panic(fmt.Sprintf("validation: unknown field %q in required_with", name))
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, candidate := range rules {
+		if candidate == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string   `json:"city" validate:"required,max=5"`
+	Lat  *float64 `json:"lat" validate:"required_with=lon,min=-90,max=90"`
+	Lon  *float64 `json:"lon" validate:"required_with=lat,min=-180,max=180"`
+}
+
+type testRequest struct {
+	Email    string       `json:"email" validate:"required,email"`
+	Name     string       `json:"name" validate:"min=2,max=4"`
+	Role     string       `json:"role" validate:"oneof=user admin"`
+	Key      string       `json:"key" validate:"uuid"`
+	Count    int          `json:"count" validate:"min=1,max=10"`
+	Address  *testAddress `json:"address"`
+	Internal string       `json:"-" validate:"required"`
+	NoTag    string
+}
+
+func TestStruct(t *testing.T) {
+	valid := func() testRequest {
+		return testRequest{Email: "a@example.com", Count: 1}
+	}
+	lat, lon, far := 43.2, 76.9, 200.0
+
+	tests := []struct {
+		name   string
+		modify func(r *testRequest)
+		want   []FieldError
+	}{
+		{name: "valid", modify: func(*testRequest) {}},
+		{name: "required blank string", modify: func(r *testRequest) { r.Email = "  " }, want: []FieldError{{"email", "is required"}}},
+		{name: "email with display name", modify: func(r *testRequest) { r.Email = "A <a@example.com>" }, want: []FieldError{{"email", "must be a valid email address"}}},
+		{name: "length counts characters", modify: func(r *testRequest) { r.Name = "Äлія" }},
+		{name: "too short", modify: func(r *testRequest) { r.Name = "Я" }, want: []FieldError{{"name", "must be at least 2 characters"}}},
+		{name: "too long", modify: func(r *testRequest) { r.Name = "Alice" }, want: []FieldError{{"name", "must be at most 4 characters"}}},
+		{name: "oneof", modify: func(r *testRequest) { r.Role = "root" }, want: []FieldError{{"role", "must be one of: user, admin"}}},
+		{name: "uuid", modify: func(r *testRequest) { r.Key = "not-a-uuid" }, want: []FieldError{{"key", "must be a UUID"}}},
+		{name: "number bounds", modify: func(r *testRequest) { r.Count = 11 }, want: []FieldError{{"count", "must be at most 10"}}},
+		{name: "zero number is checked", modify: func(r *testRequest) { r.Count = 0 }, want: []FieldError{{"count", "must be at least 1"}}},
+		{
+			name:   "nested struct uses the json path",
+			modify: func(r *testRequest) { r.Address = &testAddress{City: "Almaty"} },
+			want:   []FieldError{{"address.city", "must be at most 5 characters"}},
+		},
+		{
+			name:   "required_with",
+			modify: func(r *testRequest) { r.Address = &testAddress{City: "Oral", Lat: &lat} },
+			want:   []FieldError{{"address.lon", "is required when lat is set"}},
+		},
+		{name: "pair is valid", modify: func(r *testRequest) { r.Address = &testAddress{City: "Oral", Lat: &lat, Lon: &lon} }},
+		{
+			name:   "pointer value bounds",
+			modify: func(r *testRequest) { r.Address = &testAddress{City: "Oral", Lat: &far, Lon: &lon} },
+			want:   []FieldError{{"address.lat", "must be at most 90"}},
+		},
+		{
+			name: "all errors in field order",
+			modify: func(r *testRequest) {
+				r.Email, r.Role, r.Address = "", "root", &testAddress{}
+			},
+			want: []FieldError{{"email", "is required"}, {"role", "must be one of: user, admin"}, {"address.city", "is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := valid()
+			tt.modify(&request)
+
+			err := Struct(&request)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Struct = %v, want no error", err)
+				}
+				return
+			}
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Struct = %v, want Errors", err)
+			}
+			if len(errs) != len(tt.want) {
+				t.Fatalf("Struct = %v, want %v", errs, tt.want)
+			}
+			for i := range errs {
+				if errs[i] != tt.want[i] {
+					t.Errorf("error %d = %+v, want %+v", i, errs[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "unknown rule") {
+			t.Fatalf("recover() = %v, want a panic about the unknown rule", r)
+		}
+	}()
+	Struct(struct {
+		Name string `json:"name" validate:"regexp"`
+	}{Name: "x"})
+}